@@ -0,0 +1,5 @@
+CREATE TABLE orders (
+    id SERIAL PRIMARY KEY,
+    customer TEXT NOT NULL,
+    total NUMERIC(10, 2) NOT NULL
+);
@@ -0,0 +1,3 @@
+INSERT INTO orders (customer, total) VALUES
+    ('Jane Smith', 42.50),
+    ('John Doe', 19.99);
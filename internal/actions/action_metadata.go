@@ -0,0 +1,854 @@
+package actions
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/JianLoong/robogo/internal/types"
+)
+
+// ArgSpec describes one positional argument or option of an action
+type ArgSpec struct {
+	Name        string
+	Type        string // "string", "number", "bool", "object", "array" or "any"
+	Required    bool
+	Sensitive   bool // masked in printed steps and result messages
+	Description string
+}
+
+// ActionMetadata documents an action for discovery, completion and argument validation
+type ActionMetadata struct {
+	Name        string
+	Category    string
+	Description string
+	Args        []ArgSpec
+	Variadic    bool // the last argument may repeat
+	Options     []ArgSpec
+	Example     string
+	ReadOnly    func(args []any) bool // whether a call with these arguments only reads, so a step's cache may reuse its result; nil if it never does
+}
+
+// readOnlyHTTP reports whether an http call only reads: GET and HEAD requests
+func readOnlyHTTP(args []any) bool {
+	if len(args) == 0 {
+		return false
+	}
+	method := fmt.Sprintf("%v", args[0])
+	return strings.EqualFold(method, "GET") || strings.EqualFold(method, "HEAD")
+}
+
+// readOnlySQL reports whether a postgres or sql call only reads: a query whose statement
+// is a SELECT, or a row_exists or count check
+func readOnlySQL(args []any) bool {
+	if len(args) == 0 {
+		return false
+	}
+	switch strings.ToLower(fmt.Sprintf("%v", args[0])) {
+	case "row_exists", "count":
+		return true
+	case "query", "select":
+		if len(args) < 3 {
+			return false
+		}
+		words := strings.Fields(fmt.Sprintf("%v", args[2]))
+		return len(words) > 0 && strings.EqualFold(words[0], "SELECT")
+	}
+	return false
+}
+
+// cassandraArgs and cassandraOptions are shared by the cassandra and scylla actions
+var (
+	cassandraArgs = []ArgSpec{
+		{Name: "contact_points", Type: "string", Required: true, Description: "host[:port], comma-separated or a list"},
+		{Name: "statement", Type: "string", Required: true, Description: "CQL statement, with ? markers for params"},
+	}
+	cassandraOptions = []ArgSpec{
+		{Name: "params", Type: "array", Description: "Values bound to the ? markers"},
+		{Name: "keyspace", Type: "string"},
+		{Name: "consistency", Type: "string", Description: "one, quorum, local_quorum, all, ... (default quorum)"},
+		{Name: "username", Type: "string"},
+		{Name: "password", Type: "string", Sensitive: true},
+		{Name: "timeout", Type: "string", Description: "Duration such as \"10s\" (default 30s)"},
+	}
+)
+
+// builtinActionMetadata returns the documentation for every built-in action
+func builtinActionMetadata() []ActionMetadata {
+	timeoutOption := ArgSpec{Name: "timeout", Type: "string", Description: "Duration such as \"30s\""}
+
+	return []ActionMetadata{
+		// Core actions
+		{
+			Name:        "assert",
+			Category:    "core",
+			Description: "Compare two values, check a single boolean, or check the order of collected messages",
+			Args: []ArgSpec{
+				{Name: "actual", Type: "any", Required: true, Description: "Value under test, a boolean on its own, or a message list for ordered"},
+				{Name: "operator", Type: "string", Description: "==, !=, >, <, >=, <=, contains, ordered"},
+				{Name: "expected", Type: "any", Description: "Value to compare against, or a list of message matchers for ordered"},
+				{Name: "message", Type: "string", Description: "Note for readers of the test; not used by the comparison"},
+			},
+			Options: []ArgSpec{
+				{Name: "partial", Type: "bool", Description: "With ordered, allow other messages between the expected ones (default: false)"},
+			},
+			Example: "action: assert\nargs: [\"${status}\", \"==\", \"200\"]",
+		},
+		{
+			Name:        "log",
+			Category:    "core",
+			Description: "Print a message; multiple arguments are joined with spaces",
+			Args:        []ArgSpec{{Name: "message", Type: "any", Required: true}},
+			Variadic:    true,
+			Example:     "action: log\nargs: [\"User ID:\", \"${user_id}\"]",
+		},
+		{
+			Name:        "summary",
+			Category:    "core",
+			Description: "Record a named annotation for the test and suite summaries and --report; numbers are added up across test cases",
+			Args: []ArgSpec{
+				{Name: "name", Type: "string", Required: true},
+				{Name: "value", Type: "any", Required: true},
+			},
+			Options: []ArgSpec{
+				{Name: "add", Type: "bool", Description: "Add a number to the annotation instead of replacing it"},
+			},
+			Example: "action: summary\nargs: [\"orders_created\", \"${order_count}\"]",
+		},
+		{
+			Name:        "variable",
+			Category:    "core",
+			Description: "Set a variable",
+			Args: []ArgSpec{
+				{Name: "name", Type: "string", Required: true},
+				{Name: "value", Type: "any", Required: true},
+			},
+			Options: []ArgSpec{
+				{Name: "operation", Type: "string", Description: "set (default) or set_if_absent to keep an existing value"},
+			},
+			Example: "action: variable\nargs: [\"base_url\", \"https://api.example.com\"]",
+		},
+
+		// Utility actions
+		{
+			Name:        "uuid",
+			Category:    "utility",
+			Description: "Generate a random (v4) UUID",
+			Example:     "action: uuid\nresult: request_id",
+		},
+		{
+			Name:        "time",
+			Category:    "utility",
+			Description: "Current time, RFC3339 unless a format is given",
+			Args:        []ArgSpec{{Name: "format", Type: "string", Description: "Go time layout, or \"Unix\" for epoch seconds"}},
+			Example:     "action: time\nargs: [\"2006-01-02\"]\nresult: today",
+		},
+		{
+			Name:        "sleep",
+			Category:    "utility",
+			Description: "Pause the test",
+			Args:        []ArgSpec{{Name: "duration", Type: "string", Required: true, Description: "Duration such as \"500ms\" or \"2s\""}},
+			Example:     "action: sleep\nargs: [\"2s\"]",
+		},
+		{
+			Name:        "ping",
+			Category:    "utility",
+			Description: "ICMP ping a host",
+			Args:        []ArgSpec{{Name: "host", Type: "string", Required: true}},
+			Options: []ArgSpec{
+				{Name: "count", Type: "number", Description: "Number of packets"},
+				timeoutOption,
+			},
+			Example: "action: ping\nargs: [\"example.com\"]\noptions:\n  count: 3",
+		},
+		{
+			Name:        "tcp_connect",
+			Category:    "utility",
+			Description: "Check that a TCP port accepts connections",
+			Args: []ArgSpec{
+				{Name: "host", Type: "string", Required: true},
+				{Name: "port", Type: "number", Required: true},
+			},
+			Options: []ArgSpec{timeoutOption},
+			Example: "action: tcp_connect\nargs: [\"localhost\", 5432]",
+		},
+		{
+			Name:        "health_check",
+			Category:    "utility",
+			Description: "Poll an HTTP health endpoint or gRPC health service until it is healthy",
+			Args: []ArgSpec{
+				{Name: "target", Type: "string", Required: true, Description: "http(s)://host/path, or grpc://host:port (grpcs:// for TLS)"},
+			},
+			Options: []ArgSpec{
+				{Name: "timeout", Type: "string", Description: "How long to keep polling (default \"30s\")"},
+				{Name: "interval", Type: "string", Description: "Delay between attempts (default \"1s\")"},
+				{Name: "expected_status", Type: "number", Description: "HTTP status that counts as healthy (default any 2xx)"},
+				{Name: "service", Type: "string", Description: "gRPC service name (default the whole server)"},
+				{Name: "skip_tls_verify", Type: "bool"},
+			},
+			Example: "action: health_check\nargs: [\"http://localhost:8000/status/200\"]\noptions:\n  timeout: \"60s\"",
+		},
+
+		// Security actions
+		{
+			Name:        "ssl_cert_check",
+			Category:    "security",
+			Description: "Inspect a server's TLS certificate and its expiry",
+			Args:        []ArgSpec{{Name: "host", Type: "string", Required: true, Description: "hostname or hostname:port"}},
+			Options:     []ArgSpec{timeoutOption},
+			Example:     "action: ssl_cert_check\nargs: [\"example.com\"]",
+		},
+		{
+			Name:        "ldap",
+			Category:    "security",
+			Description: "Bind to an LDAP directory and search, compare, add, modify or delete entries",
+			Args: []ArgSpec{
+				{Name: "operation", Type: "string", Required: true, Description: "bind, search, add, modify, delete or compare"},
+				{Name: "url", Type: "string", Required: true, Description: "ldap://host[:port] or ldaps://host[:port]"},
+				{Name: "dn", Type: "string", Description: "Search base, or the entry to add, modify, delete or compare; not used by bind"},
+			},
+			Options: []ArgSpec{
+				{Name: "bind_dn", Type: "string", Description: "Simple bind user (anonymous when not set)"},
+				{Name: "bind_password", Type: "string", Sensitive: true},
+				{Name: "start_tls", Type: "bool", Description: "Upgrade an ldap:// connection with StartTLS"},
+				{Name: "skip_tls_verify", Type: "bool"},
+				{Name: "filter", Type: "string", Description: "Search filter (default (objectClass=*))"},
+				{Name: "attributes", Type: "any", Description: "Attribute names to return for search, or a map of attribute values for add"},
+				{Name: "scope", Type: "string", Description: "base, one or sub (default sub)"},
+				{Name: "max_results", Type: "number", Description: "Entries to return, read in pages (default 1000)"},
+				{Name: "page_size", Type: "number", Description: "Entries per page (default 500)"},
+				{Name: "changes", Type: "object", Description: "For modify: {add|replace|delete: {attribute: value(s)}}"},
+				{Name: "attribute", Type: "string", Description: "Attribute for compare"},
+				{Name: "value", Type: "string", Description: "Value for compare"},
+				timeoutOption,
+			},
+			Example: "action: ldap\nargs: [\"search\", \"ldaps://ldap.example.com\", \"ou=people,dc=example,dc=com\"]\noptions:\n  bind_dn: \"cn=admin,dc=example,dc=com\"\n  bind_password: \"${ENV:LDAP_PASSWORD}\"\n  filter: \"(uid=alice)\"\n  attributes: [cn, mail]",
+		},
+
+		// Encoding actions
+		{
+			Name:        "base64_encode",
+			Category:    "encoding",
+			Description: "Base64-encode a string",
+			Args:        []ArgSpec{{Name: "data", Type: "string", Required: true}},
+			Example:     "action: base64_encode\nargs: [\"user:pass\"]",
+		},
+		{
+			Name:        "base64_decode",
+			Category:    "encoding",
+			Description: "Decode a base64 string",
+			Args:        []ArgSpec{{Name: "data", Type: "string", Required: true}},
+			Example:     "action: base64_decode\nargs: [\"dXNlcjpwYXNz\"]",
+		},
+		{
+			Name:        "url_encode",
+			Category:    "encoding",
+			Description: "Percent-encode a string for use in a URL query",
+			Args:        []ArgSpec{{Name: "data", Type: "string", Required: true}},
+			Example:     "action: url_encode\nargs: [\"a b&c\"]",
+		},
+		{
+			Name:        "url_decode",
+			Category:    "encoding",
+			Description: "Decode a percent-encoded string",
+			Args:        []ArgSpec{{Name: "data", Type: "string", Required: true}},
+			Example:     "action: url_decode\nargs: [\"a+b%26c\"]",
+		},
+		{
+			Name:        "hash",
+			Category:    "encoding",
+			Description: "Hash a string",
+			Args: []ArgSpec{
+				{Name: "data", Type: "string", Required: true},
+				{Name: "algorithm", Type: "string", Required: true, Description: "md5, sha1, sha256 or sha512"},
+			},
+			Example: "action: hash\nargs: [\"hello\", \"sha256\"]",
+		},
+
+		// File actions
+		{
+			Name:        "file_read",
+			Category:    "file",
+			Description: "Read a local file, parsing JSON, YAML or CSV by extension",
+			Args:        []ArgSpec{{Name: "path", Type: "string", Required: true}},
+			Options:     []ArgSpec{{Name: "format", Type: "string", Description: "json, yaml, csv or text; overrides the detected format"}},
+			Example:     "action: file_read\nargs: [\"testdata/users.json\"]",
+		},
+		{
+			Name:        "golden",
+			Category:    "file",
+			Description: "Compare a value to a golden file, showing a unified diff on mismatch; --update-golden rewrites the file",
+			Args: []ArgSpec{
+				{Name: "actual", Type: "any", Required: true, Description: "Value to check; maps, lists and JSON strings are compared as JSON"},
+				{Name: "golden_path", Type: "string", Required: true, Description: "File holding the expected output"},
+			},
+			Options: []ArgSpec{
+				{Name: "format", Type: "string", Description: "json or text; overrides the detected format"},
+				{Name: "ignore_paths", Type: "array", Description: "JSON paths left out of the comparison, e.g. items.*.created_at"},
+			},
+			Example: "action: golden\nargs: [\"${response.body}\", \"testdata/golden/users.json\"]\noptions:\n  ignore_paths: [\"generated_at\"]",
+		},
+		{
+			Name:        "scp",
+			Category:    "file",
+			Description: "Upload or download a file over SSH",
+			Args: []ArgSpec{
+				{Name: "operation", Type: "string", Required: true, Description: "upload or download"},
+				{Name: "host", Type: "string", Required: true, Description: "[user@]host[:port]"},
+				{Name: "local_path", Type: "string", Required: true},
+				{Name: "remote_path", Type: "string", Required: true},
+			},
+			Options: []ArgSpec{
+				{Name: "username", Type: "string"},
+				{Name: "password", Type: "string"},
+				{Name: "private_key", Type: "string", Description: "Path to a private key file"},
+				timeoutOption,
+			},
+			Example: "action: scp\nargs: [\"upload\", \"deploy@server:22\", \"./app.tar\", \"/tmp/app.tar\"]",
+		},
+		{
+			Name:        "attach",
+			Category:    "file",
+			Description: "Attach a file or value to the step result, written to the --artifacts directory when the test case ends",
+			Args: []ArgSpec{
+				{Name: "name", Type: "string", Required: true, Description: "File name of the artifact"},
+				{Name: "content", Type: "any", Description: "Value to attach; non-strings are written as JSON"},
+			},
+			Options: []ArgSpec{
+				{Name: "path", Type: "string", Description: "File to attach instead of a value, e.g. a screenshot or download"},
+				{Name: "content_type", Type: "string", Description: "Overrides the type detected from the name"},
+			},
+			Example: "action: attach\nargs: [\"orders.json\", \"${orders}\"]",
+		},
+
+		// String actions
+		{
+			Name:        "string_random",
+			Category:    "string",
+			Description: "Generate a random string",
+			Args: []ArgSpec{
+				{Name: "length", Type: "number", Required: true},
+				{Name: "charset", Type: "string", Description: "alphanumeric (default), alphabetic, lowercase, uppercase, numeric, hex, special, all or custom"},
+			},
+			Options: []ArgSpec{{Name: "custom_chars", Type: "string", Description: "Characters to use with the custom charset"}},
+			Example: "action: string_random\nargs: [8, \"numeric\"]",
+		},
+		{
+			Name:        "string_replace",
+			Category:    "string",
+			Description: "Replace every occurrence of a substring",
+			Args: []ArgSpec{
+				{Name: "text", Type: "string", Required: true},
+				{Name: "old", Type: "string", Required: true},
+				{Name: "new", Type: "string", Required: true},
+			},
+			Example: "action: string_replace\nargs: [\"${text}\", \"foo\", \"bar\"]",
+		},
+		{
+			Name:        "regex_replace",
+			Category:    "string",
+			Description: "Replace regular expression matches; use $1 for capture groups",
+			Args: []ArgSpec{
+				{Name: "text", Type: "string", Required: true},
+				{Name: "pattern", Type: "string", Required: true},
+				{Name: "replacement", Type: "string", Required: true},
+			},
+			Example: "action: regex_replace\nargs: [\"2024-01-31\", \"(\\\\d+)-(\\\\d+)-(\\\\d+)\", \"$3/$2/$1\"]",
+		},
+		{
+			Name:        "string_format",
+			Category:    "string",
+			Description: "Fill {} placeholders in order",
+			Args: []ArgSpec{
+				{Name: "template", Type: "string", Required: true},
+				{Name: "value", Type: "any"},
+			},
+			Variadic: true,
+			Example:  "action: string_format\nargs: [\"Order {}: {} items\", \"${order_id}\", 3]",
+		},
+		{
+			Name:        "string",
+			Category:    "string",
+			Description: "Convert a value to a string",
+			Args:        []ArgSpec{{Name: "value", Type: "any", Required: true}},
+			Example:     "action: string\nargs: [\"${count}\"]",
+		},
+
+		// Data processing actions
+		{
+			Name:        "jq",
+			Category:    "data",
+			Description: "Run a jq query against JSON data",
+			Args: []ArgSpec{
+				{Name: "data", Type: "any", Required: true},
+				{Name: "query", Type: "string", Required: true},
+			},
+			Example: "action: jq\nargs: [\"${response}\", \".body.id\"]",
+		},
+		{
+			Name:        "xpath",
+			Category:    "data",
+			Description: "Run an XPath query against an XML string",
+			Args: []ArgSpec{
+				{Name: "xml", Type: "string", Required: true},
+				{Name: "query", Type: "string", Required: true},
+			},
+			Options: []ArgSpec{
+				{Name: "multiple", Type: "bool", Description: "Return every match instead of the first"},
+				{Name: "namespaces", Type: "object", Description: "Prefix to namespace URI map for prefixed queries like //ns:Item"},
+			},
+			Example: "action: xpath\nargs: [\"${xml}\", \"//order/id\"]",
+		},
+		{
+			Name:        "json_parse",
+			Category:    "data",
+			Description: "Parse a JSON string into structured data",
+			Args:        []ArgSpec{{Name: "json", Type: "string", Required: true}},
+			Example:     "action: json_parse\nargs: ['{\"id\": 1}']",
+		},
+		{
+			Name:        "json_build",
+			Category:    "data",
+			Description: "Build JSON data from the arguments or from the options map",
+			Args:        []ArgSpec{{Name: "data", Type: "any"}},
+			Variadic:    true,
+			Options:     []ArgSpec{{Name: "format", Type: "string", Description: "\"string\" returns a JSON string instead of data"}},
+			Example:     "action: json_build\noptions:\n  name: \"${user_name}\"\n  active: true",
+		},
+		{
+			Name:        "xml_parse",
+			Category:    "data",
+			Description: "Parse an XML string into structured data",
+			Args:        []ArgSpec{{Name: "xml", Type: "string", Required: true}},
+			Example:     "action: xml_parse\nargs: [\"${xml}\"]",
+		},
+		{
+			Name:        "xml_build",
+			Category:    "data",
+			Description: "Build XML from an argument or from the options map",
+			Args:        []ArgSpec{{Name: "data", Type: "any", Description: "Several arguments are wrapped in an items element"}},
+			Variadic:    true,
+			Example:     "action: xml_build\noptions:\n  order:\n    id: \"${order_id}\"",
+		},
+		{
+			Name:        "csv_parse",
+			Category:    "data",
+			Description: "Parse CSV from a file path or a string",
+			Args:        []ArgSpec{{Name: "source", Type: "string", Required: true, Description: "File path or CSV content"}},
+			Example:     "action: csv_parse\nargs: [\"testdata/users.csv\"]",
+		},
+
+		// HTTP actions
+		{
+			Name:        "http",
+			Category:    "http",
+			Description: "Send an HTTP request",
+			Args: []ArgSpec{
+				{Name: "method", Type: "string", Required: true},
+				{Name: "url", Type: "string", Required: true},
+				{Name: "body", Type: "any", Description: "Request body; maps are sent as JSON"},
+			},
+			Options: []ArgSpec{
+				{Name: "headers", Type: "object"},
+				{Name: "multipart", Type: "object", Description: "fields and files for multipart/form-data uploads"},
+				{Name: "xml_body", Type: "object", Description: "Map with a single root element, sent as an XML document"},
+				{Name: "body_file", Type: "string", Description: "Send the contents of a file as the body, with variables substituted"},
+				{Name: "body_file_raw", Type: "string", Description: "Send the bytes of a file unchanged, e.g. binary payloads"},
+				{Name: "correlation_header", Type: "string", Description: "Header carrying ${correlation_id} (default X-Correlation-Id, or ROBOGO_CORRELATION_HEADER); empty to omit"},
+				{Name: "idempotency_key", Type: "any", Description: "true to send ${step.idempotency_key} as Idempotency-Key, or the header name to send it in; retries send the same key"},
+				{Name: "skip_tls_verify", Type: "bool"},
+				{Name: "max_body_size", Type: "number", Description: "Fail instead of buffering a response body larger than this many bytes"},
+				timeoutOption,
+			},
+			Example:  "action: http\nargs: [\"GET\", \"https://httpbin.org/json\"]\nresult: response",
+			ReadOnly: readOnlyHTTP,
+		},
+		{
+			Name:        "compare_response",
+			Category:    "http",
+			Description: "Send two HTTP requests and fail when their status codes or bodies differ",
+			Options: []ArgSpec{
+				{Name: "request_a", Type: "object", Required: true, Description: "method (default GET), url, body and http options such as headers"},
+				{Name: "request_b", Type: "object", Required: true, Description: "Request to compare against request_a"},
+				{Name: "ignore_paths", Type: "array", Description: "JSON paths left out of the body comparison; * matches any key or index"},
+				{Name: "ignore_status", Type: "bool", Description: "Compare bodies only"},
+			},
+			Example: "action: compare_response\noptions:\n  request_a: {url: \"${old_api}/users/1\"}\n  request_b: {url: \"${new_api}/users/1\"}\n  ignore_paths: [\"updated_at\"]",
+		},
+		{
+			Name:        "sse",
+			Category:    "http",
+			Description: "Collect events from a Server-Sent Events stream",
+			Args:        []ArgSpec{{Name: "url", Type: "string", Required: true}},
+			Options: []ArgSpec{
+				{Name: "count", Type: "number", Description: "Events to collect"},
+				{Name: "headers", Type: "object"},
+				{Name: "last_event_id", Type: "string", Description: "Resume the stream after this event"},
+				timeoutOption,
+			},
+			Example: "action: sse\nargs: [\"https://example.com/events\"]\noptions:\n  count: 3",
+		},
+
+		// Database actions
+		{
+			Name:        "postgres",
+			Category:    "database",
+			Description: "Run a PostgreSQL query or statement, or check whether rows exist and count them",
+			Args: []ArgSpec{
+				{Name: "operation", Type: "string", Required: true, Description: "query/select, execute/insert/update/delete, or row_exists/count returning true/false or the number of rows"},
+				{Name: "connection_string", Type: "string", Required: true},
+				{Name: "sql", Type: "string", Required: true, Description: "Statement, or the table (schema.table) for row_exists and count"},
+				{Name: "where", Type: "string", Description: "row_exists, count: condition the rows must match, e.g. email = $1"},
+			},
+			Options: []ArgSpec{
+				{Name: "params", Type: "array", Description: "Positional parameters ($1, $2, ...) of the statement or where condition"},
+				{Name: "as_json", Type: "bool", Description: "Return query rows as a JSON string"},
+			},
+			Example:  "action: postgres\nargs: [\"row_exists\", \"${ENV:DB_URL}\", \"users\", \"email = $1\"]\noptions:\n  params: [\"${email}\"]\nresult: exists",
+			ReadOnly: readOnlySQL,
+		},
+		{
+			Name:        "sql",
+			Category:    "database",
+			Description: "Run a query, statement or transaction on a named connection declared with ROBOGO_SQL_<NAME>_DRIVER and _DSN",
+			Args: []ArgSpec{
+				{Name: "operation", Type: "string", Required: true, Description: "query/select, exec/execute/insert/update/delete, or transaction"},
+				{Name: "connection", Type: "string", Required: true, Description: "Connection name; its driver (postgres, mysql, sqlite, sqlserver) and DSN come from the environment"},
+				{Name: "sql", Type: "string", Description: "Statement for query and exec operations"},
+			},
+			Options: []ArgSpec{
+				{Name: "params", Type: "array", Description: "Positional parameters ($1 or ?), or a map of named parameters"},
+				{Name: "statements", Type: "array", Description: "SQL strings or {sql, params} committed together by transaction"},
+				{Name: "as_json", Type: "bool", Description: "Return query rows as a JSON string"},
+			},
+			Example:  "action: sql\nargs: [\"query\", \"orders\", \"SELECT status FROM orders WHERE id = $1\"]\noptions:\n  params: [\"${order_id}\"]",
+			ReadOnly: readOnlySQL,
+		},
+		{
+			Name:        "spanner",
+			Category:    "database",
+			Description: "Run Cloud Spanner queries, DML, mutations and read-write transactions",
+			Args: []ArgSpec{
+				{Name: "operation", Type: "string", Required: true, Description: "query/select, execute/insert/update/delete, mutate or transaction"},
+				{Name: "database", Type: "string", Required: true, Description: "projects/<p>/instances/<i>/databases/<d>"},
+				{Name: "sql", Type: "string", Description: "Statement for query and execute operations"},
+			},
+			Options: []ArgSpec{
+				{Name: "params", Type: "object", Description: "@name parameters as a map, or positional parameters as a list"},
+				{Name: "mutations", Type: "array", Description: "{op: insert|update|insert_or_update|replace, table, rows} or {op: delete, table, keys}"},
+				{Name: "statements", Type: "array", Description: "SQL strings or {sql, params} run in one transaction"},
+				{Name: "emulator", Type: "bool", Description: "Connect to SPANNER_EMULATOR_HOST (default localhost:9010) without credentials"},
+				{Name: "as_json", Type: "bool", Description: "Return query rows as a JSON string"},
+			},
+			Example: "action: spanner\nargs: [\"query\", \"${ENV:SPANNER_DB}\", \"SELECT name FROM users WHERE id = @id\"]\noptions:\n  params: {id: 1}",
+		},
+		{
+			Name:        "migrate",
+			Category:    "database",
+			Description: "Apply or roll back .sql migrations against PostgreSQL, tracked in a table",
+			Args: []ArgSpec{
+				{Name: "operation", Type: "string", Required: true, Description: "up, down or reset"},
+				{Name: "connection_string", Type: "string", Required: true},
+				{Name: "path", Type: "string", Required: true, Description: "Directory of NAME.up.sql/NAME.down.sql files, or a single file"},
+			},
+			Options: []ArgSpec{
+				{Name: "table", Type: "string", Description: "Tracking table (default schema_migrations)"},
+				{Name: "steps", Type: "number", Description: "Migrations to roll back with down (default 1)"},
+			},
+			Example: "action: migrate\nargs: [\"up\", \"${ENV:DB_URL}\", \"testdata/migrations\"]",
+		},
+		{
+			Name:        "db_seed",
+			Category:    "database",
+			Description: "Load fixture rows from a JSON or CSV file into a PostgreSQL table or MongoDB collection, all or nothing",
+			Args: []ArgSpec{
+				{Name: "database", Type: "string", Required: true, Description: "postgres or mongodb"},
+				{Name: "connection", Type: "string", Required: true, Description: "Connection string or URL"},
+				{Name: "target", Type: "string", Required: true, Description: "Table, or \"db.collection\" for mongodb"},
+				{Name: "fixtures", Type: "string", Required: true, Description: ".json array of objects or .csv file with a header row"},
+			},
+			Options: []ArgSpec{
+				{Name: "truncate", Type: "bool", Description: "Delete existing rows or documents first (default false)"},
+			},
+			Example: "action: db_seed\nargs: [\"postgres\", \"${ENV:DB_URL}\", \"users\", \"testdata/fixtures/users.json\"]",
+		},
+		{
+			Name:        "poll_db",
+			Category:    "database",
+			Description: "Re-run a PostgreSQL query or MongoDB find until the result matches, to wait for asynchronous writes",
+			Args: []ArgSpec{
+				{Name: "database", Type: "string", Required: true, Description: "postgres or mongodb"},
+				{Name: "connection", Type: "string", Required: true, Description: "Connection string or URL"},
+				{Name: "query", Type: "string", Required: true, Description: "SQL query, or \"db.collection\" for mongodb"},
+			},
+			Options: []ArgSpec{
+				{Name: "expected_rows", Type: "number", Description: "Wait for exactly this many rows or documents"},
+				{Name: "min_rows", Type: "number", Description: "Wait for at least this many (default 1 when nothing else is expected)"},
+				{Name: "until", Type: "string", Description: "jq expression over the result that must be true"},
+				{Name: "filter", Type: "object", Description: "MongoDB filter"},
+				{Name: "timeout", Type: "string", Description: "How long to keep polling (default \"30s\")"},
+				{Name: "interval", Type: "string", Description: "Delay between polls (default \"1s\")"},
+			},
+			Example: "action: poll_db\nargs: [\"postgres\", \"${ENV:DB_URL}\", \"SELECT status FROM orders WHERE id = '${order_id}'\"]\noptions:\n  until: '.rows[0][0] == \"shipped\"'\n  timeout: \"20s\"",
+		},
+		{
+			Name:        "mongodb",
+			Category:    "database",
+			Description: "Run a MongoDB operation against a collection",
+			Args: []ArgSpec{
+				{Name: "operation", Type: "string", Required: true, Description: "find, insert, update, delete, aggregate or count"},
+				{Name: "connection_url", Type: "string", Required: true},
+				{Name: "collection", Type: "string", Required: true, Description: "collection or database.collection"},
+			},
+			Options: []ArgSpec{
+				{Name: "filter", Type: "object"},
+				{Name: "document", Type: "object"},
+				{Name: "documents", Type: "array"},
+				{Name: "update", Type: "object"},
+				{Name: "projection", Type: "object"},
+				{Name: "sort", Type: "object"},
+				{Name: "limit", Type: "number"},
+				{Name: "skip", Type: "number"},
+				timeoutOption,
+			},
+			Example: "action: mongodb\nargs: [\"find\", \"${ENV:MONGO_URL}\", \"shop.orders\"]\noptions:\n  filter: {status: \"open\"}",
+		},
+		{
+			Name:        "cassandra",
+			Category:    "database",
+			Description: "Run a CQL statement against Cassandra or ScyllaDB; returns columns and rows",
+			Args:        cassandraArgs,
+			Options:     cassandraOptions,
+			Example:     "action: cassandra\nargs: [\"localhost:9042\", \"SELECT id, status FROM orders WHERE id = ?\"]\noptions:\n  keyspace: shop\n  params: [\"${order_id}\"]\n  consistency: local_quorum",
+		},
+		{
+			Name:        "scylla",
+			Category:    "database",
+			Description: "Same as cassandra, for ScyllaDB",
+			Args:        cassandraArgs,
+			Options:     cassandraOptions,
+			Example:     "action: scylla\nargs: [\"scylla1,scylla2\", \"SELECT count(*) FROM shop.orders\"]",
+		},
+
+		// Messaging actions
+		{
+			Name:        "kafka",
+			Category:    "messaging",
+			Description: "Publish to, consume from or list Kafka topics",
+			Args: []ArgSpec{
+				{Name: "operation", Type: "string", Required: true, Description: "publish, consume or list_topics"},
+				{Name: "broker", Type: "string", Required: true},
+				{Name: "topic", Type: "string", Description: "Required for publish and consume"},
+				{Name: "message", Type: "string", Description: "Required for publish"},
+			},
+			Options: []ArgSpec{
+				{Name: "count", Type: "number", Description: "Messages to consume"},
+				{Name: "offset", Type: "string", Description: "earliest or latest"},
+				{Name: "auto_commit", Type: "bool"},
+				timeoutOption,
+			},
+			Example: "action: kafka\nargs: [\"publish\", \"localhost:9092\", \"orders\", \"${payload}\"]",
+		},
+		{
+			Name:        "rabbitmq",
+			Category:    "messaging",
+			Description: "Publish a message to a RabbitMQ queue",
+			Args: []ArgSpec{
+				{Name: "operation", Type: "string", Required: true, Description: "publish"},
+				{Name: "connection_string", Type: "string", Required: true},
+				{Name: "queue", Type: "string", Required: true},
+				{Name: "message", Type: "string", Required: true},
+			},
+			Example: "action: rabbitmq\nargs: [\"publish\", \"${ENV:AMQP_URL}\", \"orders\", \"${payload}\"]",
+		},
+		{
+			Name:        "swift_message",
+			Category:    "messaging",
+			Description: "Render a SWIFT message (e.g. MT103) from a template",
+			Args: []ArgSpec{
+				{Name: "template", Type: "string", Required: true, Description: "File name under templates/swift"},
+				{Name: "data", Type: "object", Required: true},
+			},
+			Example: "action: swift_message\nargs: [\"mt103.txt\", {amount: \"100,00\"}]",
+		},
+	}
+}
+
+// Describe returns the documentation for an action.
+// Actions registered without metadata get a minimal entry in the "custom" category.
+func (registry *ActionRegistry) Describe(name string) (ActionMetadata, bool) {
+	if _, exists := registry.actions[name]; !exists {
+		return ActionMetadata{}, false
+	}
+	if meta, exists := registry.metadata[name]; exists {
+		return meta, true
+	}
+	return ActionMetadata{Name: name, Category: "custom"}, true
+}
+
+// ListActions returns all registered action names in alphabetical order
+func (registry *ActionRegistry) ListActions() []string {
+	names := registry.GetRegisteredActions()
+	sort.Strings(names)
+	return names
+}
+
+// ListActionsByCategory returns the sorted names of actions in a category
+func (registry *ActionRegistry) ListActionsByCategory(category string) []string {
+	var names []string
+	for _, name := range registry.ListActions() {
+		if meta, _ := registry.Describe(name); strings.EqualFold(meta.Category, category) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Categories returns the sorted, distinct categories of registered actions
+func (registry *ActionRegistry) Categories() []string {
+	seen := make(map[string]bool)
+	var categories []string
+	for _, name := range registry.ListActions() {
+		meta, _ := registry.Describe(name)
+		if !seen[meta.Category] {
+			seen[meta.Category] = true
+			categories = append(categories, meta.Category)
+		}
+	}
+	sort.Strings(categories)
+	return categories
+}
+
+// SearchActions returns actions whose name, category or description contains term (case-insensitive)
+func (registry *ActionRegistry) SearchActions(term string) []ActionMetadata {
+	term = strings.ToLower(term)
+	var matches []ActionMetadata
+	for _, name := range registry.ListActions() {
+		meta, _ := registry.Describe(name)
+		text := strings.ToLower(meta.Name + " " + meta.Category + " " + meta.Description)
+		if strings.Contains(text, term) {
+			matches = append(matches, meta)
+		}
+	}
+	return matches
+}
+
+// GetActionCompletions returns action names starting with prefix, followed by
+// fuzzy matches that contain the prefix characters in order (e.g. "b64d" -> base64_decode)
+func (registry *ActionRegistry) GetActionCompletions(prefix string) []string {
+	prefix = strings.ToLower(prefix)
+	var exact, fuzzy []string
+	for _, name := range registry.ListActions() {
+		switch {
+		case strings.HasPrefix(name, prefix):
+			exact = append(exact, name)
+		case isSubsequence(prefix, name):
+			fuzzy = append(fuzzy, name)
+		}
+	}
+	return append(exact, fuzzy...)
+}
+
+// ValidateAction checks argument count and types against the action's metadata.
+// Returns nil when the arguments look valid or the action has no metadata.
+func (registry *ActionRegistry) ValidateAction(name string, args []any) *types.ActionResult {
+	meta, exists := registry.Describe(name)
+	if !exists {
+		errorResult := types.NewErrorBuilder(types.ErrorCategoryValidation, "UNKNOWN_ACTION").
+			WithTemplate("Unknown action: %s").
+			WithContext("action", name).
+			Build(name)
+		return &errorResult
+	}
+	return validateArgsWithMetadata(meta, args)
+}
+
+// validateArgsWithMetadata checks args against a metadata entry; nil means valid
+func validateArgsWithMetadata(meta ActionMetadata, args []any) *types.ActionResult {
+	name := meta.Name
+	if meta.Args == nil {
+		return nil
+	}
+
+	required := 0
+	for _, arg := range meta.Args {
+		if arg.Required {
+			required++
+		}
+	}
+	if len(args) < required {
+		errorResult := types.MissingArgsError(name, required, len(args))
+		return &errorResult
+	}
+	if !meta.Variadic && len(args) > len(meta.Args) {
+		errorResult := types.NewErrorBuilder(types.ErrorCategoryValidation, "TOO_MANY_ARGS").
+			WithTemplate("%s action accepts at most %d arguments, got %d").
+			Build(name, len(meta.Args), len(args))
+		return &errorResult
+	}
+
+	for i, arg := range args {
+		spec := meta.Args[len(meta.Args)-1]
+		if i < len(meta.Args) {
+			spec = meta.Args[i]
+		}
+		if !matchesArgType(arg, spec.Type) {
+			errorResult := types.InvalidArgError(name, spec.Name, spec.Type)
+			return &errorResult
+		}
+	}
+	return nil
+}
+
+// matchesArgType reports whether a value fits an ArgSpec type.
+// Strings are accepted for numbers and bools since arguments are often substituted variables.
+func matchesArgType(value any, argType string) bool {
+	switch argType {
+	case "number":
+		switch v := value.(type) {
+		case int, int64, float64:
+			return true
+		case string:
+			_, err := strconv.ParseFloat(v, 64)
+			return err == nil
+		}
+		return false
+	case "bool":
+		switch v := value.(type) {
+		case bool:
+			return true
+		case string:
+			_, err := strconv.ParseBool(v)
+			return err == nil
+		}
+		return false
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "string":
+		switch value.(type) {
+		case map[string]any, []any:
+			return false
+		}
+		return true
+	}
+	return true
+}
+
+// isSubsequence reports whether all characters of pattern appear in text in order
+func isSubsequence(pattern, text string) bool {
+	if pattern == "" {
+		return false
+	}
+	i := 0
+	for j := 0; j < len(text) && i < len(pattern); j++ {
+		if text[j] == pattern[i] {
+			i++
+		}
+	}
+	return i == len(pattern)
+}
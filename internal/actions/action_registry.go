@@ -13,17 +13,22 @@ type ActionFunc func(args []any, options map[string]any, vars *common.Variables)
 
 // ActionRegistry manages action registration and lookup without global state
 type ActionRegistry struct {
-	actions map[string]ActionFunc
+	actions  map[string]ActionFunc
+	metadata map[string]ActionMetadata
 }
 
 // NewActionRegistry creates a new action registry
 func NewActionRegistry() *ActionRegistry {
 	registry := &ActionRegistry{
-		actions: make(map[string]ActionFunc),
+		actions:  make(map[string]ActionFunc),
+		metadata: make(map[string]ActionMetadata),
 	}
 
 	// Register all built-in actions
 	registry.registerBuiltinActions()
+	for _, meta := range builtinActionMetadata() {
+		registry.metadata[meta.Name] = meta
+	}
 
 	return registry
 }
@@ -33,6 +38,12 @@ func (registry *ActionRegistry) Register(name string, action ActionFunc) {
 	registry.actions[name] = action
 }
 
+// RegisterWithMetadata registers an action together with its documentation
+func (registry *ActionRegistry) RegisterWithMetadata(meta ActionMetadata, action ActionFunc) {
+	registry.actions[meta.Name] = action
+	registry.metadata[meta.Name] = meta
+}
+
 // Get retrieves an action by name
 func (registry *ActionRegistry) Get(name string) (ActionFunc, bool) {
 	action, exists := registry.actions[name]
@@ -57,6 +68,7 @@ func (registry *ActionRegistry) GetRegisteredActions() []string {
 // Unregister removes an action (useful for testing)
 func (registry *ActionRegistry) Unregister(name string) {
 	delete(registry.actions, name)
+	delete(registry.metadata, name)
 }
 
 // Clone creates a copy of the registry
@@ -67,6 +79,10 @@ func (registry *ActionRegistry) Clone() *ActionRegistry {
 	for name, action := range registry.actions {
 		newRegistry.actions[name] = action
 	}
+	newRegistry.metadata = make(map[string]ActionMetadata)
+	for name, meta := range registry.metadata {
+		newRegistry.metadata[name] = meta
+	}
 	return newRegistry
 }
 
@@ -75,6 +91,7 @@ func (registry *ActionRegistry) registerBuiltinActions() {
 	// Core actions
 	registry.Register("assert", assertAction)
 	registry.Register("log", logAction)
+	registry.Register("summary", summaryAction)
 	registry.Register("variable", variableAction)
 
 	// Utility actions
@@ -83,9 +100,11 @@ func (registry *ActionRegistry) registerBuiltinActions() {
 	registry.Register("sleep", sleepAction)
 	registry.Register("ping", pingAction)
 	registry.Register("tcp_connect", tcpConnectAction)
+	registry.Register("health_check", healthCheckAction)
 
 	// Security actions
 	registry.Register("ssl_cert_check", sslCertCheckAction)
+	registry.Register("ldap", ldapAction)
 
 	// Encoding actions
 	registry.Register("base64_encode", base64EncodeAction)
@@ -96,11 +115,14 @@ func (registry *ActionRegistry) registerBuiltinActions() {
 
 	// File actions
 	registry.Register("file_read", fileReadAction)
+	registry.Register("golden", goldenAction)
 	registry.Register("scp", scpAction)
+	registry.Register("attach", attachAction)
 
 	// String actions
 	registry.Register("string_random", stringRandomAction)
 	registry.Register("string_replace", stringReplaceAction)
+	registry.Register("regex_replace", regexReplaceAction)
 	registry.Register("string_format", stringFormatAction)
 	registry.Register("string", stringAction)
 
@@ -110,11 +132,19 @@ func (registry *ActionRegistry) registerBuiltinActions() {
 
 	// HTTP actions
 	registry.Register("http", httpAction)
+	registry.Register("sse", sseAction)
+	registry.Register("compare_response", compareResponseAction)
 
 	// Database actions
 	registry.Register("postgres", postgresAction)
+	registry.Register("sql", sqlAction)
 	registry.Register("spanner", spannerAction)
 	registry.Register("mongodb", mongodbAction)
+	registry.Register("cassandra", cassandraAction)
+	registry.Register("scylla", cassandraAction)
+	registry.Register("migrate", migrateAction)
+	registry.Register("db_seed", dbSeedAction)
+	registry.Register("poll_db", pollDBAction)
 
 	// Messaging actions
 	registry.Register("kafka", kafkaAction)
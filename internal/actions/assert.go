@@ -10,13 +10,27 @@ import (
 	"github.com/JianLoong/robogo/internal/types"
 )
 
+// SensitiveAssertOption marks an assert step as sensitive: failures never include the values
+const SensitiveAssertOption = "__sensitive"
+
 func assertAction(args []any, options map[string]any, vars *common.Variables) types.ActionResult {
 	if len(args) < 1 {
 		return types.MissingArgsError("assert", 1, len(args))
 	}
+	sensitive, _ := options[SensitiveAssertOption].(bool)
 
 	// Check for unresolved variables in any argument
-	if errorResult := validateArgsResolved("assert", args); errorResult != nil {
+	if sensitive {
+		for i, arg := range args {
+			if str, ok := arg.(string); ok && strings.Contains(str, "__UNRESOLVED") {
+				return types.NewErrorBuilder(types.ErrorCategoryVariable, "UNRESOLVED_VARIABLE").
+					WithTemplate("Action failed due to unresolved variable in assert argument %d").
+					WithContext("action", "assert").
+					WithContext("argument_index", i).
+					Build(i)
+			}
+		}
+	} else if errorResult := validateArgsResolved("assert", args); errorResult != nil {
 		return *errorResult
 	}
 
@@ -29,6 +43,9 @@ func assertAction(args []any, options map[string]any, vars *common.Variables) ty
 		}
 
 		// Use simple failure function for boolean assertion failure
+		if sensitive {
+			return types.SensitiveAssertionFailure("boolean equality")
+		}
 		return types.BooleanAssertionFailure(args[0])
 	}
 
@@ -38,6 +55,10 @@ func assertAction(args []any, options map[string]any, vars *common.Variables) ty
 		operator := args[1]
 		expected := args[2]
 
+		if operator == constants.OperatorOrdered {
+			return assertOrdered(actual, expected, options, sensitive)
+		}
+
 		// Convert to strings for comparison
 		actualStr := fmt.Sprintf("%v", actual)
 		expectedStr := fmt.Sprintf("%v", expected)
@@ -59,7 +80,7 @@ func assertAction(args []any, options map[string]any, vars *common.Variables) ty
 		case constants.OperatorContains:
 			result = strings.Contains(actualStr, expectedStr)
 		default:
-			return types.InvalidArgError("assert", "operator", "valid comparison operator (==, !=, >, <, >=, <=, contains)")
+			return types.InvalidArgError("assert", "operator", "valid comparison operator (==, !=, >, <, >=, <=, contains, ordered)")
 		}
 
 		if result {
@@ -69,10 +90,16 @@ func assertAction(args []any, options map[string]any, vars *common.Variables) ty
 		}
 
 		// Use simple failure function for comparison assertion failure
+		if sensitive {
+			return types.SensitiveAssertionFailure(fmt.Sprintf("%v", operator))
+		}
 		return types.AssertionFailure(expected, actual, fmt.Sprintf("%v", operator))
 	}
 
 	// Fallback case - treat as boolean assertion
+	if sensitive {
+		return types.SensitiveAssertionFailure("boolean equality")
+	}
 	return types.BooleanAssertionFailure(args[0])
 }
 
@@ -0,0 +1,193 @@
+package actions
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/JianLoong/robogo/internal/constants"
+	"github.com/JianLoong/robogo/internal/types"
+)
+
+// assertOrdered checks that collected messages arrive in the order of the expected matchers.
+// By default the messages must match the matchers one for one; with the partial option the
+// matchers only need to appear in order, with other messages allowed in between.
+//
+// A scalar matcher matches a message with the same text. A map matcher matches a message
+// object, or a message holding a JSON object, whose fields match the map's fields.
+func assertOrdered(actual, expected any, options map[string]any, sensitive bool) types.ActionResult {
+	messages, ok := orderedMessages(actual)
+	if !ok {
+		return types.InvalidArgError("assert", "actual", "list of messages, such as a kafka consume result or sse events")
+	}
+	matchers, ok := expected.([]any)
+	if !ok {
+		return types.InvalidArgError("assert", "expected", "list of message matchers")
+	}
+
+	partial := parseBoolOption(options, "partial", false)
+	var failure *orderedDivergence
+	if partial {
+		failure = findSubsequenceDivergence(messages, matchers)
+	} else {
+		failure = findSequenceDivergence(messages, matchers)
+	}
+	if failure == nil {
+		return types.ActionResult{
+			Status: constants.ActionStatusPassed,
+		}
+	}
+
+	if sensitive {
+		return types.SensitiveAssertionFailure(constants.OperatorOrdered)
+	}
+	builder := types.NewFailureBuilder(types.FailureCategoryAssertion, "ORDER_ASSERTION_FAILED").
+		WithTemplate("Message order diverged at position %d: %s").
+		WithExpected(failure.expected).
+		WithActual(failure.actual).
+		WithComparison(constants.OperatorOrdered).
+		WithContext("position", failure.position).
+		WithContext("message_count", len(messages)).
+		WithSuggestion("Compare the expected sequence with the messages the step collected")
+	if !partial {
+		builder = builder.WithSuggestion("Use partial: true if other messages may arrive between the expected ones")
+	}
+	return builder.Build(failure.position, failure.details)
+}
+
+// orderedDivergence describes the first place the messages stop following the matchers
+type orderedDivergence struct {
+	position int
+	expected any
+	actual   any
+	details  string
+}
+
+// orderedMessages returns the message list from a collected result: a list, a kafka
+// consume result with a messages field, or a JSON array string
+func orderedMessages(value any) ([]any, bool) {
+	switch v := value.(type) {
+	case []any:
+		return v, true
+	case []string:
+		messages := make([]any, len(v))
+		for i, message := range v {
+			messages[i] = message
+		}
+		return messages, true
+	case []map[string]any:
+		messages := make([]any, len(v))
+		for i, message := range v {
+			messages[i] = message
+		}
+		return messages, true
+	case map[string]any:
+		if messages, ok := v["messages"]; ok {
+			return orderedMessages(messages)
+		}
+	case string:
+		var messages []any
+		if err := json.Unmarshal([]byte(v), &messages); err == nil {
+			return messages, true
+		}
+	}
+	return nil, false
+}
+
+// findSequenceDivergence requires the nth message to match the nth matcher, with no extra messages
+func findSequenceDivergence(messages, matchers []any) *orderedDivergence {
+	for i := 0; i < len(messages) || i < len(matchers); i++ {
+		switch {
+		case i >= len(messages):
+			return &orderedDivergence{
+				position: i + 1,
+				expected: matchers[i],
+				details:  fmt.Sprintf("expected %s, but only %d messages were collected", formatOrderedValue(matchers[i]), len(messages)),
+			}
+		case i >= len(matchers):
+			return &orderedDivergence{
+				position: i + 1,
+				actual:   messages[i],
+				details:  fmt.Sprintf("unexpected extra message %s after the %d expected", formatOrderedValue(messages[i]), len(matchers)),
+			}
+		case !matchesMessage(matchers[i], messages[i]):
+			return &orderedDivergence{
+				position: i + 1,
+				expected: matchers[i],
+				actual:   messages[i],
+				details:  fmt.Sprintf("expected %s, got %s", formatOrderedValue(matchers[i]), formatOrderedValue(messages[i])),
+			}
+		}
+	}
+	return nil
+}
+
+// findSubsequenceDivergence requires the matchers to match messages in order, skipping others
+func findSubsequenceDivergence(messages, matchers []any) *orderedDivergence {
+	next := 0
+	for i, matcher := range matchers {
+		found := -1
+		for j := next; j < len(messages); j++ {
+			if matchesMessage(matcher, messages[j]) {
+				found = j
+				break
+			}
+		}
+		if found >= 0 {
+			next = found + 1
+			continue
+		}
+
+		details := fmt.Sprintf("expected %s, but no message matched", formatOrderedValue(matcher))
+		if next > 0 {
+			details = fmt.Sprintf("expected %s after message %d, but no later message matched", formatOrderedValue(matcher), next)
+		}
+		for j := 0; j < next; j++ {
+			if matchesMessage(matcher, messages[j]) {
+				details += fmt.Sprintf(" (it matched earlier message %d, out of order)", j+1)
+				break
+			}
+		}
+		return &orderedDivergence{
+			position: i + 1,
+			expected: matcher,
+			details:  details,
+		}
+	}
+	return nil
+}
+
+// matchesMessage reports whether a message satisfies a matcher
+func matchesMessage(matcher, message any) bool {
+	fields, ok := matcher.(map[string]any)
+	if !ok {
+		return fmt.Sprintf("%v", matcher) == fmt.Sprintf("%v", message)
+	}
+
+	object, ok := message.(map[string]any)
+	if !ok {
+		text, isString := message.(string)
+		if !isString || json.Unmarshal([]byte(text), &object) != nil {
+			return false
+		}
+	}
+	for key, want := range fields {
+		got, exists := object[key]
+		if !exists || !matchesMessage(want, got) {
+			return false
+		}
+	}
+	return true
+}
+
+// formatOrderedValue renders a matcher or message for failure output
+func formatOrderedValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return fmt.Sprintf("%q", v)
+	case map[string]any, []any:
+		if encoded, err := json.Marshal(v); err == nil {
+			return string(encoded)
+		}
+	}
+	return fmt.Sprintf("%v", value)
+}
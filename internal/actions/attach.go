@@ -0,0 +1,96 @@
+package actions
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/JianLoong/robogo/internal/common"
+	"github.com/JianLoong/robogo/internal/constants"
+	"github.com/JianLoong/robogo/internal/types"
+)
+
+// attachAction attaches a file or a value to the step result as an artifact, written to
+// the --artifacts directory when the test case ends
+// Args: [name, content?] - file name of the artifact and the value to attach, non-strings as JSON
+// Options: path - file to attach instead of a value; content_type - overrides the type
+// detected from the name
+func attachAction(args []any, options map[string]any, vars *common.Variables) types.ActionResult {
+	if len(args) < 1 {
+		return types.MissingArgsError("attach", 1, len(args))
+	}
+	name := fmt.Sprintf("%v", args[0])
+	if name == "" || filepath.Base(name) != name {
+		return types.InvalidArgError("attach", "name", "a file name without directories")
+	}
+
+	path, hasPath := options["path"]
+	if hasPath == (len(args) > 1) {
+		return types.NewErrorBuilder(types.ErrorCategoryValidation, "ATTACH_SOURCE_REQUIRED").
+			WithTemplate("attach needs either a value to attach or the path option").
+			WithContext("name", name).
+			WithSuggestion("Pass the value as the second argument, e.g. ${response.body}, or set path to attach a file").
+			Build()
+	}
+	if errorResult := validateArgsResolved("attach", args); errorResult != nil {
+		return *errorResult
+	}
+
+	var artifact types.Artifact
+	if hasPath {
+		cleanPath, errorResult := cleanFilePath(fmt.Sprintf("%v", path), options)
+		if errorResult != nil {
+			return *errorResult
+		}
+		info, err := os.Stat(cleanPath)
+		if err != nil || info.IsDir() {
+			return types.NewErrorBuilder(types.ErrorCategoryValidation, "FILE_NOT_FOUND").
+				WithTemplate("File to attach not found: %s").
+				WithContext("path", cleanPath).
+				WithSuggestion("Attach a file the earlier steps created, relative to the test file or the run directory").
+				Build(cleanPath)
+		}
+		// Read when the test case ends, so the size limit applies before the file is loaded
+		artifact = types.Artifact{Name: name, ContentType: artifactContentType(name), Source: cleanPath, Size: info.Size()}
+	} else {
+		artifact = DataArtifact(name, args[1])
+	}
+	artifact.ContentType = parseStringOption(options, "content_type", artifact.ContentType)
+
+	return types.ActionResult{
+		Status:    constants.ActionStatusPassed,
+		Data:      map[string]any{"name": artifact.Name, "content_type": artifact.ContentType, "size": artifact.Size},
+		Artifacts: []types.Artifact{artifact},
+	}
+}
+
+// DataArtifact makes an artifact of a value: strings and bytes as they are, anything
+// else as indented JSON
+func DataArtifact(name string, data any) types.Artifact {
+	var content []byte
+	switch val := data.(type) {
+	case string:
+		content = []byte(val)
+	case []byte:
+		content = val
+	default:
+		encoded, err := json.MarshalIndent(val, "", "  ")
+		if err != nil {
+			encoded = []byte(fmt.Sprintf("%v", val))
+		}
+		content = encoded
+	}
+	return types.Artifact{Name: name, ContentType: artifactContentType(name), Content: content, Size: int64(len(content))}
+}
+
+// artifactContentType guesses the content type from the file extension
+func artifactContentType(name string) string {
+	contentType := mime.TypeByExtension(strings.ToLower(filepath.Ext(name)))
+	if contentType == "" {
+		return "application/octet-stream"
+	}
+	return contentType
+}
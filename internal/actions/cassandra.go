@@ -0,0 +1,197 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gocql/gocql"
+	"gopkg.in/inf.v0"
+
+	"github.com/JianLoong/robogo/internal/common"
+	"github.com/JianLoong/robogo/internal/constants"
+	"github.com/JianLoong/robogo/internal/types"
+)
+
+// cassandraAction runs a CQL statement against Cassandra or ScyllaDB
+// Args: [contact_points, statement] - contact points as "host[:port]", comma-separated or a list
+// Options:
+//   - params: values bound to the statement's ? markers
+//   - keyspace: keyspace for unqualified table names
+//   - consistency: e.g. one, quorum, local_quorum (default: quorum)
+//   - username, password: password authentication
+//   - timeout: e.g. "10s" (default: 30s)
+func cassandraAction(args []any, options map[string]any, vars *common.Variables) types.ActionResult {
+	if len(args) < 2 {
+		return types.MissingArgsError("cassandra", 2, len(args))
+	}
+
+	if errorResult := validateArgsResolved("cassandra", args[:2]); errorResult != nil {
+		return *errorResult
+	}
+
+	hosts := cassandraHosts(args[0])
+	if len(hosts) == 0 {
+		return types.InvalidArgError("cassandra", "contact_points", "host[:port], comma-separated or a list")
+	}
+	statement := fmt.Sprintf("%v", args[1])
+
+	timeout := constants.DefaultDatabaseTimeout
+	if timeoutStr, ok := options["timeout"].(string); ok {
+		if parsedTimeout, err := time.ParseDuration(timeoutStr); err == nil {
+			timeout = parsedTimeout
+		}
+	}
+
+	consistency := gocql.Quorum
+	if value, ok := options["consistency"]; ok {
+		parsed, err := gocql.ParseConsistencyWrapper(fmt.Sprintf("%v", value))
+		if err != nil {
+			return types.InvalidArgError("cassandra", "consistency", "one of any, one, two, three, quorum, all, local_quorum, each_quorum, local_one")
+		}
+		consistency = parsed
+	}
+
+	var params []any
+	if value, ok := options["params"]; ok {
+		list, isList := value.([]any)
+		if !isList {
+			return types.InvalidArgError("cassandra", "params", "list of values for the statement's ? markers")
+		}
+		params = list
+	}
+
+	cluster := gocql.NewCluster(hosts...)
+	cluster.Timeout = timeout
+	cluster.ConnectTimeout = timeout
+	cluster.NumConns = 1
+	cluster.Consistency = consistency
+	if keyspace, ok := options["keyspace"].(string); ok {
+		cluster.Keyspace = keyspace
+	}
+	username, _ := options["username"].(string)
+	password, _ := options["password"].(string)
+	if username != "" {
+		cluster.Authenticator = gocql.PasswordAuthenticator{Username: username, Password: password}
+	}
+
+	// Open a session for this statement only
+	session, err := cluster.CreateSession()
+	if err != nil {
+		return types.NewErrorBuilder(types.ErrorCategoryDatabase, "CASSANDRA_CONNECTION_FAILED").
+			WithTemplate("Failed to connect to Cassandra: %s").
+			WithContext("contact_points", hosts).
+			WithContext("connection_options", maskedCassandraOptions(options)).
+			WithContext("error", err.Error()).
+			WithSuggestion("Check if Cassandra is running and the contact points are reachable (default port 9042)").
+			WithSuggestion("Verify the keyspace exists and the credentials are correct").
+			Build(err.Error())
+	}
+	defer session.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	iter := session.Query(statement, params...).WithContext(ctx).Iter()
+	columns, rows, err := readCassandraRows(iter)
+	if closeErr := iter.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return types.NewErrorBuilder(types.ErrorCategoryDatabase, "CASSANDRA_QUERY_FAILED").
+			WithTemplate("Cassandra statement failed: %s").
+			WithContext("statement", statement).
+			WithContext("connection_options", maskedCassandraOptions(options)).
+			WithContext("error", err.Error()).
+			WithSuggestion("Check CQL syntax, table and column names").
+			WithSuggestion("Check that params has one value of the right type per ? marker").
+			Build(err.Error())
+	}
+
+	return types.ActionResult{
+		Status: constants.ActionStatusPassed,
+		Data: map[string]any{
+			"columns": columns,
+			"rows":    rows,
+			"count":   len(rows),
+		},
+	}
+}
+
+// cassandraHosts reads the contact points from a comma-separated string or a list
+func cassandraHosts(value any) []string {
+	var items []string
+	switch v := value.(type) {
+	case []any:
+		for _, item := range v {
+			items = append(items, fmt.Sprintf("%v", item))
+		}
+	default:
+		items = strings.Split(fmt.Sprintf("%v", v), ",")
+	}
+
+	var hosts []string
+	for _, host := range items {
+		if host = strings.TrimSpace(host); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
+// readCassandraRows reads every row of a result; statements without a result have no columns
+func readCassandraRows(iter *gocql.Iter) ([]string, [][]any, error) {
+	rowData, err := iter.RowData()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows := [][]any{}
+	for iter.Scan(rowData.Values...) {
+		row := make([]any, len(rowData.Values))
+		for i, value := range rowData.Values {
+			row[i] = cassandraValue(reflect.ValueOf(value).Elem().Interface())
+		}
+		rows = append(rows, row)
+	}
+	return rowData.Columns, rows, nil
+}
+
+// cassandraValue converts CQL values without a JSON form, such as uuid and decimal, to strings
+func cassandraValue(value any) any {
+	switch v := value.(type) {
+	case gocql.UUID:
+		return v.String()
+	case *inf.Dec:
+		if v == nil {
+			return nil
+		}
+		return v.String()
+	case *big.Int:
+		if v == nil {
+			return nil
+		}
+		return v.String()
+	case []byte:
+		return string(v)
+	default:
+		return value
+	}
+}
+
+// maskedCassandraOptions returns the connection options for error context with the password masked
+func maskedCassandraOptions(options map[string]any) map[string]any {
+	masked := map[string]any{}
+	for _, key := range []string{"keyspace", "consistency", "username", "timeout"} {
+		if value, ok := options[key]; ok {
+			masked[key] = value
+		}
+	}
+	if _, ok := options["password"]; ok {
+		masked["password"] = "***"
+	}
+	return masked
+}
@@ -0,0 +1,130 @@
+package actions
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/JianLoong/robogo/internal/common"
+	"github.com/JianLoong/robogo/internal/constants"
+	"github.com/JianLoong/robogo/internal/types"
+)
+
+// compareResponseAction sends the same kind of request to two endpoints and compares the responses
+// Options:
+//   - request_a, request_b: method (default GET), url, body, and any http option such as headers
+//   - ignore_paths: JSON paths left out of the body comparison, e.g. ["id", "items.*.created_at"]
+//   - ignore_status: compare bodies only (default false)
+func compareResponseAction(args []any, options map[string]any, vars *common.Variables) types.ActionResult {
+	var ignorePaths []string
+	if paths, ok := options["ignore_paths"].([]any); ok {
+		for _, path := range paths {
+			ignorePaths = append(ignorePaths, fmt.Sprintf("%v", path))
+		}
+	}
+	ignoreStatus, _ := options["ignore_status"].(bool)
+
+	responses := make([]map[string]any, 2)
+	for i, name := range []string{"request_a", "request_b"} {
+		spec, ok := options[name].(map[string]any)
+		if !ok {
+			return types.InvalidArgError("compare_response", name, "map with url and optional method, body and headers")
+		}
+		response, errorResult := sendComparedRequest(name, spec, vars, options)
+		if errorResult != nil {
+			return *errorResult
+		}
+		responses[i] = response
+	}
+
+	statusA, statusB := responses[0]["status_code"], responses[1]["status_code"]
+	bodyA, bodyB := responses[0]["body"], responses[1]["body"]
+
+	// Compare as JSON when both bodies are JSON, so key order and formatting do not count
+	var normalizedA, normalizedB string
+	if isJSONText(bodyA) && isJSONText(bodyB) {
+		normalizedA, _ = canonicalJSON(bodyA, ignorePaths)
+		normalizedB, _ = canonicalJSON(bodyB, ignorePaths)
+	} else {
+		normalizedA = normalizeGoldenText(fmt.Sprintf("%v", bodyA))
+		normalizedB = normalizeGoldenText(fmt.Sprintf("%v", bodyB))
+	}
+
+	statusMatched := ignoreStatus || statusA == statusB
+	diff := ""
+	if normalizedA != normalizedB {
+		diff = unifiedDiff(normalizedA, normalizedB, "request_a", "request_b")
+	}
+
+	data := map[string]any{
+		"matched":       statusMatched && diff == "",
+		"status_a":      statusA,
+		"status_b":      statusB,
+		"status_differ": statusA != statusB,
+		"diff":          diff,
+	}
+
+	if statusMatched && diff == "" {
+		return types.ActionResult{
+			Status: constants.ActionStatusPassed,
+			Data:   data,
+		}
+	}
+
+	var differences []string
+	if !statusMatched {
+		differences = append(differences, fmt.Sprintf("status code %v != %v", statusA, statusB))
+	}
+	if diff != "" {
+		differences = append(differences, "body:\n"+diff)
+	}
+	result := types.NewFailureBuilder(types.FailureCategoryResponse, "RESPONSE_MISMATCH").
+		WithTemplate("Responses differ: %s").
+		WithSuggestion("Add volatile fields such as ids and timestamps to ignore_paths").
+		Build(strings.Join(differences, "\n"))
+	result.Data = data
+	return result
+}
+
+// sendComparedRequest sends one side of a comparison through the http action
+func sendComparedRequest(name string, spec map[string]any, vars *common.Variables, options map[string]any) (map[string]any, *types.ActionResult) {
+	// Nested options are not substituted by the executor
+	spec, _ = substituteVariablesInData(spec, vars).(map[string]any)
+
+	url, ok := spec["url"]
+	if !ok {
+		errorResult := types.InvalidArgError("compare_response", name+".url", "request URL")
+		return nil, &errorResult
+	}
+	method := "GET"
+	if m, ok := spec["method"]; ok {
+		method = strings.ToUpper(fmt.Sprintf("%v", m))
+	}
+
+	args := []any{method, url}
+	if body, ok := spec["body"]; ok {
+		args = append(args, body)
+	}
+	httpOptions := make(map[string]any)
+	for key, value := range spec {
+		switch key {
+		case "url", "method", "body":
+		default:
+			httpOptions[key] = value
+		}
+	}
+	// body_file paths resolve against the file that declared the step
+	if baseDir, ok := options[BaseDirOption]; ok {
+		httpOptions[BaseDirOption] = baseDir
+	}
+
+	result := httpAction(args, httpOptions, vars)
+	if result.Status != constants.ActionStatusPassed {
+		return nil, &result
+	}
+	response, ok := result.Data.(map[string]any)
+	if !ok {
+		errorResult := types.RequestError(name, "unexpected http response data")
+		return nil, &errorResult
+	}
+	return response, nil
+}
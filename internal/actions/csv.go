@@ -80,7 +80,7 @@ func csvParseAction(args []any, options map[string]any, vars *common.Variables)
 	// Check if it looks like a file path (doesn't contain newlines and commas suggest it's content)
 	if !strings.Contains(source, "\n") && !strings.Contains(source, delimiter) {
 		// Try to open as file first
-		if file, err := os.Open(source); err == nil {
+		if file, err := os.Open(resolveFilePath(source, options)); err == nil {
 			reader = file
 			isFilePath = true
 			defer file.Close()
@@ -0,0 +1,330 @@
+package actions
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	mongoOptions "go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/JianLoong/robogo/internal/common"
+	"github.com/JianLoong/robogo/internal/constants"
+	"github.com/JianLoong/robogo/internal/types"
+)
+
+// sqlColumnName matches a plain column name; fixture keys become column names unquoted
+var sqlColumnName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// dbSeedAction bulk-loads fixture rows into a PostgreSQL table or MongoDB collection,
+// mapping fixture fields to columns by name.
+// Args: [database, connection, target, fixtures] - postgres with a table name, or mongodb with a
+// "db.collection" name; fixtures is a .json file with an array of objects or a .csv file with a header row
+// Options:
+//   - truncate: delete the existing rows or documents first (default: false)
+//
+// The load is all or nothing: PostgreSQL rows are inserted in one transaction, and MongoDB
+// documents already inserted are deleted again when a later one fails.
+func dbSeedAction(args []any, options map[string]any, vars *common.Variables) types.ActionResult {
+	if len(args) < 4 {
+		return types.MissingArgsError("db_seed", 4, len(args))
+	}
+
+	if errorResult := validateArgsResolved("db_seed", args[:4]); errorResult != nil {
+		return *errorResult
+	}
+
+	database := strings.ToLower(fmt.Sprintf("%v", args[0]))
+	connection := fmt.Sprintf("%v", args[1])
+	target := fmt.Sprintf("%v", args[2])
+	path := fmt.Sprintf("%v", args[3])
+	truncate := parseBoolOption(options, "truncate", false)
+
+	rows, errorResult := loadFixtures(path, options)
+	if errorResult != nil {
+		return *errorResult
+	}
+
+	var inserted int
+	switch database {
+	case "postgres", "postgresql":
+		if !sqlIdentifier.MatchString(target) {
+			return types.InvalidArgError("db_seed", "target", "table name such as users or public.users")
+		}
+		inserted, errorResult = seedPostgres(connection, target, rows, truncate)
+	case "mongodb", "mongo":
+		inserted, errorResult = seedMongo(connection, target, rows, truncate)
+	default:
+		return types.InvalidArgError("db_seed", "database", "postgres or mongodb")
+	}
+	if errorResult != nil {
+		return *errorResult
+	}
+
+	return types.ActionResult{
+		Status: constants.ActionStatusPassed,
+		Data: map[string]any{
+			"database":       database,
+			"target":         target,
+			"fixtures":       path,
+			"inserted_count": inserted,
+			"truncated":      truncate,
+		},
+	}
+}
+
+// loadFixtures reads the rows of a .json or .csv fixtures file. CSV values are strings;
+// JSON numbers become integers when they have no fraction.
+func loadFixtures(path string, options map[string]any) ([]map[string]any, *types.ActionResult) {
+	cleanPath, errorResult := cleanFilePath(path, options)
+	if errorResult != nil {
+		return nil, errorResult
+	}
+
+	content, err := os.ReadFile(cleanPath)
+	if err != nil {
+		errorResult := types.NewErrorBuilder(types.ErrorCategoryValidation, "FIXTURES_NOT_FOUND").
+			WithTemplate("Fixtures not found: %s").
+			WithContext("path", path).
+			WithSuggestion("Check that the path is relative to where robogo runs").
+			Build(err.Error())
+		return nil, &errorResult
+	}
+
+	invalid := func(details string) *types.ActionResult {
+		errorResult := types.NewErrorBuilder(types.ErrorCategoryValidation, "FIXTURES_INVALID").
+			WithTemplate("Invalid fixtures file %s: %s").
+			WithContext("path", path).
+			WithSuggestion("Use a JSON array of objects, or a CSV file whose first row names the columns").
+			Build(path, details)
+		return &errorResult
+	}
+
+	var rows []map[string]any
+	switch strings.ToLower(filepath.Ext(cleanPath)) {
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.UseNumber()
+		if err := decoder.Decode(&rows); err != nil {
+			return nil, invalid(err.Error())
+		}
+		for _, row := range rows {
+			for key, value := range row {
+				row[key] = fixtureValue(value)
+			}
+		}
+	case ".csv":
+		records, err := csv.NewReader(bytes.NewReader(content)).ReadAll()
+		if err != nil {
+			return nil, invalid(err.Error())
+		}
+		if len(records) == 0 {
+			return nil, invalid("missing header row")
+		}
+		header := records[0]
+		for _, record := range records[1:] {
+			row := make(map[string]any, len(header))
+			for i, column := range header {
+				row[strings.TrimSpace(column)] = record[i]
+			}
+			rows = append(rows, row)
+		}
+	default:
+		return nil, invalid("expected a .json or .csv file")
+	}
+
+	for i, row := range rows {
+		if len(row) == 0 {
+			return nil, invalid(fmt.Sprintf("row %d has no fields", i+1))
+		}
+	}
+	return rows, nil
+}
+
+// fixtureValue converts decoded JSON numbers to int64 or float64
+func fixtureValue(value any) any {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]any:
+		for key, val := range v {
+			v[key] = fixtureValue(val)
+		}
+		return v
+	case []any:
+		for i, val := range v {
+			v[i] = fixtureValue(val)
+		}
+		return v
+	default:
+		return value
+	}
+}
+
+// seedFailure reports a failed load; nothing from the load is left behind
+func seedFailure(target string, row int, err error) *types.ActionResult {
+	builder := types.NewErrorBuilder(types.ErrorCategoryDatabase, "DB_SEED_FAILED").
+		WithTemplate("Seeding %s failed: %s").
+		WithContext("target", target).
+		WithSuggestion("The load was rolled back; fix the fixtures or the table and run it again")
+	if row > 0 {
+		builder = builder.WithContext("row", row)
+	}
+	errorResult := builder.Build(target, err.Error())
+	return &errorResult
+}
+
+// seedPostgres inserts the rows in one transaction, each with the columns it has
+func seedPostgres(connectionString, table string, rows []map[string]any, truncate bool) (int, *types.ActionResult) {
+	db, err := sql.Open("postgres", connectionString)
+	if err != nil {
+		errorResult := types.DatabaseConnectionError("PostgreSQL", err.Error())
+		return 0, &errorResult
+	}
+	defer db.Close()
+
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(0)
+	db.SetConnMaxLifetime(constants.DefaultConnectionLifetime)
+
+	if err = db.Ping(); err != nil {
+		errorResult := types.DatabaseConnectionError("PostgreSQL", err.Error())
+		return 0, &errorResult
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), constants.DefaultDatabaseTimeout)
+	defer cancel()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, seedFailure(table, 0, err)
+	}
+	defer tx.Rollback()
+
+	if truncate {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s", table)); err != nil {
+			return 0, seedFailure(table, 0, err)
+		}
+	}
+
+	for i, row := range rows {
+		columns := make([]string, 0, len(row))
+		for column := range row {
+			if !sqlColumnName.MatchString(column) {
+				return 0, seedFailure(table, i+1, fmt.Errorf("invalid column name %q", column))
+			}
+			columns = append(columns, column)
+		}
+		sort.Strings(columns)
+
+		placeholders := make([]string, len(columns))
+		values := make([]any, len(columns))
+		for j, column := range columns {
+			placeholders[j] = fmt.Sprintf("$%d", j+1)
+			values[j] = sqlFixtureValue(row[column])
+		}
+		insert := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table, strings.Join(columns, ", "), strings.Join(placeholders, ", "))
+		if _, err := tx.ExecContext(ctx, insert, values...); err != nil {
+			return 0, seedFailure(table, i+1, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, seedFailure(table, 0, err)
+	}
+	return len(rows), nil
+}
+
+// sqlFixtureValue passes nested objects and arrays as JSON, e.g. for jsonb columns
+func sqlFixtureValue(value any) any {
+	switch value.(type) {
+	case map[string]any, []any:
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Sprintf("%v", value)
+		}
+		return string(encoded)
+	default:
+		return value
+	}
+}
+
+// seedMongo inserts the rows as documents in order, deleting the ones already inserted
+// if a later one fails. Documents without an _id get one first so they can be found again.
+// Fixture _ids are only deleted when the failure shows they were inserted, since an
+// existing document with the same _id may be why the insert failed.
+func seedMongo(connectionURL, collectionName string, rows []map[string]any, truncate bool) (int, *types.ActionResult) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, mongoOptions.Client().ApplyURI(connectionURL))
+	if err == nil {
+		defer client.Disconnect(ctx)
+		err = client.Ping(ctx, nil)
+	}
+	if err != nil {
+		errorResult := types.DatabaseConnectionError("MongoDB", err.Error())
+		return 0, &errorResult
+	}
+
+	dbName, collName := parseCollectionName(collectionName)
+	collection := client.Database(dbName).Collection(collName)
+
+	if truncate {
+		if _, err := collection.DeleteMany(ctx, bson.M{}); err != nil {
+			return 0, seedFailure(collectionName, 0, err)
+		}
+	}
+
+	docs := make([]any, len(rows))
+	ids := make([]any, len(rows))
+	generated := make([]bool, len(rows))
+	for i, row := range rows {
+		doc := convertToBSON(row)
+		if _, ok := doc["_id"]; !ok {
+			doc["_id"] = primitive.NewObjectID()
+			generated[i] = true
+		}
+		docs[i] = doc
+		ids[i] = doc["_id"]
+	}
+
+	if _, err := collection.InsertMany(ctx, docs); err != nil {
+		failedRow := 0
+		var inserted []any
+		var bulkErr mongo.BulkWriteException
+		if errors.As(err, &bulkErr) && len(bulkErr.WriteErrors) > 0 {
+			failedRow = bulkErr.WriteErrors[0].Index + 1
+			inserted = ids[:bulkErr.WriteErrors[0].Index]
+		} else {
+			for i, id := range ids {
+				if generated[i] {
+					inserted = append(inserted, id)
+				}
+			}
+		}
+		if len(inserted) > 0 {
+			if _, cleanupErr := collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": inserted}}); cleanupErr != nil {
+				err = fmt.Errorf("%v (removing the inserted documents also failed: %v)", err, cleanupErr)
+			}
+		}
+		return 0, seedFailure(collectionName, failedRow, err)
+	}
+	return len(rows), nil
+}
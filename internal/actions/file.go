@@ -24,28 +24,9 @@ func fileReadAction(args []any, options map[string]any, vars *common.Variables)
 
 	filePath := fmt.Sprintf("%v", args[0])
 
-	// Security: Clean the path to prevent path traversal attacks
-	cleanPath := filepath.Clean(filePath)
-
-	// Security: Prevent absolute paths that could access system files
-	if filepath.IsAbs(cleanPath) && !isAllowedAbsolutePath(cleanPath) {
-		return types.NewErrorBuilder(types.ErrorCategoryValidation, "UNSAFE_FILE_PATH").
-			WithTemplate("Absolute file paths are restricted for security").
-			WithContext("file_path", filePath).
-			WithContext("clean_path", cleanPath).
-			WithSuggestion("Use relative paths from your test directory").
-			WithSuggestion("Allowed absolute paths must be in current working directory").
-			Build(fmt.Sprintf("unsafe absolute path: %s", cleanPath))
-	}
-
-	// Security: Prevent path traversal
-	if strings.Contains(cleanPath, "..") {
-		return types.NewErrorBuilder(types.ErrorCategoryValidation, "PATH_TRAVERSAL_DETECTED").
-			WithTemplate("Path traversal detected in file path").
-			WithContext("file_path", filePath).
-			WithContext("clean_path", cleanPath).
-			WithSuggestion("Use relative paths without '..' components").
-			Build(fmt.Sprintf("path traversal detected: %s", cleanPath))
+	cleanPath, errorResult := cleanFilePath(filePath, options)
+	if errorResult != nil {
+		return *errorResult
 	}
 
 	// Check if file exists and is readable
@@ -108,21 +89,62 @@ func fileReadAction(args []any, options map[string]any, vars *common.Variables)
 	}
 }
 
-// isAllowedAbsolutePath checks if an absolute path is allowed (within current working directory)
-func isAllowedAbsolutePath(path string) bool {
-	cwd, err := os.Getwd()
-	if err != nil {
-		return false
+// BaseDirOption passes the directory of the file that declared the step, which relative
+// paths in file actions resolve against, see common.ResolvePath. SuiteDirOption passes
+// the directory of the running test file, which file actions may also use.
+const (
+	BaseDirOption  = "__base_dir"
+	SuiteDirOption = "__suite_dir"
+)
+
+// resolveFilePath resolves a path from a step's arguments or options, see common.ResolvePath
+func resolveFilePath(path string, options map[string]any) string {
+	baseDir, _ := options[BaseDirOption].(string)
+	return common.ResolvePath(baseDir, path)
+}
+
+// cleanFilePath resolves a test-supplied path and rejects paths outside the run directory,
+// the running test file's directory and the directory of the file that declared the step
+func cleanFilePath(filePath string, options map[string]any) (string, *types.ActionResult) {
+	cleanPath := filepath.Clean(resolveFilePath(filePath, options))
+	if isAllowedPath(cleanPath, options) {
+		return cleanPath, nil
 	}
 
-	// Check if the absolute path is within the current working directory
-	relPath, err := filepath.Rel(cwd, path)
-	if err != nil {
-		return false
+	// Security: Prevent absolute paths that could access system files
+	if filepath.IsAbs(filePath) {
+		errorResult := types.NewErrorBuilder(types.ErrorCategoryValidation, "UNSAFE_FILE_PATH").
+			WithTemplate("Absolute file paths are restricted for security: %s").
+			WithContext("file_path", filePath).
+			WithContext("clean_path", cleanPath).
+			WithSuggestion("Use paths relative to the test file, or build them from ${suite.dir} or ${run.dir}").
+			WithSuggestion("Allowed absolute paths must be in the run directory or the test file's directory").
+			Build(cleanPath)
+		return "", &errorResult
 	}
 
-	// If relative path starts with "..", it's outside the working directory
-	return !strings.HasPrefix(relPath, "..")
+	// Security: Prevent path traversal
+	errorResult := types.NewErrorBuilder(types.ErrorCategoryValidation, "PATH_TRAVERSAL_DETECTED").
+		WithTemplate("Path traversal detected in file path: %s").
+		WithContext("file_path", filePath).
+		WithContext("clean_path", cleanPath).
+		WithSuggestion("Use paths that stay within the run directory or the test file's directory").
+		Build(cleanPath)
+	return "", &errorResult
+}
+
+// isAllowedPath checks that a resolved path is within the run directory, the running test
+// file's directory or the directory of the file that declared the step
+func isAllowedPath(path string, options map[string]any) bool {
+	if common.WithinDir(common.RunDir(), path) {
+		return true
+	}
+	for _, option := range []string{SuiteDirOption, BaseDirOption} {
+		if dir, ok := options[option].(string); ok && dir != "" && common.WithinDir(dir, path) {
+			return true
+		}
+	}
+	return false
 }
 
 // determineFileFormat determines the file format from extension or options
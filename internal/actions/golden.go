@@ -0,0 +1,396 @@
+package actions
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/JianLoong/robogo/internal/common"
+	"github.com/JianLoong/robogo/internal/constants"
+	"github.com/JianLoong/robogo/internal/types"
+)
+
+// UpdateGoldenEnvVar makes golden steps rewrite their files instead of comparing when set to "1" or "true"
+const UpdateGoldenEnvVar = "ROBOGO_UPDATE_GOLDEN"
+
+// goldenDiffContext is the number of unchanged lines shown around each change
+const goldenDiffContext = 3
+
+// maxGoldenDiffCells bounds the line alignment; larger changes are shown as one removed and added block
+const maxGoldenDiffCells = 4_000_000
+
+// goldenAction compares a value to the contents of a golden file
+// Args: [actual, golden_path] - value to check and the file holding the expected output
+// Options:
+//   - format: "json" or "text" (default: json when the value or golden file is JSON)
+//   - ignore_paths: JSON paths left out of the comparison, e.g. ["id", "items.*.created_at"]
+func goldenAction(args []any, options map[string]any, vars *common.Variables) types.ActionResult {
+	if len(args) < 2 {
+		return types.MissingArgsError("golden", 2, len(args))
+	}
+
+	if errorResult := validateArgsResolved("golden", args); errorResult != nil {
+		return *errorResult
+	}
+
+	goldenPath, errorResult := cleanFilePath(fmt.Sprintf("%v", args[1]), options)
+	if errorResult != nil {
+		return *errorResult
+	}
+
+	var ignorePaths []string
+	if paths, ok := options["ignore_paths"].([]any); ok {
+		for _, path := range paths {
+			ignorePaths = append(ignorePaths, fmt.Sprintf("%v", path))
+		}
+	}
+
+	update := false
+	switch strings.ToLower(os.Getenv(UpdateGoldenEnvVar)) {
+	case "1", "true":
+		update = true
+	}
+
+	existing, err := os.ReadFile(goldenPath)
+	if err != nil && !(update && os.IsNotExist(err)) {
+		builder := types.NewErrorBuilder(types.ErrorCategoryFileSystem, "GOLDEN_READ_FAILED").
+			WithTemplate("Failed to read golden file %s: %s").
+			WithContext("golden_path", goldenPath)
+		if os.IsNotExist(err) {
+			builder = builder.WithSuggestion("Run with --update-golden to create it from the current output")
+		}
+		return builder.Build(goldenPath, err.Error())
+	}
+
+	format, _ := options["format"].(string)
+	if format == "" {
+		format = "text"
+		if isJSONValue(args[0]) || (isJSONText(args[0]) && (existing == nil || json.Valid(existing))) {
+			format = "json"
+		}
+	}
+
+	var actual, expected string
+	switch format {
+	case "json":
+		actual, err = canonicalJSON(args[0], ignorePaths)
+		if err != nil {
+			return types.InvalidArgError("golden", "actual value", fmt.Sprintf("JSON (%s)", err.Error()))
+		}
+		if existing != nil {
+			var expectedValue any
+			if err := json.Unmarshal(existing, &expectedValue); err == nil {
+				expected, _ = canonicalJSON(expectedValue, ignorePaths)
+			} else if !update {
+				return types.NewErrorBuilder(types.ErrorCategoryValidation, "GOLDEN_INVALID_JSON").
+					WithTemplate("Golden file %s is not valid JSON: %s").
+					WithSuggestion("Run with --update-golden to regenerate it").
+					Build(goldenPath, err.Error())
+			}
+		}
+	case "text":
+		actual = normalizeGoldenText(fmt.Sprintf("%v", args[0]))
+		expected = normalizeGoldenText(string(existing))
+	default:
+		return types.InvalidArgError("golden", "format", "json or text")
+	}
+
+	if update {
+		if existing == nil || actual != expected {
+			// Ignored fields are kept in the file; they are only left out when comparing
+			content := actual
+			if format == "json" {
+				content, _ = canonicalJSON(args[0], nil)
+			}
+			if errorResult := writeGoldenFile(goldenPath, content); errorResult != nil {
+				return *errorResult
+			}
+			fmt.Printf("📝 Updated golden file %s\n", goldenPath)
+		}
+		return types.ActionResult{
+			Status: constants.ActionStatusPassed,
+			Data:   map[string]any{"matched": existing != nil && actual == expected, "updated": true, "golden_path": goldenPath},
+		}
+	}
+
+	if actual != expected {
+		return types.NewFailureBuilder(types.FailureCategoryData, "GOLDEN_MISMATCH").
+			WithTemplate("Output does not match golden file %s:\n%s").
+			WithSuggestion("Re-run with --update-golden if the new output is correct").
+			Build(goldenPath, unifiedDiff(expected, actual, goldenPath, "actual"))
+	}
+
+	return types.ActionResult{
+		Status: constants.ActionStatusPassed,
+		Data:   map[string]any{"matched": true, "updated": false, "golden_path": goldenPath},
+	}
+}
+
+// writeGoldenFile stores the current output with a trailing newline
+func writeGoldenFile(goldenPath, content string) *types.ActionResult {
+	content += "\n"
+
+	if dir := filepath.Dir(goldenPath); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			errorResult := types.NewErrorBuilder(types.ErrorCategoryFileSystem, "GOLDEN_WRITE_FAILED").
+				WithTemplate("Failed to create directory for golden file %s: %s").
+				Build(goldenPath, err.Error())
+			return &errorResult
+		}
+	}
+	if err := os.WriteFile(goldenPath, []byte(content), 0o644); err != nil {
+		errorResult := types.NewErrorBuilder(types.ErrorCategoryFileSystem, "GOLDEN_WRITE_FAILED").
+			WithTemplate("Failed to write golden file %s: %s").
+			Build(goldenPath, err.Error())
+		return &errorResult
+	}
+	return nil
+}
+
+// isJSONValue reports whether a value is structured data rather than a scalar
+func isJSONValue(value any) bool {
+	switch value.(type) {
+	case map[string]any, []any:
+		return true
+	}
+	return false
+}
+
+// isJSONText reports whether a string value holds a JSON object or array
+func isJSONText(value any) bool {
+	text, ok := value.(string)
+	if !ok {
+		return false
+	}
+	text = strings.TrimSpace(text)
+	return (strings.HasPrefix(text, "{") || strings.HasPrefix(text, "[")) && json.Valid([]byte(text))
+}
+
+// canonicalJSON returns indented JSON with sorted keys and the ignored paths removed.
+// JSON strings are parsed first so formatting differences do not count.
+func canonicalJSON(value any, ignorePaths []string) (string, error) {
+	var data any
+	if text, ok := value.(string); ok {
+		if err := json.Unmarshal([]byte(text), &data); err != nil {
+			return "", err
+		}
+	} else {
+		// Round-trip so YAML maps and numbers compare like their JSON equivalents
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return "", err
+		}
+		if err := json.Unmarshal(encoded, &data); err != nil {
+			return "", err
+		}
+	}
+
+	for _, path := range ignorePaths {
+		data = removeJSONPath(data, strings.Split(path, "."))
+	}
+
+	pretty, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(pretty), nil
+}
+
+// removeJSONPath deletes the value at a dotted path; "*" matches every key or element
+func removeJSONPath(data any, segments []string) any {
+	if len(segments) == 0 {
+		return data
+	}
+	segment, rest := segments[0], segments[1:]
+
+	switch node := data.(type) {
+	case map[string]any:
+		for key, child := range node {
+			if segment != "*" && key != segment {
+				continue
+			}
+			if len(rest) == 0 {
+				delete(node, key)
+			} else {
+				node[key] = removeJSONPath(child, rest)
+			}
+		}
+	case []any:
+		if len(rest) == 0 {
+			// Removing elements would shift indexes, so ignored elements are blanked
+			for i := range node {
+				if segment == "*" || segment == fmt.Sprint(i) {
+					node[i] = nil
+				}
+			}
+			return node
+		}
+		for i, child := range node {
+			if segment == "*" || segment == fmt.Sprint(i) {
+				node[i] = removeJSONPath(child, rest)
+			}
+		}
+	}
+	return data
+}
+
+// normalizeGoldenText drops trailing whitespace on each line and trailing blank lines
+func normalizeGoldenText(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
+
+// unifiedDiff returns a unified diff of two texts line by line
+func unifiedDiff(expected, actual, expectedName, actualName string) string {
+	a := strings.Split(expected, "\n")
+	b := strings.Split(actual, "\n")
+
+	// Only the differing middle needs a line-by-line diff
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	midA := a[prefix : len(a)-suffix]
+	midB := b[prefix : len(b)-suffix]
+
+	var ops []diffOp
+	for i := 0; i < prefix; i++ {
+		ops = append(ops, diffOp{' ', a[i]})
+	}
+	if len(midA)*len(midB) > maxGoldenDiffCells {
+		// Too large to align; show the changed block as a whole
+		for _, line := range midA {
+			ops = append(ops, diffOp{'-', line})
+		}
+		for _, line := range midB {
+			ops = append(ops, diffOp{'+', line})
+		}
+	} else {
+		ops = append(ops, lineDiff(midA, midB)...)
+	}
+	for i := len(a) - suffix; i < len(a); i++ {
+		ops = append(ops, diffOp{' ', a[i]})
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", expectedName, actualName)
+	for _, hunk := range diffHunks(ops) {
+		out.WriteString(hunk)
+	}
+	return strings.TrimRight(out.String(), "\n")
+}
+
+// diffOp is one line of a diff: ' ' unchanged, '-' only in expected, '+' only in actual
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// lineDiff aligns two line lists using their longest common subsequence
+func lineDiff(a, b []string) []diffOp {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var ops []diffOp
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, diffOp{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, diffOp{'+', b[j]})
+	}
+	return ops
+}
+
+// diffHunks groups changes with their surrounding context into @@ hunks
+func diffHunks(ops []diffOp) []string {
+	var hunks []string
+	for start := 0; start < len(ops); {
+		// Find the next change
+		for start < len(ops) && ops[start].kind == ' ' {
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+
+		// Extend until a run of unchanged lines long enough to split hunks
+		end := start
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].kind == ' ' {
+				run++
+			}
+			if run == len(ops) || run-end > 2*goldenDiffContext {
+				break
+			}
+			end = run
+		}
+
+		from := max(start-goldenDiffContext, 0)
+		to := min(end+goldenDiffContext, len(ops))
+
+		// Line numbers are 1-based positions in each file
+		lineA, lineB := 1, 1
+		for _, op := range ops[:from] {
+			if op.kind != '+' {
+				lineA++
+			}
+			if op.kind != '-' {
+				lineB++
+			}
+		}
+		countA, countB := 0, 0
+		var body strings.Builder
+		for _, op := range ops[from:to] {
+			if op.kind != '+' {
+				countA++
+			}
+			if op.kind != '-' {
+				countB++
+			}
+			fmt.Fprintf(&body, "%c%s\n", op.kind, op.line)
+		}
+		hunks = append(hunks, fmt.Sprintf("@@ -%d,%d +%d,%d @@\n%s", lineA, countA, lineB, countB, body.String()))
+		start = to
+	}
+	return hunks
+}
@@ -0,0 +1,230 @@
+package actions
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/JianLoong/robogo/internal/common"
+	"github.com/JianLoong/robogo/internal/constants"
+	"github.com/JianLoong/robogo/internal/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// healthProbe performs one health check attempt. It reports whether the service is healthy,
+// the status it reported, and an error when it could not be reached at all.
+type healthProbe func(ctx context.Context) (bool, string, error)
+
+// healthCheckAction polls an HTTP health endpoint or a gRPC health service until it is healthy
+// Args: [target] - http(s)://host/path, or grpc://host:port (grpcs:// for TLS)
+// Options:
+//   - timeout: how long to keep polling (default: "30s")
+//   - interval: delay between attempts (default: "1s")
+//   - expected_status: HTTP status that counts as healthy (default: any 2xx)
+//   - service: gRPC service name to check (default: the whole server)
+//   - skip_tls_verify: skip TLS certificate verification
+func healthCheckAction(args []any, options map[string]any, vars *common.Variables) types.ActionResult {
+	if len(args) < 1 {
+		return types.MissingArgsError("health_check", 1, len(args))
+	}
+
+	if errorResult := validateArgsResolved("health_check", args); errorResult != nil {
+		return *errorResult
+	}
+
+	target := fmt.Sprintf("%v", args[0])
+	parsed, err := url.Parse(target)
+	if err != nil || parsed.Host == "" {
+		return types.NewErrorBuilder(types.ErrorCategoryValidation, "HEALTH_CHECK_INVALID_TARGET").
+			WithTemplate("Invalid health check target '%s'").
+			WithSuggestion("Use http(s)://host/health or grpc://host:port").
+			Build(target)
+	}
+
+	timeout, errorResult := healthCheckDuration(options, "timeout", 30*time.Second)
+	if errorResult != nil {
+		return *errorResult
+	}
+	interval, errorResult := healthCheckDuration(options, "interval", time.Second)
+	if errorResult != nil {
+		return *errorResult
+	}
+
+	skipTLS, _ := options["skip_tls_verify"].(bool)
+
+	var probe healthProbe
+	switch parsed.Scheme {
+	case "http", "https":
+		expectedStatus := 0
+		if value, exists := options["expected_status"]; exists {
+			expectedStatus, err = strconv.Atoi(fmt.Sprintf("%v", value))
+			if err != nil {
+				return types.InvalidArgError("health_check", "expected_status", "an HTTP status code such as 200")
+			}
+		}
+		probe = httpHealthProbe(target, expectedStatus, skipTLS)
+	case "grpc", "grpcs":
+		service, _ := options["service"].(string)
+		conn, err := newHealthConn(parsed, skipTLS)
+		if err != nil {
+			return types.ConnectionError(target, err.Error())
+		}
+		defer conn.Close()
+		probe = grpcHealthProbe(healthpb.NewHealthClient(conn), service)
+	default:
+		return types.NewErrorBuilder(types.ErrorCategoryValidation, "HEALTH_CHECK_INVALID_TARGET").
+			WithTemplate("Unsupported health check scheme '%s'").
+			WithSuggestion("Use http, https, grpc or grpcs").
+			Build(parsed.Scheme)
+	}
+
+	return pollHealth(target, probe, timeout, interval)
+}
+
+// pollHealth runs the probe until it reports healthy or the timeout passes
+func pollHealth(target string, probe healthProbe, timeout, interval time.Duration) types.ActionResult {
+	fmt.Printf("🩺 Waiting for %s to become healthy (timeout %s)...\n", target, timeout)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	attempts := 0
+	responded := false
+	lastStatus := ""
+	var lastErr error
+	for {
+		attempts++
+		healthy, reported, err := probe(ctx)
+		if err == nil {
+			responded = true
+			lastStatus = reported
+			if healthy {
+				elapsed := time.Since(start).Round(time.Millisecond)
+				fmt.Printf("✅ %s is healthy after %d attempt(s) (%s)\n", target, attempts, elapsed)
+				return types.ActionResult{
+					Status: constants.ActionStatusPassed,
+					Data: map[string]any{
+						"healthy":  true,
+						"status":   reported,
+						"attempts": attempts,
+						"elapsed":  elapsed.String(),
+						"target":   target,
+					},
+				}
+			}
+		} else {
+			lastErr = err
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(interval):
+			continue
+		}
+		break
+	}
+
+	fmt.Printf("❌ %s did not become healthy within %s\n", target, timeout)
+
+	// A service that never answered is a connection problem, not a slow one
+	if !responded {
+		details := "no response"
+		if lastErr != nil {
+			details = lastErr.Error()
+		}
+		return types.NewErrorBuilder(types.ErrorCategoryNetwork, "HEALTH_CHECK_CONNECTION_FAILED").
+			WithTemplate("Could not reach %s within %s: %s").
+			WithContext("attempts", attempts).
+			WithSuggestion("Check that the service is started and the address is correct").
+			Build(target, timeout, details)
+	}
+
+	return types.NewErrorBuilder(types.ErrorCategoryNetwork, "TIMEOUT").
+		WithTemplate("%s was not healthy within %s (last status: %s)").
+		WithContext("attempts", attempts).
+		WithContext("last_status", lastStatus).
+		WithSuggestion("Raise the timeout option if the service needs longer to start").
+		Build(target, timeout, lastStatus)
+}
+
+// httpHealthProbe treats the expected status, or any 2xx when none is set, as healthy
+func httpHealthProbe(target string, expectedStatus int, skipTLS bool) healthProbe {
+	client := &http.Client{}
+	if skipTLS {
+		client.Transport = &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		}
+	}
+
+	return func(ctx context.Context) (bool, string, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+		if err != nil {
+			return false, "", err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return false, "", err
+		}
+		resp.Body.Close()
+
+		healthy := resp.StatusCode >= 200 && resp.StatusCode < 300
+		if expectedStatus != 0 {
+			healthy = resp.StatusCode == expectedStatus
+		}
+		return healthy, strconv.Itoa(resp.StatusCode), nil
+	}
+}
+
+// grpcHealthProbe uses the standard grpc.health.v1 protocol; only SERVING is healthy
+func grpcHealthProbe(client healthpb.HealthClient, service string) healthProbe {
+	return func(ctx context.Context) (bool, string, error) {
+		resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+		if err != nil {
+			switch status.Code(err) {
+			case codes.Unavailable, codes.DeadlineExceeded, codes.Canceled:
+				return false, "", err
+			case codes.NotFound:
+				// The service may not be registered yet while the server starts
+				return false, "SERVICE_UNKNOWN", nil
+			}
+			return false, status.Code(err).String(), nil
+		}
+		return resp.GetStatus() == healthpb.HealthCheckResponse_SERVING, resp.GetStatus().String(), nil
+	}
+}
+
+// newHealthConn creates a gRPC client; the connection is made lazily on the first check
+func newHealthConn(target *url.URL, skipTLS bool) (*grpc.ClientConn, error) {
+	creds := insecure.NewCredentials()
+	if target.Scheme == "grpcs" {
+		creds = credentials.NewTLS(&tls.Config{InsecureSkipVerify: skipTLS})
+	}
+	return grpc.NewClient(target.Host, grpc.WithTransportCredentials(creds))
+}
+
+// healthCheckDuration reads a duration option, returning an error result for bad formats
+func healthCheckDuration(options map[string]any, name string, defaultValue time.Duration) (time.Duration, *types.ActionResult) {
+	value, ok := options[name].(string)
+	if !ok {
+		return defaultValue, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		errorResult := types.NewErrorBuilder(types.ErrorCategoryValidation, "HEALTH_CHECK_INVALID_DURATION").
+			WithTemplate("Invalid %s '%s' for health check").
+			WithSuggestion("Use format like '5s', '500ms', '1m'").
+			Build(name, value)
+		return 0, &errorResult
+	}
+	return duration, nil
+}
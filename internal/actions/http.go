@@ -1,13 +1,19 @@
 package actions
 
 import (
+	"bytes"
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
+	"mime"
+	"mime/multipart"
 	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
 	"reflect"
-	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -16,6 +22,18 @@ import (
 	"github.com/JianLoong/robogo/internal/types"
 )
 
+// CorrelationIDVariable holds the correlation ID the runner generates for each test case
+const CorrelationIDVariable = "correlation_id"
+
+// CorrelationHeaderEnvVar names the correlation header when a step does not set correlation_header
+const CorrelationHeaderEnvVar = "ROBOGO_CORRELATION_HEADER"
+
+// defaultCorrelationHeader carries the correlation ID when nothing else is configured
+const defaultCorrelationHeader = "X-Correlation-Id"
+
+// defaultIdempotencyHeader carries the step's idempotency key when idempotency_key is true
+const defaultIdempotencyHeader = "Idempotency-Key"
+
 // httpAction performs an HTTP request. It always returns status code, headers, and raw body.
 func httpAction(args []any, options map[string]any, vars *common.Variables) types.ActionResult {
 
@@ -30,6 +48,8 @@ func httpAction(args []any, options map[string]any, vars *common.Variables) type
 
 	method := fmt.Sprintf("%v", args[0])
 	url := fmt.Sprintf("%v", args[1])
+	// Error messages name the request without credentials or sensitive query values
+	operation := fmt.Sprintf("HTTP %s %s", method, maskURLText(url, options))
 
 	// Extract request headers for context first (needed for body processing)
 	var requestHeaders map[string]string
@@ -40,8 +60,55 @@ func httpAction(args []any, options map[string]any, vars *common.Variables) type
 		}
 	}
 
-	var bodyReader io.Reader
+	// The request body comes from exactly one of these
+	var bodySources []string
 	if len(args) > 2 {
+		bodySources = append(bodySources, "a body argument")
+	}
+	for _, option := range []string{"xml_body", "multipart", "body_file", "body_file_raw"} {
+		if _, ok := options[option]; ok {
+			bodySources = append(bodySources, "the "+option+" option")
+		}
+	}
+	if len(bodySources) > 1 {
+		return types.NewErrorBuilder(types.ErrorCategoryValidation, "CONFLICTING_BODY").
+			WithTemplate("http action: cannot combine %s").
+			WithSuggestion("Provide the request body in one way only").
+			Build(strings.Join(bodySources, " and "))
+	}
+
+	var bodyReader io.Reader
+	multipartContentType := ""
+	defaultContentType := ""
+	if xmlSpec, ok := options["xml_body"]; ok {
+		body, errorResult := buildXMLBody(xmlSpec, vars)
+		if errorResult != nil {
+			return *errorResult
+		}
+		bodyReader = strings.NewReader(body)
+		defaultContentType = "application/xml"
+	} else if pathValue, ok := options["body_file"]; ok {
+		content, contentType, errorResult := readBodyFile(resolveFilePath(fmt.Sprintf("%v", pathValue), options))
+		if errorResult != nil {
+			return *errorResult
+		}
+		bodyReader = strings.NewReader(vars.Substitute(string(content)))
+		defaultContentType = contentType
+	} else if pathValue, ok := options["body_file_raw"]; ok {
+		content, contentType, errorResult := readBodyFile(resolveFilePath(fmt.Sprintf("%v", pathValue), options))
+		if errorResult != nil {
+			return *errorResult
+		}
+		bodyReader = bytes.NewReader(content)
+		defaultContentType = contentType
+	} else if multipartSpec, ok := options["multipart"].(map[string]any); ok {
+		body, contentType, errorResult := buildMultipartBody(multipartSpec, vars, options)
+		if errorResult != nil {
+			return *errorResult
+		}
+		bodyReader = body
+		multipartContentType = contentType
+	} else if len(args) > 2 {
 		// Get the body argument
 		bodyArg := args[2]
 
@@ -91,12 +158,12 @@ func httpAction(args []any, options map[string]any, vars *common.Variables) type
 						for i, field := range fieldsSlice {
 							customKeys[i] = fmt.Sprintf("%v", field)
 						}
-						maskedBody = maskSensitiveHTTPData(bodyStr, customKeys)
+						maskedBody = common.MaskSensitiveBody(bodyStr, common.SensitiveKeys(customKeys))
 					} else {
-						maskedBody = maskSensitiveHTTPData(bodyStr)
+						maskedBody = common.MaskSensitiveBody(bodyStr, common.SensitiveKeys())
 					}
 				} else {
-					maskedBody = maskSensitiveHTTPData(bodyStr)
+					maskedBody = common.MaskSensitiveBody(bodyStr, common.SensitiveKeys())
 				}
 				fmt.Printf("HTTP Request Body: %s\n", maskedBody)
 			}
@@ -111,9 +178,19 @@ func httpAction(args []any, options map[string]any, vars *common.Variables) type
 		}
 	}
 
+	// Guard against buffering very large response bodies
+	var maxBodySize int64
+	if sizeOpt, ok := options["max_body_size"]; ok {
+		parsed, err := strconv.ParseInt(fmt.Sprintf("%v", sizeOpt), 10, 64)
+		if err != nil || parsed < 1 {
+			return types.InvalidArgError("http", "max_body_size", "positive number of bytes")
+		}
+		maxBodySize = parsed
+	}
+
 	req, err := http.NewRequest(method, url, bodyReader)
 	if err != nil {
-		return types.RequestError(fmt.Sprintf("HTTP %s %s", method, url), err.Error())
+		return types.RequestError(operation, maskURLText(err.Error(), options))
 	}
 
 	if headers, ok := options["headers"].(map[string]any); ok {
@@ -122,6 +199,37 @@ func httpAction(args []any, options map[string]any, vars *common.Variables) type
 		}
 	}
 
+	// Tie the request to server logs; a correlation header set by the step wins
+	var correlationID string
+	if header := correlationHeader(options); header != "" {
+		correlationID = req.Header.Get(header)
+		if correlationID == "" && vars != nil && vars.Has(CorrelationIDVariable) {
+			correlationID = fmt.Sprintf("%v", vars.Get(CorrelationIDVariable))
+			req.Header.Set(header, correlationID)
+		}
+	}
+
+	// The same key is sent by every retry of the step, so the server can drop duplicates
+	var idempotencyKey string
+	if header := idempotencyHeader(options); header != "" {
+		idempotencyKey = req.Header.Get(header)
+		if idempotencyKey == "" && vars != nil {
+			if key, ok := vars.Lookup(common.StepVariable + "." + common.IdempotencyKeyField); ok {
+				idempotencyKey = fmt.Sprintf("%v", key)
+				req.Header.Set(header, idempotencyKey)
+			}
+		}
+	}
+
+	// Multipart bodies need the generated boundary, so override any user Content-Type
+	if multipartContentType != "" {
+		req.Header.Set("Content-Type", multipartContentType)
+	}
+	// Generated and file bodies keep a user Content-Type, such as text/xml for SOAP 1.1 services
+	if defaultContentType != "" && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", defaultContentType)
+	}
+
 	// Create HTTP client with optional TLS skip verification
 	client := &http.Client{Timeout: timeout}
 	
@@ -138,13 +246,39 @@ func httpAction(args []any, options map[string]any, vars *common.Variables) type
 	resp, err := client.Do(req)
 
 	if err != nil {
-		return types.RequestError(fmt.Sprintf("HTTP %s %s", method, url), err.Error())
+		return types.NewErrorBuilder(types.ErrorCategoryNetwork, "REQUEST_FAILED").
+			WithTemplate("%s failed: %s").
+			WithCorrelationID(correlationID).
+			Build(operation, maskURLText(err.Error(), options))
 	}
 	defer resp.Body.Close()
 
-	responseBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return types.RequestError(fmt.Sprintf("HTTP %s %s response read", method, url), err.Error())
+	// Streaming extraction keeps only the extracted value instead of the body
+	if query, ok := options[StreamExtractOption].(string); ok {
+		extracted, errorResult := streamExtract(resp.Body, query, maxBodySize, operation)
+		if errorResult != nil {
+			return withCorrelationID(*errorResult, correlationID)
+		}
+		data := map[string]any{
+			"status_code":     resp.StatusCode,
+			"headers":         resp.Header,
+			StreamedResultKey: extracted,
+		}
+		if correlationID != "" {
+			data["correlation_id"] = correlationID
+		}
+		if idempotencyKey != "" {
+			data["idempotency_key"] = idempotencyKey
+		}
+		return types.ActionResult{
+			Status: constants.ActionStatusPassed,
+			Data:   data,
+		}
+	}
+
+	responseBody, errorResult := readResponseBody(resp.Body, maxBodySize, operation)
+	if errorResult != nil {
+		return withCorrelationID(*errorResult, correlationID)
 	}
 
 	respBodyStr := string(responseBody)
@@ -153,6 +287,12 @@ func httpAction(args []any, options map[string]any, vars *common.Variables) type
 		"body":        respBodyStr,
 		"headers":     resp.Header,
 	}
+	if correlationID != "" {
+		result["correlation_id"] = correlationID
+	}
+	if idempotencyKey != "" {
+		result["idempotency_key"] = idempotencyKey
+	}
 
 	return types.ActionResult{
 		Status: constants.ActionStatusPassed,
@@ -160,139 +300,192 @@ func httpAction(args []any, options map[string]any, vars *common.Variables) type
 	}
 }
 
-// Helper functions to check types
-func isMap(v any) bool {
-	if v == nil {
-		return false
+// maskURLText masks URL credentials and sensitive query parameter values, including
+// those named in the step's sensitive_fields, in a URL or a message that contains one
+func maskURLText(text string, options map[string]any) string {
+	var customKeys []string
+	if fields, ok := options["sensitive_fields"].([]any); ok {
+		for _, field := range fields {
+			customKeys = append(customKeys, fmt.Sprintf("%v", field))
+		}
 	}
-	t := reflect.TypeOf(v)
-	kind := t.Kind()
-	return kind == reflect.Map
+	return common.MaskURLQuery(common.MaskCredentials(text), common.SensitiveKeys(customKeys))
 }
 
-func isSlice(v any) bool {
-	if v == nil {
-		return false
+// correlationHeader returns the header that carries the correlation ID: the
+// correlation_header option, then ROBOGO_CORRELATION_HEADER, then X-Correlation-Id.
+// An empty correlation_header turns the header off for the step.
+func correlationHeader(options map[string]any) string {
+	if header, ok := options["correlation_header"]; ok {
+		return strings.TrimSpace(fmt.Sprintf("%v", header))
 	}
-	t := reflect.TypeOf(v)
-	kind := t.Kind()
-	return kind == reflect.Slice || kind == reflect.Array
+	if header := strings.TrimSpace(os.Getenv(CorrelationHeaderEnvVar)); header != "" {
+		return header
+	}
+	return defaultCorrelationHeader
 }
 
-// maskSensitiveHTTPData masks sensitive information in HTTP request bodies
-// Now accepts custom sensitive fields from options for enhanced masking
-func maskSensitiveHTTPData(data string, customFields ...[]string) string {
-	// Combine default sensitive keys with custom fields
-	sensitiveKeys := []string{
-		"password", "pass", "passwd", "pwd",
-		"secret", "token", "key", "apikey", "api_key",
-		"authorization", "auth", "bearer",
-		"credential", "cred", "access_token", "refresh_token",
-		"session", "cookie", "jwt",
-	}
-	
-	// Add custom sensitive fields if provided
-	for _, customKeySet := range customFields {
-		sensitiveKeys = append(sensitiveKeys, customKeySet...)
-	}
-	
-	// Try to parse as JSON first for more intelligent masking
-	var jsonData map[string]any
-	if json.Unmarshal([]byte(data), &jsonData) == nil {
-		// For JSON data, use field-based masking with custom fields
-		return maskJSONSensitiveFieldsWithCustom(data, sensitiveKeys)
-	}
-	
-	// Fallback to regex-based masking for non-JSON data
-	result := data
-	for _, key := range sensitiveKeys {
-		// Match various patterns: "key":"value", key=value, key: value
-		patterns := []string{
-			fmt.Sprintf(`(?i)"%s"\s*:\s*"[^"]*"`, key),
-			fmt.Sprintf(`(?i)"%s"\s*:\s*'[^']*'`, key),
-			fmt.Sprintf(`(?i)%s\s*=\s*"[^"]*"`, key),
-			fmt.Sprintf(`(?i)%s\s*=\s*'[^']*'`, key),
-			fmt.Sprintf(`(?i)%s\s*=\s*[^\s&;]+`, key),
+// idempotencyHeader returns the header that carries the step's idempotency key: none
+// unless the idempotency_key option is set, Idempotency-Key when it is true, or the
+// header it names
+func idempotencyHeader(options map[string]any) string {
+	switch value := options["idempotency_key"].(type) {
+	case nil:
+		return ""
+	case bool:
+		if value {
+			return defaultIdempotencyHeader
 		}
-		
-		for _, pattern := range patterns {
-			re := regexp.MustCompile(pattern)
-			result = re.ReplaceAllStringFunc(result, func(match string) string {
-				// Keep the key but mask the value
-				if strings.Contains(match, ":") {
-					if strings.Contains(match, `"`) {
-						return fmt.Sprintf(`"%s": "***"`, key)
-					} else {
-						return fmt.Sprintf(`"%s": '***'`, key)
-					}
-				} else {
-					return fmt.Sprintf(`%s=***`, key)
-				}
-			})
+		return ""
+	default:
+		header := strings.TrimSpace(fmt.Sprintf("%v", value))
+		switch strings.ToLower(header) {
+		case "true":
+			return defaultIdempotencyHeader
+		case "false":
+			return ""
 		}
+		return header
 	}
-	
-	return result
 }
 
-// maskJSONSensitiveFields masks sensitive fields in JSON strings using default keys
-func maskJSONSensitiveFields(jsonStr string) string {
-	defaultKeys := []string{
-		"password", "pass", "passwd", "pwd",
-		"secret", "token", "key", "apikey", "api_key", "access_token",
-		"authorization", "auth", "bearer", "credential", "cred",
-		"session", "cookie", "jwt", "refresh_token",
+// withCorrelationID records the correlation ID on an error from reading the response
+func withCorrelationID(result types.ActionResult, correlationID string) types.ActionResult {
+	if result.ErrorInfo != nil && correlationID != "" {
+		result.ErrorInfo.CorrelationID = correlationID
 	}
-	return maskJSONSensitiveFieldsWithCustom(jsonStr, defaultKeys)
+	return result
 }
 
-// maskJSONSensitiveFieldsWithCustom masks sensitive fields in JSON strings with custom keys
-func maskJSONSensitiveFieldsWithCustom(jsonStr string, sensitiveKeys []string) string {
-	var data map[string]any
-	if err := json.Unmarshal([]byte(jsonStr), &data); err != nil {
-		return jsonStr // Return original if not valid JSON
+// buildXMLBody builds an XML document from the xml_body option.
+// The option is a map with a single key naming the root element, e.g.
+// {Envelope: {"@attributes": {xmlns: "..."}, Body: {...}}}.
+func buildXMLBody(spec any, vars *common.Variables) (string, *types.ActionResult) {
+	root, ok := spec.(map[string]any)
+	if !ok || len(root) != 1 {
+		errorResult := types.InvalidArgError("http", "xml_body", "map with a single root element")
+		return "", &errorResult
 	}
-	
-	maskSensitiveJSONValuesWithCustom(data, sensitiveKeys)
-	
-	maskedBytes, err := json.Marshal(data)
+
+	var rootElement string
+	var data any
+	for rootElement, data = range root {
+	}
+
+	xmlString, err := buildXMLFromData(substituteVariablesInData(data, vars), map[string]any{
+		"declaration":  true,
+		"root_element": rootElement,
+	})
 	if err != nil {
-		return jsonStr // Return original if can't re-marshal
+		errorResult := types.NewErrorBuilder(types.ErrorCategoryValidation, "XML_BUILD_ERROR").
+			WithTemplate("Failed to build XML body: %s").
+			Build(err.Error())
+		return "", &errorResult
 	}
-	
-	return string(maskedBytes)
+	return xmlString, nil
 }
 
-// maskSensitiveJSONValues recursively masks sensitive values in JSON objects using default keys
-func maskSensitiveJSONValues(obj map[string]any) {
-	defaultKeys := []string{
-		"password", "pass", "passwd", "pwd",
-		"secret", "token", "key", "apikey", "api_key", "access_token",
-		"authorization", "auth", "bearer", "credential", "cred",
-		"session", "cookie", "jwt", "refresh_token",
+// readBodyFile reads a request body file for the body_file and body_file_raw options.
+// The content type is guessed from the extension, falling back to application/octet-stream.
+func readBodyFile(path string) ([]byte, string, *types.ActionResult) {
+	path = filepath.Clean(path)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		errorResult := types.NewErrorBuilder(types.ErrorCategoryFileSystem, "BODY_FILE_READ_ERROR").
+			WithTemplate("Failed to read request body file: %s").
+			WithContext("file_path", path).
+			WithSuggestion("Check that the file exists and is readable").
+			WithSuggestion("Relative paths are resolved from the test file's directory, then the run directory").
+			Build(err.Error())
+		return nil, "", &errorResult
 	}
-	maskSensitiveJSONValuesWithCustom(obj, defaultKeys)
+
+	contentType := mime.TypeByExtension(filepath.Ext(path))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return content, contentType, nil
 }
 
-// maskSensitiveJSONValuesWithCustom recursively masks sensitive values in JSON objects with custom keys
-func maskSensitiveJSONValuesWithCustom(obj map[string]any, sensitiveKeys []string) {
-	for key, value := range obj {
-		lowerKey := strings.ToLower(key)
-		
-		// Check if this key should be masked
-		shouldMask := false
-		for _, sensitiveKey := range sensitiveKeys {
-			if strings.Contains(lowerKey, sensitiveKey) {
-				shouldMask = true
-				break
+// buildMultipartBody builds a multipart/form-data body from the multipart option.
+// Spec: fields - map of form field to text value; files - map of form field to file path.
+func buildMultipartBody(spec map[string]any, vars *common.Variables, options map[string]any) (io.Reader, string, *types.ActionResult) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	if fields, ok := spec["fields"].(map[string]any); ok {
+		for name, value := range fields {
+			if err := writer.WriteField(name, vars.Substitute(fmt.Sprintf("%v", value))); err != nil {
+				errorResult := types.RequestError("multipart field "+name, err.Error())
+				return nil, "", &errorResult
 			}
 		}
-		
-		if shouldMask {
-			obj[key] = "***"
-		} else if nested, ok := value.(map[string]any); ok {
-			// Recursively process nested objects
-			maskSensitiveJSONValuesWithCustom(nested, sensitiveKeys)
+	}
+
+	if files, ok := spec["files"].(map[string]any); ok {
+		for field, pathValue := range files {
+			path := filepath.Clean(resolveFilePath(vars.Substitute(fmt.Sprintf("%v", pathValue)), options))
+
+			content, err := os.ReadFile(path)
+			if err != nil {
+				errorResult := types.NewErrorBuilder(types.ErrorCategoryFileSystem, "MULTIPART_FILE_READ_ERROR").
+					WithTemplate("Failed to read file for multipart upload: %s").
+					WithContext("field", field).
+					WithContext("file_path", path).
+					WithSuggestion("Check that the file exists and is readable").
+					WithSuggestion("Relative paths are resolved from the test file's directory, then the run directory").
+					Build(err.Error())
+				return nil, "", &errorResult
+			}
+
+			contentType := mime.TypeByExtension(filepath.Ext(path))
+			if contentType == "" {
+				contentType = "application/octet-stream"
+			}
+
+			header := make(textproto.MIMEHeader)
+			header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, escapeQuotes(field), escapeQuotes(filepath.Base(path))))
+			header.Set("Content-Type", contentType)
+
+			part, err := writer.CreatePart(header)
+			if err == nil {
+				_, err = part.Write(content)
+			}
+			if err != nil {
+				errorResult := types.RequestError("multipart file "+field, err.Error())
+				return nil, "", &errorResult
+			}
 		}
 	}
+
+	if err := writer.Close(); err != nil {
+		errorResult := types.RequestError("multipart body", err.Error())
+		return nil, "", &errorResult
+	}
+
+	return &buf, writer.FormDataContentType(), nil
+}
+
+// escapeQuotes escapes a value for use in a Content-Disposition header
+func escapeQuotes(s string) string {
+	return strings.NewReplacer("\\", "\\\\", `"`, "\\\"").Replace(s)
+}
+
+// Helper functions to check types
+func isMap(v any) bool {
+	if v == nil {
+		return false
+	}
+	t := reflect.TypeOf(v)
+	kind := t.Kind()
+	return kind == reflect.Map
+}
+
+func isSlice(v any) bool {
+	if v == nil {
+		return false
+	}
+	t := reflect.TypeOf(v)
+	kind := t.Kind()
+	return kind == reflect.Slice || kind == reflect.Array
 }
@@ -0,0 +1,246 @@
+package actions
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/JianLoong/robogo/internal/constants"
+	"github.com/JianLoong/robogo/internal/types"
+)
+
+// StreamExtractOption passes a jq query to the http action so it can extract from the
+// response body while reading it, instead of buffering the whole body first
+const StreamExtractOption = "__stream_extract"
+
+// StreamedResultKey holds the extracted value in the data of a streamed http response
+const StreamedResultKey = "extracted"
+
+// streamPathSegment matches one leading path segment: .name, .["name"] or [index]
+var streamPathSegment = regexp.MustCompile(`^(?:\.([A-Za-z_][A-Za-z0-9_]*)|\.?\["((?:[^"\\]|\\.)*)"\]|\[(\d+)\])`)
+
+// splitStreamPath splits a jq query into a leading path that can be followed while
+// decoding and the rest of the query, applied to the value found there.
+// ok is false when the query has no such path and the body must be buffered.
+func splitStreamPath(query string) (path []any, rest string, ok bool) {
+	remaining := strings.TrimSpace(query)
+	for {
+		match := streamPathSegment.FindStringSubmatch(remaining)
+		// A leading [..] without a dot is an array literal in jq, not a path
+		if match == nil || (len(path) == 0 && !strings.HasPrefix(match[0], ".")) {
+			break
+		}
+		switch {
+		case match[1] != "":
+			path = append(path, match[1])
+		case match[3] != "":
+			index, _ := strconv.Atoi(match[3])
+			path = append(path, index)
+		default:
+			key, err := strconv.Unquote(`"` + match[2] + `"`)
+			if err != nil {
+				return nil, "", false
+			}
+			path = append(path, key)
+		}
+		remaining = remaining[len(match[0]):]
+	}
+
+	if len(path) == 0 {
+		return nil, "", false
+	}
+	if remaining == "" {
+		return path, "", true
+	}
+	// Only split where the rest is a valid query on its own, e.g. "[] | .id" or "| length"
+	if strings.HasPrefix(remaining, "[]") || strings.HasPrefix(strings.TrimSpace(remaining), "|") {
+		return path, "." + remaining, true
+	}
+	return nil, "", false
+}
+
+// streamExtract applies a jq query to a JSON body. Values outside the query's leading
+// path are skipped while decoding, so only the selected part is held in memory.
+// Queries without a leading path fall back to buffering, bounded by maxBodySize.
+func streamExtract(body io.Reader, query string, maxBodySize int64, operation string) (any, *types.ActionResult) {
+	path, rest, ok := splitStreamPath(query)
+	if !ok {
+		data, errorResult := readResponseBody(body, maxBodySize, operation)
+		if errorResult != nil {
+			return nil, errorResult
+		}
+		var document any
+		if err := json.Unmarshal(data, &document); err != nil {
+			return nil, streamExtractError(query, err)
+		}
+		return runStreamQuery(document, query)
+	}
+
+	decoder := json.NewDecoder(body)
+	value, err := decodeJSONPath(decoder, path)
+	if err != nil {
+		return nil, streamExtractError(query, err)
+	}
+	if rest == "" {
+		return value, nil
+	}
+	return runStreamQuery(value, rest)
+}
+
+// runStreamQuery runs the remaining jq query on the decoded value
+func runStreamQuery(value any, query string) (any, *types.ActionResult) {
+	result := jqAction([]any{value, query}, map[string]any{}, nil)
+	if result.Status != constants.ActionStatusPassed {
+		return nil, &result
+	}
+	return result.Data, nil
+}
+
+// streamExtractError reports a body that could not be decoded along the query path
+func streamExtractError(query string, err error) *types.ActionResult {
+	errorResult := types.NewErrorBuilder(types.ErrorCategoryExecution, "STREAM_EXTRACTION_FAILED").
+		WithTemplate("Failed to extract %s from the response body: %s").
+		WithContext("extraction_path", query).
+		WithSuggestion("Streaming extraction needs a JSON response body").
+		WithSuggestion("The path is applied to the parsed body, e.g. '.data.items[0].id'").
+		Build(query, err.Error())
+	return &errorResult
+}
+
+// decodeJSONPath walks the decoder to the value at path and decodes only that value.
+// Missing keys, out-of-range indexes and null parents give nil, as in jq.
+func decodeJSONPath(decoder *json.Decoder, path []any) (any, error) {
+	for depth, segment := range path {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		if token == nil {
+			return nil, nil
+		}
+
+		found := false
+		switch segment := segment.(type) {
+		case string:
+			if token != json.Delim('{') {
+				return nil, fmt.Errorf("cannot index %s with \"%s\" at %s", jsonTokenType(token), segment, formatStreamPath(path[:depth]))
+			}
+			for decoder.More() {
+				key, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				if key == segment {
+					found = true
+					break
+				}
+				if err := skipJSONValue(decoder); err != nil {
+					return nil, err
+				}
+			}
+		case int:
+			if token != json.Delim('[') {
+				return nil, fmt.Errorf("cannot index %s with number at %s", jsonTokenType(token), formatStreamPath(path[:depth]))
+			}
+			for index := 0; decoder.More(); index++ {
+				if index == segment {
+					found = true
+					break
+				}
+				if err := skipJSONValue(decoder); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if !found {
+			return nil, nil
+		}
+	}
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// skipJSONValue reads past the next value without building it
+func skipJSONValue(decoder *json.Decoder) error {
+	depth := 0
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+// jsonTokenType names the JSON type a token starts, for error messages
+func jsonTokenType(token json.Token) string {
+	switch token {
+	case json.Delim('{'):
+		return "object"
+	case json.Delim('['):
+		return "array"
+	}
+	switch token.(type) {
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	}
+	return "value"
+}
+
+// formatStreamPath renders a path prefix in jq syntax
+func formatStreamPath(path []any) string {
+	if len(path) == 0 {
+		return "."
+	}
+	var builder strings.Builder
+	for _, segment := range path {
+		if index, ok := segment.(int); ok {
+			fmt.Fprintf(&builder, "[%d]", index)
+		} else {
+			fmt.Fprintf(&builder, ".%s", segment)
+		}
+	}
+	return builder.String()
+}
+
+// readResponseBody reads a whole response body, failing once it exceeds maxBodySize.
+// A maxBodySize of zero means no limit.
+func readResponseBody(body io.Reader, maxBodySize int64, operation string) ([]byte, *types.ActionResult) {
+	if maxBodySize > 0 {
+		body = io.LimitReader(body, maxBodySize+1)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		errorResult := types.RequestError(operation+" response read", err.Error())
+		return nil, &errorResult
+	}
+	if maxBodySize > 0 && int64(len(data)) > maxBodySize {
+		errorResult := types.NewErrorBuilder(types.ErrorCategoryValidation, "RESPONSE_TOO_LARGE").
+			WithTemplate("%s response body exceeds max_body_size of %d bytes").
+			WithContext("max_body_size", maxBodySize).
+			WithSuggestion("Raise the max_body_size option if the full body is needed").
+			WithSuggestion("Use 'stream: true' on a jq extract to decode only the part you need").
+			Build(operation, maxBodySize)
+		return nil, &errorResult
+	}
+	return data, nil
+}
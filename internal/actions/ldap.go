@@ -0,0 +1,403 @@
+package actions
+
+import (
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-ldap/ldap/v3"
+
+	"github.com/JianLoong/robogo/internal/common"
+	"github.com/JianLoong/robogo/internal/constants"
+	"github.com/JianLoong/robogo/internal/types"
+)
+
+const (
+	ldapDefaultMaxResults = 1000
+	ldapDefaultPageSize   = 500
+)
+
+// ldapBinaryAttributes are returned base64-encoded even when their bytes happen to be valid UTF-8
+var ldapBinaryAttributes = map[string]bool{
+	"objectguid":        true,
+	"objectsid":         true,
+	"jpegphoto":         true,
+	"photo":             true,
+	"thumbnailphoto":    true,
+	"usercertificate":   true,
+	"cacertificate":     true,
+	"userpkcs12":        true,
+	"msexchmailboxguid": true,
+}
+
+// ldapAction binds to an LDAP directory and searches, compares or changes entries
+// Args: [operation, url, dn] - url is ldap://host[:port] or ldaps://host[:port]; dn is the
+// search base for search and the entry for add, modify, delete and compare (not used by bind)
+// Options:
+//   - bind_dn, bind_password: simple bind credentials (anonymous when bind_dn is not set)
+//   - start_tls: upgrade an ldap:// connection with StartTLS
+//   - skip_tls_verify: skip certificate verification for ldaps:// and start_tls
+//   - timeout: e.g. "10s" (default: 30s)
+//   - filter: search filter (default: (objectClass=*))
+//   - attributes: attributes to return for search (default: all), or a map of attribute
+//     values for add
+//   - scope: base, one or sub (default: sub)
+//   - max_results: entries to return before stopping (default: 1000), read in pages
+//   - page_size: entries per page (default: 500)
+//   - changes: for modify, {add|replace|delete: {attribute: value(s)}}
+//   - attribute, value: for compare
+func ldapAction(args []any, options map[string]any, vars *common.Variables) types.ActionResult {
+	if len(args) < 2 {
+		return types.MissingArgsError("ldap", 2, len(args))
+	}
+
+	if errorResult := validateArgsResolved("ldap", args); errorResult != nil {
+		return *errorResult
+	}
+
+	operation := strings.ToLower(fmt.Sprintf("%v", args[0]))
+	serverURL := fmt.Sprintf("%v", args[1])
+	dn := ""
+	if len(args) > 2 {
+		dn = fmt.Sprintf("%v", args[2])
+	}
+
+	switch operation {
+	case "bind", "search", "add", "modify", "delete", "compare":
+	default:
+		return types.UnknownOperationError("ldap", operation)
+	}
+	if operation != "bind" && dn == "" {
+		return types.MissingArgsError("ldap "+operation, 3, len(args))
+	}
+
+	parsedURL, err := url.Parse(serverURL)
+	if err != nil || (parsedURL.Scheme != "ldap" && parsedURL.Scheme != "ldaps") || parsedURL.Host == "" {
+		return types.InvalidArgError("ldap", "url", "ldap://host[:port] or ldaps://host[:port]")
+	}
+	startTLS := parseBoolOption(options, "start_tls", false)
+	if startTLS && parsedURL.Scheme == "ldaps" {
+		return types.InvalidArgError("ldap", "start_tls", "start_tls with an ldap:// url; ldaps:// is already TLS")
+	}
+
+	// Validate the operation's options before connecting
+	var request func(conn *ldap.Conn) (map[string]any, error)
+	switch operation {
+	case "bind":
+		request = func(conn *ldap.Conn) (map[string]any, error) {
+			return map[string]any{"bound": true}, nil
+		}
+	case "search":
+		searchRequest, maxResults, pageSize, errorResult := ldapSearchRequest(dn, options)
+		if errorResult != nil {
+			return *errorResult
+		}
+		request = func(conn *ldap.Conn) (map[string]any, error) {
+			return ldapSearch(conn, searchRequest, maxResults, pageSize)
+		}
+	case "add":
+		attributes, ok := options["attributes"].(map[string]any)
+		if !ok || len(attributes) == 0 {
+			return types.InvalidArgError("ldap", "attributes", "map of attribute values for the new entry, e.g. {objectClass: [person], cn: alice}")
+		}
+		addRequest := ldap.NewAddRequest(dn, nil)
+		for _, name := range sortedKeys(attributes) {
+			addRequest.Attribute(name, ldapValues(attributes[name]))
+		}
+		request = func(conn *ldap.Conn) (map[string]any, error) {
+			return map[string]any{"dn": dn}, conn.Add(addRequest)
+		}
+	case "modify":
+		modifyRequest, errorResult := ldapModifyRequest(dn, options)
+		if errorResult != nil {
+			return *errorResult
+		}
+		request = func(conn *ldap.Conn) (map[string]any, error) {
+			return map[string]any{"dn": dn, "changes": len(modifyRequest.Changes)}, conn.Modify(modifyRequest)
+		}
+	case "delete":
+		request = func(conn *ldap.Conn) (map[string]any, error) {
+			return map[string]any{"dn": dn}, conn.Del(ldap.NewDelRequest(dn, nil))
+		}
+	case "compare":
+		attribute, hasAttribute := options["attribute"].(string)
+		value, hasValue := options["value"]
+		if !hasAttribute || attribute == "" || !hasValue {
+			return types.InvalidArgError("ldap", "attribute", "attribute and value options for compare")
+		}
+		request = func(conn *ldap.Conn) (map[string]any, error) {
+			matched, err := conn.Compare(dn, attribute, fmt.Sprintf("%v", value))
+			return map[string]any{"dn": dn, "attribute": attribute, "matched": matched}, err
+		}
+	}
+
+	timeout := constants.DefaultDatabaseTimeout
+	if timeoutStr, ok := options["timeout"].(string); ok {
+		if parsedTimeout, err := time.ParseDuration(timeoutStr); err == nil {
+			timeout = parsedTimeout
+		}
+	}
+	tlsConfig := &tls.Config{
+		ServerName:         parsedURL.Hostname(),
+		InsecureSkipVerify: parseBoolOption(options, "skip_tls_verify", false),
+	}
+
+	conn, err := ldap.DialURL(serverURL,
+		ldap.DialWithDialer(&net.Dialer{Timeout: timeout}),
+		ldap.DialWithTLSConfig(tlsConfig))
+	if err != nil {
+		return ldapConnectionError(serverURL, options, err)
+	}
+	defer conn.Close()
+	conn.SetTimeout(timeout)
+
+	if startTLS {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			return ldapConnectionError(serverURL, options, err)
+		}
+	}
+
+	bindDN, _ := options["bind_dn"].(string)
+	bindPassword := parseStringOption(options, "bind_password", "")
+	if bindDN != "" {
+		if err := conn.Bind(bindDN, bindPassword); err != nil {
+			return types.NewErrorBuilder(types.ErrorCategoryDatabase, "LDAP_BIND_FAILED").
+				WithTemplate("LDAP bind failed: %s").
+				WithContext("url", serverURL).
+				WithContext("bind_options", maskedLDAPOptions(options)).
+				WithContext("error", err.Error()).
+				WithSuggestion("Check bind_dn and bind_password").
+				WithSuggestion("Servers that refuse simple binds over plain connections need ldaps:// or start_tls").
+				Build(ldapErrorMessage(err))
+		}
+	}
+
+	data, err := request(conn)
+	if err != nil {
+		return types.NewErrorBuilder(types.ErrorCategoryDatabase, "LDAP_OPERATION_FAILED").
+			WithTemplate("LDAP %s failed: %s").
+			WithContext("operation", operation).
+			WithContext("dn", dn).
+			WithContext("bind_options", maskedLDAPOptions(options)).
+			WithContext("error", err.Error()).
+			WithSuggestion("Check that the DN exists and the bind user may access it").
+			WithSuggestion("Check the filter syntax and attribute names against the directory schema").
+			Build(operation, ldapErrorMessage(err))
+	}
+
+	return types.ActionResult{
+		Status: constants.ActionStatusPassed,
+		Data:   data,
+	}
+}
+
+// ldapSearchRequest builds a search request from the options, returning the result limit and page size
+func ldapSearchRequest(baseDN string, options map[string]any) (*ldap.SearchRequest, int, int, *types.ActionResult) {
+	scope := ldap.ScopeWholeSubtree
+	switch strings.ToLower(parseStringOption(options, "scope", "sub")) {
+	case "base":
+		scope = ldap.ScopeBaseObject
+	case "one":
+		scope = ldap.ScopeSingleLevel
+	case "sub":
+	default:
+		errorResult := types.InvalidArgError("ldap", "scope", "base, one or sub")
+		return nil, 0, 0, &errorResult
+	}
+
+	filter := parseStringOption(options, "filter", "(objectClass=*)")
+	if _, err := ldap.CompileFilter(filter); err != nil {
+		errorResult := types.InvalidArgError("ldap", "filter", "an LDAP filter such as (&(objectClass=person)(uid=alice)): "+err.Error())
+		return nil, 0, 0, &errorResult
+	}
+
+	var attributes []string
+	if value, ok := options["attributes"]; ok {
+		list, isList := value.([]any)
+		if !isList {
+			errorResult := types.InvalidArgError("ldap", "attributes", "list of attribute names to return")
+			return nil, 0, 0, &errorResult
+		}
+		for _, item := range list {
+			attributes = append(attributes, fmt.Sprintf("%v", item))
+		}
+	}
+
+	maxResults := parseIntOption(options, "max_results", ldapDefaultMaxResults)
+	pageSize := parseIntOption(options, "page_size", ldapDefaultPageSize)
+	if maxResults <= 0 || pageSize <= 0 {
+		errorResult := types.InvalidArgError("ldap", "max_results", "max_results and page_size greater than 0")
+		return nil, 0, 0, &errorResult
+	}
+	if pageSize > maxResults {
+		pageSize = maxResults
+	}
+
+	return ldap.NewSearchRequest(baseDN, scope, ldap.NeverDerefAliases, 0, 0, false, filter, attributes, nil),
+		maxResults, pageSize, nil
+}
+
+// ldapSearch reads entries page by page until the server has no more or maxResults is reached
+func ldapSearch(conn *ldap.Conn, request *ldap.SearchRequest, maxResults, pageSize int) (map[string]any, error) {
+	paging := ldap.NewControlPaging(uint32(pageSize))
+	request.Controls = []ldap.Control{paging}
+
+	entries := []any{}
+	truncated := false
+	for {
+		result, err := conn.Search(request)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range result.Entries {
+			if len(entries) == maxResults {
+				truncated = true
+				break
+			}
+			entries = append(entries, ldapEntry(entry))
+		}
+
+		var cookie []byte
+		if control, ok := ldap.FindControl(result.Controls, ldap.ControlTypePaging).(*ldap.ControlPaging); ok {
+			cookie = control.Cookie
+		}
+		if len(cookie) == 0 {
+			break
+		}
+		if truncated || len(entries) == maxResults {
+			// More pages are left; a zero page size tells the server to drop the search
+			truncated = true
+			paging.PagingSize = 0
+			paging.SetCookie(cookie)
+			conn.Search(request)
+			break
+		}
+		paging.SetCookie(cookie)
+	}
+
+	return map[string]any{
+		"entries":   entries,
+		"count":     len(entries),
+		"truncated": truncated,
+	}, nil
+}
+
+// ldapEntry converts an entry to a map of its DN and attribute values; values are always
+// lists, with binary values base64-encoded
+func ldapEntry(entry *ldap.Entry) map[string]any {
+	result := map[string]any{"dn": entry.DN}
+	for _, attribute := range entry.Attributes {
+		binary := ldapBinaryAttributes[strings.ToLower(attribute.Name)] || strings.HasSuffix(strings.ToLower(attribute.Name), ";binary")
+		values := make([]any, len(attribute.ByteValues))
+		for i, value := range attribute.ByteValues {
+			if binary || !utf8.Valid(value) {
+				values[i] = base64.StdEncoding.EncodeToString(value)
+			} else {
+				values[i] = string(value)
+			}
+		}
+		result[attribute.Name] = values
+	}
+	return result
+}
+
+// ldapModifyRequest builds a modify request from the changes option
+func ldapModifyRequest(dn string, options map[string]any) (*ldap.ModifyRequest, *types.ActionResult) {
+	changes, ok := options["changes"].(map[string]any)
+	if !ok || len(changes) == 0 {
+		errorResult := types.InvalidArgError("ldap", "changes", "map of add, replace or delete to {attribute: value(s)}")
+		return nil, &errorResult
+	}
+
+	modifyRequest := ldap.NewModifyRequest(dn, nil)
+	// Apply add, replace and delete in a fixed order so the request is repeatable
+	for _, change := range []string{"add", "replace", "delete"} {
+		value, exists := changes[change]
+		if !exists {
+			continue
+		}
+		attributes, isMap := value.(map[string]any)
+		if !isMap {
+			errorResult := types.InvalidArgError("ldap", "changes."+change, "map of attribute names to value(s)")
+			return nil, &errorResult
+		}
+		for _, name := range sortedKeys(attributes) {
+			values := ldapValues(attributes[name])
+			switch change {
+			case "add":
+				modifyRequest.Add(name, values)
+			case "replace":
+				modifyRequest.Replace(name, values)
+			case "delete":
+				modifyRequest.Delete(name, values)
+			}
+		}
+	}
+	for change := range changes {
+		if change != "add" && change != "replace" && change != "delete" {
+			errorResult := types.InvalidArgError("ldap", "changes", "keys add, replace or delete, got '"+change+"'")
+			return nil, &errorResult
+		}
+	}
+	return modifyRequest, nil
+}
+
+// ldapValues reads attribute values from a single value or a list; nil gives no values,
+// which deletes every value of the attribute in a modify delete
+func ldapValues(value any) []string {
+	switch v := value.(type) {
+	case nil:
+		return []string{}
+	case []any:
+		values := make([]string, len(v))
+		for i, item := range v {
+			values[i] = fmt.Sprintf("%v", item)
+		}
+		return values
+	default:
+		return []string{fmt.Sprintf("%v", v)}
+	}
+}
+
+// ldapConnectionError reports a failed dial or StartTLS
+func ldapConnectionError(serverURL string, options map[string]any, err error) types.ActionResult {
+	return types.NewErrorBuilder(types.ErrorCategoryDatabase, "LDAP_CONNECTION_FAILED").
+		WithTemplate("Failed to connect to LDAP server: %s").
+		WithContext("url", serverURL).
+		WithContext("bind_options", maskedLDAPOptions(options)).
+		WithContext("error", err.Error()).
+		WithSuggestion("Check if the LDAP server is running and reachable (default ports 389 for ldap://, 636 for ldaps://)").
+		WithSuggestion("For self-signed certificates, set skip_tls_verify: true in test environments").
+		Build(err.Error())
+}
+
+// ldapErrorMessage names the LDAP result code of an error when it has one
+func ldapErrorMessage(err error) string {
+	var ldapErr *ldap.Error
+	if errors.As(err, &ldapErr) {
+		if name, known := ldap.LDAPResultCodeMap[ldapErr.ResultCode]; known {
+			return fmt.Sprintf("%s (result code %d): %v", name, ldapErr.ResultCode, ldapErr.Err)
+		}
+	}
+	return err.Error()
+}
+
+// maskedLDAPOptions returns the connection options for error context with the bind password masked
+func maskedLDAPOptions(options map[string]any) map[string]any {
+	masked := map[string]any{}
+	for _, key := range []string{"bind_dn", "start_tls", "skip_tls_verify", "timeout"} {
+		if value, ok := options[key]; ok {
+			masked[key] = value
+		}
+	}
+	if _, ok := options["bind_password"]; ok {
+		masked["bind_password"] = "***"
+	}
+	return masked
+}
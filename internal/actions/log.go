@@ -12,6 +12,11 @@ import (
 	"github.com/JianLoong/robogo/internal/types"
 )
 
+// MaskOutputOption tells actions that print values themselves, such as log, to mask the
+// secret values of the test case first (mask_output). Without it, only the secret-looking
+// values found in variables are masked, see common.DetectedSecretValues.
+const MaskOutputOption = "__mask_output"
+
 func logAction(args []any, options map[string]any, vars *common.Variables) types.ActionResult {
 	if len(args) == 0 {
 		return types.MissingArgsError("log", 1, 0)
@@ -42,6 +47,11 @@ func logAction(args []any, options map[string]any, vars *common.Variables) types
 	}
 
 	message := strings.Join(parts, " ")
+	if masked, _ := options[MaskOutputOption].(bool); masked {
+		message = common.MaskSecretValues(message, vars.SecretValues())
+	} else {
+		message = common.MaskSecretValues(message, vars.DetectedSecretValues())
+	}
 	fmt.Println(message)
 	os.Stdout.Sync() // Flush output immediately
 
@@ -0,0 +1,322 @@
+package actions
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/JianLoong/robogo/internal/common"
+	"github.com/JianLoong/robogo/internal/constants"
+	"github.com/JianLoong/robogo/internal/types"
+)
+
+// Migration operation constants
+const (
+	MigrateOperationUp    = "up"
+	MigrateOperationDown  = "down"
+	MigrateOperationReset = "reset"
+)
+
+// defaultMigrationsTable records which migrations have been applied
+const defaultMigrationsTable = "schema_migrations"
+
+// sqlIdentifier matches a plain or schema-qualified table name
+var sqlIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// migration is one migration found on disk. Files are named NAME.up.sql with an
+// optional NAME.down.sql, or NAME.sql for migrations that cannot be rolled back.
+type migration struct {
+	name     string
+	upPath   string
+	downPath string
+}
+
+// migrateAction applies or rolls back .sql migrations against PostgreSQL.
+// Args: [operation, connection_string, path] - up, down or reset; path is a directory or a single file
+// Options: table - tracking table (default schema_migrations), steps - migrations to roll back with down (default 1)
+func migrateAction(args []any, options map[string]any, vars *common.Variables) types.ActionResult {
+	if len(args) < 3 {
+		return types.MissingArgsError("migrate", 3, len(args))
+	}
+
+	if errorResult := validateArgsResolved("migrate", args[:3]); errorResult != nil {
+		return *errorResult
+	}
+
+	operation := strings.ToLower(fmt.Sprintf("%v", args[0]))
+	connectionString := fmt.Sprintf("%v", args[1])
+	path := fmt.Sprintf("%v", args[2])
+
+	if operation != MigrateOperationUp && operation != MigrateOperationDown && operation != MigrateOperationReset {
+		return types.UnknownOperationError("migrate", operation)
+	}
+
+	table := defaultMigrationsTable
+	if tableOpt, ok := options["table"]; ok {
+		table = fmt.Sprintf("%v", tableOpt)
+		if !sqlIdentifier.MatchString(table) {
+			return types.InvalidArgError("migrate", "table", "table name such as schema_migrations or public.schema_migrations")
+		}
+	}
+
+	steps := 1
+	if stepsOpt, ok := options["steps"]; ok {
+		parsed, err := strconv.Atoi(fmt.Sprintf("%v", stepsOpt))
+		if err != nil || parsed < 1 {
+			return types.InvalidArgError("migrate", "steps", "positive number of migrations")
+		}
+		steps = parsed
+	}
+
+	migrations, errorResult := loadMigrations(path, options)
+	if errorResult != nil {
+		return *errorResult
+	}
+
+	db, err := sql.Open("postgres", connectionString)
+	if err != nil {
+		return types.DatabaseConnectionError("PostgreSQL", err.Error())
+	}
+	defer db.Close()
+
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(0)
+	db.SetConnMaxLifetime(constants.DefaultConnectionLifetime)
+
+	if err = db.Ping(); err != nil {
+		return types.DatabaseConnectionError("PostgreSQL", err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), constants.DefaultDatabaseTimeout)
+	defer cancel()
+
+	createTable := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (name TEXT PRIMARY KEY, applied_at TIMESTAMPTZ NOT NULL DEFAULT now())", table)
+	if _, err := db.ExecContext(ctx, createTable); err != nil {
+		return types.DatabaseExecuteError("PostgreSQL", fmt.Sprintf("creating migrations table %s: %s", table, err.Error()))
+	}
+
+	applied, err := appliedMigrations(ctx, db, table)
+	if err != nil {
+		return types.DatabaseQueryError("PostgreSQL", fmt.Sprintf("reading migrations table %s: %s", table, err.Error()))
+	}
+
+	rolledBack := []string{}
+	switch operation {
+	case MigrateOperationDown:
+		if len(applied) < steps {
+			steps = len(applied)
+		}
+		rolledBack, errorResult = rollbackMigrations(ctx, db, table, migrations, applied[len(applied)-steps:])
+	case MigrateOperationReset:
+		rolledBack, errorResult = rollbackMigrations(ctx, db, table, migrations, applied)
+		applied = nil
+	}
+	if errorResult != nil {
+		return *errorResult
+	}
+	if operation == MigrateOperationDown {
+		return types.ActionResult{
+			Status: constants.ActionStatusPassed,
+			Data:   map[string]any{"operation": operation, "rolled_back": rolledBack},
+		}
+	}
+
+	appliedNow, errorResult := applyMigrations(ctx, db, table, migrations, applied)
+	if errorResult != nil {
+		return *errorResult
+	}
+
+	data := map[string]any{"operation": operation, "applied": appliedNow}
+	if operation == MigrateOperationReset {
+		data["rolled_back"] = rolledBack
+	}
+	return types.ActionResult{
+		Status: constants.ActionStatusPassed,
+		Data:   data,
+	}
+}
+
+// loadMigrations finds the migrations in a directory, sorted by name, or the single migration in a file
+func loadMigrations(path string, options map[string]any) ([]migration, *types.ActionResult) {
+	cleanPath, errorResult := cleanFilePath(path, options)
+	if errorResult != nil {
+		return nil, errorResult
+	}
+
+	info, err := os.Stat(cleanPath)
+	if err != nil {
+		errorResult := types.NewErrorBuilder(types.ErrorCategoryValidation, "MIGRATIONS_NOT_FOUND").
+			WithTemplate("Migrations not found: %s").
+			WithContext("path", path).
+			WithSuggestion("Check that the path is relative to where robogo runs").
+			Build(err.Error())
+		return nil, &errorResult
+	}
+
+	var files []string
+	if info.IsDir() {
+		files, err = filepath.Glob(filepath.Join(cleanPath, "*.sql"))
+		if err != nil {
+			errorResult := types.RequestError("migrations directory "+path, err.Error())
+			return nil, &errorResult
+		}
+	} else {
+		files = []string{cleanPath}
+		// A single up file still rolls back with its .down.sql sibling
+		if strings.HasSuffix(cleanPath, ".up.sql") {
+			downPath := strings.TrimSuffix(cleanPath, ".up.sql") + ".down.sql"
+			if _, err := os.Stat(downPath); err == nil {
+				files = append(files, downPath)
+			}
+		}
+	}
+
+	byName := make(map[string]*migration)
+	for _, file := range files {
+		base := filepath.Base(file)
+		name := strings.TrimSuffix(base, ".sql")
+		isDown := strings.HasSuffix(name, ".down")
+		name = strings.TrimSuffix(strings.TrimSuffix(name, ".down"), ".up")
+
+		entry, exists := byName[name]
+		if !exists {
+			entry = &migration{name: name}
+			byName[name] = entry
+		}
+		if isDown {
+			entry.downPath = file
+		} else {
+			entry.upPath = file
+		}
+	}
+
+	migrations := make([]migration, 0, len(byName))
+	for _, entry := range byName {
+		if entry.upPath == "" {
+			errorResult := types.NewErrorBuilder(types.ErrorCategoryValidation, "MIGRATION_INVALID").
+				WithTemplate("Migration %s has a down file but no up file").
+				WithContext("file", entry.downPath).
+				WithSuggestion("Add " + entry.name + ".up.sql next to the down file").
+				Build(entry.name)
+			return nil, &errorResult
+		}
+		migrations = append(migrations, *entry)
+	}
+	if len(migrations) == 0 {
+		errorResult := types.NewErrorBuilder(types.ErrorCategoryValidation, "MIGRATIONS_NOT_FOUND").
+			WithTemplate("No .sql migration files in %s").
+			WithContext("path", path).
+			Build(path)
+		return nil, &errorResult
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].name < migrations[j].name })
+	return migrations, nil
+}
+
+// appliedMigrations returns the names of applied migrations in the order they were applied
+func appliedMigrations(ctx context.Context, db *sql.DB, table string) ([]string, error) {
+	rows, err := db.QueryContext(ctx, fmt.Sprintf("SELECT name FROM %s ORDER BY applied_at, name", table))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+// applyMigrations runs every migration not yet applied, each in its own transaction
+func applyMigrations(ctx context.Context, db *sql.DB, table string, migrations []migration, applied []string) ([]string, *types.ActionResult) {
+	done := make(map[string]bool, len(applied))
+	for _, name := range applied {
+		done[name] = true
+	}
+
+	appliedNow := []string{}
+	for _, m := range migrations {
+		if done[m.name] {
+			continue
+		}
+		record := fmt.Sprintf("INSERT INTO %s (name) VALUES ($1)", table)
+		if errorResult := runMigrationFile(ctx, db, m.upPath, record, m.name); errorResult != nil {
+			return appliedNow, errorResult
+		}
+		appliedNow = append(appliedNow, m.name)
+	}
+	return appliedNow, nil
+}
+
+// rollbackMigrations runs the down files of the given applied migrations, newest first
+func rollbackMigrations(ctx context.Context, db *sql.DB, table string, migrations []migration, names []string) ([]string, *types.ActionResult) {
+	byName := make(map[string]migration, len(migrations))
+	for _, m := range migrations {
+		byName[m.name] = m
+	}
+
+	rolledBack := []string{}
+	for i := len(names) - 1; i >= 0; i-- {
+		m, exists := byName[names[i]]
+		if !exists || m.downPath == "" {
+			errorResult := types.NewErrorBuilder(types.ErrorCategoryDatabase, "MIGRATION_NOT_REVERSIBLE").
+				WithTemplate("Cannot roll back migration %s: no %s.down.sql file").
+				WithContext("migration", names[i]).
+				WithSuggestion("Add a .down.sql file, or use up on a fresh database instead of reset").
+				Build(names[i], names[i])
+			return rolledBack, &errorResult
+		}
+		record := fmt.Sprintf("DELETE FROM %s WHERE name = $1", table)
+		if errorResult := runMigrationFile(ctx, db, m.downPath, record, m.name); errorResult != nil {
+			return rolledBack, errorResult
+		}
+		rolledBack = append(rolledBack, m.name)
+	}
+	return rolledBack, nil
+}
+
+// runMigrationFile executes a migration file and updates the tracking table in one transaction
+func runMigrationFile(ctx context.Context, db *sql.DB, file, record, name string) *types.ActionResult {
+	migrationError := func(err error) *types.ActionResult {
+		errorResult := types.NewErrorBuilder(types.ErrorCategoryDatabase, "MIGRATION_FAILED").
+			WithTemplate("Migration %s failed: %s").
+			WithContext("file", file).
+			WithSuggestion("The failed migration was rolled back; fix the file and run migrate again").
+			Build(filepath.Base(file), err.Error())
+		return &errorResult
+	}
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return migrationError(err)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return migrationError(err)
+	}
+	if _, err := tx.ExecContext(ctx, string(content)); err != nil {
+		tx.Rollback()
+		return migrationError(err)
+	}
+	if _, err := tx.ExecContext(ctx, record, name); err != nil {
+		tx.Rollback()
+		return migrationError(err)
+	}
+	if err := tx.Commit(); err != nil {
+		return migrationError(err)
+	}
+	return nil
+}
@@ -84,13 +84,13 @@ func pingAction(args []any, options map[string]any, vars *common.Variables) type
 	}
 
 	// Execute ping command
-	result := executePing(host, resolvedIP, count, timeoutDuration)
+	result := executePing(host, resolvedIP, count, timeoutDuration, vars.Environ())
 	
 	return result
 }
 
-// executePing runs the actual ping command
-func executePing(host, resolvedIP string, count int, timeout time.Duration) types.ActionResult {
+// executePing runs the actual ping command with the step's environment
+func executePing(host, resolvedIP string, count int, timeout time.Duration, env []string) types.ActionResult {
 	var cmd *exec.Cmd
 	var args []string
 
@@ -106,6 +106,7 @@ func executePing(host, resolvedIP string, count int, timeout time.Duration) type
 		args = []string{"-c", strconv.Itoa(count), "-W", strconv.Itoa(int(timeout.Seconds())), host}
 		cmd = exec.Command("ping", args...)
 	}
+	cmd.Env = env
 
 	fmt.Printf("🏓 Pinging %s (%s) with %d packets...\n", host, resolvedIP, count)
 	
@@ -0,0 +1,321 @@
+package actions
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/JianLoong/robogo/internal/common"
+	"github.com/JianLoong/robogo/internal/constants"
+	"github.com/JianLoong/robogo/internal/types"
+	"gopkg.in/yaml.v3"
+)
+
+// PluginsEnvVar names the plugin manifest when --plugins is not given
+const PluginsEnvVar = "ROBOGO_PLUGINS"
+
+// defaultPluginTimeout bounds a plugin call when neither the manifest nor the step sets a timeout
+const defaultPluginTimeout = 30 * time.Second
+
+// pluginManifest is the layout of the plugins YAML file
+type pluginManifest struct {
+	Plugins []pluginSpec `yaml:"plugins"`
+}
+
+// pluginSpec declares one external action
+type pluginSpec struct {
+	Name        string        `yaml:"name"`
+	Command     string        `yaml:"command"`
+	CommandArgs []string      `yaml:"command_args,omitempty"`
+	Timeout     string        `yaml:"timeout,omitempty"`
+	Category    string        `yaml:"category,omitempty"`
+	Description string        `yaml:"description,omitempty"`
+	Args        []pluginParam `yaml:"args,omitempty"`
+	Variadic    bool          `yaml:"variadic,omitempty"`
+	Options     []pluginParam `yaml:"options,omitempty"`
+	Variables   []string      `yaml:"variables,omitempty"`
+	Example     string        `yaml:"example,omitempty"`
+}
+
+// pluginParam declares one argument or option of a plugin action
+type pluginParam struct {
+	Name        string `yaml:"name"`
+	Type        string `yaml:"type,omitempty"`
+	Required    bool   `yaml:"required,omitempty"`
+	Sensitive   bool   `yaml:"sensitive,omitempty"`
+	Description string `yaml:"description,omitempty"`
+}
+
+// pluginRequest is written as JSON to the plugin's stdin
+type pluginRequest struct {
+	Action    string         `json:"action"`
+	Args      []any          `json:"args"`
+	Options   map[string]any `json:"options"`
+	Variables map[string]any `json:"variables"`
+}
+
+// LoadPlugins registers the external actions declared in a manifest file.
+// Any problem is returned as a configuration error so runs never start half-configured.
+func (registry *ActionRegistry) LoadPlugins(manifestPath string) error {
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return fmt.Errorf("failed to read plugin manifest %s: %w", manifestPath, err)
+	}
+
+	var manifest pluginManifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return fmt.Errorf("failed to parse plugin manifest %s: %w", manifestPath, err)
+	}
+
+	baseDir := filepath.Dir(manifestPath)
+	for i, spec := range manifest.Plugins {
+		if err := registry.loadPlugin(spec, baseDir); err != nil {
+			return fmt.Errorf("plugin %d (%s) in %s: %w", i+1, spec.Name, manifestPath, err)
+		}
+	}
+	return nil
+}
+
+// loadPlugin validates one plugin declaration and registers it
+func (registry *ActionRegistry) loadPlugin(spec pluginSpec, baseDir string) error {
+	if spec.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if registry.Has(spec.Name) {
+		return fmt.Errorf("action '%s' is already registered", spec.Name)
+	}
+	if spec.Command == "" {
+		return fmt.Errorf("command is required")
+	}
+
+	// Commands with a path are relative to the manifest; bare names are looked up in PATH
+	command := spec.Command
+	if strings.ContainsRune(command, filepath.Separator) || strings.Contains(command, "/") {
+		if !filepath.IsAbs(command) {
+			absolute, err := filepath.Abs(filepath.Join(baseDir, command))
+			if err != nil {
+				return fmt.Errorf("invalid command path '%s': %w", spec.Command, err)
+			}
+			command = absolute
+		}
+	}
+	resolved, err := exec.LookPath(command)
+	if err != nil {
+		return fmt.Errorf("command '%s' not found or not executable: %w", spec.Command, err)
+	}
+
+	timeout := defaultPluginTimeout
+	if spec.Timeout != "" {
+		timeout, err = time.ParseDuration(spec.Timeout)
+		if err != nil {
+			return fmt.Errorf("invalid timeout '%s': %w", spec.Timeout, err)
+		}
+	}
+
+	args, err := pluginArgSpecs(spec.Args)
+	if err != nil {
+		return err
+	}
+	options, err := pluginArgSpecs(spec.Options)
+	if err != nil {
+		return err
+	}
+	for _, name := range spec.Variables {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("variables must not contain an empty name")
+		}
+	}
+
+	category := spec.Category
+	if category == "" {
+		category = "plugin"
+	}
+
+	meta := ActionMetadata{
+		Name:        spec.Name,
+		Category:    category,
+		Description: spec.Description,
+		Args:        args,
+		Variadic:    spec.Variadic,
+		Options:     options,
+		Example:     strings.TrimRight(spec.Example, "\n"),
+	}
+	registry.RegisterWithMetadata(meta, newPluginAction(meta, resolved, spec.CommandArgs, spec.Variables, timeout))
+	return nil
+}
+
+// pluginArgSpecs converts manifest parameters to ArgSpecs, rejecting unknown types
+func pluginArgSpecs(params []pluginParam) ([]ArgSpec, error) {
+	if len(params) == 0 {
+		return nil, nil
+	}
+	specs := make([]ArgSpec, 0, len(params))
+	for _, param := range params {
+		if param.Name == "" {
+			return nil, fmt.Errorf("every argument and option needs a name")
+		}
+		argType := param.Type
+		if argType == "" {
+			argType = "any"
+		}
+		switch argType {
+		case "string", "number", "bool", "object", "array", "any":
+		default:
+			return nil, fmt.Errorf("%s: unknown type '%s' (use string, number, bool, object, array or any)", param.Name, argType)
+		}
+		specs = append(specs, ArgSpec{
+			Name:        param.Name,
+			Type:        argType,
+			Required:    param.Required,
+			Sensitive:   param.Sensitive,
+			Description: param.Description,
+		})
+	}
+	return specs, nil
+}
+
+// newPluginAction returns an action that runs the plugin command once per call.
+// The request is sent as JSON on stdin and an ActionResult is read from stdout.
+// Only the variables named in the manifest are sent.
+func newPluginAction(meta ActionMetadata, command string, commandArgs []string, variableNames []string, timeout time.Duration) ActionFunc {
+	return func(args []any, options map[string]any, vars *common.Variables) types.ActionResult {
+		if errorResult := validateArgsResolved(meta.Name, args); errorResult != nil {
+			return *errorResult
+		}
+		if errorResult := validateArgsWithMetadata(meta, args); errorResult != nil {
+			return *errorResult
+		}
+
+		callTimeout := timeout
+		if timeoutVal, exists := options["timeout"]; exists {
+			timeoutStr := fmt.Sprintf("%v", timeoutVal)
+			parsed, err := time.ParseDuration(timeoutStr)
+			if err != nil || parsed <= 0 {
+				return types.NewErrorBuilder(types.ErrorCategoryValidation, "INVALID_TIMEOUT").
+					WithTemplate("Invalid timeout format for plugin %s").
+					WithContext("timeout", timeoutStr).
+					WithContext("valid_examples", "5s, 1000ms, 1m").
+					WithSuggestion("Use a positive Go duration: ns, us, ms, s, m, h").
+					Build(meta.Name)
+			}
+			callTimeout = parsed
+		}
+
+		// Internal options such as __no_log are not part of the plugin contract
+		pluginOptions := make(map[string]any, len(options))
+		for key, value := range options {
+			if !strings.HasPrefix(key, "__") {
+				pluginOptions[key] = value
+			}
+		}
+		variables, err := pluginVariables(variableNames, options, vars)
+		if err != nil {
+			return types.NewErrorBuilder(types.ErrorCategoryExecution, "PLUGIN_REQUEST_FAILED").
+				WithTemplate("Failed to encode variables for plugin %s: %s").
+				Build(meta.Name, err.Error())
+		}
+		request, err := json.Marshal(pluginRequest{
+			Action:    meta.Name,
+			Args:      args,
+			Options:   pluginOptions,
+			Variables: variables,
+		})
+		if err != nil {
+			return types.NewErrorBuilder(types.ErrorCategoryExecution, "PLUGIN_REQUEST_FAILED").
+				WithTemplate("Failed to encode request for plugin %s: %s").
+				Build(meta.Name, err.Error())
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+		defer cancel()
+
+		cmd := exec.CommandContext(ctx, command, commandArgs...)
+		cmd.Env = vars.Environ() // the step's env applies to the plugin process only
+		cmd.Stdin = bytes.NewReader(request)
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		runErr := cmd.Run()
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return types.NewErrorBuilder(types.ErrorCategoryExecution, "PLUGIN_TIMEOUT").
+				WithTemplate("Plugin %s did not finish within %s").
+				WithContext("command", command).
+				WithSuggestion("Raise the plugin's timeout in the manifest or with the step's timeout option").
+				Build(meta.Name, callTimeout)
+		}
+
+		var result types.ActionResult
+		if err := json.Unmarshal(stdout.Bytes(), &result); err != nil || result.Status == "" {
+			if runErr != nil {
+				return types.NewErrorBuilder(types.ErrorCategoryExecution, "PLUGIN_FAILED").
+					WithTemplate("Plugin %s failed: %s").
+					WithContext("command", command).
+					WithContext("stderr", firstLines(stderr.String(), 5)).
+					Build(meta.Name, runErr.Error())
+			}
+			return types.NewErrorBuilder(types.ErrorCategoryExecution, "PLUGIN_INVALID_RESPONSE").
+				WithTemplate("Plugin %s did not write an ActionResult as JSON to stdout").
+				WithContext("command", command).
+				WithContext("stdout", firstLines(stdout.String(), 5)).
+				WithSuggestion(fmt.Sprintf("Print e.g. {\"status\": \"%s\", \"data\": ...}", constants.ActionStatusPassed)).
+				Build(meta.Name)
+		}
+
+		switch result.Status {
+		case constants.ActionStatusPassed, constants.ActionStatusFailed, constants.ActionStatusError, constants.ActionStatusSkipped:
+		default:
+			return types.NewErrorBuilder(types.ErrorCategoryExecution, "PLUGIN_INVALID_RESPONSE").
+				WithTemplate("Plugin %s returned unknown status '%s'").
+				WithContext("command", command).
+				Build(meta.Name, result.Status)
+		}
+		return result
+	}
+}
+
+// pluginVariables returns the declared variables that are set, as a JSON copy with
+// sensitive keys masked. Secrets a plugin needs are passed as sensitive arguments.
+func pluginVariables(names []string, options map[string]any, vars *common.Variables) (map[string]any, error) {
+	declared := make(map[string]any, len(names))
+	for _, name := range names {
+		if value, ok := vars.Lookup(name); ok {
+			declared[name] = value
+		}
+	}
+
+	// The copy keeps masking from changing the values the test still uses
+	data, err := json.Marshal(declared)
+	if err != nil {
+		return nil, err
+	}
+	variables := make(map[string]any, len(declared))
+	if err := json.Unmarshal(data, &variables); err != nil {
+		return nil, err
+	}
+
+	var stepKeys []string
+	if fields, ok := options["sensitive_fields"].([]any); ok {
+		for _, field := range fields {
+			stepKeys = append(stepKeys, fmt.Sprintf("%v", field))
+		}
+	}
+	common.MaskSensitiveFields(variables, common.SensitiveKeys(stepKeys))
+	return variables, nil
+}
+
+// firstLines returns at most n lines of text for error context
+func firstLines(text string, n int) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	if len(lines) > n {
+		lines = append(lines[:n], "...")
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,212 @@
+package actions
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JianLoong/robogo/internal/common"
+	"github.com/JianLoong/robogo/internal/constants"
+	"github.com/JianLoong/robogo/internal/types"
+)
+
+// pollDBAction runs a query repeatedly until its result meets the expectation, for
+// waiting on asynchronous writes such as a consumer persisting a published message.
+// Args: [database, connection, query] - postgres with a SQL query, or mongodb with a "db.collection" name
+// Options:
+//   - expected_rows: stop once exactly this many rows or documents are returned
+//   - min_rows: stop once at least this many are returned (default 1 when no other expectation is set)
+//   - until: jq expression over the result that must be true, e.g. '.rows[0][0] == "shipped"'
+//   - filter: mongodb filter
+//   - timeout: how long to keep polling (default: "30s")
+//   - interval: delay between polls (default: "1s")
+func pollDBAction(args []any, options map[string]any, vars *common.Variables) types.ActionResult {
+	if len(args) < 3 {
+		return types.MissingArgsError("poll_db", 3, len(args))
+	}
+
+	if errorResult := validateArgsResolved("poll_db", args[:3]); errorResult != nil {
+		return *errorResult
+	}
+
+	database := strings.ToLower(fmt.Sprintf("%v", args[0]))
+	connection := fmt.Sprintf("%v", args[1])
+	query := fmt.Sprintf("%v", args[2])
+
+	var poll func() types.ActionResult
+	switch database {
+	case "postgres", "postgresql":
+		poll = func() types.ActionResult {
+			return postgresAction([]any{constants.OperationQuery, connection, query}, map[string]any{}, vars)
+		}
+	case "mongodb", "mongo":
+		findOptions := map[string]any{}
+		if filter, ok := options["filter"]; ok {
+			// Nested options are not substituted by the executor
+			findOptions["filter"] = substituteVariablesInData(filter, vars)
+		}
+		poll = func() types.ActionResult {
+			return mongodbAction([]any{"find", connection, query}, findOptions, vars)
+		}
+	default:
+		return types.InvalidArgError("poll_db", "database", "postgres or mongodb")
+	}
+
+	expectedRows, errorResult := pollRowsOption(options, "expected_rows")
+	if errorResult != nil {
+		return *errorResult
+	}
+	minRows, errorResult := pollRowsOption(options, "min_rows")
+	if errorResult != nil {
+		return *errorResult
+	}
+	until, _ := options["until"].(string)
+	if expectedRows < 0 && minRows < 0 && until == "" {
+		minRows = 1
+	}
+
+	timeout, errorResult := pollDurationOption(options, "timeout", 30*time.Second)
+	if errorResult != nil {
+		return *errorResult
+	}
+	interval, errorResult := pollDurationOption(options, "interval", time.Second)
+	if errorResult != nil {
+		return *errorResult
+	}
+
+	fmt.Printf("⏳ Polling %s until the query result matches (timeout %s)...\n", database, timeout)
+
+	start := time.Now()
+	deadline := start.Add(timeout)
+	polls := 0
+	var lastData map[string]any
+	var lastError *types.ActionResult
+	for {
+		polls++
+		result := poll()
+		if result.Status == constants.ActionStatusPassed {
+			lastData, _ = result.Data.(map[string]any)
+			lastError = nil
+
+			met, errorResult := pollConditionMet(lastData, expectedRows, minRows, until, vars)
+			if errorResult != nil {
+				return *errorResult
+			}
+			if met {
+				elapsed := time.Since(start).Round(time.Millisecond)
+				fmt.Printf("✅ Query result matched after %d poll(s) (%s)\n", polls, elapsed)
+				return types.ActionResult{
+					Status: constants.ActionStatusPassed,
+					Data: map[string]any{
+						"result":    lastData,
+						"row_count": pollRowCount(lastData),
+						"polls":     polls,
+						"elapsed":   elapsed.String(),
+					},
+				}
+			}
+		} else {
+			lastError = &result
+		}
+
+		if time.Now().Add(interval).After(deadline) {
+			break
+		}
+		time.Sleep(interval)
+	}
+
+	fmt.Printf("❌ Query result did not match within %s\n", timeout)
+
+	// A query that never succeeded is a database problem, not a slow write
+	if lastData == nil && lastError != nil {
+		return *lastError
+	}
+
+	expectation := describePollExpectation(expectedRows, minRows, until)
+	return types.NewErrorBuilder(types.ErrorCategoryExecution, "TIMEOUT").
+		WithTemplate("Database poll timed out after %s: expected %s, last poll returned %d row(s)").
+		WithContext("polls", polls).
+		WithContext("query", query).
+		WithSuggestion("Raise the timeout option if the write takes longer to arrive").
+		Build(timeout, expectation, pollRowCount(lastData))
+}
+
+// pollConditionMet checks one poll result against the row count and until expectations
+func pollConditionMet(data map[string]any, expectedRows, minRows int, until string, vars *common.Variables) (bool, *types.ActionResult) {
+	rowCount := pollRowCount(data)
+	if expectedRows >= 0 && rowCount != expectedRows {
+		return false, nil
+	}
+	if minRows >= 0 && rowCount < minRows {
+		return false, nil
+	}
+	if until == "" {
+		return true, nil
+	}
+
+	result := jqAction([]any{data, until}, map[string]any{}, vars)
+	if result.Status != constants.ActionStatusPassed {
+		// A query that does not fit the data, e.g. .rows[0][0] on no rows, is not met yet
+		if result.ErrorInfo != nil && result.ErrorInfo.Code == "JQ_PARSE_ERROR" {
+			return false, &result
+		}
+		return false, nil
+	}
+	met, _ := result.Data.(bool)
+	return met, nil
+}
+
+// pollRowCount returns the number of rows of a postgres result or documents of a mongodb result
+func pollRowCount(data map[string]any) int {
+	if rows, ok := data["rows"].([]any); ok {
+		return len(rows)
+	}
+	if documents, ok := data["documents"].([]any); ok {
+		return len(documents)
+	}
+	return 0
+}
+
+// describePollExpectation summarizes what the poll was waiting for
+func describePollExpectation(expectedRows, minRows int, until string) string {
+	var parts []string
+	if expectedRows >= 0 {
+		parts = append(parts, fmt.Sprintf("%d row(s)", expectedRows))
+	}
+	if minRows >= 0 {
+		parts = append(parts, fmt.Sprintf("at least %d row(s)", minRows))
+	}
+	if until != "" {
+		parts = append(parts, fmt.Sprintf("'%s' to be true", until))
+	}
+	return strings.Join(parts, " and ")
+}
+
+// pollRowsOption reads a non-negative row count option; -1 means it was not set
+func pollRowsOption(options map[string]any, name string) (int, *types.ActionResult) {
+	value, ok := options[name]
+	if !ok {
+		return -1, nil
+	}
+	rows, err := strconv.Atoi(fmt.Sprintf("%v", value))
+	if err != nil || rows < 0 {
+		errorResult := types.InvalidArgError("poll_db", name, "non-negative number of rows")
+		return -1, &errorResult
+	}
+	return rows, nil
+}
+
+// pollDurationOption reads a positive duration option
+func pollDurationOption(options map[string]any, name string, defaultValue time.Duration) (time.Duration, *types.ActionResult) {
+	value, ok := options[name].(string)
+	if !ok {
+		return defaultValue, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		errorResult := types.InvalidArgError("poll_db", name, "duration such as '500ms' or '30s'")
+		return 0, &errorResult
+	}
+	return duration, nil
+}
@@ -10,23 +10,42 @@ import (
 	"github.com/JianLoong/robogo/internal/common"
 	"github.com/JianLoong/robogo/internal/constants"
 	"github.com/JianLoong/robogo/internal/types"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 // PostgreSQL action - simplified implementation with proper resource management
+// Args: [operation, connection_string, sql] for query/select and execute/insert/update/delete,
+// [operation, connection_string, table, where?] for row_exists and count
+// Options: params - positional parameters ($1, $2, ...) of the sql or where clause;
+// as_json - return query rows as a JSON string
 func postgresAction(args []any, options map[string]any, vars *common.Variables) types.ActionResult {
 	if len(args) < 3 {
 		return types.MissingArgsError("postgres", 3, len(args))
 	}
 
 	// Check for unresolved variables in critical arguments
-	if errorResult := validateArgsResolved("postgres", args[:3]); errorResult != nil {
+	if errorResult := validateArgsResolved("postgres", args); errorResult != nil {
 		return *errorResult
 	}
 
 	operation := strings.ToLower(fmt.Sprintf("%v", args[0]))
 	connectionString := fmt.Sprintf("%v", args[1])
 	query := fmt.Sprintf("%v", args[2])
+	params, errorResult := sqlParams("postgres", substituteOption(vars, options["params"]))
+	if errorResult != nil {
+		return *errorResult
+	}
+
+	// row_exists and count take a table and an optional where clause instead of a statement
+	if operation == constants.OperationRowExists || operation == constants.OperationCount {
+		where := ""
+		if len(args) > 3 {
+			where = fmt.Sprintf("%v", args[3])
+		}
+		if query, errorResult = postgresCountQuery(operation, query, where); errorResult != nil {
+			return *errorResult
+		}
+	}
 
 	// Open connection for this operation only
 	db, err := sql.Open("postgres", connectionString)
@@ -48,7 +67,7 @@ func postgresAction(args []any, options map[string]any, vars *common.Variables)
 
 	switch operation {
 	case constants.OperationQuery, constants.OperationSelect:
-		rows, err := db.QueryContext(ctx, query)
+		rows, err := db.QueryContext(ctx, query, params...)
 		if err != nil {
 			return types.DatabaseQueryError("PostgreSQL", err.Error())
 		}
@@ -118,7 +137,7 @@ func postgresAction(args []any, options map[string]any, vars *common.Variables)
 		}
 
 	case constants.OperationExecute, constants.OperationInsert, constants.OperationUpdate, constants.OperationDelete:
-		result, err := db.ExecContext(ctx, query)
+		result, err := db.ExecContext(ctx, query, params...)
 		if err != nil {
 			return types.DatabaseExecuteError("PostgreSQL", err.Error())
 		}
@@ -128,7 +147,54 @@ func postgresAction(args []any, options map[string]any, vars *common.Variables)
 			Data:   map[string]any{"rows_affected": rowsAffected},
 		}
 
+	case constants.OperationRowExists:
+		var exists bool
+		if err := db.QueryRowContext(ctx, query, params...).Scan(&exists); err != nil {
+			return types.DatabaseQueryError("PostgreSQL", err.Error())
+		}
+		return types.ActionResult{
+			Status: constants.ActionStatusPassed,
+			Data:   exists,
+		}
+
+	case constants.OperationCount:
+		var count int64
+		if err := db.QueryRowContext(ctx, query, params...).Scan(&count); err != nil {
+			return types.DatabaseQueryError("PostgreSQL", err.Error())
+		}
+		return types.ActionResult{
+			Status: constants.ActionStatusPassed,
+			Data:   count,
+		}
+
 	default:
 		return types.UnknownOperationError("postgres", operation)
 	}
 }
+
+// postgresCountQuery builds the query of row_exists or count. The table, optionally
+// schema-qualified, is quoted as identifiers; the where clause is used as written, with
+// its values bound through the params option.
+func postgresCountQuery(operation, table, where string) (string, *types.ActionResult) {
+	parts := strings.Split(table, ".")
+	if len(parts) > 2 {
+		result := types.InvalidArgError("postgres", "table", "a table name or schema.table")
+		return "", &result
+	}
+	for i, part := range parts {
+		if part == "" {
+			result := types.InvalidArgError("postgres", "table", "a table name or schema.table")
+			return "", &result
+		}
+		parts[i] = pq.QuoteIdentifier(part)
+	}
+
+	from := "FROM " + strings.Join(parts, ".")
+	if strings.TrimSpace(where) != "" {
+		from += " WHERE " + where
+	}
+	if operation == constants.OperationRowExists {
+		return "SELECT EXISTS (SELECT 1 " + from + ")", nil
+	}
+	return "SELECT COUNT(*) " + from, nil
+}
@@ -30,6 +30,7 @@ func scpAction(args []any, options map[string]any, vars *common.Variables) types
 	host := fmt.Sprintf("%v", args[1])        // "user@hostname:22" or "hostname:22"
 	localPath := fmt.Sprintf("%v", args[2])   // "/path/to/local/file.txt"
 	remotePath := fmt.Sprintf("%v", args[3])  // "/remote/path/file.txt"
+	localPath = resolveFilePath(localPath, options)
 
 	// Parse connection details
 	username, hostname, port := parseSSHHost(host)
@@ -47,7 +48,7 @@ func scpAction(args []any, options map[string]any, vars *common.Variables) types
 		password = pass
 	}
 	if key, ok := options["private_key"].(string); ok {
-		keyPath = key
+		keyPath = resolveFilePath(key, options)
 	}
 
 	// Extract timeout
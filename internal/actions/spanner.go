@@ -6,89 +6,116 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"regexp"
 	"strings"
+	"time"
+
+	"cloud.google.com/go/spanner"
+	spannerdriver "github.com/googleapis/go-sql-spanner"
 
 	"github.com/JianLoong/robogo/internal/common"
 	"github.com/JianLoong/robogo/internal/constants"
 	"github.com/JianLoong/robogo/internal/types"
-	_ "github.com/googleapis/go-sql-spanner"
 )
 
+// Spanner operations beyond the SQL ones
+const (
+	spannerOperationMutate      = "mutate"
+	spannerOperationTransaction = "transaction"
+)
+
+// spannerDefaultEmulatorHost is used with emulator: true when SPANNER_EMULATOR_HOST is not set
+const spannerDefaultEmulatorHost = "localhost:9010"
+
+// spannerDatabasePattern matches database paths so their identifiers can be masked in logs and errors
+var spannerDatabasePattern = regexp.MustCompile(`projects/[^/\s]+/instances/[^/\s]+/databases/[^/;?\s"']+`)
+
+// spannerAction runs Cloud Spanner statements through the database/sql driver
+// Args: [operation, database, sql] - sql is not used by mutate and transaction
+// Operations:
+//   - query/select: rows of a query
+//   - execute/insert/update/delete: DML or DDL, with the commit timestamp of DML
+//   - mutate: apply the mutations option in one commit
+//   - transaction: run the statements option, then the mutations option, in one read-write
+//     transaction; later statements see the writes of earlier ones
+//
+// Options:
+//   - params: named (map, for @name) or positional (list) statement parameters
+//   - mutations: list of {op, table, rows} or {op: delete, table, keys}
+//   - statements: list of SQL strings or {sql, params} for transaction
+//   - emulator: connect to the emulator without credentials, creating the instance and database
+//   - as_json: return query rows as a JSON string
+//
+// SPANNER_EMULATOR_HOST is honored by the client library without the emulator option.
 func spannerAction(args []any, options map[string]any, vars *common.Variables) types.ActionResult {
-	if len(args) < 3 {
+	if len(args) < 2 {
 		return types.MissingArgsError("spanner", 3, len(args))
 	}
 
 	operation := strings.ToLower(fmt.Sprintf("%v", args[0]))
 	dbPath := fmt.Sprintf("%v", args[1])
-	query := fmt.Sprintf("%v", args[2])
+	query := ""
+	if len(args) > 2 {
+		query = fmt.Sprintf("%v", args[2])
+	} else if operation != spannerOperationMutate && operation != spannerOperationTransaction {
+		return types.MissingArgsError("spanner", 3, len(args))
+	}
+
+	params, errorResult := sqlParams("spanner", substituteOption(vars, options["params"]))
+	if errorResult != nil {
+		return *errorResult
+	}
+	mutations, errorResult := spannerMutations(options["mutations"])
+	if errorResult != nil {
+		return *errorResult
+	}
+	var statements []sqlStatement
+	switch operation {
+	case spannerOperationMutate:
+		if len(mutations) == 0 {
+			return types.InvalidArgError("spanner", "mutations", "non-empty list of mutations")
+		}
+	case spannerOperationTransaction:
+		if statements, errorResult = sqlStatements("spanner", substituteOption(vars, options["statements"])); errorResult != nil {
+			return *errorResult
+		}
+		if len(statements) == 0 && len(mutations) == 0 {
+			return types.InvalidArgError("spanner", "statements", "non-empty list of statements, or mutations")
+		}
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), constants.DefaultDatabaseTimeout)
 	defer cancel()
 
-	db, err := sql.Open("spanner", dbPath)
+	dsn := dbPath
+	if parseBoolOption(options, "emulator", false) {
+		dsn = spannerEmulatorDSN(dbPath, vars.Getenv("SPANNER_EMULATOR_HOST"))
+	}
+	masked := maskSpannerDatabase(dbPath)
+
+	db, err := sql.Open("spanner", dsn)
 	if err != nil {
-		log.Printf("[spanner/sql] failed to open database: %v", err)
-		return types.DatabaseConnectionError("Cloud Spanner", err.Error())
+		log.Printf("[spanner/sql] failed to open database %s: %s", masked, maskSpannerDatabase(err.Error()))
+		return types.DatabaseConnectionError("Cloud Spanner", maskSpannerDatabase(err.Error()))
 	}
 	defer db.Close()
 
+	// A single connection, so the commit timestamp can be read from it after a write
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		log.Printf("[spanner/sql] failed to connect to %s: %s", masked, maskSpannerDatabase(err.Error()))
+		return types.DatabaseConnectionError("Cloud Spanner", maskSpannerDatabase(err.Error()))
+	}
+	defer conn.Close()
+
 	switch operation {
 	case constants.OperationQuery, constants.OperationSelect:
-		rows, err := db.QueryContext(ctx, query)
-		if err != nil {
-			log.Printf("[spanner/sql] query failed: %v", err)
-			return types.DatabaseQueryError("Cloud Spanner", err.Error())
-		}
-		defer rows.Close()
-
-		columns, err := rows.Columns()
+		result, err := queryRows(ctx, conn, query, params)
 		if err != nil {
-			return types.DatabaseQueryError("Cloud Spanner", err.Error())
+			log.Printf("[spanner/sql] query on %s failed: %s", masked, maskSpannerDatabase(err.Error()))
+			return types.DatabaseQueryError("Cloud Spanner", maskSpannerDatabase(err.Error()))
 		}
 
-		var results [][]any
-		rowCount := 0
-		for rows.Next() {
-			values := make([]any, len(columns))
-			valuePtrs := make([]any, len(columns))
-			for i := range values {
-				valuePtrs[i] = &values[i]
-			}
-			if err := rows.Scan(valuePtrs...); err != nil {
-				log.Printf("[spanner/sql] failed to scan row: %v", err)
-				return types.DatabaseQueryError("Cloud Spanner", err.Error())
-			}
-			for i, v := range values {
-				if b, ok := v.([]byte); ok {
-					values[i] = string(b)
-				}
-			}
-			results = append(results, values)
-			rowCount++
-		}
-		if err := rows.Err(); err != nil {
-			return types.DatabaseQueryError("Cloud Spanner", err.Error())
-		}
-
-		// Create the initial result structure
-		resultData := map[string]any{
-			"columns": columns,
-			"rows":    results,
-		}
-		
-		// Marshal and unmarshal to ensure JSON compatibility for jq
-		jsonBytes, err := json.Marshal(resultData)
-		if err != nil {
-			return types.DatabaseQueryError("Cloud Spanner", fmt.Sprintf("JSON marshal error: %v", err))
-		}
-		
-		var jsonCompatibleResult map[string]any
-		if err := json.Unmarshal(jsonBytes, &jsonCompatibleResult); err != nil {
-			return types.DatabaseQueryError("Cloud Spanner", fmt.Sprintf("JSON unmarshal error: %v", err))
-		}
-		
-		result := jsonCompatibleResult
 		if asJSON, ok := options["as_json"].(bool); ok && asJSON {
 			jsonBytes, err := json.Marshal(result)
 			if err == nil {
@@ -105,18 +132,208 @@ func spannerAction(args []any, options map[string]any, vars *common.Variables) t
 		}
 
 	case constants.OperationInsert, constants.OperationUpdate, constants.OperationDelete, constants.OperationExecute:
-		res, err := db.ExecContext(ctx, query)
+		res, err := conn.ExecContext(ctx, query, params...)
 		if err != nil {
-			log.Printf("[spanner/sql] DML failed: %v", err)
-			return types.DatabaseExecuteError("Cloud Spanner", err.Error())
+			log.Printf("[spanner/sql] DML on %s failed: %s", masked, maskSpannerDatabase(err.Error()))
+			return types.DatabaseExecuteError("Cloud Spanner", maskSpannerDatabase(err.Error()))
 		}
 		affected, _ := res.RowsAffected()
+		data := map[string]any{"rows_affected": affected}
+		// DDL statements have no commit timestamp
+		if timestamp, ok := spannerCommitTimestamp(conn); ok {
+			data["commit_timestamp"] = timestamp
+		}
 		return types.ActionResult{
 			Status: constants.ActionStatusPassed,
-			Data:   map[string]any{"rows_affected": affected},
+			Data:   data,
 		}
 
+	case spannerOperationMutate:
+		var commitTimestamp time.Time
+		err := conn.Raw(func(driverConn any) error {
+			var applyErr error
+			commitTimestamp, applyErr = driverConn.(spannerdriver.SpannerConn).Apply(ctx, mutations)
+			return applyErr
+		})
+		if err != nil {
+			log.Printf("[spanner/sql] mutations on %s failed: %s", masked, maskSpannerDatabase(err.Error()))
+			return types.DatabaseExecuteError("Cloud Spanner", maskSpannerDatabase(err.Error()))
+		}
+		return types.ActionResult{
+			Status: constants.ActionStatusPassed,
+			Data: map[string]any{
+				"mutation_count":   len(mutations),
+				"commit_timestamp": commitTimestamp.UTC().Format(time.RFC3339Nano),
+			},
+		}
+
+	case spannerOperationTransaction:
+		return spannerTransaction(ctx, conn, statements, mutations, masked)
+
 	default:
 		return types.UnknownOperationError("spanner", operation)
 	}
 }
+
+// spannerTransaction runs statements, then buffers mutations, in one read-write transaction.
+// Each statement's result is a query result, or rows_affected for DML.
+func spannerTransaction(ctx context.Context, conn *sql.Conn, statements []sqlStatement, mutations []*spanner.Mutation, masked string) types.ActionResult {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("[spanner/sql] failed to begin transaction on %s: %s", masked, maskSpannerDatabase(err.Error()))
+		return types.DatabaseExecuteError("Cloud Spanner", maskSpannerDatabase(err.Error()))
+	}
+
+	results := []any{}
+	for i, statement := range statements {
+		var result map[string]any
+		if sqlReturnsRows(statement.sql) {
+			result, err = queryRows(ctx, tx, statement.sql, statement.params)
+		} else {
+			var res sql.Result
+			if res, err = tx.ExecContext(ctx, statement.sql, statement.params...); err == nil {
+				affected, _ := res.RowsAffected()
+				result = map[string]any{"rows_affected": affected}
+			}
+		}
+		if err != nil {
+			tx.Rollback()
+			log.Printf("[spanner/sql] statement %d of transaction on %s failed: %s", i+1, masked, maskSpannerDatabase(err.Error()))
+			return types.DatabaseExecuteError("Cloud Spanner", fmt.Sprintf("statement %d: %s", i+1, maskSpannerDatabase(err.Error())))
+		}
+		results = append(results, result)
+	}
+
+	if len(mutations) > 0 {
+		err := conn.Raw(func(driverConn any) error {
+			return driverConn.(spannerdriver.SpannerConn).BufferWrite(mutations)
+		})
+		if err != nil {
+			tx.Rollback()
+			log.Printf("[spanner/sql] failed to buffer mutations on %s: %s", masked, maskSpannerDatabase(err.Error()))
+			return types.DatabaseExecuteError("Cloud Spanner", maskSpannerDatabase(err.Error()))
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("[spanner/sql] commit on %s failed: %s", masked, maskSpannerDatabase(err.Error()))
+		return types.DatabaseExecuteError("Cloud Spanner", maskSpannerDatabase(err.Error()))
+	}
+
+	data := map[string]any{
+		"results":        results,
+		"mutation_count": len(mutations),
+	}
+	if timestamp, ok := spannerCommitTimestamp(conn); ok {
+		data["commit_timestamp"] = timestamp
+	}
+	return types.ActionResult{
+		Status: constants.ActionStatusPassed,
+		Data:   data,
+	}
+}
+
+// spannerMutations converts the mutations option. Each entry has an op (insert, update,
+// insert_or_update, replace or delete) and a table; writes list rows as column maps and
+// deletes list keys, each a value or a list of values for composite keys.
+func spannerMutations(value any) ([]*spanner.Mutation, *types.ActionResult) {
+	if value == nil {
+		return nil, nil
+	}
+	invalid := func(expected string) ([]*spanner.Mutation, *types.ActionResult) {
+		result := types.InvalidArgError("spanner", "mutations", expected)
+		return nil, &result
+	}
+
+	entries, ok := value.([]any)
+	if !ok {
+		return invalid("list of {op, table, rows} or {op: delete, table, keys}")
+	}
+	var mutations []*spanner.Mutation
+	for _, item := range entries {
+		entry, ok := item.(map[string]any)
+		if !ok {
+			return invalid("list of {op, table, rows} or {op: delete, table, keys}")
+		}
+		op := strings.ToLower(fmt.Sprintf("%v", entry["op"]))
+		table, _ := entry["table"].(string)
+		if table == "" {
+			return invalid("each mutation needs a table")
+		}
+
+		if op == constants.OperationDelete {
+			keys, ok := entry["keys"].([]any)
+			if !ok || len(keys) == 0 {
+				return invalid("delete mutations need a non-empty keys list")
+			}
+			var keySets []spanner.KeySet
+			for _, key := range keys {
+				if parts, ok := key.([]any); ok {
+					keySets = append(keySets, spanner.Key(parts))
+				} else {
+					keySets = append(keySets, spanner.Key{key})
+				}
+			}
+			mutations = append(mutations, spanner.Delete(table, spanner.KeySets(keySets...)))
+			continue
+		}
+
+		var write func(string, map[string]any) *spanner.Mutation
+		switch op {
+		case constants.OperationInsert:
+			write = spanner.InsertMap
+		case constants.OperationUpdate:
+			write = spanner.UpdateMap
+		case "insert_or_update":
+			write = spanner.InsertOrUpdateMap
+		case "replace":
+			write = spanner.ReplaceMap
+		default:
+			return invalid("op of insert, update, insert_or_update, replace or delete")
+		}
+		rows, ok := entry["rows"].([]any)
+		if !ok || len(rows) == 0 {
+			return invalid(op + " mutations need a non-empty rows list of column maps")
+		}
+		for _, row := range rows {
+			columns, ok := row.(map[string]any)
+			if !ok {
+				return invalid(op + " mutations need a non-empty rows list of column maps")
+			}
+			mutations = append(mutations, write(table, columns))
+		}
+	}
+	return mutations, nil
+}
+
+// spannerCommitTimestamp returns the commit timestamp of the connection's last read-write transaction
+func spannerCommitTimestamp(conn *sql.Conn) (string, bool) {
+	var commitTimestamp time.Time
+	err := conn.Raw(func(driverConn any) error {
+		var err error
+		commitTimestamp, err = driverConn.(spannerdriver.SpannerConn).CommitTimestamp()
+		return err
+	})
+	if err != nil {
+		return "", false
+	}
+	return commitTimestamp.UTC().Format(time.RFC3339Nano), true
+}
+
+// spannerEmulatorDSN points a database path at the emulator host, from SPANNER_EMULATOR_HOST or
+// the default port, without credentials. The driver creates the instance and database if missing.
+func spannerEmulatorDSN(dbPath, host string) string {
+	dsn := dbPath
+	if strings.HasPrefix(dsn, "projects/") {
+		if host == "" {
+			host = spannerDefaultEmulatorHost
+		}
+		dsn = host + "/" + dsn
+	}
+	return dsn + ";autoConfigEmulator=true"
+}
+
+// maskSpannerDatabase hides the project, instance and database identifiers in a path or message
+func maskSpannerDatabase(text string) string {
+	return spannerDatabasePattern.ReplaceAllString(text, "projects/***/instances/***/databases/***")
+}
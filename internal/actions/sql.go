@@ -0,0 +1,385 @@
+package actions
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/JianLoong/robogo/internal/common"
+	"github.com/JianLoong/robogo/internal/constants"
+	"github.com/JianLoong/robogo/internal/types"
+)
+
+// SQLConnectionEnvPrefix starts the settings of a named sql connection:
+// ROBOGO_SQL_<NAME>_DRIVER and ROBOGO_SQL_<NAME>_DSN, usually in .env and .env.local
+const SQLConnectionEnvPrefix = "ROBOGO_SQL_"
+
+// sqlOperationTransaction runs the statements option in one transaction
+const sqlOperationTransaction = "transaction"
+
+// sqlDrivers maps the driver setting to the database/sql driver names that provide it.
+// Only postgres is built in; the others are used when a build registers their driver.
+var sqlDrivers = map[string][]string{
+	"postgres":  {"postgres"},
+	"mysql":     {"mysql"},
+	"sqlite":    {"sqlite", "sqlite3"},
+	"sqlserver": {"sqlserver", "mssql"},
+}
+
+// sqlConnections holds the connection pools opened during a run, by connection name
+var sqlConnections = struct {
+	sync.Mutex
+	pools map[string]*sql.DB
+}{pools: map[string]*sql.DB{}}
+
+// sqlEnvName turns a connection name such as "orders-db" into its settings prefix
+var sqlEnvName = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// sqlAction runs a statement on a named connection declared in the environment, so DSNs
+// never appear in test files
+// Args: [operation, connection, sql] - sql is not used by transaction
+// Operations: query/select returns columns and rows, exec/execute/insert/update/delete
+// returns rows_affected, transaction runs the statements option and commits them together
+// Options:
+//   - params: statement parameters, a list for positional markers ($1 or ?) or a map for named ones
+//   - statements: list of SQL strings or {sql, params} for transaction
+//   - as_json: return query rows as a JSON string
+func sqlAction(args []any, options map[string]any, vars *common.Variables) types.ActionResult {
+	if len(args) < 2 {
+		return types.MissingArgsError("sql", 3, len(args))
+	}
+	if errorResult := validateArgsResolved("sql", args); errorResult != nil {
+		return *errorResult
+	}
+
+	operation := strings.ToLower(fmt.Sprintf("%v", args[0]))
+	name := fmt.Sprintf("%v", args[1])
+	query := ""
+	if len(args) > 2 {
+		query = fmt.Sprintf("%v", args[2])
+	} else if operation != sqlOperationTransaction {
+		return types.MissingArgsError("sql", 3, len(args))
+	}
+
+	params, errorResult := sqlParams("sql", substituteOption(vars, options["params"]))
+	if errorResult != nil {
+		return *errorResult
+	}
+	var statements []sqlStatement
+	if operation == sqlOperationTransaction {
+		if statements, errorResult = sqlStatements("sql", substituteOption(vars, options["statements"])); errorResult != nil {
+			return *errorResult
+		}
+		if len(statements) == 0 {
+			return types.InvalidArgError("sql", "statements", "non-empty list of SQL strings or {sql, params} objects")
+		}
+	}
+
+	db, driver, errorResult := sqlConnection(name)
+	if errorResult != nil {
+		return *errorResult
+	}
+	database := fmt.Sprintf("SQL (%s, %s)", name, driver)
+
+	ctx, cancel := context.WithTimeout(context.Background(), constants.DefaultDatabaseTimeout)
+	defer cancel()
+
+	switch operation {
+	case constants.OperationQuery, constants.OperationSelect:
+		result, err := queryRows(ctx, db, query, params)
+		if err != nil {
+			return types.DatabaseQueryError(database, err.Error())
+		}
+		if asJSON, ok := options["as_json"].(bool); ok && asJSON {
+			jsonBytes, err := json.Marshal(result)
+			if err == nil {
+				return types.ActionResult{
+					Status: constants.ActionStatusPassed,
+					Data:   map[string]any{"json_string": string(jsonBytes)},
+				}
+			}
+			// If marshaling fails, fall through to structured result
+		}
+		return types.ActionResult{
+			Status: constants.ActionStatusPassed,
+			Data:   result,
+		}
+
+	case "exec", constants.OperationExecute, constants.OperationInsert, constants.OperationUpdate, constants.OperationDelete:
+		result, err := db.ExecContext(ctx, query, params...)
+		if err != nil {
+			return types.DatabaseExecuteError(database, err.Error())
+		}
+		rowsAffected, _ := result.RowsAffected()
+		return types.ActionResult{
+			Status: constants.ActionStatusPassed,
+			Data:   map[string]any{"rows_affected": rowsAffected},
+		}
+
+	case sqlOperationTransaction:
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return types.DatabaseExecuteError(database, err.Error())
+		}
+		results := []any{}
+		for i, statement := range statements {
+			var result map[string]any
+			if sqlReturnsRows(statement.sql) {
+				result, err = queryRows(ctx, tx, statement.sql, statement.params)
+			} else {
+				var res sql.Result
+				if res, err = tx.ExecContext(ctx, statement.sql, statement.params...); err == nil {
+					rowsAffected, _ := res.RowsAffected()
+					result = map[string]any{"rows_affected": rowsAffected}
+				}
+			}
+			if err != nil {
+				tx.Rollback()
+				return types.DatabaseExecuteError(database, fmt.Sprintf("statement %d (rolled back): %s", i+1, err.Error()))
+			}
+			results = append(results, result)
+		}
+		if err := tx.Commit(); err != nil {
+			return types.DatabaseExecuteError(database, err.Error())
+		}
+		return types.ActionResult{
+			Status: constants.ActionStatusPassed,
+			Data:   map[string]any{"results": results},
+		}
+
+	default:
+		return types.UnknownOperationError("sql", operation)
+	}
+}
+
+// sqlConnection returns the pool of a named connection, opening it on first use
+func sqlConnection(name string) (*sql.DB, string, *types.ActionResult) {
+	prefix := SQLConnectionEnvPrefix + strings.ToUpper(strings.Trim(sqlEnvName.ReplaceAllString(name, "_"), "_")) + "_"
+	driver := strings.ToLower(os.Getenv(prefix + "DRIVER"))
+	dsn := os.Getenv(prefix + "DSN")
+	if driver == "" || dsn == "" {
+		result := types.NewErrorBuilder(types.ErrorCategoryDatabase, "SQL_CONNECTION_NOT_DECLARED").
+			WithTemplate("SQL connection '%s' is not declared").
+			WithContext("connection", name).
+			WithContext("driver_variable", prefix+"DRIVER").
+			WithContext("dsn_variable", prefix+"DSN").
+			WithSuggestion(fmt.Sprintf("Set %sDRIVER (postgres, mysql, sqlite or sqlserver) in .env", prefix)).
+			WithSuggestion(fmt.Sprintf("Set %sDSN in .env.local, which keeps the secret out of version control", prefix)).
+			Build(name)
+		return nil, "", &result
+	}
+
+	sqlConnections.Lock()
+	defer sqlConnections.Unlock()
+	if db, ok := sqlConnections.pools[name]; ok {
+		return db, driver, nil
+	}
+
+	driverName, errorResult := sqlDriverName(name, driver)
+	if errorResult != nil {
+		return nil, "", errorResult
+	}
+	// The DSN is left out of errors: it usually holds a password
+	db, err := sql.Open(driverName, dsn)
+	if err == nil {
+		db.SetConnMaxLifetime(constants.DefaultConnectionLifetime)
+		ctx, cancel := context.WithTimeout(context.Background(), constants.DefaultDatabaseTimeout)
+		err = db.PingContext(ctx)
+		cancel()
+		if err != nil {
+			db.Close()
+		}
+	}
+	if err != nil {
+		result := types.NewErrorBuilder(types.ErrorCategoryDatabase, "SQL_CONNECTION_FAILED").
+			WithTemplate("Failed to connect to SQL connection '%s' (%s): %s").
+			WithContext("connection", name).
+			WithContext("driver", driver).
+			WithContext("error", common.MaskCredentials(err.Error())).
+			WithSuggestion("Check if the database is running and the DSN in "+prefix+"DSN is correct").
+			Build(name, driver, common.MaskCredentials(err.Error()))
+		return nil, "", &result
+	}
+	sqlConnections.pools[name] = db
+	return db, driver, nil
+}
+
+// sqlDriverName finds the registered database/sql driver for a driver setting
+func sqlDriverName(name, driver string) (string, *types.ActionResult) {
+	candidates, known := sqlDrivers[driver]
+	registered := sql.Drivers()
+	for _, candidate := range candidates {
+		for _, available := range registered {
+			if candidate == available {
+				return candidate, nil
+			}
+		}
+	}
+
+	var supported []string
+	for key := range sqlDrivers {
+		supported = append(supported, key)
+	}
+	sort.Strings(supported)
+	builder := types.NewErrorBuilder(types.ErrorCategoryDatabase, "SQL_DRIVER_UNAVAILABLE").
+		WithContext("connection", name).
+		WithContext("driver", driver)
+	if !known {
+		builder = builder.WithTemplate("Unknown SQL driver '%s' for connection '%s'").
+			WithSuggestion("Use one of: " + strings.Join(supported, ", "))
+	} else {
+		builder = builder.WithTemplate("SQL driver '%s' for connection '%s' is not included in this build").
+			WithSuggestion("Build robogo with the Go driver registered under one of: " + strings.Join(candidates, ", "))
+	}
+	result := builder.Build(driver, name)
+	return "", &result
+}
+
+// CloseSQLConnections closes the connection pools opened by sql steps; called when a run ends
+func CloseSQLConnections() {
+	sqlConnections.Lock()
+	defer sqlConnections.Unlock()
+	for name, db := range sqlConnections.pools {
+		db.Close()
+		delete(sqlConnections.pools, name)
+	}
+}
+
+// sqlQuerier is a connection, pool or transaction that can run queries
+type sqlQuerier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// queryRows runs a query and returns its columns and rows in a JSON-compatible form for jq
+func queryRows(ctx context.Context, querier sqlQuerier, query string, params []any) (map[string]any, error) {
+	rows, err := querier.QueryContext(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	var results [][]any
+	for rows.Next() {
+		values := make([]any, len(columns))
+		valuePtrs := make([]any, len(columns))
+		for i := range values {
+			valuePtrs[i] = &values[i]
+		}
+		if err := rows.Scan(valuePtrs...); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		for i, v := range values {
+			if b, ok := v.([]byte); ok {
+				values[i] = string(b)
+			}
+		}
+		results = append(results, values)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Marshal and unmarshal to ensure JSON compatibility for jq
+	jsonBytes, err := json.Marshal(map[string]any{
+		"columns": columns,
+		"rows":    results,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("JSON marshal error: %w", err)
+	}
+	var result map[string]any
+	if err := json.Unmarshal(jsonBytes, &result); err != nil {
+		return nil, fmt.Errorf("JSON unmarshal error: %w", err)
+	}
+	return result, nil
+}
+
+// sqlReturnsRows reports whether a transaction statement returns rows
+func sqlReturnsRows(statement string) bool {
+	fields := strings.Fields(statement)
+	if len(fields) == 0 {
+		return false
+	}
+	keyword := strings.ToUpper(strings.TrimLeft(fields[0], "("))
+	upper := strings.ToUpper(statement)
+	return keyword == "SELECT" || keyword == "WITH" || keyword == "VALUES" ||
+		strings.Contains(upper, " RETURNING ") || strings.Contains(upper, " THEN RETURN ")
+}
+
+// substituteOption resolves variables inside a list or map option, such as params; steps
+// substitute only options that are plain strings
+func substituteOption(vars *common.Variables, value any) any {
+	if vars == nil || value == nil {
+		return value
+	}
+	return vars.SubstituteArgs([]any{value})[0]
+}
+
+// sqlParams converts the params option of an action to statement arguments: a list binds
+// positional parameters and a map binds named ones
+func sqlParams(action string, value any) ([]any, *types.ActionResult) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case map[string]any:
+		params := make([]any, 0, len(v))
+		for name, param := range v {
+			params = append(params, sql.Named(name, param))
+		}
+		return params, nil
+	case []any:
+		return v, nil
+	default:
+		result := types.InvalidArgError(action, "params", "list of positional parameters or map of named parameters")
+		return nil, &result
+	}
+}
+
+// sqlStatement is one statement of a transaction
+type sqlStatement struct {
+	sql    string
+	params []any
+}
+
+// sqlStatements reads the statements option of an action: SQL strings or {sql, params} objects
+func sqlStatements(action string, value any) ([]sqlStatement, *types.ActionResult) {
+	if value == nil {
+		return nil, nil
+	}
+	invalid := types.InvalidArgError(action, "statements", "list of SQL strings or {sql, params} objects")
+	items, ok := value.([]any)
+	if !ok {
+		return nil, &invalid
+	}
+	var statements []sqlStatement
+	for _, item := range items {
+		switch v := item.(type) {
+		case string:
+			statements = append(statements, sqlStatement{sql: v})
+		case map[string]any:
+			statement, ok := v["sql"].(string)
+			if !ok {
+				return nil, &invalid
+			}
+			params, errorResult := sqlParams(action, v["params"])
+			if errorResult != nil {
+				return nil, errorResult
+			}
+			statements = append(statements, sqlStatement{sql: statement, params: params})
+		default:
+			return nil, &invalid
+		}
+	}
+	return statements, nil
+}
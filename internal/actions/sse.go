@@ -0,0 +1,173 @@
+package actions
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JianLoong/robogo/internal/common"
+	"github.com/JianLoong/robogo/internal/constants"
+	"github.com/JianLoong/robogo/internal/types"
+)
+
+// sseAction connects to a Server-Sent Events endpoint and collects events
+// Args: [url] - the text/event-stream endpoint
+// Options: count - events to collect before returning (default: 1)
+//
+//	timeout - maximum time to wait for events (default: "30s")
+//	headers - additional request headers
+//	last_event_id - resume the stream after this event id
+//	skip_tls_verify - disable TLS certificate verification
+func sseAction(args []any, options map[string]any, vars *common.Variables) types.ActionResult {
+	if len(args) < 1 {
+		return types.MissingArgsError("sse", 1, len(args))
+	}
+
+	if errorResult := validateArgsResolved("sse", args[:1]); errorResult != nil {
+		return *errorResult
+	}
+
+	url := fmt.Sprintf("%v", args[0])
+
+	count := 1
+	if countOpt, ok := options["count"]; ok {
+		parsed, err := strconv.Atoi(fmt.Sprintf("%v", countOpt))
+		if err != nil || parsed < 1 {
+			return types.InvalidArgError("sse", "count", "positive integer")
+		}
+		count = parsed
+	}
+
+	timeout := 30 * time.Second
+	if timeoutStr, ok := options["timeout"].(string); ok {
+		t, err := time.ParseDuration(timeoutStr)
+		if err != nil {
+			return types.InvalidArgError("sse", "timeout", "duration such as '10s' or '500ms'")
+		}
+		timeout = t
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return types.RequestError(fmt.Sprintf("SSE %s", url), err.Error())
+	}
+
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+	if lastEventID, ok := options["last_event_id"]; ok {
+		req.Header.Set("Last-Event-ID", fmt.Sprintf("%v", lastEventID))
+	}
+	if headers, ok := options["headers"].(map[string]any); ok {
+		for key, value := range headers {
+			req.Header.Set(key, fmt.Sprintf("%v", value))
+		}
+	}
+
+	// No client timeout: the stream stays open and the context bounds the wait
+	client := &http.Client{}
+	if skipTLS, ok := options["skip_tls_verify"].(bool); ok && skipTLS {
+		client.Transport = &http.Transport{
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+		}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return types.RequestError(fmt.Sprintf("SSE %s", url), err.Error())
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return types.NewErrorBuilder(types.ErrorCategoryNetwork, "SSE_BAD_STATUS").
+			WithTemplate("SSE endpoint returned HTTP %d").
+			WithContext("url", url).
+			WithSuggestion("Check that the endpoint serves text/event-stream").
+			Build(resp.StatusCode)
+	}
+
+	if contentType := resp.Header.Get("Content-Type"); !strings.HasPrefix(strings.ToLower(contentType), "text/event-stream") {
+		return types.NewErrorBuilder(types.ErrorCategoryNetwork, "SSE_BAD_CONTENT_TYPE").
+			WithTemplate("SSE endpoint returned unexpected content type '%s'").
+			WithContext("url", url).
+			WithSuggestion("Use the http action for non-streaming endpoints").
+			Build(contentType)
+	}
+
+	events, timedOut := readSSEEvents(resp, count)
+
+	return types.ActionResult{
+		Status: constants.ActionStatusPassed,
+		Data:   events,
+		Meta: map[string]any{
+			"count":     len(events),
+			"timed_out": timedOut,
+		},
+	}
+}
+
+// readSSEEvents parses the event stream until count events arrive or the stream ends.
+// Returns the events and whether reading stopped before count was reached.
+func readSSEEvents(resp *http.Response, count int) ([]any, bool) {
+	events := make([]any, 0, count)
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	eventType := ""
+	lastID := ""
+	var data []string
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		// A blank line dispatches the buffered event
+		if line == "" {
+			if len(data) > 0 {
+				if eventType == "" {
+					eventType = "message"
+				}
+				events = append(events, map[string]any{
+					"event": eventType,
+					"data":  strings.Join(data, "\n"),
+					"id":    lastID,
+				})
+				if len(events) >= count {
+					return events, false
+				}
+			}
+			eventType = ""
+			data = nil
+			continue
+		}
+
+		// Lines starting with a colon are comments (often keep-alives)
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+
+		switch field {
+		case "event":
+			eventType = value
+		case "data":
+			data = append(data, value)
+		case "id":
+			lastID = value
+		}
+	}
+
+	// Stream closed or the timeout cancelled the request
+	return events, true
+}
@@ -57,6 +57,41 @@ func stringReplaceAction(args []any, options map[string]any, vars *common.Variab
 	}
 }
 
+// regexReplaceAction replaces all matches of a regular expression in a string
+// Args: [text, pattern, replacement] - replacement may reference capture groups as $1, $2, ...
+// (${name} references would be substituted as robogo variables before the action runs)
+func regexReplaceAction(args []any, options map[string]any, vars *common.Variables) types.ActionResult {
+	if len(args) < 3 {
+		return types.MissingArgsError("regex_replace", 3, len(args))
+	}
+
+	text := fmt.Sprintf("%v", args[0])
+	pattern := fmt.Sprintf("%v", args[1])
+	replacement := fmt.Sprintf("%v", args[2])
+
+	re, err := common.CompileCached(pattern)
+	if err != nil {
+		return types.NewErrorBuilder(types.ErrorCategoryValidation, "INVALID_REGEX_PATTERN").
+			WithTemplate("Invalid regular expression pattern '%s'").
+			WithContext("pattern", pattern).
+			WithContext("error", err.Error()).
+			WithSuggestion("Check the pattern syntax (Go RE2 syntax, no lookaheads or backreferences)").
+			Build(pattern)
+	}
+
+	matches := len(re.FindAllStringIndex(text, -1))
+	result := re.ReplaceAllString(text, replacement)
+
+	return types.ActionResult{
+		Status: constants.ActionStatusPassed,
+		Data: map[string]any{
+			"result":            result,
+			"original_text":     text,
+			"replacements_made": matches,
+		},
+	}
+}
+
 // stringFormatAction formats a string with placeholders
 // Args: [template, ...values] - template string with {} placeholders and values
 func stringFormatAction(args []any, options map[string]any, vars *common.Variables) types.ActionResult {
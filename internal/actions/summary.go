@@ -0,0 +1,73 @@
+package actions
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/JianLoong/robogo/internal/common"
+	"github.com/JianLoong/robogo/internal/constants"
+	"github.com/JianLoong/robogo/internal/types"
+)
+
+// AnnotationsVariable holds the annotations recorded by summary steps of a test case
+const AnnotationsVariable = "__annotations"
+
+// summaryAction records a named annotation, such as orders_created: 42, that is printed
+// in the test and suite summaries and written to reports
+// Args: [name, value]
+// Options: add - add a numeric value to the annotation instead of replacing it, for counting in loops
+func summaryAction(args []any, options map[string]any, vars *common.Variables) types.ActionResult {
+	if len(args) < 2 {
+		return types.MissingArgsError("summary", 2, len(args))
+	}
+
+	if errorResult := validateArgsResolved("summary", args[:2]); errorResult != nil {
+		return *errorResult
+	}
+
+	name := strings.TrimSpace(fmt.Sprintf("%v", args[0]))
+	if name == "" {
+		return types.InvalidArgError("summary", "name", "non-empty annotation name")
+	}
+	value := annotationValue(args[1])
+
+	annotations, _ := vars.Get(AnnotationsVariable).(map[string]any)
+	if annotations == nil {
+		annotations = make(map[string]any)
+		vars.Set(AnnotationsVariable, annotations)
+	}
+
+	if add, _ := options["add"].(bool); add {
+		increment, ok := value.(float64)
+		if !ok {
+			return types.InvalidArgError("summary", "value", "number when add is true")
+		}
+		current, _ := annotations[name].(float64)
+		value = current + increment
+	}
+	annotations[name] = value
+
+	return types.ActionResult{
+		Status: constants.ActionStatusPassed,
+		Data:   map[string]any{"name": name, "value": value},
+	}
+}
+
+// annotationValue stores numbers, including numeric strings from substituted
+// variables, as float64 so they can be added up across test cases
+func annotationValue(value any) any {
+	switch v := value.(type) {
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case float64:
+		return v
+	case string:
+		if parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+			return parsed
+		}
+	}
+	return value
+}
@@ -8,6 +8,8 @@ import (
 	"github.com/JianLoong/robogo/internal/types"
 )
 
+// variableAction sets a variable. With operation "set_if_absent" an existing value is kept,
+// and the result reports whether the variable was set along with its current value.
 func variableAction(args []any, options map[string]any, vars *common.Variables) types.ActionResult {
 	if len(args) < 2 {
 		return types.MissingArgsError("variable", 2, len(args))
@@ -15,11 +17,35 @@ func variableAction(args []any, options map[string]any, vars *common.Variables)
 
 	name := fmt.Sprintf("%v", args[0])
 	value := args[1]
+	if common.ReservedVariable(name) {
+		return types.NewErrorBuilder(types.ErrorCategoryValidation, "RESERVED_VARIABLE").
+			WithTemplate("Variable name '%s' is reserved for the running step's values, e.g. ${step.idempotency_key}").
+			WithSuggestion("Rename the variable").
+			Build(name)
+	}
 
-	vars.Set(name, value)
+	operation := "set"
+	if op, ok := options["operation"].(string); ok && op != "" {
+		operation = op
+	}
 
-	return types.ActionResult{
-		Status: constants.ActionStatusPassed,
-		Data:   value,
+	switch operation {
+	case "set":
+		vars.Set(name, value)
+		return types.ActionResult{
+			Status: constants.ActionStatusPassed,
+			Data:   value,
+		}
+	case "set_if_absent":
+		set := vars.SetIfAbsent(name, value)
+		return types.ActionResult{
+			Status: constants.ActionStatusPassed,
+			Data: map[string]any{
+				"set":   set,
+				"value": vars.Get(name),
+			},
+		}
+	default:
+		return types.UnknownOperationError("variable", operation)
 	}
 }
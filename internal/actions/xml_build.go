@@ -1,7 +1,9 @@
 package actions
 
 import (
+	"encoding/xml"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/JianLoong/robogo/internal/common"
@@ -84,13 +86,13 @@ func buildXMLElement(builder *strings.Builder, name string, data interface{}, in
 		// Separate attributes, text, and children
 		for key, value := range v {
 			if key == "@attributes" {
-				if attrs, ok := value.(map[string]string); ok {
-					for attrName, attrValue := range attrs {
-						builder.WriteString(fmt.Sprintf(` %s="%s"`, attrName, attrValue))
+				if attrs, ok := toStringMap(value); ok {
+					for _, attrName := range sortedKeys(attrs) {
+						builder.WriteString(fmt.Sprintf(` %s="%s"`, attrName, escapeXMLText(attrs[attrName])))
 					}
 				}
 			} else if key == "text" {
-				text = fmt.Sprintf("%v", value)
+				text = escapeXMLText(fmt.Sprintf("%v", value))
 			} else {
 				children[key] = value
 			}
@@ -108,8 +110,9 @@ func buildXMLElement(builder *strings.Builder, name string, data interface{}, in
 			if text != "" {
 				builder.WriteString(fmt.Sprintf("%s  %s\n", indentStr, text))
 			}
-			for childName, childValue := range children {
-				err := buildXMLElement(builder, childName, childValue, indent+1)
+			// Sorted so the same data always builds the same document
+			for _, childName := range sortedKeys(children) {
+				err := buildXMLElement(builder, childName, children[childName], indent+1)
 				if err != nil {
 					return err
 				}
@@ -128,9 +131,26 @@ func buildXMLElement(builder *strings.Builder, name string, data interface{}, in
 		
 	default:
 		// Simple value
-		builder.WriteString(fmt.Sprintf("%s<%s>%v</%s>\n", indentStr, name, v, name))
+		builder.WriteString(fmt.Sprintf("%s<%s>%s</%s>\n", indentStr, name, escapeXMLText(fmt.Sprintf("%v", v)), name))
 	}
 	
 	return nil
 }
 
+// escapeXMLText escapes a value for use as element text or an attribute value
+func escapeXMLText(value string) string {
+	var builder strings.Builder
+	xml.EscapeText(&builder, []byte(value))
+	return builder.String()
+}
+
+// sortedKeys returns the keys of a map in sorted order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
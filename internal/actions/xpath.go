@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/antchfx/xmlquery"
+	"github.com/antchfx/xpath"
 	"github.com/JianLoong/robogo/internal/common"
 	"github.com/JianLoong/robogo/internal/constants"
 	"github.com/JianLoong/robogo/internal/types"
@@ -35,9 +36,31 @@ func xpathAction(args []any, options map[string]any, vars *common.Variables) typ
 		}
 	}
 
+	// Prefixes in the query, like //ns:Item, resolve through the namespaces option
+	var find func() []*xmlquery.Node
+	if nsOption, ok := options["namespaces"]; ok {
+		namespaces, valid := toStringMap(nsOption)
+		if !valid {
+			return types.InvalidArgError("xpath", "namespaces", "map of prefix to namespace URI")
+		}
+		expr, err := xpath.CompileWithNS(xpathQuery, namespaces)
+		if err != nil {
+			return types.NewErrorBuilder(types.ErrorCategoryValidation, "XPATH_NAMESPACE_ERROR").
+				WithTemplate("Failed to resolve XPath %s: %s").
+				WithContext("query", xpathQuery).
+				WithContext("namespaces", namespaces).
+				WithSuggestion("Declare every prefix used in the query in the namespaces option").
+				WithSuggestion("Prefixes are matched by namespace URI, so they need not match the document's prefixes").
+				Build(xpathQuery, err.Error())
+		}
+		find = func() []*xmlquery.Node { return xmlquery.QuerySelectorAll(doc, expr) }
+	} else {
+		find = func() []*xmlquery.Node { return xmlquery.Find(doc, xpathQuery) }
+	}
+
 	if multiple {
 		// Find all matching nodes
-		nodes := xmlquery.Find(doc, xpathQuery)
+		nodes := find()
 		var results []string
 		
 		for _, node := range nodes {
@@ -56,14 +79,15 @@ func xpathAction(args []any, options map[string]any, vars *common.Variables) typ
 		}
 	} else {
 		// Find first matching node
-		node := xmlquery.FindOne(doc, xpathQuery)
-		if node == nil {
+		nodes := find()
+		if len(nodes) == 0 {
 			return types.ActionResult{
 				Status: constants.ActionStatusPassed,
 				Data:   nil,
 			}
 		}
 		
+		node := nodes[0]
 		var result string
 		if node.Type == xmlquery.AttributeNode {
 			result = node.InnerText()
@@ -78,4 +102,18 @@ func xpathAction(args []any, options map[string]any, vars *common.Variables) typ
 			Data:   result,
 		}
 	}
-}
\ No newline at end of file
+}
+// toStringMap converts a YAML map option to string keys and values
+func toStringMap(value any) (map[string]string, bool) {
+	switch v := value.(type) {
+	case map[string]string:
+		return v, true
+	case map[string]any:
+		converted := make(map[string]string, len(v))
+		for key, item := range v {
+			converted[key] = fmt.Sprintf("%v", item)
+		}
+		return converted, true
+	}
+	return nil, false
+}
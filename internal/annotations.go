@@ -0,0 +1,86 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/JianLoong/robogo/internal/types"
+)
+
+// aggregateAnnotations combines the annotations of every test case in a run: numbers
+// are added up, other values are listed once each in the order they were first seen
+func aggregateAnnotations(results []*types.TestResult) map[string]any {
+	values := make(map[string][]any)
+	var names []string
+	for _, result := range results {
+		for _, name := range sortedAnnotationNames(result.Annotations) {
+			if _, seen := values[name]; !seen {
+				names = append(names, name)
+			}
+			values[name] = append(values[name], result.Annotations[name])
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	aggregated := make(map[string]any, len(names))
+	for _, name := range names {
+		total, numeric := 0.0, true
+		for _, value := range values[name] {
+			number, ok := value.(float64)
+			if !ok {
+				numeric = false
+				break
+			}
+			total += number
+		}
+		if numeric {
+			aggregated[name] = total
+			continue
+		}
+
+		var distinct []string
+		seen := make(map[string]bool)
+		for _, value := range values[name] {
+			formatted := formatAnnotation(value)
+			if !seen[formatted] {
+				seen[formatted] = true
+				distinct = append(distinct, formatted)
+			}
+		}
+		aggregated[name] = strings.Join(distinct, ", ")
+	}
+	return aggregated
+}
+
+// printAnnotations prints annotations sorted by name
+func printAnnotations(annotations map[string]any) {
+	if len(annotations) == 0 {
+		return
+	}
+	fmt.Println("  Annotations:")
+	for _, name := range sortedAnnotationNames(annotations) {
+		fmt.Printf("    %s: %s\n", name, formatAnnotation(annotations[name]))
+	}
+}
+
+// sortedAnnotationNames returns annotation names in alphabetical order
+func sortedAnnotationNames(annotations map[string]any) []string {
+	names := make([]string, 0, len(annotations))
+	for name := range annotations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// formatAnnotation prints whole numbers without a decimal point
+func formatAnnotation(value any) string {
+	if number, ok := value.(float64); ok {
+		return strconv.FormatFloat(number, 'f', -1, 64)
+	}
+	return fmt.Sprintf("%v", value)
+}
@@ -0,0 +1,197 @@
+package internal
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/JianLoong/robogo/internal/common"
+	"github.com/JianLoong/robogo/internal/types"
+)
+
+// defaultMaxArtifactBytes is the largest artifact written when --max-artifact-bytes is not set
+const defaultMaxArtifactBytes = 10 * 1024 * 1024
+
+// artifactStore writes artifacts into one directory as <test case>-<name>, numbering
+// names that are already taken in this run
+type artifactStore struct {
+	dir      string
+	maxBytes int64
+	mu       sync.Mutex
+	used     map[string]bool
+}
+
+// newArtifactStore returns a store writing to dir; maxBytes 0 means defaultMaxArtifactBytes
+func newArtifactStore(dir string, maxBytes int) *artifactStore {
+	if maxBytes <= 0 {
+		maxBytes = defaultMaxArtifactBytes
+	}
+	return &artifactStore{dir: dir, maxBytes: int64(maxBytes), used: make(map[string]bool)}
+}
+
+// saveResult writes the artifacts of a test case's steps and of the test case itself,
+// recording where each one went or why it was skipped. Text is masked like printed
+// output, along with the secret values of the test case (see Variables.SecretValues);
+// binary content holding such a value is not written.
+func (s *artifactStore) saveResult(result *types.TestResult, variables *common.Variables) {
+	secrets := variables.SecretValues()
+	forEachArtifact(result, func(artifact *types.Artifact) {
+		s.save(result.Name, artifact, secrets)
+	})
+}
+
+// save writes one artifact and drops its content from memory
+func (s *artifactStore) save(testCase string, artifact *types.Artifact, secrets []string) {
+	content := artifact.Content
+	artifact.Content = nil
+	if artifact.Skipped != "" || artifact.Path != "" {
+		return
+	}
+
+	if artifact.Size > s.maxBytes {
+		artifact.Skipped = fmt.Sprintf("larger than --max-artifact-bytes (%d)", s.maxBytes)
+		return
+	}
+	if artifact.Source != "" {
+		data, err := os.ReadFile(artifact.Source)
+		if err != nil {
+			artifact.Skipped = fmt.Sprintf("failed to read: %v", err)
+			return
+		}
+		content = data
+		if int64(len(content)) > s.maxBytes {
+			artifact.Skipped = fmt.Sprintf("larger than --max-artifact-bytes (%d)", s.maxBytes)
+			return
+		}
+	}
+
+	if utf8.Valid(content) {
+		masked := maskArtifactText(string(content), secrets)
+		if masked != string(content) {
+			content, artifact.Masked = []byte(masked), true
+		}
+	} else {
+		for _, secret := range secrets {
+			if bytes.Contains(content, []byte(secret)) {
+				artifact.Skipped = "binary content holds the value of a sensitive variable"
+				return
+			}
+		}
+	}
+
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		artifact.Skipped = fmt.Sprintf("failed to create artifact directory: %v", err)
+		return
+	}
+	path := s.reserve(testCase, artifact.Name)
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		artifact.Skipped = fmt.Sprintf("failed to write: %v", err)
+		return
+	}
+	sum := sha256.Sum256(content)
+	artifact.Path, artifact.Size, artifact.SHA256 = path, int64(len(content)), hex.EncodeToString(sum[:])
+}
+
+// reserve returns an unused path for an artifact of a test case
+func (s *artifactStore) reserve(testCase, name string) string {
+	prefix := strings.Trim(unsafeFileChars.ReplaceAllString(testCase, "_"), "_")
+	if prefix == "" {
+		prefix = "testcase"
+	}
+	name = strings.Trim(unsafeFileChars.ReplaceAllString(name, "_"), "_")
+	ext := filepath.Ext(name)
+	base := prefix + "-" + strings.TrimSuffix(name, ext)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	path := filepath.Join(s.dir, base+ext)
+	for n := 2; s.used[path]; n++ {
+		path = filepath.Join(s.dir, fmt.Sprintf("%s-%d%s", base, n, ext))
+	}
+	s.used[path] = true
+	return path
+}
+
+// skipArtifacts records that a run without --artifacts keeps no artifacts
+func skipArtifacts(result *types.TestResult) {
+	forEachArtifact(result, func(artifact *types.Artifact) {
+		if artifact.Skipped == "" {
+			artifact.Skipped = "not written without --artifacts"
+		}
+		artifact.Content = nil
+	})
+}
+
+// forEachArtifact visits the artifacts of every step, including the steps of called
+// test cases, and then those of the test case itself
+func forEachArtifact(result *types.TestResult, visit func(*types.Artifact)) {
+	var walk func(steps []types.StepResult)
+	walk = func(steps []types.StepResult) {
+		for i := range steps {
+			for j := range steps[i].Artifacts {
+				visit(&steps[i].Artifacts[j])
+			}
+			walk(steps[i].Steps)
+		}
+	}
+	walk(result.SetupSteps)
+	walk(result.Steps)
+	walk(result.TeardownSteps)
+	for i := range result.Artifacts {
+		visit(&result.Artifacts[i])
+	}
+}
+
+// caseArtifacts lists the artifacts of a test case and its steps, for the run report
+func caseArtifacts(result *types.TestResult) []types.Artifact {
+	var artifacts []types.Artifact
+	forEachArtifact(result, func(artifact *types.Artifact) {
+		artifacts = append(artifacts, *artifact)
+	})
+	return artifacts
+}
+
+// maskArtifactText masks known secret values and what the debug dump masks: sensitive
+// fields of JSON documents, and credentials and sensitive key=value pairs in other text
+func maskArtifactText(text string, secrets []string) string {
+	masked := common.MaskSensitiveData(common.MaskCredentials(text), common.SensitiveKeys())
+	var original, document any
+	if json.Unmarshal([]byte(text), &original) == nil && json.Unmarshal([]byte(text), &document) == nil {
+		masked = text
+		unchanged, _ := json.Marshal(original)
+		if encoded, err := json.Marshal(maskDumpValue(document)); err == nil && !bytes.Equal(encoded, unchanged) {
+			indented, _ := json.MarshalIndent(document, "", "  ")
+			masked = string(indented)
+		}
+	}
+	return common.MaskSecretValues(masked, secrets)
+}
+
+// printArtifacts says where the artifacts of a run went and lists those that were skipped
+func printArtifacts(dir string, results []*types.TestResult) {
+	written := 0
+	var skipped []string
+	for _, result := range results {
+		forEachArtifact(result, func(artifact *types.Artifact) {
+			if artifact.Path != "" {
+				written++
+			} else {
+				skipped = append(skipped, fmt.Sprintf("%s [%s]: %s", artifact.Name, result.Name, artifact.Skipped))
+			}
+		})
+	}
+	if written == 0 && len(skipped) == 0 {
+		return
+	}
+	fmt.Printf("\nArtifacts written to: %s (%d files)\n", dir, written)
+	for _, line := range skipped {
+		fmt.Printf("  [SKIPPED] %s\n", line)
+	}
+}
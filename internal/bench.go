@@ -0,0 +1,271 @@
+package internal
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/JianLoong/robogo/internal/actions"
+	"github.com/JianLoong/robogo/internal/execution"
+	"github.com/JianLoong/robogo/internal/types"
+)
+
+// Defaults for the bench command
+const (
+	defaultBenchIterations  = 100
+	defaultBenchConcurrency = 1
+)
+
+// benchSample is the outcome of one benchmark iteration
+type benchSample struct {
+	iteration int
+	start     time.Time
+	duration  time.Duration
+	status    string
+	message   string
+}
+
+// runBenchmark runs one test case repeatedly across concurrent workers and prints
+// throughput, error rate and latency percentiles. Each iteration gets a fresh runner
+// and a freshly loaded test case, so no variables leak between iterations.
+// Exits non-zero when the failure rate exceeds --max-failure-rate.
+func runBenchmark(ctx context.Context, filename string, args ParsedArgs) {
+	// Every iteration measures its actions, so none is served from a step's cache
+	execution.DisableResultCache()
+	testCases, err := LoadTestCases(filename)
+	if err != nil {
+		fmt.Printf("Error: failed to parse test file: %v\n", err)
+		os.Exit(ExitUsageError)
+	}
+	filter, err := NewTestCaseFilter(args.filters)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(ExitUsageError)
+	}
+	caseIndex := -1
+	for i, testCase := range testCases {
+		if !filter.Matches(testCase.Name) {
+			continue
+		}
+		if caseIndex >= 0 {
+			fmt.Printf("Error: %s has more than one test case; pick one with --filter\n", filename)
+			os.Exit(ExitUsageError)
+		}
+		caseIndex = i
+	}
+	if caseIndex < 0 {
+		fmt.Printf("Error: no test case in %s matches --filter\n", filename)
+		os.Exit(ExitUsageError)
+	}
+	name := testCases[caseIndex].Name
+
+	fmt.Printf("Benchmarking %s: %d iterations, %d workers\n", name, args.iterations, args.concurrency)
+
+	// Per-iteration step output would interleave across workers, so it is discarded
+	restoreStdout, err := discardStdout()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(ExitUsageError)
+	}
+
+	iterations := make(chan int)
+	samples := make([]benchSample, 0, args.iterations)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	start := time.Now()
+	for worker := 0; worker < args.concurrency; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for iteration := range iterations {
+				sample := runBenchIteration(ctx, filename, caseIndex, iteration, args)
+				mu.Lock()
+				samples = append(samples, sample)
+				mu.Unlock()
+			}
+		}()
+	}
+	for iteration := 1; iteration <= args.iterations && ctx.Err() == nil; iteration++ {
+		iterations <- iteration
+	}
+	close(iterations)
+	wg.Wait()
+	elapsed := time.Since(start)
+	actions.CloseSQLConnections()
+
+	restoreStdout()
+
+	sort.Slice(samples, func(i, j int) bool { return samples[i].iteration < samples[j].iteration })
+	failureRate := printBenchSummary(samples, elapsed)
+
+	if args.samples != "" {
+		if err := writeBenchSamples(args.samples, samples); err != nil {
+			fmt.Printf("[WARN] Failed to write samples: %v\n", err)
+		} else {
+			fmt.Printf("\nSamples written to: %s\n", args.samples)
+		}
+	}
+
+	if failureRate*100 > args.failureLimit {
+		fmt.Printf("\nFailure rate %.2f%% exceeds --max-failure-rate %.2f%%\n", failureRate*100, args.failureLimit)
+		os.Exit(ExitTestFailure)
+	}
+}
+
+// runBenchIteration runs one iteration with its own runner and variables
+func runBenchIteration(ctx context.Context, filename string, caseIndex, iteration int, args ParsedArgs) benchSample {
+	sample := benchSample{iteration: iteration, status: string(types.ActionStatusError)}
+
+	testCases, err := LoadTestCases(filename)
+	if err != nil || caseIndex >= len(testCases) {
+		sample.message = fmt.Sprintf("failed to reload test file: %v", err)
+		return sample
+	}
+
+	runner := NewTestRunner()
+	runner.SetStrict(args.strict)
+	runner.SetMaxDataBytes(args.maxDataBytes)
+	if args.pluginsFile != "" {
+		if err := runner.LoadPlugins(args.pluginsFile); err != nil {
+			sample.message = fmt.Sprintf("plugin configuration: %v", err)
+			return sample
+		}
+	}
+
+	sample.start = time.Now()
+	result, err := runner.RunTest(ctx, filename, testCases[caseIndex])
+	sample.duration = time.Since(sample.start)
+	if err != nil {
+		sample.message = err.Error()
+		return sample
+	}
+	sample.status = result.Status
+	sample.message = firstFailureMessage(result)
+	return sample
+}
+
+// firstFailureMessage returns the message of the first failed or errored step
+func firstFailureMessage(result *types.TestResult) string {
+	if message := result.GetMessage(); message != "" {
+		return message
+	}
+	for _, steps := range [][]types.StepResult{result.SetupSteps, result.Steps, result.TeardownSteps} {
+		for _, step := range steps {
+			if message := step.Result.GetMessage(); message != "" && step.Result.Status != types.ActionStatusPassed {
+				return message
+			}
+		}
+	}
+	return ""
+}
+
+// printBenchSummary prints throughput, error rate and latency percentiles; returns the failure rate
+func printBenchSummary(samples []benchSample, elapsed time.Duration) float64 {
+	fmt.Printf("\nBenchmark Summary:\n")
+	if len(samples) == 0 {
+		fmt.Println("  No iterations completed")
+		return 0
+	}
+
+	durations := make([]time.Duration, len(samples))
+	failures := make(map[string]int)
+	failed := 0
+	for i, sample := range samples {
+		durations[i] = sample.duration
+		if sample.status != string(types.ActionStatusPassed) {
+			failed++
+			failures[sample.message]++
+		}
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	failureRate := float64(failed) / float64(len(samples))
+
+	fmt.Printf("  Iterations: %d in %s\n", len(samples), elapsed.Round(time.Millisecond))
+	fmt.Printf("  Throughput: %.2f iterations/s\n", float64(len(samples))/elapsed.Seconds())
+	fmt.Printf("  Passed: %d, failed: %d (failure rate %.2f%%)\n", len(samples)-failed, failed, failureRate*100)
+	fmt.Printf("  Latency: min %s, p50 %s, p90 %s, p99 %s, max %s\n",
+		durations[0].Round(time.Microsecond),
+		percentileDuration(durations, 50).Round(time.Microsecond),
+		percentileDuration(durations, 90).Round(time.Microsecond),
+		percentileDuration(durations, 99).Round(time.Microsecond),
+		durations[len(durations)-1].Round(time.Microsecond))
+
+	if len(failures) > 0 {
+		messages := make([]string, 0, len(failures))
+		for message := range failures {
+			messages = append(messages, message)
+		}
+		sort.Slice(messages, func(i, j int) bool {
+			if failures[messages[i]] != failures[messages[j]] {
+				return failures[messages[i]] > failures[messages[j]]
+			}
+			return messages[i] < messages[j]
+		})
+		fmt.Println("  Failures:")
+		for _, message := range messages {
+			fmt.Printf("    %5d × %s\n", failures[message], firstLine(message))
+		}
+	}
+	return failureRate
+}
+
+// firstLine returns the first line of a message; the rest is context and suggestions
+func firstLine(message string) string {
+	line, _, _ := strings.Cut(message, "\n")
+	return line
+}
+
+// percentileDuration returns the nearest-rank percentile of sorted durations
+func percentileDuration(sorted []time.Duration, percentile int) time.Duration {
+	return sorted[(len(sorted)*percentile+99)/100-1]
+}
+
+// writeBenchSamples writes one CSV row per iteration
+func writeBenchSamples(path string, samples []benchSample) error {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create samples directory: %w", err)
+		}
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create samples file: %w", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	writer.Write([]string{"iteration", "start", "duration_ms", "status", "message"})
+	for _, sample := range samples {
+		writer.Write([]string{
+			strconv.Itoa(sample.iteration),
+			sample.start.Format(time.RFC3339Nano),
+			strconv.FormatFloat(float64(sample.duration)/float64(time.Millisecond), 'f', 3, 64),
+			sample.status,
+			firstLine(sample.message),
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// discardStdout sends everything printed to stdout to the void until the returned function is called
+func discardStdout() (func(), error) {
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to silence iteration output: %w", err)
+	}
+	original := os.Stdout
+	os.Stdout = devNull
+	return func() {
+		os.Stdout = original
+		devNull.Close()
+	}, nil
+}
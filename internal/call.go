@@ -0,0 +1,163 @@
+package internal
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/JianLoong/robogo/internal/actions"
+	"github.com/JianLoong/robogo/internal/common"
+	"github.com/JianLoong/robogo/internal/execution"
+	"github.com/JianLoong/robogo/internal/types"
+)
+
+// maxCallDepth limits how deeply call steps nest, so a test case that ends up calling
+// itself fails with a clear error instead of running forever
+const maxCallDepth = 10
+
+// callTestCase runs the test case file of a call step in a child runner with its own
+// variables, seeded from the step's with: values. The step passes with the callee's
+// declared outputs as its data, and the callee's step results are nested under it.
+func (r *TestRunner) callTestCase(step types.Step, stepNum int, inputs map[string]any) *types.StepResult {
+	includeSummary := true
+	if step.Summary != nil {
+		includeSummary = *step.Summary
+	}
+	stepResult := &types.StepResult{
+		Name:           step.Name,
+		ID:             step.ID,
+		Action:         "call",
+		IncludeSummary: includeSummary,
+	}
+
+	// A call in included steps is relative to their snippet file
+	callerFile := r.filename
+	if step.Source != "" {
+		callerFile = step.Source
+	}
+	path := resolveCallPath(callerFile, step.Call)
+	if r.verbosity > execution.VerbosityQuiet {
+		fmt.Printf("Step %d: %s\n  Call: %s\n\n", stepNum, step.Name, path)
+	}
+
+	callers := append(append([]string{}, r.callStack...), r.filename)
+	if len(callers) > maxCallDepth {
+		stepResult.Result = types.NewErrorBuilder(types.ErrorCategoryValidation, "CALL_DEPTH_EXCEEDED").
+			WithTemplate("Call depth limit of %d exceeded: %s").
+			WithContext("call", step.Call).
+			WithSuggestion("Check for test cases that call each other in a cycle").
+			Build(maxCallDepth, strings.Join(append(callers, path), " -> "))
+		return stepResult
+	}
+
+	testCase, err := loadCalledTestCase(path)
+	if err != nil {
+		stepResult.Result = types.NewErrorBuilder(types.ErrorCategoryValidation, "CALL_LOAD_FAILED").
+			WithTemplate("Failed to load called test case: %v").
+			WithContext("call", step.Call).
+			Build(err)
+		return stepResult
+	}
+	if missing := missingCallInputs(testCase, inputs); len(missing) > 0 {
+		stepResult.Result = types.NewErrorBuilder(types.ErrorCategoryValidation, "CALL_MISSING_INPUTS").
+			WithTemplate("Call to '%s' is missing required input(s): %s").
+			WithContext("call", step.Call).
+			WithSuggestion("Pass every variable listed in the called test case's inputs with with:").
+			Build(testCase.Name, strings.Join(missing, ", "))
+		return stepResult
+	}
+
+	child := NewTestRunner()
+	child.SetStrict(r.strict)
+	child.SetVerbosity(r.verbosity)
+	child.SetMaxDataBytes(r.maxDataBytes)
+	child.maskOutput = r.maskOutput
+	child.SetRunVariables(r.runVariables)
+	for _, manifest := range r.pluginManifests {
+		if err := child.LoadPlugins(manifest); err != nil {
+			stepResult.Result = types.NewErrorBuilder(types.ErrorCategoryExecution, "CALL_FAILED").
+				WithTemplate("Failed to load plugins for called test case: %v").
+				Build(err)
+			return stepResult
+		}
+	}
+	child.callStack = callers
+	child.inputs = inputs
+	// Requests made by the called test case share the caller's correlation ID
+	if _, passed := inputs[actions.CorrelationIDVariable]; !passed {
+		child.inputs = make(map[string]any, len(inputs)+1)
+		for name, value := range inputs {
+			child.inputs[name] = value
+		}
+		child.inputs[actions.CorrelationIDVariable] = r.variables.Get(actions.CorrelationIDVariable)
+	}
+
+	result, err := child.RunTest(r.ctx, path, testCase)
+	if err != nil {
+		stepResult.Result = types.NewErrorBuilder(types.ErrorCategoryExecution, "CALL_FAILED").
+			WithTemplate("Called test case '%s' could not run: %v").
+			WithContext("call", step.Call).
+			Build(testCase.Name, err)
+		return stepResult
+	}
+	stepResult.Duration = result.Duration
+	stepResult.Steps = append(append(append([]types.StepResult{}, result.SetupSteps...), result.Steps...), result.TeardownSteps...)
+
+	switch {
+	case result.SkipInfo != nil:
+		stepResult.Result = types.ActionResult{Status: types.ActionStatusSkipped, SkipInfo: result.SkipInfo}
+		return stepResult
+	case result.Status != string(types.ActionStatusPassed):
+		stepResult.Result = types.ActionResult{Status: types.ActionStatus(result.Status), ErrorInfo: result.ErrorInfo}
+		return stepResult
+	}
+
+	outputs := make(map[string]any, len(testCase.Outputs))
+	for _, name := range testCase.Outputs {
+		if !child.variables.Has(name) {
+			stepResult.Result = types.NewErrorBuilder(types.ErrorCategoryVariable, "CALL_OUTPUT_MISSING").
+				WithTemplate("Called test case '%s' did not set its output '%s'").
+				WithContext("call", step.Call).
+				WithSuggestion("Set the variable in the called test case, e.g. with result:").
+				Build(testCase.Name, name)
+			return stepResult
+		}
+		outputs[name] = child.variables.Get(name)
+	}
+	stepResult.Result = types.ActionResult{Status: types.ActionStatusPassed, Data: outputs}
+	return stepResult
+}
+
+// resolveCallPath resolves a call: or include: path relative to the directory of the
+// calling file, as file actions resolve their paths, see common.ResolvePath
+func resolveCallPath(callerFile, call string) string {
+	if callerFile == "" {
+		return call
+	}
+	return common.ResolvePath(filepath.Dir(callerFile), call)
+}
+
+// loadCalledTestCase loads the single test case of a called file
+func loadCalledTestCase(path string) (*types.TestCase, error) {
+	testCases, err := LoadTestCases(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(testCases) != 1 {
+		return nil, fmt.Errorf("%s defines %d test cases; a called file must define exactly one", path, len(testCases))
+	}
+	return testCases[0], nil
+}
+
+// missingCallInputs returns the declared inputs of a called test case that were not passed, sorted
+func missingCallInputs(testCase *types.TestCase, inputs map[string]any) []string {
+	var missing []string
+	for _, name := range testCase.Inputs {
+		if _, ok := inputs[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
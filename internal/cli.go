@@ -1,14 +1,22 @@
 package internal
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/JianLoong/robogo/internal/actions"
 	"github.com/JianLoong/robogo/internal/common"
+	"github.com/JianLoong/robogo/internal/execution"
 	"github.com/JianLoong/robogo/internal/types"
 )
 
@@ -21,8 +29,48 @@ const (
 
 // ParsedArgs holds parsed command line arguments
 type ParsedArgs struct {
-	envFile    string   // --env flag value
-	positional []string // non-flag arguments
+	envFile       string   // --env flag value
+	workdir       string   // --workdir flag value: run directory that command line paths and ${run.dir} resolve against
+	sentryDSN     string   // --sentry-dsn flag value
+	dumpDir       string   // --debug-dump flag value
+	dumpAlways    bool     // --debug-dump=always mode: dump even when the test passes
+	pluginsFile   string   // --plugins flag value
+	hooksFile     string   // --hooks flag value: YAML file of pre_run and post_run commands
+	globalSetup   string   // --global-setup flag value: test case run before the first test case, torn down after the last
+	secretPolicy  string   // --secret-policy flag value: off, mask, warn or fail for secret-looking values in variables
+	secretRegexps []string // --secret-pattern flag values: regexps of values treated as secrets; repeatable
+	noCache       bool     // --no-cache flag: steps with a cache option always run their action
+	errorReport   string   // --error-report flag value
+	reportSamples int      // --error-report-samples flag value
+	check         bool     // --check flag: fmt reports unformatted files instead of writing
+	toStdout      bool     // --stdout flag: fmt prints the formatted file instead of writing
+	strict        bool     // --strict flag: treat ambiguous step names as errors
+	timing        bool     // --timing flag: print per-action timing after the summary
+	updateGolden  bool     // --update-golden flag: golden steps rewrite their files instead of comparing
+	pattern       string   // --pattern flag value: which files a directory run picks up
+	filters       []string // --filter flag values: test case name globs or /regexps/; repeatable
+	listOnly      bool     // --list flag: print the test cases that would run without running them
+	report        string   // --report flag value: JSON file with the outcome of every test case
+	history       string   // --history flag value: JSON file of past runs, compared with each new run
+	threshold     float64  // --threshold flag value: duration ratio report diff counts as slower
+	format        string   // --format flag value: report diff output, text or json
+	profileSteps  string   // --profile-steps flag value: trace file with the start and duration of every step
+	iterations    int      // --iterations flag value: how many times bench runs the test case
+	concurrency   int      // --concurrency flag value: bench workers running iterations at once
+	failureLimit  float64  // --max-failure-rate flag value: bench failure percentage allowed before exiting non-zero
+	samples       string   // --samples flag value: CSV file with one row per bench iteration
+	environment   string   // --environment flag value: active environment for only_on/not_on
+	verbosity     string   // --verbosity flag value: quiet, normal, verbose or debug
+	maxDataBytes  int      // --max-data-bytes flag value, 0 for the default
+	artifactsDir  string   // --artifacts flag value: directory that gets a run directory of step artifacts
+	artifactLimit int      // --max-artifact-bytes flag value, 0 for the default
+	debugScript   string   // --debug-script flag value: file of debug commands, for non-interactive sessions
+	compact       bool     // --compact flag: watch prints one line per test case
+	force         bool     // --force flag: init overwrites existing files
+	fix           bool     // --fix flag: validate applies auto-fixable suggestions to the files
+	dryRun        bool     // --dry-run flag: validate --fix prints the changes without writing them
+	fixConfidence float64  // --fix-confidence flag value: lowest confidence validate --fix applies
+	positional    []string // non-flag arguments
 }
 
 // Table formatting and truncation widths for printTestSummary
@@ -39,11 +87,28 @@ const (
 	truncCategory = 9  // Truncate category to this length before adding '...'
 )
 
+// slowestStepsShown is how many steps the suite summary lists as slowest
+const slowestStepsShown = 10
+
+// Table formatting widths for printActionMetrics
+const (
+	colActionWidth   = 20 // Width for action column
+	colCountWidth    = 5  // Width for execution count column
+	colShareWidth    = 6  // Width for share of total action time column
+	colFailRateWidth = 9  // Width for failure rate column
+)
+
 // parseArgs parses command line arguments, handling flags and positional arguments
 func parseArgs() ParsedArgs {
 	args := ParsedArgs{
-		envFile:    "",
-		positional: []string{},
+		envFile:       "",
+		reportSamples: defaultErrorReportSamples,
+		threshold:     defaultSlowerThreshold,
+		iterations:    defaultBenchIterations,
+		concurrency:   defaultBenchConcurrency,
+		format:        "text",
+		fixConfidence: defaultFixConfidence,
+		positional:    []string{},
 	}
 
 	for i := 1; i < len(os.Args); i++ {
@@ -54,6 +119,184 @@ func parseArgs() ParsedArgs {
 		} else if arg == "--env" && i+1 < len(os.Args) {
 			i++ // Move to next argument
 			args.envFile = os.Args[i]
+		} else if strings.HasPrefix(arg, "--sentry-dsn=") {
+			args.sentryDSN = arg[13:] // Remove "--sentry-dsn=" prefix
+		} else if arg == "--sentry-dsn" && i+1 < len(os.Args) {
+			i++
+			args.sentryDSN = os.Args[i]
+		} else if arg == "--debug-dump=always" {
+			// --debug-dump=always <dir> also dumps test cases that pass; --debug-dump always
+			// is a directory named always
+			if i+1 >= len(os.Args) || strings.HasPrefix(os.Args[i+1], "-") {
+				fmt.Println("Error: --debug-dump=always needs a directory, e.g. --debug-dump=always ./debug")
+				os.Exit(ExitUsageError)
+			}
+			i++
+			args.dumpDir = os.Args[i]
+			args.dumpAlways = true
+		} else if strings.HasPrefix(arg, "--debug-dump=") {
+			args.dumpDir = arg[13:] // Remove "--debug-dump=" prefix
+		} else if arg == "--debug-dump" && i+1 < len(os.Args) {
+			i++
+			args.dumpDir = os.Args[i]
+		} else if strings.HasPrefix(arg, "--plugins=") {
+			args.pluginsFile = arg[10:] // Remove "--plugins=" prefix
+		} else if arg == "--plugins" && i+1 < len(os.Args) {
+			i++
+			args.pluginsFile = os.Args[i]
+		} else if strings.HasPrefix(arg, "--workdir=") {
+			args.workdir = arg[10:] // Remove "--workdir=" prefix
+		} else if arg == "--workdir" && i+1 < len(os.Args) {
+			i++
+			args.workdir = os.Args[i]
+		} else if strings.HasPrefix(arg, "--hooks=") {
+			args.hooksFile = arg[8:] // Remove "--hooks=" prefix
+		} else if arg == "--hooks" && i+1 < len(os.Args) {
+			i++
+			args.hooksFile = os.Args[i]
+		} else if strings.HasPrefix(arg, "--global-setup=") {
+			args.globalSetup = arg[15:] // Remove "--global-setup=" prefix
+		} else if arg == "--global-setup" && i+1 < len(os.Args) {
+			i++
+			args.globalSetup = os.Args[i]
+		} else if strings.HasPrefix(arg, "--secret-policy=") {
+			args.secretPolicy = arg[16:] // Remove "--secret-policy=" prefix
+		} else if arg == "--secret-policy" && i+1 < len(os.Args) {
+			i++
+			args.secretPolicy = os.Args[i]
+		} else if strings.HasPrefix(arg, "--secret-pattern=") {
+			args.secretRegexps = append(args.secretRegexps, arg[17:]) // Remove "--secret-pattern=" prefix
+		} else if arg == "--secret-pattern" && i+1 < len(os.Args) {
+			i++
+			args.secretRegexps = append(args.secretRegexps, os.Args[i])
+		} else if strings.HasPrefix(arg, "--error-report=") {
+			args.errorReport = arg[15:] // Remove "--error-report=" prefix
+		} else if arg == "--error-report" && i+1 < len(os.Args) {
+			i++
+			args.errorReport = os.Args[i]
+		} else if strings.HasPrefix(arg, "--error-report-samples=") {
+			args.reportSamples = parseSampleCount(arg[23:]) // Remove "--error-report-samples=" prefix
+		} else if arg == "--error-report-samples" && i+1 < len(os.Args) {
+			i++
+			args.reportSamples = parseSampleCount(os.Args[i])
+		} else if arg == "--check" {
+			args.check = true
+		} else if arg == "--stdout" {
+			args.toStdout = true
+		} else if arg == "--strict" {
+			args.strict = true
+		} else if arg == "--timing" {
+			args.timing = true
+		} else if arg == "--no-cache" {
+			args.noCache = true
+		} else if arg == "--compact" {
+			args.compact = true
+		} else if arg == "--force" {
+			args.force = true
+		} else if arg == "--fix" {
+			args.fix = true
+		} else if arg == "--dry-run" {
+			args.dryRun = true
+		} else if arg == "--update-golden" {
+			args.updateGolden = true
+		} else if arg == "-q" || arg == "--quiet" {
+			args.verbosity = "quiet"
+		} else if arg == "-v" {
+			args.verbosity = "verbose"
+		} else if arg == "-vv" {
+			args.verbosity = "debug"
+		} else if strings.HasPrefix(arg, "--filter=") {
+			args.filters = append(args.filters, arg[9:]) // Remove "--filter=" prefix
+		} else if arg == "--filter" && i+1 < len(os.Args) {
+			i++
+			args.filters = append(args.filters, os.Args[i])
+		} else if arg == "--list" {
+			args.listOnly = true
+		} else if strings.HasPrefix(arg, "--report=") {
+			args.report = arg[9:] // Remove "--report=" prefix
+		} else if arg == "--report" && i+1 < len(os.Args) {
+			i++
+			args.report = os.Args[i]
+		} else if strings.HasPrefix(arg, "--history=") {
+			args.history = arg[10:] // Remove "--history=" prefix
+		} else if arg == "--history" && i+1 < len(os.Args) {
+			i++
+			args.history = os.Args[i]
+		} else if strings.HasPrefix(arg, "--profile-steps=") {
+			args.profileSteps = arg[16:] // Remove "--profile-steps=" prefix
+		} else if arg == "--profile-steps" && i+1 < len(os.Args) {
+			i++
+			args.profileSteps = os.Args[i]
+		} else if strings.HasPrefix(arg, "--iterations=") {
+			args.iterations = parsePositiveInt("--iterations", arg[13:]) // Remove "--iterations=" prefix
+		} else if arg == "--iterations" && i+1 < len(os.Args) {
+			i++
+			args.iterations = parsePositiveInt("--iterations", os.Args[i])
+		} else if strings.HasPrefix(arg, "--concurrency=") {
+			args.concurrency = parsePositiveInt("--concurrency", arg[14:]) // Remove "--concurrency=" prefix
+		} else if arg == "--concurrency" && i+1 < len(os.Args) {
+			i++
+			args.concurrency = parsePositiveInt("--concurrency", os.Args[i])
+		} else if strings.HasPrefix(arg, "--max-failure-rate=") {
+			args.failureLimit = parsePercentage("--max-failure-rate", arg[19:]) // Remove "--max-failure-rate=" prefix
+		} else if arg == "--max-failure-rate" && i+1 < len(os.Args) {
+			i++
+			args.failureLimit = parsePercentage("--max-failure-rate", os.Args[i])
+		} else if strings.HasPrefix(arg, "--samples=") {
+			args.samples = arg[10:] // Remove "--samples=" prefix
+		} else if arg == "--samples" && i+1 < len(os.Args) {
+			i++
+			args.samples = os.Args[i]
+		} else if strings.HasPrefix(arg, "--threshold=") {
+			args.threshold = parseThreshold(arg[12:]) // Remove "--threshold=" prefix
+		} else if arg == "--threshold" && i+1 < len(os.Args) {
+			i++
+			args.threshold = parseThreshold(os.Args[i])
+		} else if strings.HasPrefix(arg, "--fix-confidence=") {
+			args.fixConfidence = parseConfidence(arg[17:]) // Remove "--fix-confidence=" prefix
+		} else if arg == "--fix-confidence" && i+1 < len(os.Args) {
+			i++
+			args.fixConfidence = parseConfidence(os.Args[i])
+		} else if strings.HasPrefix(arg, "--format=") {
+			args.format = arg[9:] // Remove "--format=" prefix
+		} else if arg == "--format" && i+1 < len(os.Args) {
+			i++
+			args.format = os.Args[i]
+		} else if strings.HasPrefix(arg, "--environment=") {
+			args.environment = arg[14:] // Remove "--environment=" prefix
+		} else if arg == "--environment" && i+1 < len(os.Args) {
+			i++
+			args.environment = os.Args[i]
+		} else if strings.HasPrefix(arg, "--verbosity=") {
+			args.verbosity = arg[12:] // Remove "--verbosity=" prefix
+		} else if arg == "--verbosity" && i+1 < len(os.Args) {
+			i++
+			args.verbosity = os.Args[i]
+		} else if strings.HasPrefix(arg, "--max-data-bytes=") {
+			args.maxDataBytes = parsePositiveInt("--max-data-bytes", arg[17:]) // Remove "--max-data-bytes=" prefix
+		} else if arg == "--max-data-bytes" && i+1 < len(os.Args) {
+			i++
+			args.maxDataBytes = parsePositiveInt("--max-data-bytes", os.Args[i])
+		} else if strings.HasPrefix(arg, "--artifacts=") {
+			args.artifactsDir = arg[12:] // Remove "--artifacts=" prefix
+		} else if arg == "--artifacts" && i+1 < len(os.Args) {
+			i++
+			args.artifactsDir = os.Args[i]
+		} else if strings.HasPrefix(arg, "--max-artifact-bytes=") {
+			args.artifactLimit = parsePositiveInt("--max-artifact-bytes", arg[21:]) // Remove "--max-artifact-bytes=" prefix
+		} else if arg == "--max-artifact-bytes" && i+1 < len(os.Args) {
+			i++
+			args.artifactLimit = parsePositiveInt("--max-artifact-bytes", os.Args[i])
+		} else if strings.HasPrefix(arg, "--debug-script=") {
+			args.debugScript = arg[15:] // Remove "--debug-script=" prefix
+		} else if arg == "--debug-script" && i+1 < len(os.Args) {
+			i++
+			args.debugScript = os.Args[i]
+		} else if strings.HasPrefix(arg, "--pattern=") {
+			args.pattern = arg[10:] // Remove "--pattern=" prefix
+		} else if arg == "--pattern" && i+1 < len(os.Args) {
+			i++
+			args.pattern = os.Args[i]
 		} else if !strings.HasPrefix(arg, "-") {
 			args.positional = append(args.positional, arg)
 		} else {
@@ -66,11 +309,69 @@ func parseArgs() ParsedArgs {
 	return args
 }
 
+// parseSampleCount parses the --error-report-samples value, exiting on invalid input
+func parseSampleCount(value string) int {
+	count, err := strconv.Atoi(value)
+	if err != nil || count < 0 {
+		fmt.Printf("Error: --error-report-samples must be a non-negative integer, got '%s'\n", value)
+		os.Exit(ExitUsageError)
+	}
+	return count
+}
+
+// parsePositiveInt parses a flag value that must be at least 1, exiting on invalid input
+func parsePositiveInt(flag, value string) int {
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 1 {
+		fmt.Printf("Error: %s must be a positive integer, got '%s'\n", flag, value)
+		os.Exit(ExitUsageError)
+	}
+	return parsed
+}
+
+// parsePercentage parses a flag value between 0 and 100, exiting on invalid input
+func parsePercentage(flag, value string) float64 {
+	parsed, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+	if err != nil || parsed < 0 || parsed > 100 {
+		fmt.Printf("Error: %s must be a percentage between 0 and 100, got '%s'\n", flag, value)
+		os.Exit(ExitUsageError)
+	}
+	return parsed
+}
+
+// parseThreshold parses the --threshold value, exiting on invalid input
+func parseThreshold(value string) float64 {
+	threshold, err := strconv.ParseFloat(value, 64)
+	if err != nil || threshold <= 1 {
+		fmt.Printf("Error: --threshold must be a number greater than 1, got '%s'\n", value)
+		os.Exit(ExitUsageError)
+	}
+	return threshold
+}
+
+// parseConfidence parses the --fix-confidence value, exiting on invalid input
+func parseConfidence(value string) float64 {
+	confidence, err := strconv.ParseFloat(value, 64)
+	if err != nil || confidence < 0 || confidence > 1 {
+		fmt.Printf("Error: --fix-confidence must be a number between 0 and 1, got '%s'\n", value)
+		os.Exit(ExitUsageError)
+	}
+	return confidence
+}
+
 // SimpleCLI - direct, no-abstraction CLI
 func RunCLI() {
 	// Parse command line arguments first to check for --env flag
 	args := parseArgs()
 
+	// Paths on the command line, including --env and the default .env, resolve against the run directory
+	if args.workdir != "" {
+		if err := os.Chdir(args.workdir); err != nil {
+			fmt.Printf("Error: invalid --workdir: %v\n", err)
+			os.Exit(ExitUsageError)
+		}
+	}
+
 	// Load .env file - use custom file if specified, otherwise try default
 	if args.envFile != "" {
 		if err := common.LoadDotEnv(args.envFile); err != nil {
@@ -84,14 +385,19 @@ func RunCLI() {
 		}
 	}
 
-	// Setup signal handling for graceful shutdown
-	c := make(chan os.Signal, 1)
+	// Setup signal handling for graceful shutdown: the first signal cancels the run
+	// so teardown still executes, a second one exits immediately
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := make(chan os.Signal, 2)
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 	go func() {
 		<-c
-		fmt.Println("\nShutting down gracefully...")
-		// No cleanup needed - connections close automatically
-		os.Exit(ExitSuccess)
+		fmt.Println("\nShutting down gracefully after the current step (press Ctrl+C again to force exit)...")
+		cancel()
+		<-c
+		fmt.Println("\nForced exit")
+		os.Exit(ExitTestFailure)
 	}()
 
 	if len(args.positional) < 1 {
@@ -99,19 +405,129 @@ func RunCLI() {
 		os.Exit(ExitUsageError)
 	}
 
+	// Plugins may also be configured through the environment, e.g. in .env
+	if args.pluginsFile == "" {
+		args.pluginsFile = os.Getenv(actions.PluginsEnvVar)
+	}
+	if args.hooksFile == "" {
+		args.hooksFile = os.Getenv(HooksEnvVar)
+	}
+	if args.globalSetup == "" {
+		args.globalSetup = os.Getenv(GlobalSetupEnvVar)
+	}
+	if args.secretPolicy == "" {
+		args.secretPolicy = os.Getenv(common.SecretPolicyEnvVar)
+	}
+	if err := configureSecretGuard(args.secretPolicy, args.secretRegexps); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(ExitUsageError)
+	}
+	if args.noCache {
+		execution.DisableResultCache()
+	}
+
+	// The active environment may also be set in the environment, e.g. in .env
+	if args.environment == "" {
+		args.environment = os.Getenv(EnvironmentEnvVar)
+	}
+
+	if args.verbosity != "" {
+		if _, err := execution.ParseVerbosity(args.verbosity); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(ExitUsageError)
+		}
+	}
+
+	// Golden steps read the setting from the environment, so CI can also set it directly
+	if args.updateGolden {
+		os.Setenv(actions.UpdateGoldenEnvVar, "1")
+	}
+
 	command := args.positional[0]
 
 	switch command {
 	case "run":
 		if len(args.positional) < 2 {
-			fmt.Println("Error: run command requires a test file")
+			fmt.Println("Error: run command requires a test file, directory or glob")
+			printUsage()
+			os.Exit(ExitUsageError)
+		}
+		runTest(ctx, args.positional[1], args)
+
+	case "validate":
+		if len(args.positional) < 2 {
+			fmt.Println("Error: validate command requires a test file, directory or glob")
 			printUsage()
 			os.Exit(ExitUsageError)
 		}
-		runTest(args.positional[1])
+		runValidate(args.positional[1], args)
 
 	case "list":
-		listActions()
+		listActions(newActionRegistry(args), "")
+
+	case "fmt":
+		if len(args.positional) < 2 {
+			fmt.Println("Error: fmt command requires at least one test file")
+			printUsage()
+			os.Exit(ExitUsageError)
+		}
+		formatTestFiles(args.positional[1:], args)
+
+	case "actions":
+		runActionsCommand(newActionRegistry(args), args.positional[1:])
+
+	case "schema":
+		printTestSchema(newActionRegistry(args))
+
+	case "bench":
+		if len(args.positional) < 2 {
+			fmt.Println("Error: bench command requires a test file")
+			printUsage()
+			os.Exit(ExitUsageError)
+		}
+		runBenchmark(ctx, args.positional[1], args)
+
+	case "debug":
+		if len(args.positional) < 2 {
+			fmt.Println("Error: debug command requires a test file")
+			printUsage()
+			os.Exit(ExitUsageError)
+		}
+		runDebugger(ctx, args.positional[1], args)
+
+	case "watch":
+		if len(args.positional) < 2 {
+			fmt.Println("Error: watch command requires a test file, directory or glob")
+			printUsage()
+			os.Exit(ExitUsageError)
+		}
+		runWatch(ctx, args.positional[1], args)
+
+	case "init":
+		dir := "."
+		if len(args.positional) > 1 {
+			dir = args.positional[1]
+		}
+		runInit(dir, args)
+
+	case "completion":
+		if len(args.positional) < 2 {
+			fmt.Println("Error: completion command requires a shell: bash, zsh or fish")
+			printUsage()
+			os.Exit(ExitUsageError)
+		}
+		printCompletion(args.positional[1])
+
+	case "__complete":
+		printCompletionCandidates(args, args.positional[1:])
+
+	case "report":
+		if len(args.positional) != 4 || args.positional[1] != "diff" {
+			fmt.Println("Error: report command expects: report diff <old.json> <new.json>")
+			printUsage()
+			os.Exit(ExitUsageError)
+		}
+		diffReports(args.positional[2], args.positional[3], args)
 
 	case "version":
 		fmt.Println("Robogo Simple v1.0.0")
@@ -123,28 +539,685 @@ func RunCLI() {
 	}
 }
 
-func runTest(filename string) {
-	runner := NewTestRunner()
-	result, err := runner.RunTest(filename)
+// plannedTestCase is a test case to run, with the file it was loaded from.
+// loadErr is set instead of testCase when the file could not be parsed.
+type plannedTestCase struct {
+	filename string
+	testCase *types.TestCase
+	loadErr  error
+	skip     *types.SkipInfo // excluded by --filter or the environment; reported as skipped
+}
 
+// runTest runs every test case in a file, directory or glob. Several test cases run as
+// a suite named after the target, each with its own variables. In a directory run, a
+// file that does not parse is reported as an errored test case and the others still run.
+func runTest(ctx context.Context, target string, args ParsedArgs) {
+	files, err := DiscoverTestFiles(target, args.pattern)
 	if err != nil {
-		fmt.Printf("\nERROR: Test execution failed: %s\n", err.Error())
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(ExitUsageError)
+	}
+
+	var planned []plannedTestCase
+	for _, filename := range files {
+		testCases, err := LoadTestCases(filename)
+		if err != nil {
+			if len(files) == 1 {
+				fmt.Printf("\nERROR: Test execution failed: failed to parse test file: %s\n", err.Error())
+				os.Exit(ExitTestFailure)
+			}
+			planned = append(planned, plannedTestCase{filename: filename, loadErr: err})
+			continue
+		}
+		for _, testCase := range testCases {
+			planned = append(planned, plannedTestCase{filename: filename, testCase: testCase})
+		}
+	}
+
+	filter, err := NewTestCaseFilter(args.filters)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(ExitUsageError)
+	}
+	matched := 0
+	for i := range planned {
+		if planned[i].testCase == nil {
+			continue
+		}
+		if !filter.Matches(planned[i].testCase.Name) {
+			planned[i].skip = types.NewSkipInfo(types.SkipCategoryFiltered, "does not match --filter")
+			continue
+		}
+		matched++
+		if reason := environmentSkipReason(planned[i].testCase, args.environment); reason != "" {
+			planned[i].skip = types.NewSkipInfo(types.SkipCategoryEnvironment, reason)
+		}
+	}
+	if len(args.filters) > 0 && matched == 0 {
+		fmt.Printf("Error: no test cases match --filter %s\n", strings.Join(args.filters, ", "))
+		os.Exit(ExitUsageError)
+	}
+	warnUnknownEnvironments(planned, args.environment)
+
+	if duplicates := duplicateTestCaseNames(planned); len(duplicates) > 0 {
+		if args.strict {
+			fmt.Printf("Error: duplicate test case names: %s\n", strings.Join(duplicates, "; "))
+			os.Exit(ExitUsageError)
+		}
+		for _, duplicate := range duplicates {
+			fmt.Printf("[WARN] %s; summaries and --filter tell test cases apart by name\n", duplicate)
+		}
+	}
+
+	if args.listOnly {
+		listPlannedTestCases(planned)
+		return
+	}
+
+	var hooks *runHooks
+	if args.hooksFile != "" {
+		if hooks, err = loadRunHooks(args.hooksFile); err != nil {
+			fmt.Printf("Error: hooks configuration: %v\n", err)
+			os.Exit(ExitUsageError)
+		}
+	}
+	var global *globalSetup
+	if args.globalSetup != "" {
+		if global, err = loadGlobalSetup(args.globalSetup); err != nil {
+			fmt.Printf("Error: global setup: %v\n", err)
+			os.Exit(ExitUsageError)
+		}
+	}
+
+	// Aggregate errors and failures across test cases into one report file
+	var collector *ErrorReportCollector
+	if args.errorReport != "" {
+		collector = NewErrorReportCollector(args.reportSamples)
+	}
+
+	// Export failures to the error tracker (best-effort, never affects exit code)
+	var exporter *SentryExporter
+	if args.sentryDSN != "" {
+		if exporter, err = NewSentryExporter(args.sentryDSN); err != nil {
+			fmt.Printf("[WARN] Sentry export disabled: %v\n", err)
+		}
+	}
+
+	// Artifacts of every test case go into one directory per run
+	var artifacts *artifactStore
+	if args.artifactsDir != "" {
+		artifacts = newArtifactStore(filepath.Join(args.artifactsDir, time.Now().Format("20060102-150405")), args.artifactLimit)
+	}
+
+	// pre_run hooks and then the global setup run once before the first test case, and
+	// their outputs become variables of every test case; when either fails, the test cases
+	// are skipped like after a failed setup
+	start := time.Now()
+	var runVariables map[string]any
+	var hookEnv map[string]string
+	var setupErr error
+	if hooks != nil {
+		hookEnv = hookRunEnvironment(target, args.environment)
+		runVariables, setupErr = runPreRunHooks(ctx, hooks.PreRun, hookEnv)
+	}
+	if global != nil && setupErr == nil {
+		runVariables, setupErr = global.start(ctx, args, runVariables)
+	}
+	if setupErr != nil {
+		fmt.Printf("\n[SETUP] Test cases skipped: %v\n", setupErr)
+		for i := range planned {
+			if planned[i].testCase != nil && planned[i].skip == nil {
+				planned[i].skip = types.NewSkipInfo(types.SkipCategorySetupFailure, setupErr.Error())
+			}
+		}
+	}
+
+	results := make([]*types.TestResult, 0, len(planned))
+	anyFailed := false
+	for _, next := range planned {
+		if ctx.Err() != nil {
+			break
+		}
+		if next.loadErr != nil {
+			fmt.Printf("\n[ERROR] Failed to parse test file: %s\n", next.loadErr.Error())
+			results = append(results, parseFailureResult(next.filename, next.loadErr))
+			anyFailed = true
+			continue
+		}
+		if next.skip != nil {
+			if next.skip.Category == types.SkipCategoryEnvironment {
+				fmt.Printf("\n[SKIPPED] %s: %s\n", next.testCase.Name, next.skip.Reason)
+			}
+			results = append(results, &types.TestResult{
+				Name:     next.testCase.Name,
+				Status:   "SKIPPED",
+				SkipInfo: next.skip,
+			})
+			continue
+		}
+		result, testFailed := runTestCase(ctx, next.filename, next.testCase, args, runVariables, collector, exporter, artifacts)
+		results = append(results, result)
+		anyFailed = anyFailed || testFailed
+	}
+	// Connection pools of sql steps are shared by the test cases of a run
+	actions.CloseSQLConnections()
+
+	// The global teardown and then post_run hooks always run, also after an interrupt or a
+	// failed setup, like teardown
+	if global != nil {
+		global.finish()
+	}
+	if hooks != nil {
+		env := hookResultEnvironment(hookEnv, results, anyFailed, time.Since(start))
+		runPostRunHooks(context.WithoutCancel(ctx), hooks.PostRun, env)
+	}
+
+	if len(planned) > 1 {
+		printSuiteSummary(target, results, len(planned))
+	}
+
+	if artifacts != nil {
+		printArtifacts(artifacts.dir, results)
+	}
+
+	if args.profileSteps != "" {
+		if err := writeStepProfile(args.profileSteps, results); err != nil {
+			fmt.Printf("[WARN] Failed to write step profile: %v\n", err)
+		} else {
+			fmt.Printf("\nStep profile written to: %s\n", args.profileSteps)
+		}
+	}
+
+	if args.report != "" {
+		if err := writeRunReport(args.report, target, args.environment, planned, results); err != nil {
+			fmt.Printf("[WARN] Failed to write report: %v\n", err)
+		} else {
+			fmt.Printf("\nReport written to: %s\n", args.report)
+		}
+	}
+
+	if args.history != "" {
+		delta, err := recordRunHistory(args.history, target, args.environment, planned, results, args.threshold)
+		if err != nil {
+			fmt.Printf("[WARN] Failed to update run history: %v\n", err)
+		} else if delta != nil {
+			printRunDelta(delta, args.threshold)
+		} else {
+			fmt.Printf("\nFirst run of %s recorded in: %s\n", target, args.history)
+		}
+	}
+
+	if collector != nil {
+		if err := collector.Write(args.errorReport); err != nil {
+			fmt.Printf("[WARN] Failed to write error report: %v\n", err)
+		} else {
+			fmt.Printf("\nError report written to: %s\n", args.errorReport)
+		}
+	}
+
+	if anyFailed {
 		os.Exit(ExitTestFailure)
 	}
+}
+
+// runTestCase runs one test case with a fresh runner and reports its result.
+// Returns the result and whether the test case failed.
+func runTestCase(ctx context.Context, filename string, testCase *types.TestCase, args ParsedArgs, runVariables map[string]any, collector *ErrorReportCollector, exporter *SentryExporter, artifacts *artifactStore) (*types.TestResult, bool) {
+	runner := newRunRunner(args, runVariables)
+	result, err := runner.RunTest(ctx, filename, testCase)
+
+	// A test case that cannot start, e.g. with an invalid typed variable, errors on its
+	// own; the other test cases, global teardown and reports still run
+	if err != nil {
+		fmt.Printf("\nERROR: Test execution failed: %s\n", err.Error())
+		result = prepareFailureResult(filename, testCase, err)
+	}
 
 	printTestSummary(result)
+	if args.timing {
+		printActionMetrics("Action Timing", result.ActionMetrics)
+	}
+
+	testFailed := result.Status == "FAIL" || result.Status == "FAILED" || result.Status == "failed" || result.Status == "error" || result.Status == "ERROR"
+
+	// Artifacts are written once the test case ends, so they hold its final state
+	if artifacts != nil {
+		artifacts.saveResult(result, runner.variables)
+	} else {
+		skipArtifacts(result)
+	}
+
+	// Write post-mortem dump of variables and step results, with the artifacts when there are any
+	if args.dumpDir != "" && (testFailed || args.dumpAlways) {
+		dumps := artifacts
+		if dumps == nil {
+			dumps = newArtifactStore(args.dumpDir, args.artifactLimit)
+		}
+		if path, err := writeDebugDump(dumps, result, runner.variables, runner.outputSecrets()); err != nil {
+			fmt.Printf("[WARN] Failed to write debug dump: %v\n", err)
+		} else {
+			fmt.Printf("\nDebug dump written to: %s\n", path)
+		}
+	}
+
+	if collector != nil {
+		collector.Collect(result)
+	}
+	if exporter != nil {
+		exporter.ExportFailures(filename, result)
+	}
+
+	return result, testFailed
+}
+
+// newRunRunner returns a runner configured by the run's flags, with the run variables set
+func newRunRunner(args ParsedArgs, runVariables map[string]any) *TestRunner {
+	runner := NewTestRunner()
+	runner.SetStrict(args.strict)
+	runner.SetMaxDataBytes(args.maxDataBytes)
+	runner.SetRunVariables(runVariables)
+	if args.verbosity != "" {
+		verbosity, _ := execution.ParseVerbosity(args.verbosity) // validated in runCommand
+		runner.SetVerbosity(verbosity)
+	}
+	if args.pluginsFile != "" {
+		if err := runner.LoadPlugins(args.pluginsFile); err != nil {
+			fmt.Printf("Error: plugin configuration: %v\n", err)
+			os.Exit(ExitUsageError)
+		}
+	}
+	return runner
+}
+
+// listPlannedTestCases prints the test cases a run would execute, for --list
+func listPlannedTestCases(planned []plannedTestCase) {
+	fmt.Println("Test cases that would run:")
+	for _, next := range planned {
+		switch {
+		case next.loadErr != nil:
+			fmt.Printf("  [parse error] %v\n", next.loadErr)
+		case next.skip == nil:
+			fmt.Printf("  %s (%s)\n", next.testCase.Name, next.filename)
+		}
+	}
+}
+
+// duplicateTestCaseNames describes test case names used more than once in a run
+func duplicateTestCaseNames(planned []plannedTestCase) []string {
+	var names []string
+	files := make(map[string][]string)
+	for _, next := range planned {
+		if next.testCase == nil {
+			continue
+		}
+		name := next.testCase.Name
+		if _, seen := files[name]; !seen {
+			names = append(names, name)
+		}
+		files[name] = append(files[name], next.filename)
+	}
+
+	var duplicates []string
+	for _, name := range names {
+		if len(files[name]) > 1 {
+			duplicates = append(duplicates, fmt.Sprintf("test case name '%s' is used in %s", name, strings.Join(files[name], ", ")))
+		}
+	}
+	return duplicates
+}
+
+// parseFailureResult records a test file that could not be loaded as an errored test case
+func parseFailureResult(filename string, err error) *types.TestResult {
+	failure := types.NewErrorBuilder(types.ErrorCategoryValidation, "TEST_FILE_PARSE_ERROR").
+		WithTemplate("failed to parse test file: %s").
+		WithContext("file", filename).
+		Build(err.Error())
+	return &types.TestResult{
+		Name:      filename,
+		Status:    "ERROR",
+		ErrorInfo: failure.ErrorInfo,
+	}
+}
+
+// prepareFailureResult records a test case that failed before its first step as an
+// errored test case
+func prepareFailureResult(filename string, testCase *types.TestCase, err error) *types.TestResult {
+	failure := types.NewErrorBuilder(types.ErrorCategoryValidation, "TEST_CASE_PREPARE_ERROR").
+		WithTemplate("test case could not start: %s").
+		WithContext("file", filename).
+		Build(err.Error())
+	return &types.TestResult{
+		Name:      testCase.Name,
+		Status:    "ERROR",
+		ErrorInfo: failure.ErrorInfo,
+	}
+}
+
+// printSuiteSummary prints one line per test case run from a file, directory or glob
+func printSuiteSummary(target string, results []*types.TestResult, total int) {
+	suite := target
+	if !isGlobPattern(target) {
+		suite = filepath.Base(target)
+		if _, isTestFile := testFileLoaders[strings.ToLower(filepath.Ext(suite))]; isTestFile {
+			suite = strings.TrimSuffix(suite, filepath.Ext(suite))
+		}
+	}
+	passed, skipped := 0, 0
+	for _, result := range results {
+		switch result.Status {
+		case string(types.ActionStatusPassed):
+			passed++
+		case "SKIPPED":
+			skipped++
+		}
+	}
+
+	fmt.Printf("\nSuite Summary: %s\n", suite)
+	fmt.Printf("  Test cases: %d, passed: %d, not passed: %d", total, passed, len(results)-passed-skipped)
+	if skipped > 0 {
+		fmt.Printf(", skipped: %d", skipped)
+	}
+	if notRun := total - len(results); notRun > 0 {
+		fmt.Printf(", not run: %d", notRun)
+	}
+	fmt.Println()
+	for _, result := range results {
+		if result.SkipInfo != nil {
+			fmt.Printf("  %-8s %s (%s)\n", result.Status, result.Name, result.SkipInfo.Reason)
+			continue
+		}
+		fmt.Printf("  %-8s %s (%s)\n", result.Status, result.Name, result.Duration)
+	}
+	printAnnotations(aggregateAnnotations(results))
+
+	if timings := runTimings(results); len(timings) > 0 {
+		printSlowestSteps(execution.SlowestTimings(timings, slowestStepsShown))
+		printActionMetrics("Time by Action", execution.SummarizeTimings(timings))
+	}
+}
+
+// runTimings collects the action executions of every test case in a run
+func runTimings(results []*types.TestResult) []types.ActionTiming {
+	var timings []types.ActionTiming
+	for _, result := range results {
+		timings = append(timings, result.Timings...)
+	}
+	return timings
+}
 
-	if result.Status == "FAIL" || result.Status == "FAILED" || result.Status == "failed" || result.Status == "error" || result.Status == "ERROR" {
+// printSlowestSteps lists the slowest step executions with their test case
+func printSlowestSteps(timings []types.ActionTiming) {
+	fmt.Printf("\nSlowest Steps:\n")
+	for i, timing := range timings {
+		fmt.Printf("  %2d. %*s  %-*s %s (%s)\n", i+1,
+			colDurationWidth, timing.Duration.Round(time.Microsecond).String(),
+			colActionWidth, timing.Action, timing.Step, timing.TestCase)
+	}
+}
+
+// diffReports compares two --report files and exits non-zero when a test case regressed
+func diffReports(oldPath, newPath string, args ParsedArgs) {
+	oldReport, err := loadRunReport(oldPath)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(ExitUsageError)
+	}
+	newReport, err := loadRunReport(newPath)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(ExitUsageError)
+	}
+
+	diff := diffRunReports(oldReport, newReport, args.threshold)
+	switch args.format {
+	case "json":
+		data, err := json.MarshalIndent(diff, "", "  ")
+		if err != nil {
+			fmt.Printf("Error: failed to marshal report diff: %v\n", err)
+			os.Exit(ExitUsageError)
+		}
+		fmt.Println(string(data))
+	case "text":
+		printReportDiff(diff, args.threshold)
+	default:
+		fmt.Printf("Error: --format must be text or json, got '%s'\n", args.format)
+		os.Exit(ExitUsageError)
+	}
+
+	if diff.Regressions > 0 {
 		os.Exit(ExitTestFailure)
 	}
 }
 
-func listActions() {
-	fmt.Println("Available actions:")
+// formatTestFiles rewrites test files in canonical form, or reports them with --check
+func formatTestFiles(filenames []string, args ParsedArgs) {
+	unformatted := 0
+	for _, filename := range filenames {
+		formatted, err := FormatTestFile(filename)
+		if err != nil {
+			fmt.Printf("Error: %s: %v\n", filename, err)
+			os.Exit(ExitUsageError)
+		}
+
+		if args.toStdout {
+			fmt.Print(string(formatted))
+			continue
+		}
+
+		current, err := readTestFile(filename)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(ExitUsageError)
+		}
+		if bytes.Equal(current, formatted) {
+			continue
+		}
+
+		if args.check {
+			fmt.Printf("%s: needs formatting\n", filename)
+			unformatted++
+			continue
+		}
+
+		info, err := os.Stat(filename)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(ExitUsageError)
+		}
+		if err := os.WriteFile(filename, formatted, info.Mode().Perm()); err != nil {
+			fmt.Printf("Error: failed to write %s: %v\n", filename, err)
+			os.Exit(ExitUsageError)
+		}
+		fmt.Printf("Formatted %s\n", filename)
+	}
+
+	if unformatted > 0 {
+		os.Exit(ExitTestFailure)
+	}
+}
+
+// newActionRegistry creates the action registry for discovery commands, including plugins
+func newActionRegistry(args ParsedArgs) *actions.ActionRegistry {
 	registry := actions.NewActionRegistry()
-	for _, action := range registry.GetRegisteredActions() {
-		fmt.Printf("  - %s\n", action)
+	if args.pluginsFile != "" {
+		if err := registry.LoadPlugins(args.pluginsFile); err != nil {
+			fmt.Printf("Error: plugin configuration: %v\n", err)
+			os.Exit(ExitUsageError)
+		}
 	}
+	return registry
+}
+
+// printTestSchema prints the JSON Schema for test files, for editor completion and validation
+func printTestSchema(registry *actions.ActionRegistry) {
+	data, err := json.MarshalIndent(GenerateTestSchema(registry), "", "  ")
+	if err != nil {
+		fmt.Printf("Error: failed to generate schema: %v\n", err)
+		os.Exit(ExitUsageError)
+	}
+	fmt.Println(string(data))
+}
+
+// runActionsCommand handles "actions list [category]", "actions describe <action>" and "actions search <term>"
+func runActionsCommand(registry *actions.ActionRegistry, positional []string) {
+	subcommand := "list"
+	if len(positional) > 0 {
+		subcommand = positional[0]
+	}
+
+	switch subcommand {
+	case "list":
+		category := ""
+		if len(positional) > 1 {
+			category = positional[1]
+		}
+		listActions(registry, category)
+
+	case "describe":
+		if len(positional) < 2 {
+			fmt.Println("Error: actions describe requires an action name")
+			os.Exit(ExitUsageError)
+		}
+		describeAction(registry, positional[1])
+
+	case "search":
+		if len(positional) < 2 {
+			fmt.Println("Error: actions search requires a search term")
+			os.Exit(ExitUsageError)
+		}
+		searchActions(registry, positional[1])
+
+	default:
+		fmt.Printf("Error: unknown actions subcommand '%s'\n", subcommand)
+		printUsage()
+		os.Exit(ExitUsageError)
+	}
+}
+
+// listActions prints actions grouped by category, optionally limited to one category
+func listActions(registry *actions.ActionRegistry, category string) {
+
+	categories := registry.Categories()
+	if category != "" {
+		categories = []string{category}
+		if len(registry.ListActionsByCategory(category)) == 0 {
+			fmt.Printf("Error: unknown category '%s' (available: %s)\n", category, strings.Join(registry.Categories(), ", "))
+			os.Exit(ExitUsageError)
+		}
+	}
+
+	fmt.Println("Available actions:")
+	for _, cat := range categories {
+		fmt.Printf("\n%s:\n", cat)
+		for _, name := range registry.ListActionsByCategory(cat) {
+			meta, _ := registry.Describe(name)
+			fmt.Printf("  %-16s %s\n", name, meta.Description)
+		}
+	}
+}
+
+// describeAction prints the arguments, options and an example for one action
+func describeAction(registry *actions.ActionRegistry, name string) {
+	meta, exists := registry.Describe(name)
+	if !exists {
+		fmt.Printf("Error: unknown action '%s'\n", name)
+		if suggestions := registry.GetActionCompletions(name); len(suggestions) > 0 {
+			fmt.Printf("Did you mean: %s\n", strings.Join(suggestions, ", "))
+		}
+		os.Exit(ExitUsageError)
+	}
+
+	fmt.Printf("%s (%s)\n", meta.Name, meta.Category)
+	if meta.Description != "" {
+		fmt.Printf("  %s\n", meta.Description)
+	}
+
+	if len(meta.Args) > 0 {
+		fmt.Println("\nArguments:")
+		for i, arg := range meta.Args {
+			printArgSpec(i+1, arg, meta.Variadic && i == len(meta.Args)-1)
+		}
+	}
+
+	if len(meta.Options) > 0 {
+		fmt.Println("\nOptions:")
+		for _, option := range meta.Options {
+			printArgSpec(0, option, false)
+		}
+	}
+
+	if meta.Example != "" {
+		fmt.Println("\nExample:")
+		for _, line := range strings.Split(meta.Example, "\n") {
+			fmt.Printf("  %s\n", line)
+		}
+	}
+}
+
+// printArgSpec prints one argument (position > 0) or option (position 0)
+func printArgSpec(position int, spec actions.ArgSpec, variadic bool) {
+	label := spec.Name
+	if position > 0 {
+		label = fmt.Sprintf("%d. %s", position, spec.Name)
+	}
+	if variadic {
+		label += "..."
+	}
+
+	qualifier := spec.Type
+	if spec.Required {
+		qualifier += ", required"
+	}
+	if spec.Sensitive {
+		qualifier += ", sensitive"
+	}
+
+	line := fmt.Sprintf("  %-22s %s", label, qualifier)
+	if spec.Description != "" {
+		line += " - " + spec.Description
+	}
+	fmt.Println(line)
+}
+
+// searchActions prints actions matching a term, falling back to fuzzy name matches
+func searchActions(registry *actions.ActionRegistry, term string) {
+
+	matches := registry.SearchActions(term)
+	if len(matches) == 0 {
+		for _, name := range registry.GetActionCompletions(term) {
+			meta, _ := registry.Describe(name)
+			matches = append(matches, meta)
+		}
+	}
+
+	if len(matches) == 0 {
+		fmt.Printf("No actions match '%s'\n", term)
+		return
+	}
+	for _, meta := range matches {
+		fmt.Printf("  %-16s [%s] %s\n", meta.Name, meta.Category, meta.Description)
+	}
+}
+
+// configureSecretGuard sets the policy for secret-looking values in variables
+func configureSecretGuard(policyName string, patterns []string) error {
+	policy, err := common.ParseSecretPolicy(policyName)
+	if err != nil {
+		return err
+	}
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		regex, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid --secret-pattern '%s': %v", pattern, err)
+		}
+		compiled = append(compiled, regex)
+	}
+	if len(compiled) > 0 && policy == common.SecretPolicyOff {
+		return fmt.Errorf("--secret-pattern needs --secret-policy mask, warn or fail")
+	}
+	common.SetSecretGuard(policy, compiled)
+	return nil
 }
 
 func printUsage() {
@@ -152,17 +1225,77 @@ func printUsage() {
 	fmt.Println("  robogo [flags] <command> [args]")
 	fmt.Println("")
 	fmt.Println("Commands:")
-	fmt.Println("  run <test-file>               Run a single test")
+	fmt.Println("  run <file|dir|glob>           Run the test cases in a file, or every test file found")
+	fmt.Println("  fmt <test-file>...            Rewrite test files in canonical form")
+	fmt.Println("  validate <file|dir|glob>      Check test files without running them; exits non-zero on errors")
+	fmt.Println("  schema                        Print a JSON Schema for test files (for editors)")
 	fmt.Println("  list                          List available actions")
+	fmt.Println("  actions list [category]       List actions, optionally in one category")
+	fmt.Println("  actions describe <action>     Show arguments, options and an example")
+	fmt.Println("  actions search <term>         Find actions by name or description")
+	fmt.Println("  bench <file>                  Run one test case repeatedly and report throughput and latency")
+	fmt.Println("  debug <file>                  Run a test case one step at a time from an interactive prompt")
+	fmt.Println("  watch <file|dir|glob>         Run the test cases, then re-run the ones affected by each file change")
+	fmt.Println("  init [dir]                    Create starter settings, a secrets template and sample test cases")
+	fmt.Println("  completion <bash|zsh|fish>    Print a shell completion script")
+	fmt.Println("  report diff <old> <new>       Compare two --report files; exits non-zero on pass -> fail")
 	fmt.Println("  version                       Show version")
 	fmt.Println("")
 	fmt.Println("Flags:")
 	fmt.Println("  --env <file>                  Load environment variables from specified file")
 	fmt.Println("                                (default: .env in current directory)")
+	fmt.Println("  --workdir <dir>               Run in dir: paths on the command line and ${run.dir} resolve against it")
+	fmt.Println("  --plugins <file>              Load custom actions from a plugin manifest (or set ROBOGO_PLUGINS)")
+	fmt.Println("  --hooks <file>                run: commands to run before and after the test cases (or set ROBOGO_HOOKS)")
+	fmt.Println("  --global-setup <file>         run: test case whose setup and steps run before the first test case, its")
+	fmt.Println("                                teardown after the last, and whose outputs are variables of every test case")
+	fmt.Println("                                (or set ROBOGO_GLOBAL_SETUP)")
+	fmt.Println("  --secret-policy <policy>      run: off (default), mask, warn or fail when a variable without a sensitive")
+	fmt.Println("                                name gets a JWT, AWS key or other secret-looking value (or set ROBOGO_SECRET_POLICY)")
+	fmt.Println("  --secret-pattern <regexp>     run: also treat values matching this as secrets (repeatable)")
+	fmt.Println("  --sentry-dsn <dsn>            Send failed steps to Sentry (best-effort)")
+	fmt.Println("  --debug-dump <dir>            Write variables and step results to <dir> on failure")
+	fmt.Println("  --debug-dump=always <dir>     Write the debug dump even when the test passes")
+	fmt.Println("  --error-report <file>         Write errors and failures grouped by code to a JSON file")
+	fmt.Println("  --error-report-samples <n>    Full occurrences kept per error group (default: 5)")
+	fmt.Println("  --timing                      run: print count, duration and failure rate per action")
+	fmt.Println("  --no-cache                    run: ignore the cache option of steps and run every read-only step")
+	fmt.Println("  --update-golden               run: rewrite golden files instead of comparing against them")
+	fmt.Println("  --strict                      run: fail on duplicate step or test case names instead of warning;")
+	fmt.Println("                                validate: fail on warnings")
+	fmt.Println("  --compact                     watch: print one line per test case instead of the step output")
+	fmt.Println("  --pattern <glob>              run, validate: files to pick up in a directory (default: **/*.{yaml,yml,json})")
+	fmt.Println("  --filter <pattern>            run: only test cases whose name matches a glob or /regexp/ (repeatable)")
+	fmt.Println("  --environment <name>          run: active environment for only_on/not_on (or set ROBOGO_ENVIRONMENT)")
+	fmt.Println("  --verbosity <level>           run: quiet, normal (default), verbose or debug step output")
+	fmt.Println("  -q, -v, -vv                   run: shorthand for --verbosity quiet, verbose and debug")
+	fmt.Println("  --max-data-bytes <n>          run, bench: bytes of result data kept per step (default: 65536)")
+	fmt.Println("  --artifacts <dir>             run: write step attachments and debug dumps to a new run directory in dir")
+	fmt.Println("  --max-artifact-bytes <n>      run: largest artifact written; larger ones are listed as skipped (default: 10485760)")
+	fmt.Println("  --list                        run: print the test cases that would run without running them")
+	fmt.Println("  --report <file>               run: write the status and duration of every test case to a JSON file")
+	fmt.Println("  --profile-steps <file>        run: write a trace of every step (open in chrome://tracing or Perfetto)")
+	fmt.Println("  --iterations <n>              bench: how many times to run the test case (default: 100)")
+	fmt.Println("  --concurrency <n>             bench: iterations run at once (default: 1)")
+	fmt.Println("  --max-failure-rate <percent>  bench: exit non-zero above this failure rate (default: 0)")
+	fmt.Println("  --samples <file>              bench: write one CSV row per iteration")
+	fmt.Println("  --debug-script <file>         debug: read commands from a file instead of the terminal")
+	fmt.Println("  --history <file>              run: compare with the last run recorded in a JSON file, then record this one")
+	fmt.Println("  --threshold <ratio>           report diff, run --history: duration ratio counted as slower (default: 1.5)")
+	fmt.Println("  --format <text|json>          report diff, validate: output format (default: text)")
+	fmt.Println("  --fix                         validate: apply auto-fixable suggestions and rewrite the files")
+	fmt.Println("  --dry-run                     validate: with --fix, print the changes without writing them")
+	fmt.Println("  --fix-confidence <0-1>        validate: lowest suggestion confidence --fix applies (default: 0.8)")
+	fmt.Println("  --check                       fmt: list files that need formatting and exit non-zero")
+	fmt.Println("  --stdout                      fmt: print formatted files instead of writing them")
+	fmt.Println("  --force                       init: overwrite existing files")
 }
 
-// getCategory returns the category from ErrorInfo or FailureInfo
+// getCategory returns the category from ErrorInfo, FailureInfo or SkipInfo
 func getCategory(result types.ActionResult) string {
+	if result.SkipInfo != nil {
+		return string(result.SkipInfo.Category)
+	}
 	if result.ErrorInfo != nil {
 		return string(result.ErrorInfo.Category)
 	}
@@ -180,6 +1313,16 @@ func printTestSummary(result *types.TestResult) {
 	if errorMsg := result.GetMessage(); errorMsg != "" {
 		fmt.Printf("  Error: %s\n", errorMsg)
 	}
+	if result.SkipInfo != nil {
+		fmt.Printf("  Skipped: %s (%s)\n", result.SkipInfo.Reason, result.SkipInfo.Category)
+	}
+	if result.TeardownStatus != "" && result.TeardownStatus != string(types.ActionStatusPassed) {
+		fmt.Printf("  Teardown: %s\n", result.TeardownStatus)
+	}
+	for _, event := range result.CircuitEvents {
+		fmt.Printf("  Circuit: %s\n", event)
+	}
+	printAnnotations(result.Annotations)
 	fmt.Println()
 
 	// Print table header
@@ -236,8 +1379,13 @@ func printStepRow(stepNum int, step types.StepResult, prefix string) {
 		stepName = stepName[:truncStepName] + "..."
 	}
 
-	// Get message (error or failure message)
+	// Get message (error, failure or skip reason)
 	message := step.Result.GetMessage()
+	if step.Result.IsSkipped() {
+		message = step.Result.GetSkipReason()
+	} else if message == "" && step.Cached {
+		message = "served from cache"
+	}
 	if len(message) > colMessageWidth {
 		message = message[:truncMessage] + "..."
 	}
@@ -258,3 +1406,57 @@ func printStepRow(stepNum int, step types.StepResult, prefix string) {
 		colMessageWidth, message,
 		colCategoryWidth, category)
 }
+
+// printActionMetrics prints per-action timing, slowest total first
+func printActionMetrics(title string, stats []types.ActionStats) {
+	fmt.Printf("\n%s:\n", title)
+	if len(stats) == 0 {
+		fmt.Println("  No actions were executed")
+		return
+	}
+
+	var overall time.Duration
+	for _, stat := range stats {
+		overall += stat.TotalDuration
+	}
+
+	headerFormat := "| %-*s | %*s | %*s | %*s | %*s | %*s | %*s | %*s | %*s |\n"
+	fmt.Printf(headerFormat,
+		colActionWidth, "Action",
+		colCountWidth, "Count",
+		colDurationWidth, "Total",
+		colShareWidth, "% Time",
+		colDurationWidth, "Average",
+		colDurationWidth, "P50",
+		colDurationWidth, "P95",
+		colDurationWidth, "Max",
+		colFailRateWidth, "Fail Rate")
+	fmt.Printf("|%s|%s|%s|%s|%s|%s|%s|%s|%s|\n",
+		strings.Repeat("-", colActionWidth+2),
+		strings.Repeat("-", colCountWidth+2),
+		strings.Repeat("-", colDurationWidth+2),
+		strings.Repeat("-", colShareWidth+2),
+		strings.Repeat("-", colDurationWidth+2),
+		strings.Repeat("-", colDurationWidth+2),
+		strings.Repeat("-", colDurationWidth+2),
+		strings.Repeat("-", colDurationWidth+2),
+		strings.Repeat("-", colFailRateWidth+2))
+
+	rowFormat := "| %-*s | %*d | %*s | %*s | %*s | %*s | %*s | %*s | %*s |\n"
+	for _, stat := range stats {
+		share := 0.0
+		if overall > 0 {
+			share = float64(stat.TotalDuration) / float64(overall) * 100
+		}
+		fmt.Printf(rowFormat,
+			colActionWidth, stat.Action,
+			colCountWidth, stat.Count,
+			colDurationWidth, stat.TotalDuration.Round(time.Microsecond).String(),
+			colShareWidth, fmt.Sprintf("%.1f%%", share),
+			colDurationWidth, stat.AverageDuration.Round(time.Microsecond).String(),
+			colDurationWidth, stat.P50Duration.Round(time.Microsecond).String(),
+			colDurationWidth, stat.P95Duration.Round(time.Microsecond).String(),
+			colDurationWidth, stat.MaxDuration.Round(time.Microsecond).String(),
+			colFailRateWidth, fmt.Sprintf("%.0f%%", stat.FailureRate*100))
+	}
+}
@@ -68,8 +68,12 @@ func LoadDotEnv(filepath string) error {
 	return nil
 }
 
-// LoadDotEnvWithDefault attempts to load .env file from the current directory
-// This is a convenience function for the common case
+// LoadDotEnvWithDefault attempts to load .env.local and .env files from the current directory.
+// .env.local is loaded first so its values, usually secrets kept out of version control,
+// take precedence over the shared settings in .env
 func LoadDotEnvWithDefault() error {
+	if err := LoadDotEnv(".env.local"); err != nil {
+		return err
+	}
 	return LoadDotEnv(".env")
 }
\ No newline at end of file
@@ -0,0 +1,61 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Paths written in a test file, such as a file_read path, a body_file, a seed fixture or
+// an include, resolve against the directory of the file that declares them: the test
+// file, or the snippet file of included steps. A relative path that does not exist there
+// but does in the run directory resolves there instead, as paths in file actions did
+// before, so existing suites run unchanged from the repository root. Paths given on the
+// command line resolve against the run directory: the working directory, or --workdir.
+
+// RunVariable and SuiteVariable hold ${run.dir}, the absolute run directory, and
+// ${suite.dir}, the absolute directory of the running test file, for building paths
+// explicitly, e.g. "${suite.dir}/fixtures/users.json"
+const (
+	RunVariable   = "run"
+	SuiteVariable = "suite"
+	DirField      = "dir"
+)
+
+// RunDir returns the absolute run directory
+func RunDir() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "."
+	}
+	return dir
+}
+
+// ResolvePath resolves a path declared in a file in baseDir. Absolute paths are kept, and
+// an empty baseDir leaves the path relative to the run directory.
+func ResolvePath(baseDir, path string) string {
+	if path == "" || baseDir == "" || filepath.IsAbs(path) {
+		return path
+	}
+	declared := filepath.Join(baseDir, path)
+	if _, err := os.Stat(declared); err != nil {
+		if _, err := os.Stat(path); err == nil {
+			return filepath.Clean(path)
+		}
+	}
+	return declared
+}
+
+// WithinDir reports whether path is dir or inside it, comparing absolute paths
+func WithinDir(dir, path string) bool {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(absDir, absPath)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
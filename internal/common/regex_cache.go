@@ -0,0 +1,77 @@
+package common
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// regexCache holds compiled patterns keyed by their source so masking and
+// extraction don't recompile the same expression on every step
+var regexCache sync.Map
+
+// CompileCached compiles a regular expression once and reuses it afterwards
+func CompileCached(pattern string) (*regexp.Regexp, error) {
+	if cached, ok := regexCache.Load(pattern); ok {
+		return cached.(*regexp.Regexp), nil
+	}
+
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	actual, _ := regexCache.LoadOrStore(pattern, compiled)
+	return actual.(*regexp.Regexp), nil
+}
+
+// MustCompileCached is like CompileCached but panics if the pattern is invalid.
+// Only use it for patterns built by robogo itself, never for user input.
+func MustCompileCached(pattern string) *regexp.Regexp {
+	compiled, err := CompileCached(pattern)
+	if err != nil {
+		panic(`regexp: Compile(` + pattern + `): ` + err.Error())
+	}
+	return compiled
+}
+
+// keyPatterns are the compiled masking patterns of one sensitive key
+type keyPatterns struct {
+	key  string
+	data *regexp.Regexp   // key=value and key: value, for MaskSensitiveData
+	body []*regexp.Regexp // "key": "value" and key=value forms, for MaskSensitiveBody
+}
+
+// sensitivePatternSets holds the patterns of every sensitive key list used, keyed by
+// the full list, so the defaults are built once and steps with their own
+// sensitive_fields get a set of their own instead of the defaults' set
+var sensitivePatternSets sync.Map
+
+// sensitivePatternSet returns the compiled masking patterns of a sensitive key list
+func sensitivePatternSet(sensitiveKeys []string) []keyPatterns {
+	setKey := strings.Join(sensitiveKeys, "\x00")
+	if cached, ok := sensitivePatternSets.Load(setKey); ok {
+		return cached.([]keyPatterns)
+	}
+
+	set := make([]keyPatterns, len(sensitiveKeys))
+	for i, key := range sensitiveKeys {
+		quotedKey := regexp.QuoteMeta(key)
+		set[i] = keyPatterns{
+			key:  key,
+			data: MustCompileCached(`(?i)(` + quotedKey + `[=:]\s*)([^\s,;}&]+)`),
+			// Match various patterns: "key":"value", key=value, key: value
+			body: []*regexp.Regexp{
+				MustCompileCached(fmt.Sprintf(`(?i)"%s"\s*:\s*"[^"]*"`, quotedKey)),
+				MustCompileCached(fmt.Sprintf(`(?i)"%s"\s*:\s*'[^']*'`, quotedKey)),
+				MustCompileCached(fmt.Sprintf(`(?i)%s\s*=\s*"[^"]*"`, quotedKey)),
+				MustCompileCached(fmt.Sprintf(`(?i)%s\s*=\s*'[^']*'`, quotedKey)),
+				MustCompileCached(fmt.Sprintf(`(?i)%s\s*=\s*[^\s&;]+`, quotedKey)),
+			},
+		}
+	}
+
+	actual, _ := sensitivePatternSets.LoadOrStore(setKey, set)
+	return actual.([]keyPatterns)
+}
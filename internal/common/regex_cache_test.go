@@ -0,0 +1,116 @@
+package common
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// suiteSteps is the size of the suite the benchmarks simulate
+const suiteSteps = 500
+
+// stepBodies returns the JSON bodies of a suite's steps, as printed in their arguments
+// and error messages
+func stepBodies() []string {
+	bodies := make([]string, suiteSteps)
+	for i := range bodies {
+		bodies[i] = fmt.Sprintf(`{"id": %d, "username": "user%d", "password": "secret-%d", "api_key": "key-%d", "items": [1, 2, 3]}`, i, i, i, i)
+	}
+	return bodies
+}
+
+// uncachedMaskSensitiveData masks as MaskSensitiveData did before patterns were
+// cached, compiling every key's pattern on every call
+func uncachedMaskSensitiveData(data string, sensitiveKeys []string) string {
+	masked := data
+	for _, key := range sensitiveKeys {
+		pattern := regexp.MustCompile(`(?i)(` + regexp.QuoteMeta(key) + `[=:]\s*)([^\s,;}&]+)`)
+		masked = pattern.ReplaceAllString(masked, "${1}***")
+	}
+	return masked
+}
+
+func BenchmarkMaskSuite(b *testing.B) {
+	bodies := stepBodies()
+	keys := SensitiveKeys()
+
+	b.Run("uncached", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			for _, body := range bodies {
+				uncachedMaskSensitiveData(body, keys)
+			}
+		}
+	})
+	b.Run("cached", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			for _, body := range bodies {
+				MaskSensitiveData(body, keys)
+			}
+		}
+	})
+}
+
+// BenchmarkMaskSuiteCustomKeys alternates steps with and without sensitive_fields, as a
+// suite does when some steps declare their own keys
+func BenchmarkMaskSuiteCustomKeys(b *testing.B) {
+	bodies := stepBodies()
+	defaults := SensitiveKeys()
+	custom := SensitiveKeys([]string{"username"})
+
+	b.Run("uncached", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			for i, body := range bodies {
+				if i%2 == 0 {
+					uncachedMaskSensitiveData(body, defaults)
+				} else {
+					uncachedMaskSensitiveData(body, custom)
+				}
+			}
+		}
+	})
+	b.Run("cached", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			for i, body := range bodies {
+				if i%2 == 0 {
+					MaskSensitiveData(body, defaults)
+				} else {
+					MaskSensitiveData(body, custom)
+				}
+			}
+		}
+	})
+}
+
+func TestMaskSensitiveDataMatchesUncached(t *testing.T) {
+	keys := SensitiveKeys([]string{"username"})
+	for _, body := range stepBodies()[:20] {
+		if got, want := MaskSensitiveData(body, keys), uncachedMaskSensitiveData(body, keys); got != want {
+			t.Fatalf("MaskSensitiveData(%q) = %q, want %q", body, got, want)
+		}
+	}
+}
+
+func TestSensitivePatternSetKeyedOnFullKeySet(t *testing.T) {
+	body := `login failed for username: alice, password: hunter2`
+	defaults := SensitiveKeys()
+	custom := SensitiveKeys([]string{"username"})
+
+	// The defaults' set, built first, must not be reused for the custom keys
+	if masked := MaskSensitiveData(body, defaults); !strings.Contains(masked, "alice") || strings.Contains(masked, "hunter2") {
+		t.Fatalf("default keys: got %q", masked)
+	}
+	if masked := MaskSensitiveData(body, custom); strings.Contains(masked, "alice") || strings.Contains(masked, "hunter2") {
+		t.Fatalf("custom keys: got %q", masked)
+	}
+	if masked := MaskSensitiveBody(`username=alice&password=hunter2`, custom); masked != "username=***&password=***" {
+		t.Fatalf("custom keys in a form body: got %q", masked)
+	}
+
+	if len(sensitivePatternSet(defaults)) == len(sensitivePatternSet(custom)) {
+		t.Fatal("custom keys share the default pattern set")
+	}
+	if &sensitivePatternSet(custom)[0] != &sensitivePatternSet(custom)[0] {
+		t.Fatal("pattern set rebuilt for the same keys")
+	}
+}
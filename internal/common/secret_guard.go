@@ -0,0 +1,216 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// SecretPolicy says what happens when a variable without a sensitive name is given a
+// value that looks like a secret at run time, e.g. a token extracted into a result
+// variable, a --global-setup output or a ${ENV:...} reference in vars
+type SecretPolicy string
+
+// Secret policies, see SetSecretGuard
+const (
+	SecretPolicyOff  SecretPolicy = "off"  // no checks (default)
+	SecretPolicyMask SecretPolicy = "mask" // mask the value in all output
+	SecretPolicyWarn SecretPolicy = "warn" // mask the value and warn about the variable
+	SecretPolicyFail SecretPolicy = "fail" // mask the value and fail the step or test case that set it
+)
+
+// SecretPolicyEnvVar sets the policy when --secret-policy is not given
+const SecretPolicyEnvVar = "ROBOGO_SECRET_POLICY"
+
+// maxSecretScanLength keeps large payloads, such as response bodies, out of the checks
+const maxSecretScanLength = 4096
+
+// A long random token is at least minEntropyLength characters with at least
+// minEntropyBits of entropy per character, of which less than maxWordFraction are in
+// runs of lower case letters, as in the words of identifiers such as UserServiceTest
+const (
+	minEntropyLength = 32
+	minEntropyBits   = 3.5
+	maxWordFraction  = 0.5
+)
+
+// secretShapes are the built-in patterns, checked in order
+var secretShapes = []struct {
+	kind  string
+	regex *regexp.Regexp
+}{
+	{"a JWT", regexp.MustCompile(`^(Bearer )?eyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]*$`)},
+	{"an AWS access key", regexp.MustCompile(`^(AKIA|ASIA)[A-Z0-9]{16}$`)},
+	{"a private key", regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`)},
+}
+
+// tokenChars are the characters of base64 and similar token encodings
+var tokenChars = regexp.MustCompile(`^[A-Za-z0-9+/=_-]+$`)
+
+// secretGuard is the policy and extra patterns set for the run, see SetSecretGuard
+var secretGuard struct {
+	policy   SecretPolicy
+	patterns []*regexp.Regexp
+}
+
+// ParseSecretPolicy parses a --secret-policy value; empty means off
+func ParseSecretPolicy(value string) (SecretPolicy, error) {
+	switch policy := SecretPolicy(strings.ToLower(strings.TrimSpace(value))); policy {
+	case "":
+		return SecretPolicyOff, nil
+	case SecretPolicyOff, SecretPolicyMask, SecretPolicyWarn, SecretPolicyFail:
+		return policy, nil
+	default:
+		return SecretPolicyOff, fmt.Errorf("unknown secret policy '%s' (use off, mask, warn or fail)", value)
+	}
+}
+
+// SetSecretGuard sets the policy for secret-looking values in variables, and patterns
+// matched in addition to the built-in JWT, AWS key, private key and random token shapes.
+// It is set once before test cases run.
+func SetSecretGuard(policy SecretPolicy, patterns []*regexp.Regexp) {
+	secretGuard.policy = policy
+	secretGuard.patterns = patterns
+}
+
+// CurrentSecretPolicy returns the policy set with SetSecretGuard
+func CurrentSecretPolicy() SecretPolicy {
+	if secretGuard.policy == "" {
+		return SecretPolicyOff
+	}
+	return secretGuard.policy
+}
+
+// SecretFinding is a variable found holding a secret-looking value
+type SecretFinding struct {
+	Variable string
+	Kind     string // what the value looks like, e.g. a JWT
+}
+
+func (f SecretFinding) Error() string {
+	return fmt.Sprintf("variable '%s' holds what looks like %s", f.Variable, f.Kind)
+}
+
+// guardSecret checks a value stored in a variable. A secret-looking value is masked from
+// now on, see DetectedSecretValues, and kept as a finding for the warn and fail policies.
+// Only strings are checked, and only up to maxSecretScanLength, so it stays cheap.
+func (v *Variables) guardSecret(name string, value any) {
+	policy := CurrentSecretPolicy()
+	if policy == SecretPolicyOff {
+		return
+	}
+	str, ok := value.(string)
+	if !ok || len(str) < minSecretValueLength || len(str) > maxSecretScanLength || v.secrets[str] || v.detected[str] {
+		return
+	}
+	kind := secretKind(str)
+	if kind == "" || IsSensitiveKey(name, SensitiveKeys()) {
+		return
+	}
+	v.detected[str] = true
+	if policy != SecretPolicyMask {
+		v.findings = append(v.findings, SecretFinding{Variable: name, Kind: kind})
+	}
+}
+
+// secretKind names what a value looks like, or returns "" for values that do not look
+// like secrets
+func secretKind(value string) string {
+	for _, shape := range secretShapes {
+		if shape.regex.MatchString(value) {
+			return shape.kind
+		}
+	}
+	for _, pattern := range secretGuard.patterns {
+		if pattern.MatchString(value) {
+			return "a secret (" + pattern.String() + ")"
+		}
+	}
+	if looksRandom(value) {
+		return "a random token"
+	}
+	return ""
+}
+
+// looksRandom reports whether a value is a long token of mixed case letters and digits,
+// such as an API key. Hex digests and UUIDs have no upper case letters, ordinary text
+// has spaces or punctuation, and identifiers are mostly words, so none of them count.
+func looksRandom(value string) bool {
+	// Unresolved references, e.g. __UNRESOLVED_base_url__/status, are placeholders
+	if len(value) < minEntropyLength || !tokenChars.MatchString(value) || strings.Contains(value, "__UNRESOLVED_") {
+		return false
+	}
+	if !strings.ContainsAny(value, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") ||
+		!strings.ContainsAny(value, "abcdefghijklmnopqrstuvwxyz") ||
+		!strings.ContainsAny(value, "0123456789") {
+		return false
+	}
+	return wordFraction(value) < maxWordFraction && shannonEntropy(value) >= minEntropyBits
+}
+
+// wordFraction returns the fraction of characters in runs of three or more lower case letters
+func wordFraction(value string) float64 {
+	inWords, run := 0, 0
+	for i := 0; i <= len(value); i++ {
+		if i < len(value) && value[i] >= 'a' && value[i] <= 'z' {
+			run++
+			continue
+		}
+		if run >= 3 {
+			inWords += run
+		}
+		run = 0
+	}
+	return float64(inWords) / float64(len(value))
+}
+
+// shannonEntropy returns the bits of entropy per character of a string
+func shannonEntropy(value string) float64 {
+	counts := make(map[rune]int)
+	for _, r := range value {
+		counts[r]++
+	}
+	entropy := 0.0
+	total := float64(len(value))
+	for _, count := range counts {
+		p := float64(count) / total
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// DetectedSecretValues returns the secret-looking values found in variables, longest
+// first. They are masked in output even when mask_output is off.
+func (v *Variables) DetectedSecretValues() []string {
+	if len(v.detected) == 0 {
+		return nil
+	}
+	return sortedSecrets(v.detected)
+}
+
+// SecretFindings reports the variables found holding secret-looking values since the
+// last call: under the warn policy it prints a warning for each and returns nil, under
+// the fail policy it returns them as an error
+func (v *Variables) SecretFindings() error {
+	findings := v.findings
+	v.findings = nil
+	if len(findings) == 0 {
+		return nil
+	}
+	// Variables loaded together are found in map order
+	sort.SliceStable(findings, func(i, j int) bool { return findings[i].Variable < findings[j].Variable })
+	if CurrentSecretPolicy() == SecretPolicyFail {
+		errs := make([]error, len(findings))
+		for i, finding := range findings {
+			errs[i] = finding
+		}
+		return errors.Join(errs...)
+	}
+	for _, finding := range findings {
+		fmt.Printf("[WARN] %v; it is masked in output. Give the variable a sensitive name or read it with ${ENV:...}\n", finding)
+	}
+	return nil
+}
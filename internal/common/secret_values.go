@@ -0,0 +1,113 @@
+package common
+
+import (
+	"sort"
+	"strings"
+)
+
+// minSecretValueLength keeps short values such as "1" or "yes" from being masked
+// wherever they happen to appear
+const minSecretValueLength = 4
+
+// rememberSecret records the value of a sensitive ${ENV:...} reference, see SecretValues
+func (v *Variables) rememberSecret(value string) {
+	if len(value) >= minSecretValueLength {
+		v.secrets[value] = true
+	}
+}
+
+// SecretValues returns the values of variables with sensitive names, of the sensitive
+// ${ENV:...} references resolved so far and of secret-looking values found in other
+// variables (see DetectedSecretValues), longest first so that a secret containing
+// another one is masked whole
+func (v *Variables) SecretValues() []string {
+	found := make(map[string]bool, len(v.secrets))
+	for value := range v.secrets {
+		found[value] = true
+	}
+	for value := range v.detected {
+		found[value] = true
+	}
+	keys := SensitiveKeys()
+	for name, value := range v.data {
+		if str, ok := value.(string); ok && len(str) >= minSecretValueLength && IsSensitiveKey(name, keys) {
+			found[str] = true
+		}
+	}
+	return sortedSecrets(found)
+}
+
+// sortedSecrets returns a set of secret values longest first
+func sortedSecrets(found map[string]bool) []string {
+	secrets := make([]string, 0, len(found))
+	for value := range found {
+		secrets = append(secrets, value)
+	}
+	sort.Slice(secrets, func(i, j int) bool {
+		if len(secrets[i]) != len(secrets[j]) {
+			return len(secrets[i]) > len(secrets[j])
+		}
+		return secrets[i] < secrets[j]
+	})
+	return secrets
+}
+
+// MaskSecretValues replaces every occurrence of the secret values in text with ***
+func MaskSecretValues(text string, secrets []string) string {
+	for _, secret := range secrets {
+		text = strings.ReplaceAll(text, secret, "***")
+	}
+	return text
+}
+
+// MaskSecretValuesIn returns a copy of data with the secret values masked in every string
+// it holds, however deeply nested in maps and slices. Other values are returned as they are.
+func MaskSecretValuesIn(data any, secrets []string) any {
+	if len(secrets) == 0 {
+		return data
+	}
+	switch val := data.(type) {
+	case string:
+		return MaskSecretValues(val, secrets)
+	case []byte:
+		return []byte(MaskSecretValues(string(val), secrets))
+	case map[string]any:
+		masked := make(map[string]any, len(val))
+		for key, item := range val {
+			masked[key] = MaskSecretValuesIn(item, secrets)
+		}
+		return masked
+	case map[any]any:
+		masked := make(map[any]any, len(val))
+		for key, item := range val {
+			masked[key] = MaskSecretValuesIn(item, secrets)
+		}
+		return masked
+	case map[string]string:
+		masked := make(map[string]string, len(val))
+		for key, item := range val {
+			masked[key] = MaskSecretValues(item, secrets)
+		}
+		return masked
+	case []any:
+		masked := make([]any, len(val))
+		for i, item := range val {
+			masked[i] = MaskSecretValuesIn(item, secrets)
+		}
+		return masked
+	case []map[string]any:
+		masked := make([]map[string]any, len(val))
+		for i, item := range val {
+			masked[i] = MaskSecretValuesIn(item, secrets).(map[string]any)
+		}
+		return masked
+	case []string:
+		masked := make([]string, len(val))
+		for i, item := range val {
+			masked[i] = MaskSecretValues(item, secrets)
+		}
+		return masked
+	default:
+		return data
+	}
+}
@@ -0,0 +1,64 @@
+package common
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/google/uuid"
+)
+
+// StepVariable holds built-in values of the running step, e.g. ${step.idempotency_key}
+const StepVariable = "step"
+
+// ReservedVariable reports whether a test may not set a variable name because robogo sets
+// it for every step
+func ReservedVariable(name string) bool {
+	return name == StepVariable
+}
+
+// IdempotencyKeyField is the field of StepVariable with the step's idempotency key
+const IdempotencyKeyField = "idempotency_key"
+
+// runSeed makes idempotency keys and unique() values differ between runs
+var runSeed = uuid.New()
+
+// keyGroups numbers the idempotency keys handed out in this run
+var keyGroups atomic.Uint64
+
+// NewIdempotencyKey returns a key no other step, iteration or run gets. Retries of a step
+// reuse the key they were given instead of asking for a new one.
+func NewIdempotencyKey() string {
+	return uuid.NewSHA1(runSeed, []byte(strconv.FormatUint(keyGroups.Add(1), 10))).String()
+}
+
+// uniqueFunction returns the name in a ${unique()} or ${unique(name)} reference
+func uniqueFunction(reference string) (string, bool) {
+	if !strings.HasPrefix(reference, "unique(") || !strings.HasSuffix(reference, ")") {
+		return "", false
+	}
+	return strings.TrimSpace(reference[len("unique(") : len(reference)-1]), true
+}
+
+// uniqueValue returns the value of ${unique(name)}: 16 hex characters derived from the
+// running step's idempotency key, so retries of the step see the same value, prefixed
+// with "name-" when a name is given. Outside a step, e.g. in vars, every reference gets
+// a new value.
+func (v *Variables) uniqueValue(name string) string {
+	key := ""
+	if step, ok := v.data[StepVariable].(map[string]any); ok {
+		key, _ = step[IdempotencyKeyField].(string)
+	}
+	if key == "" {
+		key = NewIdempotencyKey()
+	}
+
+	sum := sha1.Sum([]byte(key + "/" + name))
+	value := hex.EncodeToString(sum[:8])
+	if name != "" {
+		value = name + "-" + value
+	}
+	return value
+}
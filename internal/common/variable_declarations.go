@@ -0,0 +1,92 @@
+package common
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+)
+
+// VariableDeclaration checks the values of a declared variable, see Variables.Declare
+type VariableDeclaration interface {
+	// Convert returns the value to store, converted as declared where needed, or an
+	// error describing why the value does not match the declaration
+	Convert(name string, value any) (any, error)
+	// String describes the declared type, e.g. int or enum
+	String() string
+}
+
+// Declare attaches a declaration to a variable; Assign and CheckDeclared check its values
+func (v *Variables) Declare(name string, declaration VariableDeclaration) {
+	v.declared[name] = declaration
+}
+
+// Declarations returns the declared variables and their declarations
+func (v *Variables) Declarations() map[string]VariableDeclaration {
+	declarations := make(map[string]VariableDeclaration, len(v.declared))
+	for name, declaration := range v.declared {
+		declarations[name] = declaration
+	}
+	return declarations
+}
+
+// Assign stores a variable, such as a step's result, after checking it against the
+// variable's declaration. A value that does not match is not stored.
+func (v *Variables) Assign(name string, value any) error {
+	if declaration, ok := v.declared[name]; ok {
+		converted, err := declaration.Convert(name, value)
+		if err != nil {
+			return err
+		}
+		value = converted
+	}
+	v.data[name] = value
+	v.guardSecret(name, value)
+	return nil
+}
+
+// CheckDeclared checks the current values of the declared variables and stores them
+// converted. Declared variables without a value are not checked.
+func (v *Variables) CheckDeclared() error {
+	names := make([]string, 0, len(v.declared))
+	for name := range v.declared {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		value, ok := v.data[name]
+		if !ok {
+			continue
+		}
+		if err := v.Assign(name, value); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ValueTypeName names the type of a value as variable declarations do: string, int,
+// number, bool, list, object or null
+func ValueTypeName(value any) string {
+	if value == nil {
+		return "null"
+	}
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "bool"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "int"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "list"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	default:
+		return reflect.TypeOf(value).String()
+	}
+}
@@ -9,19 +9,44 @@ import (
 
 // Variables provides simple variable storage and substitution
 type Variables struct {
-	data map[string]any
+	data     map[string]any
+	secrets  map[string]bool                // values of sensitive ${ENV:...} references, see SecretValues
+	declared map[string]VariableDeclaration // declared variable types, see Declare
+	detected map[string]bool                // secret-looking values of other variables, see DetectedSecretValues
+	findings []SecretFinding                // variables holding them, not yet reported, see SecretFindings
+	env      map[string]string              // env of the running step, see SetStepEnv
 }
 
 // NewVariables creates a new Variables instance
 func NewVariables() *Variables {
 	return &Variables{
-		data: make(map[string]any),
+		data:     make(map[string]any),
+		secrets:  make(map[string]bool),
+		declared: make(map[string]VariableDeclaration),
+		detected: make(map[string]bool),
 	}
 }
 
 // Set stores a variable
 func (v *Variables) Set(key string, value any) {
 	v.data[key] = value
+	v.guardSecret(key, value)
+}
+
+// SetIfAbsent stores a variable only if it is not already defined.
+// Returns true if the value was stored.
+func (v *Variables) SetIfAbsent(key string, value any) bool {
+	if v.Has(key) {
+		return false
+	}
+	v.data[key] = value
+	v.guardSecret(key, value)
+	return true
+}
+
+// Delete removes a variable
+func (v *Variables) Delete(key string) {
+	delete(v.data, key)
 }
 
 // Get retrieves a variable
@@ -48,6 +73,44 @@ func (v *Variables) Load(vars map[string]any) {
 	}
 }
 
+// SetStepEnv sets the env of the running step, seen by its ${ENV:...} references and by
+// its action through Getenv and Environ; the process environment is not changed. Returns
+// a function that restores the previous env.
+func (v *Variables) SetStepEnv(env map[string]string) func() {
+	previous := v.env
+	v.env = env
+	return func() { v.env = previous }
+}
+
+// Getenv returns an environment variable as the running step sees it: from its env,
+// otherwise from the process environment
+func (v *Variables) Getenv(name string) string {
+	if value, ok := v.env[name]; ok {
+		return value
+	}
+	return os.Getenv(name)
+}
+
+// Environ returns the process environment with the running step's env applied, in the
+// form of exec.Cmd.Env, for actions that start a process
+func (v *Variables) Environ() []string {
+	environ := os.Environ()
+	if len(v.env) == 0 {
+		return environ
+	}
+	merged := make([]string, 0, len(environ)+len(v.env))
+	for _, entry := range environ {
+		name, _, _ := strings.Cut(entry, "=")
+		if _, overridden := v.env[name]; !overridden {
+			merged = append(merged, entry)
+		}
+	}
+	for name, value := range v.env {
+		merged = append(merged, name+"="+value)
+	}
+	return merged
+}
+
 // GetSnapshot returns a copy of all current variables
 func (v *Variables) GetSnapshot() map[string]interface{} {
 	snapshot := make(map[string]interface{}, len(v.data))
@@ -75,7 +138,10 @@ func (v *Variables) Substitute(template string) string {
 
 		// Extract environment variable name
 		envVar := result[start+6 : end] // Skip "${ENV:"
-		envValue := os.Getenv(envVar)
+		envValue := v.Getenv(envVar)
+		if envValue != "" && IsSensitiveKey(envVar, SensitiveKeys()) {
+			v.rememberSecret(envValue)
+		}
 
 		// Replace with environment value
 		result = result[:start] + envValue + result[end+1:]
@@ -106,8 +172,11 @@ func (v *Variables) Substitute(template string) string {
 			continue
 		}
 
-		// Check if this is dot notation (e.g., "response.status_code")
-		if strings.Contains(varName, ".") {
+		// ${unique()} and ${unique(name)} generate identifiers, see uniqueValue
+		if name, isUnique := uniqueFunction(varName); isUnique {
+			result = result[:start] + v.uniqueValue(name) + result[end+1:]
+		} else if strings.ContainsAny(varName, ".[") {
+			// Dot notation (e.g., "response.status_code" or "items[id=5]")
 			resolvedValue := v.resolveDotNotation(varName)
 			result = result[:start] + resolvedValue + result[end+1:]
 		} else {
@@ -199,36 +268,66 @@ func (v *Variables) isSimpleVariableReference(str string) bool {
 	return true
 }
 
-// resolveDotNotation resolves dot notation like "response.status_code" or "user.profile.name"
+// resolveDotNotation resolves dot notation like "response.status_code" or "user.profile.name".
+// Array elements may be selected by position, "items.0" or "items[0]", or by a field
+// value, "items[id=5].name", which does not depend on the order of the array.
 func (v *Variables) resolveDotNotation(dotPath string) string {
-	parts := strings.Split(dotPath, ".")
-	if len(parts) < 2 {
+	parts := splitDotPath(dotPath)
+	if _, selectors := splitSelectors(parts[0]); len(parts) < 2 && len(selectors) == 0 {
 		return "__UNRESOLVED_" + dotPath + "__"
 	}
 
-	// Get the root variable
-	rootVar := parts[0]
+	current, failedPath := v.lookupPath(dotPath)
+	if failedPath != "" {
+		return "__UNRESOLVED_" + failedPath + "__"
+	}
+
+	// Convert final value to string
+	if current == nil {
+		return ""
+	}
+	return strings.TrimSpace(strings.Trim(strings.Trim(strings.Trim(fmt.Sprintf("%v", current), "\""), "'"), "`"))
+}
+
+// Lookup returns the value of a variable, or of a path into one in the dot notation
+// of ${...} references, e.g. "response.body.items[id=5].name"
+func (v *Variables) Lookup(path string) (any, bool) {
+	value, failedPath := v.lookupPath(path)
+	return value, failedPath == ""
+}
+
+// lookupPath navigates a dot notation path. When a part is missing, it returns the
+// path up to that part for error reporting.
+func (v *Variables) lookupPath(dotPath string) (any, string) {
+	parts := splitDotPath(dotPath)
+
+	// Get the root variable, which may itself be indexed, e.g. items[id=5]
+	rootVar, selectors := splitSelectors(parts[0])
 	value, exists := v.data[rootVar]
 	if !exists {
-		return "__UNRESOLVED_" + dotPath + "__"
+		return nil, dotPath
 	}
 
 	// Navigate through the dot path
 	current := value
-	for i, field := range parts[1:] {
-		current = v.getFieldValue(current, field)
+	for i, part := range parts {
+		field := rootVar
+		if i > 0 {
+			field, selectors = splitSelectors(part)
+			current = v.getFieldValue(current, field)
+		}
+		for _, selector := range selectors {
+			if current == nil {
+				break
+			}
+			current = v.selectElement(current, selector)
+		}
 		if current == nil {
 			// Build the path up to the failed field for better error reporting
-			failedPath := strings.Join(parts[:i+2], ".")
-			return "__UNRESOLVED_" + failedPath + "__"
+			return nil, strings.Join(parts[:i+1], ".")
 		}
 	}
-
-	// Convert final value to string
-	if current == nil {
-		return ""
-	}
-	return strings.TrimSpace(strings.Trim(strings.Trim(strings.Trim(fmt.Sprintf("%v", current), "\""), "'"), "`"))
+	return current, ""
 }
 
 // getFieldValue extracts a field value from various data types
@@ -264,11 +363,81 @@ func (v *Variables) getFieldValue(data any, field string) any {
 	}
 }
 
+// splitDotPath splits a dot path on dots outside brackets, so predicate values such as
+// [email=a.b@example.com] stay whole
+func splitDotPath(dotPath string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, c := range dotPath {
+		switch c {
+		case '[':
+			depth++
+		case ']':
+			if depth > 0 {
+				depth--
+			}
+		case '.':
+			if depth == 0 {
+				parts = append(parts, dotPath[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, dotPath[start:])
+}
+
+// splitSelectors splits a path part such as "items[id=5][0]" into its field name and
+// the contents of its brackets
+func splitSelectors(part string) (string, []string) {
+	open := strings.Index(part, "[")
+	if open == -1 || !strings.HasSuffix(part, "]") {
+		return part, nil
+	}
+	return part[:open], strings.Split(part[open+1:len(part)-1], "][")
+}
+
+// selectElement applies one bracket selector to an array: a position such as "0",
+// or a predicate such as "id=5" or "user.name='Ada'" matching the first element whose
+// field has that value. It returns nil when nothing matches.
+func (v *Variables) selectElement(data any, selector string) any {
+	items, ok := data.([]any)
+	if !ok {
+		return nil
+	}
+
+	key, expected, isPredicate := strings.Cut(selector, "=")
+	if !isPredicate {
+		return v.getFieldValue(items, strings.TrimSpace(selector))
+	}
+	key = strings.TrimSpace(key)
+	expected = strings.Trim(strings.TrimSpace(expected), `"'`)
+
+	for _, item := range items {
+		current := item
+		for _, field := range strings.Split(key, ".") {
+			current = v.getFieldValue(current, field)
+		}
+		if current != nil && fmt.Sprintf("%v", current) == expected {
+			return item
+		}
+	}
+	return nil
+}
+
 // Clone creates a copy of the Variables with the same data
 func (v *Variables) Clone() *Variables {
 	newVars := NewVariables()
 	for key, value := range v.data {
 		newVars.data[key] = value
 	}
+	for value := range v.secrets {
+		newVars.secrets[value] = true
+	}
+	for name, declaration := range v.declared {
+		newVars.declared[name] = declaration
+	}
+	for value := range v.detected {
+		newVars.detected[value] = true
+	}
 	return newVars
 }
@@ -0,0 +1,313 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// completionCommands are the commands offered by shell completion
+var completionCommands = []string{
+	"run", "watch", "debug", "bench", "fmt", "validate", "list", "actions", "schema",
+	"report", "init", "completion", "version",
+}
+
+// testFileCommands take test file paths as their arguments
+var testFileCommands = []string{"run", "watch", "debug", "bench", "fmt", "validate"}
+
+// completionFlag is a flag offered by shell completion. Flags with a value have a kind:
+// "file" completes paths, anything else is free text.
+type completionFlag struct {
+	name  string
+	value string
+}
+
+var completionFlags = []completionFlag{
+	{"--env", "file"}, {"--workdir", "file"}, {"--plugins", "file"}, {"--hooks", "file"}, {"--global-setup", "file"}, {"--sentry-dsn", "text"},
+	{"--secret-policy", "text"}, {"--secret-pattern", "text"},
+	{"--debug-dump", "file"},
+	{"--error-report", "file"}, {"--error-report-samples", "text"},
+	{"--timing", ""}, {"--no-cache", ""}, {"--update-golden", ""}, {"--strict", ""},
+	{"--pattern", "text"}, {"--filter", "text"}, {"--environment", "text"},
+	{"--verbosity", "text"}, {"--quiet", ""}, {"-q", ""}, {"-v", ""}, {"-vv", ""},
+	{"--max-data-bytes", "text"}, {"--list", ""}, {"--report", "file"}, {"--history", "file"},
+	{"--artifacts", "file"}, {"--max-artifact-bytes", "text"},
+	{"--profile-steps", "file"}, {"--iterations", "text"}, {"--concurrency", "text"},
+	{"--max-failure-rate", "text"}, {"--samples", "file"}, {"--debug-script", "file"},
+	{"--compact", ""}, {"--threshold", "text"}, {"--format", "text"},
+	{"--check", ""}, {"--stdout", ""}, {"--force", ""},
+	{"--fix", ""}, {"--dry-run", ""}, {"--fix-confidence", "text"},
+}
+
+// printCompletion prints the completion script for a shell. Action names and categories
+// are completed by calling back into robogo, so plugin actions are included.
+func printCompletion(shell string) {
+	var names, valueFlags, fileFlags []string
+	for _, flag := range completionFlags {
+		names = append(names, flag.name)
+		if flag.value != "" {
+			valueFlags = append(valueFlags, flag.name)
+		}
+		if flag.value == "file" {
+			fileFlags = append(fileFlags, flag.name)
+		}
+	}
+
+	var script string
+	switch shell {
+	case "bash":
+		script = bashCompletion
+	case "zsh":
+		script = zshCompletion
+	case "fish":
+		script = fishCompletion
+		var lines []string
+		for _, flag := range completionFlags {
+			line := "complete -c robogo"
+			switch {
+			case strings.HasPrefix(flag.name, "--"):
+				line += " -l " + flag.name[2:]
+			case len(flag.name) == 2:
+				line += " -s " + flag.name[1:]
+			default:
+				line += " -o " + flag.name[1:]
+			}
+			switch flag.value {
+			case "file":
+				line += " -r -F"
+			case "text":
+				line += " -x"
+			}
+			lines = append(lines, line)
+		}
+		script = strings.Replace(script, "{{FLAG_COMPLETIONS}}", strings.Join(lines, "\n"), 1)
+	default:
+		fmt.Printf("Error: unsupported shell '%s' (available: bash, zsh, fish)\n", shell)
+		os.Exit(ExitUsageError)
+	}
+
+	fmt.Print(strings.NewReplacer(
+		"{{COMMANDS}}", strings.Join(completionCommands, " "),
+		"{{TEST_FILE_COMMANDS}}", strings.Join(testFileCommands, "|"),
+		"{{TEST_FILE_COMMAND_LIST}}", strings.Join(testFileCommands, " "),
+		"{{FLAGS}}", strings.Join(names, " "),
+		"{{VALUE_FLAGS}}", strings.Join(valueFlags, "|"),
+		"{{VALUE_FLAG_LIST}}", strings.Join(valueFlags, " "),
+		"{{FILE_FLAGS}}", strings.Join(fileFlags, "|"),
+	).Replace(script))
+}
+
+// printCompletionCandidates prints one candidate per line for the completion scripts:
+// "actions [prefix]" for action names, "categories" for action categories
+func printCompletionCandidates(args ParsedArgs, positional []string) {
+	if len(positional) == 0 {
+		return
+	}
+	registry := newActionRegistry(args)
+	switch positional[0] {
+	case "actions":
+		prefix := ""
+		if len(positional) > 1 {
+			prefix = positional[1]
+		}
+		for _, name := range registry.GetActionCompletions(prefix) {
+			fmt.Println(name)
+		}
+	case "categories":
+		for _, category := range registry.Categories() {
+			fmt.Println(category)
+		}
+	}
+}
+
+const bashCompletion = `# bash completion for robogo
+# Load with: source <(robogo completion bash)
+
+_robogo_test_files() {
+    compopt -o filenames 2>/dev/null
+    COMPREPLY=($(compgen -d -- "$cur")
+        $(compgen -f -X '!*.yaml' -- "$cur")
+        $(compgen -f -X '!*.yml' -- "$cur")
+        $(compgen -f -X '!*.json' -- "$cur"))
+}
+
+_robogo() {
+    local cur="${COMP_WORDS[COMP_CWORD]}"
+    local prev="${COMP_WORDS[COMP_CWORD-1]}"
+    local robogo="${COMP_WORDS[0]}"
+    COMPREPLY=()
+
+    case "$prev" in
+        {{FILE_FLAGS}})
+            compopt -o filenames 2>/dev/null
+            COMPREPLY=($(compgen -f -- "$cur"))
+            return ;;
+        --verbosity)
+            COMPREPLY=($(compgen -W "quiet normal verbose debug" -- "$cur"))
+            return ;;
+        --format)
+            COMPREPLY=($(compgen -W "text json" -- "$cur"))
+            return ;;
+        {{VALUE_FLAGS}})
+            return ;;
+    esac
+
+    if [[ "$cur" == -* ]]; then
+        COMPREPLY=($(compgen -W "{{FLAGS}}" -- "$cur"))
+        return
+    fi
+
+    # The command and subcommand are the first words that are not flags or flag values
+    local command="" sub="" i
+    for ((i = 1; i < COMP_CWORD; i++)); do
+        case "${COMP_WORDS[i]}" in
+            {{VALUE_FLAGS}}) ((i++)) ;;
+            -*) ;;
+            *)
+                if [[ -z "$command" ]]; then
+                    command="${COMP_WORDS[i]}"
+                elif [[ -z "$sub" ]]; then
+                    sub="${COMP_WORDS[i]}"
+                fi ;;
+        esac
+    done
+
+    case "$command" in
+        "")
+            COMPREPLY=($(compgen -W "{{COMMANDS}}" -- "$cur")) ;;
+        {{TEST_FILE_COMMANDS}})
+            _robogo_test_files ;;
+        init)
+            if [[ -z "$sub" ]]; then
+                compopt -o filenames 2>/dev/null
+                COMPREPLY=($(compgen -d -- "$cur"))
+            fi ;;
+        actions)
+            case "$sub" in
+                "") COMPREPLY=($(compgen -W "list describe search" -- "$cur")) ;;
+                describe) COMPREPLY=($(compgen -W "$("$robogo" __complete actions 2>/dev/null)" -- "$cur")) ;;
+                list) COMPREPLY=($(compgen -W "$("$robogo" __complete categories 2>/dev/null)" -- "$cur")) ;;
+            esac ;;
+        report)
+            if [[ -z "$sub" ]]; then
+                COMPREPLY=($(compgen -W "diff" -- "$cur"))
+            else
+                compopt -o filenames 2>/dev/null
+                COMPREPLY=($(compgen -d -- "$cur") $(compgen -f -X '!*.json' -- "$cur"))
+            fi ;;
+        completion)
+            [[ -z "$sub" ]] && COMPREPLY=($(compgen -W "bash zsh fish" -- "$cur")) ;;
+    esac
+}
+
+complete -F _robogo robogo ./robogo
+`
+
+const zshCompletion = `#compdef robogo
+# zsh completion for robogo
+# Load with: source <(robogo completion zsh)
+
+_robogo() {
+    local robogo=${words[1]} command="" sub="" i
+
+    case ${words[CURRENT-1]} in
+        {{FILE_FLAGS}}) _files; return ;;
+        --verbosity) compadd quiet normal verbose debug; return ;;
+        --format) compadd text json; return ;;
+        {{VALUE_FLAGS}}) return ;;
+    esac
+
+    if [[ ${words[CURRENT]} == -* ]]; then
+        compadd -- {{FLAGS}}
+        return
+    fi
+
+    # The command and subcommand are the first words that are not flags or flag values
+    for ((i = 2; i < CURRENT; i++)); do
+        case ${words[i]} in
+            {{VALUE_FLAGS}}) ((i++)) ;;
+            -*) ;;
+            *)
+                if [[ -z $command ]]; then
+                    command=${words[i]}
+                elif [[ -z $sub ]]; then
+                    sub=${words[i]}
+                fi ;;
+        esac
+    done
+
+    case $command in
+        "") compadd -- {{COMMANDS}} ;;
+        {{TEST_FILE_COMMANDS}}) _files -g '*.(yaml|yml|json)' ;;
+        init) [[ -z $sub ]] && _files -/ ;;
+        actions)
+            case $sub in
+                "") compadd list describe search ;;
+                describe) compadd -- ${(f)"$($robogo __complete actions 2>/dev/null)"} ;;
+                list) compadd -- ${(f)"$($robogo __complete categories 2>/dev/null)"} ;;
+            esac ;;
+        report)
+            if [[ -z $sub ]]; then
+                compadd diff
+            else
+                _files -g '*.json'
+            fi ;;
+        completion) [[ -z $sub ]] && compadd bash zsh fish ;;
+    esac
+}
+
+compdef _robogo robogo ./robogo
+`
+
+const fishCompletion = `# fish completion for robogo
+# Load with: robogo completion fish | source
+
+# Prints the words typed so far that are not flags or flag values
+function __robogo_args
+    set -l skip 0
+    for token in (commandline -opc)[2..-1]
+        if test $skip -eq 1
+            set skip 0
+            continue
+        end
+        if contains -- $token {{VALUE_FLAG_LIST}}
+            set skip 1
+        else if not string match -q -- '-*' $token
+            echo $token
+        end
+    end
+end
+
+# True when exactly the given words have been typed, e.g. __robogo_at actions describe
+function __robogo_at
+    set -l args (__robogo_args)
+    test (count $args) -eq (count $argv); or return 1
+    for i in (seq (count $argv))
+        test "$args[$i]" = "$argv[$i]"; or return 1
+    end
+end
+
+function __robogo_test_command
+    set -l args (__robogo_args)
+    contains -- "$args[1]" {{TEST_FILE_COMMAND_LIST}}
+end
+
+function __robogo_candidates
+    set -l robogo (commandline -opc)[1]
+    $robogo __complete $argv 2>/dev/null
+end
+
+complete -c robogo -f
+complete -c robogo -n '__robogo_at' -a '{{COMMANDS}}'
+complete -c robogo -n '__robogo_test_command' -a '(__fish_complete_suffix .yaml; __fish_complete_suffix .yml; __fish_complete_suffix .json)'
+complete -c robogo -n '__robogo_at init' -a '(__fish_complete_directories)'
+complete -c robogo -n '__robogo_at actions' -a 'list describe search'
+complete -c robogo -n '__robogo_at actions describe' -a '(__robogo_candidates actions)'
+complete -c robogo -n '__robogo_at actions list' -a '(__robogo_candidates categories)'
+complete -c robogo -n '__robogo_at report' -a 'diff'
+complete -c robogo -n '__fish_seen_subcommand_from diff' -a '(__fish_complete_suffix .json)'
+complete -c robogo -n '__robogo_at completion' -a 'bash zsh fish'
+{{FLAG_COMPLETIONS}}
+complete -c robogo -l verbosity -x -a 'quiet normal verbose debug'
+complete -c robogo -l format -x -a 'text json'
+`
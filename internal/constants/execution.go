@@ -21,6 +21,7 @@ const (
 	OperatorContains           = "contains"
 	OperatorStartsWith         = "starts_with"
 	OperatorEndsWith           = "ends_with"
+	OperatorOrdered            = "ordered"
 )
 
 // HTTP operations supported
@@ -41,6 +42,9 @@ const (
 	OperationInsert  = "insert"
 	OperationUpdate  = "update"
 	OperationDelete  = "delete"
+
+	OperationRowExists = "row_exists"
+	OperationCount     = "count"
 )
 
 // Messaging operation constants
@@ -0,0 +1,221 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/JianLoong/robogo/internal/common"
+	"github.com/JianLoong/robogo/internal/types"
+)
+
+// maxDumpValueSize caps each dumped value so large responses don't bloat CI artifacts
+const maxDumpValueSize = 4096
+
+// unsafeFileChars matches characters that are not safe in dump file names
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// debugDump is the on-disk layout of a post-mortem dump for one test case
+type debugDump struct {
+	TestCase      string                      `json:"test_case"`
+	Status        string                      `json:"status"`
+	Duration      string                      `json:"duration"`
+	CreatedAt     time.Time                   `json:"created_at"`
+	Error         string                      `json:"error,omitempty"`
+	SkipInfo      *types.SkipInfo             `json:"skip_info,omitempty"`
+	Variables     map[string]any              `json:"variables"`
+	VariableTypes map[string]dumpVariableType `json:"variable_types,omitempty"` // declared variables only
+	SetupSteps    []dumpStep                  `json:"setup_steps,omitempty"`
+	Steps         []dumpStep                  `json:"steps"`
+	TeardownSteps []dumpStep                  `json:"teardown_steps,omitempty"`
+	ActionMetrics []types.ActionStats         `json:"action_metrics,omitempty"`
+}
+
+// dumpVariableType compares the declared type of a variable with the type of its value
+type dumpVariableType struct {
+	Declared string `json:"declared"`
+	Actual   string `json:"actual"`
+}
+
+// dumpStep is a step result with its data capped and masked
+type dumpStep struct {
+	Name        string             `json:"name"`
+	ID          string             `json:"id,omitempty"`
+	Action      string             `json:"action"`
+	Status      string             `json:"status"`
+	Duration    string             `json:"duration"`
+	ErrorInfo   *types.ErrorInfo   `json:"error_info,omitempty"`
+	FailureInfo *types.FailureInfo `json:"failure_info,omitempty"`
+	SkipInfo    *types.SkipInfo    `json:"skip_info,omitempty"`
+	ControlFlow *types.ControlFlow `json:"control_flow,omitempty"`
+	Data        any                `json:"data,omitempty"`
+	DataSize    int                `json:"data_size,omitempty"` // size of truncated or discarded data
+	DataHash    string             `json:"data_hash,omitempty"`
+	Truncated   bool               `json:"data_truncated,omitempty"`
+	Discarded   bool               `json:"data_discarded,omitempty"`
+	Steps       []dumpStep         `json:"steps,omitempty"`         // steps of a called test case
+	Finally     []dumpStep         `json:"finally_steps,omitempty"` // finally steps of a block
+	Artifacts   []types.Artifact   `json:"artifacts,omitempty"`
+}
+
+// writeDebugDump writes the final variables and step results of a test case to the
+// artifact store and attaches the dump to the test case's artifacts. The secrets are
+// masked by value in the variables, as mask_output already did in the step results.
+// Returns the path of the written file.
+func writeDebugDump(store *artifactStore, result *types.TestResult, variables *common.Variables, secrets []string) (string, error) {
+	dump := debugDump{
+		TestCase:      result.Name,
+		Status:        result.Status,
+		Duration:      result.Duration.String(),
+		CreatedAt:     time.Now(),
+		Error:         result.GetMessage(),
+		SkipInfo:      result.SkipInfo,
+		Variables:     make(map[string]any),
+		SetupSteps:    toDumpSteps(result.SetupSteps),
+		Steps:         toDumpSteps(result.Steps),
+		TeardownSteps: toDumpSteps(result.TeardownSteps),
+		ActionMetrics: result.ActionMetrics,
+	}
+
+	for key, value := range variables.GetSnapshot() {
+		if common.IsSensitiveKey(key, common.SensitiveKeys()) {
+			dump.Variables[key] = "***"
+			continue
+		}
+		dump.Variables[key] = common.MaskSecretValuesIn(dumpValue(value), secrets)
+	}
+	for name, declaration := range variables.Declarations() {
+		if dump.VariableTypes == nil {
+			dump.VariableTypes = make(map[string]dumpVariableType)
+		}
+		actual := "unset"
+		if variables.Has(name) {
+			actual = common.ValueTypeName(variables.Get(name))
+		}
+		dump.VariableTypes[name] = dumpVariableType{Declared: declaration.String(), Actual: actual}
+	}
+
+	data, err := json.MarshalIndent(dump, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal debug dump: %w", err)
+	}
+
+	artifact := types.Artifact{
+		Name:        time.Now().Format("20060102-150405") + ".json",
+		ContentType: "application/json",
+		Content:     data,
+		Size:        int64(len(data)),
+	}
+	store.save(result.Name, &artifact, nil)
+	result.Artifacts = append(result.Artifacts, artifact)
+	if artifact.Skipped != "" {
+		return "", fmt.Errorf("failed to write debug dump: %s", artifact.Skipped)
+	}
+	return artifact.Path, nil
+}
+
+// toDumpSteps converts step results into their dump representation
+func toDumpSteps(steps []types.StepResult) []dumpStep {
+	dumped := make([]dumpStep, 0, len(steps))
+	for _, step := range steps {
+		dumped = append(dumped, dumpStep{
+			Name:        step.Name,
+			ID:          step.ID,
+			Action:      step.Action,
+			Status:      string(step.Result.Status),
+			Duration:    step.Duration.String(),
+			ErrorInfo:   step.Result.ErrorInfo,
+			FailureInfo: step.Result.FailureInfo,
+			SkipInfo:    step.Result.SkipInfo,
+			ControlFlow: step.ControlFlow,
+			Data:        dumpValue(step.Result.Data),
+			DataSize:    step.DataSize,
+			DataHash:    step.DataHash,
+			Truncated:   step.DataTruncated,
+			Discarded:   step.DataDiscarded,
+			Steps:       toDumpSteps(step.Steps),
+			Finally:     toDumpSteps(step.FinallySteps),
+			Artifacts:   step.Artifacts,
+		})
+	}
+	return dumped
+}
+
+// dumpValue masks and size-caps a value for the dump.
+// Values within the cap stay structured; larger values become a truncated string.
+func dumpValue(value any) any {
+	if value == nil {
+		return nil
+	}
+
+	masked := maskDumpValue(normalizeDumpValue(value))
+	encoded, err := json.Marshal(masked)
+	if err != nil {
+		encoded = []byte(fmt.Sprintf("%v", masked))
+	}
+
+	if len(encoded) > maxDumpValueSize {
+		return fmt.Sprintf("%s...[truncated %d bytes]", encoded[:maxDumpValueSize], len(encoded)-maxDumpValueSize)
+	}
+	return masked
+}
+
+// normalizeDumpValue converts a value into JSON-compatible maps, slices and scalars
+func normalizeDumpValue(value any) any {
+	switch val := value.(type) {
+	case map[any]any:
+		// YAML-loaded variables may use non-string keys, which encoding/json rejects
+		normalized := make(map[string]any, len(val))
+		for key, item := range val {
+			normalized[fmt.Sprintf("%v", key)] = normalizeDumpValue(item)
+		}
+		return normalized
+	case map[string]any:
+		normalized := make(map[string]any, len(val))
+		for key, item := range val {
+			normalized[key] = normalizeDumpValue(item)
+		}
+		return normalized
+	case []any:
+		normalized := make([]any, len(val))
+		for i, item := range val {
+			normalized[i] = normalizeDumpValue(item)
+		}
+		return normalized
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	var normalized any
+	if err := json.Unmarshal(encoded, &normalized); err != nil {
+		return string(encoded)
+	}
+	return normalized
+}
+
+// maskDumpValue masks sensitive keys and credentials embedded in strings
+func maskDumpValue(value any) any {
+	switch val := value.(type) {
+	case string:
+		return common.MaskSensitiveData(common.MaskCredentials(val), common.SensitiveKeys())
+	case map[string]any:
+		for key, item := range val {
+			if common.IsSensitiveKey(key, common.SensitiveKeys()) {
+				val[key] = "***"
+			} else {
+				val[key] = maskDumpValue(item)
+			}
+		}
+		return val
+	case []any:
+		for i, item := range val {
+			val[i] = maskDumpValue(item)
+		}
+		return val
+	default:
+		return val
+	}
+}
@@ -0,0 +1,403 @@
+package internal
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/JianLoong/robogo/internal/actions"
+	"github.com/JianLoong/robogo/internal/common"
+	"github.com/JianLoong/robogo/internal/execution"
+	"github.com/JianLoong/robogo/internal/types"
+)
+
+// debugPrompt is printed before each debug command is read
+const debugPrompt = "(robogo) "
+
+// debugHelp lists the commands of a debug session
+const debugHelp = `Commands:
+  next, n, <enter>   run the next step
+  continue, c        run the remaining steps
+  run-to <step>      run up to, not including, a step given by name, id or list number
+  inspect, i [path]  print all variables, or one such as response.body.items[0]
+  eval, e <expr>     substitute variables in an expression and evaluate it as a condition
+  rerun, r           reload the file from disk and run the last step again
+  list, l            list the steps and their results
+  quit, q            run the teardown steps that have not run, then exit`
+
+// debugEntry is one top-level step of the debugged test case, in run order
+type debugEntry struct {
+	phase  string // setup, steps or teardown
+	index  int    // position within the phase
+	step   types.Step
+	result *types.StepResult // set once the step has run
+}
+
+// debugSession steps through a test case with the runner's normal execution strategies
+type debugSession struct {
+	runner    *TestRunner
+	filename  string
+	caseIndex int // position of the test case in its file, to reload it
+	entries   []debugEntry
+	next      int // entry run by the next step command
+	last      int // entry run most recently, -1 before the first
+	input     *bufio.Scanner
+	echo      bool // print commands read from a script
+}
+
+// runDebugger runs `robogo debug`: the first test case of filename that matches --filter,
+// one step at a time. Commands come from the terminal, or from --debug-script.
+func runDebugger(ctx context.Context, filename string, args ParsedArgs) {
+	var input io.Reader = os.Stdin
+	echo := false
+	if args.debugScript != "" {
+		script, err := os.Open(args.debugScript)
+		if err != nil {
+			fmt.Printf("Error: debug script: %v\n", err)
+			os.Exit(ExitUsageError)
+		}
+		defer script.Close()
+		input, echo = script, true
+	} else if !isTerminal(os.Stdin) {
+		fmt.Println("Error: debug needs an interactive terminal; pass --debug-script <file> to read commands from a file")
+		os.Exit(ExitUsageError)
+	}
+
+	testCases, err := LoadTestCases(filename)
+	if err != nil {
+		fmt.Printf("Error: failed to parse test file: %v\n", err)
+		os.Exit(ExitUsageError)
+	}
+	filter, err := NewTestCaseFilter(args.filters)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(ExitUsageError)
+	}
+	caseIndex := -1
+	for i, testCase := range testCases {
+		if filter.Matches(testCase.Name) {
+			caseIndex = i
+			break
+		}
+	}
+	if caseIndex < 0 {
+		fmt.Printf("Error: no test cases match --filter %s\n", strings.Join(args.filters, ", "))
+		os.Exit(ExitUsageError)
+	}
+	testCase := testCases[caseIndex]
+
+	runner := NewTestRunner()
+	runner.SetStrict(args.strict)
+	runner.SetMaxDataBytes(args.maxDataBytes)
+	if args.verbosity != "" {
+		verbosity, _ := execution.ParseVerbosity(args.verbosity) // validated in RunCLI
+		runner.SetVerbosity(verbosity)
+	}
+	if args.pluginsFile != "" {
+		if err := runner.LoadPlugins(args.pluginsFile); err != nil {
+			fmt.Printf("Error: plugin configuration: %v\n", err)
+			os.Exit(ExitUsageError)
+		}
+	}
+	if _, _, err := runner.prepare(ctx, filename, testCase); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(ExitUsageError)
+	}
+
+	session := &debugSession{
+		runner:    runner,
+		filename:  filename,
+		caseIndex: caseIndex,
+		entries:   debugEntries(testCase),
+		last:      -1,
+		input:     bufio.NewScanner(input),
+		echo:      echo,
+	}
+	runner.printTestHeader(testCase)
+	fmt.Println("Debugging; type help for commands.")
+	session.list()
+	defer actions.CloseSQLConnections()
+	session.loop()
+}
+
+// isTerminal reports whether f is an interactive terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	// The null device is a character device too, e.g. the stdin of many CI jobs
+	if null, err := os.Stat(os.DevNull); err == nil && os.SameFile(info, null) {
+		return false
+	}
+	return true
+}
+
+// debugEntries lists the setup, main and teardown steps of a test case in run order
+func debugEntries(testCase *types.TestCase) []debugEntry {
+	var entries []debugEntry
+	for _, phase := range []struct {
+		name  string
+		steps []types.Step
+	}{{"setup", testCase.Setup}, {"steps", testCase.Steps}, {"teardown", testCase.Teardown}} {
+		for i, step := range phase.steps {
+			entries = append(entries, debugEntry{phase: phase.name, index: i, step: step})
+		}
+	}
+	return entries
+}
+
+// loop reads and runs commands until quit, the end of the input or the last step
+func (s *debugSession) loop() {
+	for {
+		if s.next >= len(s.entries) {
+			s.finish()
+			return
+		}
+		fmt.Print(debugPrompt)
+		if !s.input.Scan() {
+			fmt.Println()
+			s.quit()
+			return
+		}
+		line := strings.TrimSpace(s.input.Text())
+		if s.echo {
+			fmt.Println(line)
+		}
+		command, argument, _ := strings.Cut(line, " ")
+		argument = strings.TrimSpace(argument)
+
+		switch command {
+		case "", "next", "n":
+			s.runNext()
+		case "continue", "c":
+			for s.next < len(s.entries) {
+				s.runNext()
+			}
+		case "run-to":
+			s.runTo(argument)
+		case "inspect", "i":
+			s.inspect(argument)
+		case "eval", "e":
+			s.eval(argument)
+		case "rerun", "r":
+			s.rerun()
+		case "list", "l":
+			s.list()
+		case "help", "h", "?":
+			fmt.Println(debugHelp)
+		case "quit", "q", "exit":
+			s.quit()
+			return
+		default:
+			fmt.Printf("Unknown command '%s'; type help for commands\n", command)
+		}
+	}
+}
+
+// runNext runs the next step
+func (s *debugSession) runNext() {
+	s.run(s.next)
+	s.next++
+}
+
+// run runs one entry with the runner's execution strategies and records its result
+func (s *debugSession) run(i int) {
+	entry := &s.entries[i]
+	if entry.phase != "steps" {
+		fmt.Printf("[%s] ", strings.ToUpper(entry.phase))
+	}
+	entry.result = s.runner.executeStep(entry.step, entry.index+1)
+	s.last = i
+
+	if entry.phase == "steps" && entry.result != nil && s.runner.anyStepFailedOrErrored([]types.StepResult{*entry.result}) && !entry.step.Continue {
+		fmt.Println("⚠️  Step failed; a normal run would stop here and run teardown")
+	}
+}
+
+// runTo runs steps up to, not including, the step named by target
+func (s *debugSession) runTo(target string) {
+	if target == "" {
+		fmt.Println("Usage: run-to <step name, id or list number>")
+		return
+	}
+	i, found := s.find(target)
+	if !found {
+		fmt.Printf("No step '%s'; use list to see the steps\n", target)
+		return
+	}
+	if i < s.next {
+		fmt.Printf("Step '%s' has already run; use rerun to run the last step again\n", target)
+		return
+	}
+	for s.next < i {
+		s.runNext()
+	}
+}
+
+// find returns the entry with the given list number, id or name, searching from the next step
+func (s *debugSession) find(target string) (int, bool) {
+	if number, err := strconv.Atoi(target); err == nil {
+		return number - 1, number >= 1 && number <= len(s.entries)
+	}
+	for _, from := range []int{s.next, 0} {
+		for i := from; i < len(s.entries); i++ {
+			if s.entries[i].step.ID == target || s.entries[i].step.Name == target {
+				return i, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// inspect prints all variables or the value at a dot notation path, masking secrets
+func (s *debugSession) inspect(path string) {
+	if path == "" {
+		snapshot := s.runner.variables.GetSnapshot()
+		masked := make(map[string]any, len(snapshot))
+		for key, value := range snapshot {
+			masked[key] = debugMaskedValue(key, value)
+		}
+		printDebugValue(masked)
+		return
+	}
+
+	path = strings.TrimSuffix(strings.TrimPrefix(path, "${"), "}")
+	value, found := s.runner.variables.Lookup(path)
+	if !found {
+		fmt.Printf("%s is not set\n", path)
+		return
+	}
+	for _, part := range strings.FieldsFunc(path, func(r rune) bool { return r == '.' || r == '[' }) {
+		if common.IsSensitiveKey(part, common.SensitiveKeys()) {
+			fmt.Println("***")
+			return
+		}
+	}
+	printDebugValue(debugMaskedValue("", value))
+}
+
+// debugMaskedValue masks a variable value like the debug dump does
+func debugMaskedValue(key string, value any) any {
+	if key != "" && common.IsSensitiveKey(key, common.SensitiveKeys()) {
+		return "***"
+	}
+	return maskDumpValue(normalizeDumpValue(value))
+}
+
+// printDebugValue prints a value as indented JSON, or as is when it is a string
+func printDebugValue(value any) {
+	if str, ok := value.(string); ok {
+		fmt.Println(str)
+		return
+	}
+	encoded, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		fmt.Printf("%v\n", value)
+		return
+	}
+	fmt.Println(string(encoded))
+}
+
+// eval prints an expression with variables substituted and its value as a condition.
+// Values of variables with sensitive names are masked in the output.
+func (s *debugSession) eval(expression string) {
+	if expression == "" {
+		fmt.Println("Usage: eval <expression>, e.g. eval ${status} == 200")
+		return
+	}
+	substituted := s.runner.variables.Substitute(expression)
+	for key, value := range s.runner.variables.GetSnapshot() {
+		secret := fmt.Sprintf("%v", value)
+		if value != nil && secret != "" && common.IsSensitiveKey(key, common.SensitiveKeys()) {
+			substituted = strings.ReplaceAll(substituted, secret, "***")
+		}
+	}
+	fmt.Printf("value: %s\n", common.MaskSensitiveData(common.MaskCredentials(substituted), common.SensitiveKeys()))
+
+	result, err := execution.NewBasicConditionEvaluator(s.runner.variables).Evaluate(expression)
+	if err != nil {
+		fmt.Printf("condition: %v\n", err)
+		return
+	}
+	fmt.Printf("condition: %t\n", result)
+}
+
+// rerun reloads the test file and runs the last step again. Variables keep their
+// current values; later steps also run as they are now written.
+func (s *debugSession) rerun() {
+	if s.last < 0 {
+		fmt.Println("No step has run yet")
+		return
+	}
+	testCases, err := LoadTestCases(s.filename)
+	if err != nil {
+		fmt.Printf("Error: failed to reload %s: %v\n", s.filename, err)
+		return
+	}
+	if s.caseIndex >= len(testCases) {
+		fmt.Printf("Error: %s no longer has the debugged test case\n", s.filename)
+		return
+	}
+
+	entries := debugEntries(testCases[s.caseIndex])
+	for i := range entries {
+		if i < len(s.entries) && entries[i].phase == s.entries[i].phase {
+			entries[i].result = s.entries[i].result
+		}
+	}
+	if s.last >= len(entries) || entries[s.last].phase != s.entries[s.last].phase {
+		fmt.Println("Error: the last step no longer exists in the reloaded file")
+		return
+	}
+	s.entries = entries
+	fmt.Printf("Reloaded %s\n", s.filename)
+	s.run(s.last)
+}
+
+// list prints every step with its list number, result and the next step marked
+func (s *debugSession) list() {
+	for i, entry := range s.entries {
+		marker := "  "
+		if i == s.next {
+			marker = "=>"
+		}
+		status := ""
+		if entry.result != nil {
+			status = fmt.Sprintf(" [%s]", entry.result.Result.Status)
+		}
+		name := entry.step.Name
+		if entry.step.ID != "" {
+			name += " (" + entry.step.ID + ")"
+		}
+		fmt.Printf("%s %2d. %-8s %s%s\n", marker, i+1, entry.phase, name, status)
+	}
+}
+
+// quit runs the teardown steps that have not run yet, so resources are cleaned up
+func (s *debugSession) quit() {
+	for s.next < len(s.entries) {
+		if s.entries[s.next].phase != "teardown" {
+			s.next++
+			continue
+		}
+		s.runNext()
+	}
+	s.finish()
+}
+
+// finish prints the status of the steps that ran
+func (s *debugSession) finish() {
+	var results []types.StepResult
+	for _, entry := range s.entries {
+		if entry.result != nil && entry.phase == "steps" {
+			results = append(results, *entry.result)
+		}
+	}
+	fmt.Printf("\nDebug session finished: %d main step(s) run, status %s\n", len(results), s.runner.aggregateStatus(results))
+}
@@ -0,0 +1,77 @@
+package internal
+
+import "github.com/JianLoong/robogo/internal/types"
+
+// allActionsDefaults is the defaults key whose options apply to steps of every action
+const allActionsDefaults = "all"
+
+// applyStepDefaults merges the test case's defaults: options into every step with an
+// action, including setup, teardown, nested and finally steps. Options of the action's
+// own defaults win over defaults.all, and the step's own options win over both.
+func applyStepDefaults(testCase *types.TestCase) {
+	if len(testCase.Defaults) == 0 {
+		return
+	}
+	for _, steps := range [][]types.Step{testCase.Setup, testCase.Steps, testCase.Teardown} {
+		applyDefaultsToSteps(steps, testCase.Defaults)
+	}
+}
+
+// applyTestCaseNoLog enables no_log on every step that does not set it, including
+// setup, teardown, nested and finally steps, when the test case sets no_log
+func applyTestCaseNoLog(testCase *types.TestCase) {
+	if !testCase.NoLog {
+		return
+	}
+	for _, steps := range [][]types.Step{testCase.Setup, testCase.Steps, testCase.Teardown} {
+		applyNoLogToSteps(steps)
+	}
+}
+
+// applyNoLogToSteps enables no_log in place on steps that leave it unset
+func applyNoLogToSteps(steps []types.Step) {
+	for i := range steps {
+		step := &steps[i]
+		if step.NoLog == nil {
+			noLog := true
+			step.NoLog = &noLog
+		}
+		applyNoLogToSteps(step.Steps)
+		applyNoLogToSteps(step.Finally)
+	}
+}
+
+// applyDefaultsToSteps merges defaults into steps in place, recursing into nested steps
+func applyDefaultsToSteps(steps []types.Step, defaults map[string]types.StepDefaults) {
+	for i := range steps {
+		step := &steps[i]
+		if step.Action != "" {
+			options := mergeDefaultOptions(defaults[allActionsDefaults].Options, defaults[step.Action].Options)
+			step.Options = mergeDefaultOptions(options, step.Options)
+		}
+		applyDefaultsToSteps(step.Steps, defaults)
+		applyDefaultsToSteps(step.Finally, defaults)
+	}
+}
+
+// mergeDefaultOptions returns defaults overlaid with options. Map values such as
+// headers are merged key by key, so a step can add one header and keep the others.
+func mergeDefaultOptions(defaults, options map[string]any) map[string]any {
+	if len(defaults) == 0 {
+		return options
+	}
+	merged := make(map[string]any, len(defaults)+len(options))
+	for key, value := range defaults {
+		merged[key] = value
+	}
+	for key, value := range options {
+		defaultMap, defaultIsMap := merged[key].(map[string]any)
+		valueMap, valueIsMap := value.(map[string]any)
+		if defaultIsMap && valueIsMap {
+			merged[key] = mergeDefaultOptions(defaultMap, valueMap)
+			continue
+		}
+		merged[key] = value
+	}
+	return merged
+}
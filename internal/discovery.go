@@ -0,0 +1,168 @@
+package internal
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultDiscoveryPattern matches every test file format LoadTestCases reads
+const defaultDiscoveryPattern = "**/*.{yaml,yml,json}"
+
+// DiscoverTestFiles resolves a run target to test files in sorted order.
+// A file is returned as is. A directory is searched for files matching pattern,
+// relative to the directory; a glob target is matched against paths itself.
+// Files found by searching are kept only if they look like test cases, so plugin
+// manifests and other YAML next to the tests are skipped.
+func DiscoverTestFiles(target, pattern string) ([]string, error) {
+	root := target
+	if isGlobPattern(target) {
+		// Search from the longest directory prefix without wildcards
+		root, pattern = splitGlobRoot(target)
+	} else {
+		info, err := os.Stat(target)
+		if err != nil {
+			return nil, fmt.Errorf("test path not found: %w", err)
+		}
+		if !info.IsDir() {
+			return []string{target}, nil
+		}
+	}
+	if pattern == "" {
+		pattern = defaultDiscoveryPattern
+	}
+
+	matcher, err := globToRegexp(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	err = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			// Skip hidden directories such as .git
+			if path != root && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		relative, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if matcher.MatchString(filepath.ToSlash(relative)) && looksLikeTestFile(path) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search %s: %w", root, err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no test files found in %s matching %s", root, pattern)
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// looksLikeTestFile reports whether a file defines a test case, i.e. has a top-level testcase key.
+// Files that do not parse are kept so the run reports them as failures.
+func looksLikeTestFile(path string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return true
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var document yaml.Node
+		if err := decoder.Decode(&document); err != nil {
+			// Stop at the end of the file; a syntax error means it may be a broken test
+			return !errors.Is(err, io.EOF)
+		}
+		if len(document.Content) > 0 && mappingValue(document.Content[0], "testcase") != nil {
+			return true
+		}
+	}
+}
+
+// isGlobPattern reports whether a path contains glob wildcards
+func isGlobPattern(path string) bool {
+	return strings.ContainsAny(path, "*?[{")
+}
+
+// splitGlobRoot splits a glob into the directory to search and the pattern below it
+func splitGlobRoot(glob string) (string, string) {
+	parts := strings.Split(filepath.ToSlash(glob), "/")
+	for i, part := range parts {
+		if isGlobPattern(part) {
+			root := strings.Join(parts[:i], "/")
+			if root == "" {
+				root = "."
+			}
+			return filepath.FromSlash(root), strings.Join(parts[i:], "/")
+		}
+	}
+	return glob, ""
+}
+
+// globToRegexp converts a glob to a regular expression over slash-separated paths.
+// "*" and "?" stay within one path segment, "**" spans directories, [a-z] matches a character
+// class ([!a-z] negates it) and {a,b} lists alternatives.
+func globToRegexp(glob string) (*regexp.Regexp, error) {
+	var builder strings.Builder
+	builder.WriteString("^")
+	inAlternatives := false
+	for i := 0; i < len(glob); i++ {
+		char := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			builder.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			builder.WriteString(".*")
+			i++
+		case char == '*':
+			builder.WriteString("[^/]*")
+		case char == '?':
+			builder.WriteString("[^/]")
+		case char == '[' && strings.Contains(glob[i:], "]"):
+			end := i + strings.Index(glob[i:], "]")
+			class := strings.TrimPrefix(glob[i+1:end], "!")
+			if len(class) < end-i-1 {
+				class = "^" + class
+			}
+			builder.WriteString("[" + class + "]")
+			i = end
+		case char == '{' && !inAlternatives:
+			builder.WriteString("(?:")
+			inAlternatives = true
+		case char == '}' && inAlternatives:
+			builder.WriteString(")")
+			inAlternatives = false
+		case char == ',' && inAlternatives:
+			builder.WriteString("|")
+		default:
+			builder.WriteString(regexp.QuoteMeta(string(char)))
+		}
+	}
+	builder.WriteString("$")
+
+	matcher, err := regexp.Compile(builder.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", glob, err)
+	}
+	return matcher, nil
+}
@@ -0,0 +1,76 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/JianLoong/robogo/internal/types"
+)
+
+// EnvironmentEnvVar names the active environment when --environment is not given
+const EnvironmentEnvVar = "ROBOGO_ENVIRONMENT"
+
+// KnownEnvironmentsEnvVar lists the environments test cases may name, comma separated.
+// When set, only_on and not_on entries outside the list are reported as likely typos.
+const KnownEnvironmentsEnvVar = "ROBOGO_ENVIRONMENTS"
+
+// environmentSkipReason returns why a test case does not apply to the active environment,
+// or "" when it runs. A case limited with only_on does not run when no environment is set.
+func environmentSkipReason(testCase *types.TestCase, environment string) string {
+	if len(testCase.OnlyOn) > 0 {
+		if environment == "" {
+			return fmt.Sprintf("only runs on %s; no environment set", strings.Join(testCase.OnlyOn, ", "))
+		}
+		if !containsEnvironment(testCase.OnlyOn, environment) {
+			return fmt.Sprintf("not applicable in %s", environment)
+		}
+	}
+	if environment != "" && containsEnvironment(testCase.NotOn, environment) {
+		return fmt.Sprintf("not applicable in %s", environment)
+	}
+	return ""
+}
+
+// warnUnknownEnvironments prints a warning for every environment that is not in
+// ROBOGO_ENVIRONMENTS, so a misspelt "prdo" does not quietly gate nothing
+func warnUnknownEnvironments(planned []plannedTestCase, environment string) {
+	known := knownEnvironments()
+	if len(known) == 0 {
+		return
+	}
+	if environment != "" && !containsEnvironment(known, environment) {
+		fmt.Printf("[WARN] Environment '%s' is not listed in %s\n", environment, KnownEnvironmentsEnvVar)
+	}
+	for _, next := range planned {
+		if next.testCase == nil {
+			continue
+		}
+		for _, name := range append(append([]string{}, next.testCase.OnlyOn...), next.testCase.NotOn...) {
+			if !containsEnvironment(known, name) {
+				fmt.Printf("[WARN] %s (%s): environment '%s' is not listed in %s\n", next.testCase.Name, next.filename, name, KnownEnvironmentsEnvVar)
+			}
+		}
+	}
+}
+
+// knownEnvironments reads the declared environments from ROBOGO_ENVIRONMENTS
+func knownEnvironments() []string {
+	var known []string
+	for _, name := range strings.Split(os.Getenv(KnownEnvironmentsEnvVar), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			known = append(known, name)
+		}
+	}
+	return known
+}
+
+// containsEnvironment reports whether names includes environment, ignoring case
+func containsEnvironment(names []string, environment string) bool {
+	for _, name := range names {
+		if strings.EqualFold(strings.TrimSpace(name), environment) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,152 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/JianLoong/robogo/internal/common"
+	"github.com/JianLoong/robogo/internal/types"
+)
+
+// driverSecret is the password a database driver echoes in its connection errors
+const driverSecret = "Sup3rS3cretPw"
+
+// driverError is an error from a database driver that quotes the DSN it was given,
+// as some drivers do when a connection fails
+type driverError struct {
+	dsn string
+}
+
+func (e *driverError) Error() string {
+	return fmt.Sprintf("cannot connect using %s: connection refused", e.dsn)
+}
+
+// failedDatabaseResult returns a test result whose step failed with a driver error,
+// wrapped twice as actions and connection pools do, for both DSN forms
+func failedDatabaseResult() *types.TestResult {
+	urlErr := fmt.Errorf("open pool: %w", fmt.Errorf("ping: %w",
+		&driverError{dsn: "postgres://app:" + driverSecret + "@db:5432/orders"}))
+	keywordErr := fmt.Errorf("open pool: %w",
+		&driverError{dsn: "host=db user=app password=" + driverSecret + " dbname=orders"})
+
+	connect := types.NewErrorBuilder(types.ErrorCategoryDatabase, "POSTGRES_CONNECTION_FAILED").
+		WithTemplate("Failed to connect to database: %v").
+		WithContext("connection", "postgres://app:"+driverSecret+"@db:5432/orders").
+		WithContext("cause", urlErr).
+		Build(urlErr)
+	query := types.NewFailureBuilder(types.FailureCategoryValidation, "ROW_COUNT_MISMATCH").
+		WithTemplate("Query failed after reconnecting: %v").
+		WithContext("cause", keywordErr).
+		Build(keywordErr)
+
+	return &types.TestResult{
+		Name:      "orders",
+		Status:    "ERROR",
+		ErrorInfo: connect.ErrorInfo,
+		Steps: []types.StepResult{
+			{Name: "connect", Action: "postgres", Result: connect, IncludeSummary: true},
+			{Name: "count orders", Action: "postgres", Result: query, IncludeSummary: true},
+		},
+	}
+}
+
+// assertMasked fails when an output surface holds the driver secret or no mask at all
+func assertMasked(t *testing.T, surface, output string) {
+	t.Helper()
+	if strings.Contains(output, driverSecret) {
+		t.Errorf("%s leaks the secret:\n%s", surface, output)
+	}
+	if !strings.Contains(output, "***") {
+		t.Errorf("%s holds no masked value:\n%s", surface, output)
+	}
+}
+
+func TestWrappedDriverErrorMaskedOnEveryOutputSurface(t *testing.T) {
+	result := failedDatabaseResult()
+
+	t.Run("step results", func(t *testing.T) {
+		for _, step := range result.Steps {
+			assertMasked(t, step.Name, step.Result.GetMessage())
+			encoded, err := json.Marshal(step)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertMasked(t, step.Name+" as JSON", string(encoded))
+		}
+	})
+
+	t.Run("console summary", func(t *testing.T) {
+		assertMasked(t, "test summary", captureStdout(t, func() { printTestSummary(result) }))
+	})
+
+	t.Run("debug dump", func(t *testing.T) {
+		variables := common.NewVariables()
+		variables.Set("db_url", "postgres://app:"+driverSecret+"@db:5432/orders")
+		store := newArtifactStore(t.TempDir(), 0)
+		path, err := writeDebugDump(store, result, variables, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertMasked(t, "debug dump", readFile(t, path))
+	})
+
+	t.Run("error report", func(t *testing.T) {
+		collector := NewErrorReportCollector(-1)
+		collector.Collect(result)
+		path := filepath.Join(t.TempDir(), "errors.json")
+		if err := collector.Write(path); err != nil {
+			t.Fatal(err)
+		}
+		assertMasked(t, "error report", readFile(t, path))
+	})
+
+	t.Run("run report", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "report.json")
+		planned := []plannedTestCase{{filename: "orders.yaml"}}
+		if err := writeRunReport(path, "orders.yaml", "", planned, []*types.TestResult{result}); err != nil {
+			t.Fatal(err)
+		}
+		assertMasked(t, "run report", readFile(t, path))
+	})
+
+	t.Run("sentry", func(t *testing.T) {
+		var mu sync.Mutex
+		var events []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			mu.Lock()
+			events = append(events, string(body))
+			mu.Unlock()
+		}))
+		defer server.Close()
+
+		exporter, err := NewSentryExporter(strings.Replace(server.URL, "://", "://public@", 1) + "/1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		exporter.ExportFailures("orders.yaml", result)
+		if len(events) != len(result.Steps) {
+			t.Fatalf("got %d Sentry events, want %d", len(events), len(result.Steps))
+		}
+		for i, event := range events {
+			assertMasked(t, fmt.Sprintf("Sentry event %d", i+1), event)
+		}
+	})
+}
+
+func TestWrappedDriverErrorKeepsCause(t *testing.T) {
+	// Masking the message must not lose what went wrong
+	message := failedDatabaseResult().Steps[0].Result.GetMessage()
+	for _, want := range []string{"connection refused", "db:5432/orders", "open pool"} {
+		if !strings.Contains(message, want) {
+			t.Errorf("message lost %q:\n%s", want, message)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/JianLoong/robogo/internal/common"
+	"github.com/JianLoong/robogo/internal/types"
+)
+
+// defaultErrorReportSamples is how many occurrences are kept in full per error group
+const defaultErrorReportSamples = 5
+
+// errorReport is the on-disk layout of the --error-report file
+type errorReport struct {
+	CreatedAt   time.Time          `json:"created_at"`
+	TotalIssues int                `json:"total_issues"`
+	TestCases   []string           `json:"test_cases"`
+	Groups      []errorReportGroup `json:"groups"`
+}
+
+// errorReportGroup aggregates every occurrence of one error or failure code
+type errorReportGroup struct {
+	Kind      string              `json:"kind"`
+	Category  string              `json:"category"`
+	Code      string              `json:"code"`
+	Count     int                 `json:"count"`
+	FirstSeen time.Time           `json:"first_seen"`
+	LastSeen  time.Time           `json:"last_seen"`
+	TestCases []string            `json:"test_cases"`
+	Samples   []errorReportSample `json:"samples"`
+}
+
+// errorReportSample is a single occurrence with its full, masked message
+type errorReportSample struct {
+	TestCase  string    `json:"test_case"`
+	Phase     string    `json:"phase"`
+	Step      string    `json:"step"`
+	StepID    string    `json:"step_id,omitempty"`
+	Action    string    `json:"action"`
+	Status    string    `json:"status"`
+	Timestamp time.Time `json:"timestamp"`
+	Message   string    `json:"message"`
+}
+
+// ErrorReportCollector groups errors and failures from one or more test results
+type ErrorReportCollector struct {
+	maxSamples int
+	groups     map[string]*errorReportGroup
+	testCases  []string
+	total      int
+}
+
+// NewErrorReportCollector creates a collector keeping up to maxSamples occurrences per group
+func NewErrorReportCollector(maxSamples int) *ErrorReportCollector {
+	if maxSamples < 0 {
+		maxSamples = defaultErrorReportSamples
+	}
+	return &ErrorReportCollector{
+		maxSamples: maxSamples,
+		groups:     make(map[string]*errorReportGroup),
+	}
+}
+
+// Collect adds every failed or errored step of a test result to the report
+func (c *ErrorReportCollector) Collect(result *types.TestResult) {
+	c.testCases = append(c.testCases, result.Name)
+
+	phases := []struct {
+		name  string
+		steps []types.StepResult
+	}{
+		{"setup", result.SetupSteps},
+		{"steps", result.Steps},
+		{"teardown", result.TeardownSteps},
+	}
+
+	for _, phase := range phases {
+		for _, step := range phase.steps {
+			if step.Result.HasIssue() {
+				c.add(result.Name, phase.name, step)
+			}
+		}
+	}
+}
+
+// add records a single step occurrence in its group
+func (c *ErrorReportCollector) add(testCase, phase string, step types.StepResult) {
+	kind, category, code := "error", "", ""
+	timestamp := time.Now()
+
+	if info := step.Result.ErrorInfo; info != nil {
+		category, code, timestamp = string(info.Category), info.Code, info.Timestamp
+	} else if info := step.Result.FailureInfo; info != nil {
+		kind = "failure"
+		category, code, timestamp = string(info.Category), info.Code, info.Timestamp
+	}
+
+	key := kind + "/" + category + "/" + code
+	group, exists := c.groups[key]
+	if !exists {
+		group = &errorReportGroup{
+			Kind:      kind,
+			Category:  category,
+			Code:      code,
+			FirstSeen: timestamp,
+			LastSeen:  timestamp,
+		}
+		c.groups[key] = group
+	}
+
+	group.Count++
+	c.total++
+	if timestamp.Before(group.FirstSeen) {
+		group.FirstSeen = timestamp
+	}
+	if timestamp.After(group.LastSeen) {
+		group.LastSeen = timestamp
+	}
+	if !containsString(group.TestCases, testCase) {
+		group.TestCases = append(group.TestCases, testCase)
+	}
+
+	if len(group.Samples) < c.maxSamples {
+		// Messages are masked when built; mask again since the report leaves the machine
+		message := common.MaskSensitiveData(common.MaskCredentials(step.Result.GetMessage()), common.SensitiveKeys())
+		group.Samples = append(group.Samples, errorReportSample{
+			TestCase:  testCase,
+			Phase:     phase,
+			Step:      step.Name,
+			StepID:    step.ID,
+			Action:    step.Action,
+			Status:    string(step.Result.Status),
+			Timestamp: timestamp,
+			Message:   message,
+		})
+	}
+}
+
+// Write saves the report as JSON, most frequent groups first
+func (c *ErrorReportCollector) Write(path string) error {
+	report := errorReport{
+		CreatedAt:   time.Now(),
+		TotalIssues: c.total,
+		TestCases:   c.testCases,
+		Groups:      make([]errorReportGroup, 0, len(c.groups)),
+	}
+	for _, group := range c.groups {
+		report.Groups = append(report.Groups, *group)
+	}
+	sort.Slice(report.Groups, func(i, j int) bool {
+		if report.Groups[i].Count != report.Groups[j].Count {
+			return report.Groups[i].Count > report.Groups[j].Count
+		}
+		return report.Groups[i].FirstSeen.Before(report.Groups[j].FirstSeen)
+	})
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal error report: %w", err)
+	}
+
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create error report directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write error report: %w", err)
+	}
+	return nil
+}
+
+// containsString reports whether list contains value
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,112 @@
+package execution
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/JianLoong/robogo/internal/constants"
+	"github.com/JianLoong/robogo/internal/types"
+)
+
+// MetricsCollector records how long each action type takes and how often it fails.
+// Every action execution is recorded once, including each retry attempt and repeat iteration.
+type MetricsCollector struct {
+	testCase string
+	mu       sync.Mutex // held only to append, so concurrent steps barely contend
+	timings  []types.ActionTiming
+}
+
+// NewMetricsCollector creates an empty collector for the named test case
+func NewMetricsCollector(testCase string) *MetricsCollector {
+	return &MetricsCollector{testCase: testCase}
+}
+
+// Record adds one execution of an action by a step
+func (m *MetricsCollector) Record(action, step string, start time.Time, duration time.Duration, status constants.ActionStatus) {
+	timing := types.ActionTiming{
+		TestCase: m.testCase,
+		Step:     step,
+		Action:   action,
+		Start:    start,
+		Duration: duration,
+		Status:   string(status),
+	}
+	m.mu.Lock()
+	m.timings = append(m.timings, timing)
+	m.mu.Unlock()
+}
+
+// Timings returns every recorded execution in the order it finished
+func (m *MetricsCollector) Timings() []types.ActionTiming {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]types.ActionTiming(nil), m.timings...)
+}
+
+// Summary returns per-action statistics, slowest total first
+func (m *MetricsCollector) Summary() []types.ActionStats {
+	return SummarizeTimings(m.Timings())
+}
+
+// SummarizeTimings groups executions by action, slowest total first.
+// Used per test case and across the test cases of a run.
+func SummarizeTimings(timings []types.ActionTiming) []types.ActionStats {
+	byAction := make(map[string][]types.ActionTiming)
+	for _, timing := range timings {
+		byAction[timing.Action] = append(byAction[timing.Action], timing)
+	}
+
+	stats := make([]types.ActionStats, 0, len(byAction))
+	for action, executions := range byAction {
+		sort.SliceStable(executions, func(i, j int) bool { return executions[i].Duration < executions[j].Duration })
+
+		var total time.Duration
+		failures := 0
+		for _, execution := range executions {
+			total += execution.Duration
+			if execution.Status == string(constants.ActionStatusFailed) || execution.Status == string(constants.ActionStatusError) {
+				failures++
+			}
+		}
+		count := len(executions)
+		slowest := executions[count-1]
+
+		stats = append(stats, types.ActionStats{
+			Action:          action,
+			Count:           count,
+			Failures:        failures,
+			FailureRate:     float64(failures) / float64(count),
+			TotalDuration:   total,
+			AverageDuration: total / time.Duration(count),
+			P50Duration:     executions[nearestRank(count, 50)].Duration,
+			P95Duration:     executions[nearestRank(count, 95)].Duration,
+			MaxDuration:     slowest.Duration,
+			MaxStep:         slowest.Step,
+			MaxTestCase:     slowest.TestCase,
+		})
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].TotalDuration != stats[j].TotalDuration {
+			return stats[i].TotalDuration > stats[j].TotalDuration
+		}
+		return stats[i].Action < stats[j].Action
+	})
+	return stats
+}
+
+// SlowestTimings returns up to limit executions, slowest first
+func SlowestTimings(timings []types.ActionTiming, limit int) []types.ActionTiming {
+	sorted := append([]types.ActionTiming(nil), timings...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Duration > sorted[j].Duration })
+	if len(sorted) > limit {
+		sorted = sorted[:limit]
+	}
+	return sorted
+}
+
+// nearestRank returns the index of a percentile in a sorted list of count items
+func nearestRank(count, percentile int) int {
+	return (count*percentile+99)/100 - 1
+}
@@ -1,149 +1,321 @@
-package execution
-
-import (
-	"fmt"
-	"time"
-
-	"github.com/JianLoong/robogo/internal/actions"
-	"github.com/JianLoong/robogo/internal/common"
-	"github.com/JianLoong/robogo/internal/constants"
-	"github.com/JianLoong/robogo/internal/templates"
-	"github.com/JianLoong/robogo/internal/types"
-)
-
-// BasicExecutionStrategy handles simple action execution without any control flow
-type BasicExecutionStrategy struct {
-	variables      *common.Variables
-	actionRegistry *actions.ActionRegistry
-}
-
-// NewBasicExecutionStrategy creates a new basic execution strategy
-func NewBasicExecutionStrategy(variables *common.Variables, actionRegistry *actions.ActionRegistry) *BasicExecutionStrategy {
-	return &BasicExecutionStrategy{
-		variables:      variables,
-		actionRegistry: actionRegistry,
-	}
-}
-
-// Execute performs basic action execution directly
-func (s *BasicExecutionStrategy) Execute(step types.Step, stepNum int, loopCtx *types.LoopContext) *types.StepResult {
-	start := time.Now()
-
-	// Determine if step should be included in summary (default: true)
-	includeSummary := true
-	if step.Summary != nil {
-		includeSummary = *step.Summary
-	}
-
-	result := &types.StepResult{
-		Name:           step.Name,
-		Action:         step.Action,
-		Result:         types.ActionResult{Status: constants.ActionStatusError},
-		IncludeSummary: includeSummary,
-	}
-
-	// Get action from registry
-	action, exists := s.actionRegistry.Get(step.Action)
-	if !exists {
-		errorResult := types.NewErrorBuilder(types.ErrorCategoryValidation, "UNKNOWN_ACTION").
-			WithTemplate(templates.GetTemplateConstant(constants.TemplateUnknownAction)).
-			WithContext("action", step.Action).
-			WithContext("step", step.Name).
-			Build(step.Action)
-		
-		result.Result = errorResult
-		result.Duration = time.Since(start)
-		return result
-	}
-
-	// Substitute variables in arguments
-	args := s.variables.SubstituteArgs(step.Args)
-
-	// Substitute variables in options
-	options := make(map[string]any)
-	for k, v := range step.Options {
-		if str, ok := v.(string); ok {
-			options[k] = s.variables.Substitute(str)
-		} else {
-			options[k] = v
-		}
-	}
-	
-	// Pass security information to actions for security-aware behavior
-	if step.NoLog {
-		options["__no_log"] = true
-	}
-	if len(step.SensitiveFields) > 0 {
-		// Convert []string to []any for options interface
-		sensitiveFieldsAny := make([]any, len(step.SensitiveFields))
-		for i, field := range step.SensitiveFields {
-			sensitiveFieldsAny[i] = field
-		}
-		options["sensitive_fields"] = sensitiveFieldsAny
-	}
-
-	// Print step execution details (unless no_log is enabled)
-	if !step.NoLog {
-		// Apply masking using step-level sensitive fields
-		maskedArgs := s.getMaskedArgsForPrinting(step.Action, args, step.SensitiveFields)
-		s.printStepExecution(step, stepNum, maskedArgs, options)
-	} else {
-		// For no_log steps, print minimal info without sensitive details
-		fmt.Printf("Step %d: %s [no_log enabled]\n", stepNum, step.Name)
-		fmt.Printf("  Action: %s\n", step.Action)
-		fmt.Println("  Executing... ")
-	}
-
-	// Execute action directly
-	output := action(args, options, s.variables)
-	result.Duration = time.Since(start)
-	result.Result = output
-
-	// Print execution result (unless no_log is enabled)
-	if !step.NoLog {
-		s.printStepResult(output, result.Duration)
-	} else {
-		// For no_log steps, print only status and duration, no sensitive data
-		s.printSecureStepResult(output, result.Duration)
-	}
-
-	// Apply extraction if specified and action was successful
-	var finalData any = output.Data
-	if step.Extract != nil && output.Status == constants.ActionStatusPassed {
-		extractedData, err := s.applyExtraction(output.Data, step.Extract)
-		if err != nil {
-			errorResult := types.NewErrorBuilder(types.ErrorCategoryExecution, "EXTRACTION_FAILED").
-				WithTemplate("Failed to extract data: %s").
-				WithContext("extraction_type", step.Extract.Type).
-				WithContext("extraction_path", step.Extract.Path).
-				WithContext("error", err.Error()).
-				Build(err)
-			result.Result = errorResult
-			return result
-		}
-		finalData = extractedData
-		result.Result.Data = finalData
-	}
-
-	// Store result variable if specified and action was successful
-	if step.Result != "" && (output.Status == constants.ActionStatusPassed || finalData != nil) {
-		s.variables.Set(step.Result, finalData)
-	}
-
-	return result
-}
-
-// CanHandle returns true for steps that have an action and no control flow
-func (s *BasicExecutionStrategy) CanHandle(step types.Step) bool {
-	return step.Action != "" && 
-		step.Retry == nil && 
-		step.If == "" && 
-		step.For == "" && 
-		step.While == "" &&
-		len(step.Steps) == 0
-}
-
-// Priority returns low priority as this is the fallback strategy
-func (s *BasicExecutionStrategy) Priority() int {
-	return 1
+package execution
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/JianLoong/robogo/internal/actions"
+	"github.com/JianLoong/robogo/internal/common"
+	"github.com/JianLoong/robogo/internal/constants"
+	"github.com/JianLoong/robogo/internal/templates"
+	"github.com/JianLoong/robogo/internal/types"
+)
+
+// BasicExecutionStrategy handles simple action execution without any control flow
+type BasicExecutionStrategy struct {
+	variables      *common.Variables
+	actionRegistry *actions.ActionRegistry
+	circuitBreaker *CircuitBreaker
+	metrics        *MetricsCollector
+	verbosity      Verbosity
+	maxDataBytes   int // default bytes of result data kept per step, 0 for DefaultMaxDataBytes
+	idempotencyKey string // pinned by RetryExecutionStrategy for every attempt of a step
+	maskOutput     bool   // mask secret values anywhere in output, see SetMaskOutput
+	suiteDir       string // directory of the running test file, see SetSuiteDir
+}
+
+// NewBasicExecutionStrategy creates a new basic execution strategy
+func NewBasicExecutionStrategy(variables *common.Variables, actionRegistry *actions.ActionRegistry) *BasicExecutionStrategy {
+	return &BasicExecutionStrategy{
+		variables:      variables,
+		actionRegistry: actionRegistry,
+		verbosity:      VerbosityNormal,
+	}
+}
+
+// SetVerbosity sets how much step output is printed
+func (s *BasicExecutionStrategy) SetVerbosity(verbosity Verbosity) {
+	s.verbosity = verbosity
+}
+
+// SetCircuitBreaker enables fail-fast handling for repeatedly failing dependencies (nil disables it)
+func (s *BasicExecutionStrategy) SetCircuitBreaker(breaker *CircuitBreaker) {
+	s.circuitBreaker = breaker
+}
+
+// SetMetricsCollector records the duration and outcome of every action executed; nil disables it
+func (s *BasicExecutionStrategy) SetMetricsCollector(metrics *MetricsCollector) {
+	s.metrics = metrics
+}
+
+// SetSuiteDir sets the directory of the running test file, where file actions may read
+// and write besides the run directory and the directory of the file declaring the step
+func (s *BasicExecutionStrategy) SetSuiteDir(dir string) {
+	s.suiteDir = dir
+}
+
+// Execute performs basic action execution directly
+func (s *BasicExecutionStrategy) Execute(step types.Step, stepNum int, loopCtx *types.LoopContext) *types.StepResult {
+	start := time.Now()
+
+	// Determine if step should be included in summary (default: true)
+	includeSummary := true
+	if step.Summary != nil {
+		includeSummary = *step.Summary
+	}
+
+	result := &types.StepResult{
+		Name:           step.Name,
+		Action:         step.Action,
+		Result:         types.ActionResult{Status: constants.ActionStatusError},
+		IncludeSummary: includeSummary,
+	}
+
+	// Get action from registry
+	action, exists := s.actionRegistry.Get(step.Action)
+	if !exists {
+		builder := types.NewErrorBuilder(types.ErrorCategoryValidation, "UNKNOWN_ACTION").
+			WithTemplate(templates.GetTemplateConstant(constants.TemplateUnknownAction)).
+			WithContext("action", step.Action).
+			WithContext("step", step.Name)
+		if step.ID != "" {
+			builder = builder.WithContext("step_id", step.ID)
+		}
+		if suggestions := s.actionRegistry.GetActionCompletions(step.Action); len(suggestions) > 0 {
+			builder = builder.WithSuggestion(fmt.Sprintf("Did you mean: %s", strings.Join(suggestions, ", ")))
+		}
+		errorResult := builder.Build(step.Action)
+		
+		result.Result = errorResult
+		result.Duration = time.Since(start)
+		return result
+	}
+
+	// Recorded on return so extraction failures count against the action
+	if s.metrics != nil {
+		defer func() {
+			if result.Cached {
+				return
+			}
+			s.metrics.Record(step.Action, step.Name, start, result.Duration, result.Result.Status)
+		}()
+	}
+
+	// ${step.idempotency_key} and ${unique()} resolve to this step's values until the action returns;
+	// the deferred call restores the variable if the action panics
+	restoreStepVariable := s.setStepVariable()
+	defer restoreStepVariable()
+
+	// The step's env is resolved first, so a value can extend ${ENV:PATH}, and then seen by
+	// its arguments, options and action until the action returns
+	env := s.substituteStepEnv(step.Env)
+	restoreEnv := s.variables.SetStepEnv(env)
+	defer restoreEnv()
+
+	// Substitute variables in arguments
+	args := s.variables.SubstituteArgs(step.Args)
+
+	// Substitute variables in options
+	options := make(map[string]any)
+	for k, v := range step.Options {
+		if str, ok := v.(string); ok {
+			options[k] = s.variables.Substitute(str)
+		} else {
+			options[k] = v
+		}
+	}
+	
+	outputSecrets := s.outputSecrets()
+
+	// Pass security information to actions for security-aware behavior
+	if step.LogSuppressed() {
+		options["__no_log"] = true
+	}
+	if len(step.SensitiveFields) > 0 {
+		// Convert []string to []any for options interface
+		sensitiveFieldsAny := make([]any, len(step.SensitiveFields))
+		for i, field := range step.SensitiveFields {
+			sensitiveFieldsAny[i] = field
+		}
+		options["sensitive_fields"] = sensitiveFieldsAny
+	}
+
+	// Print step execution details (unless no_log is enabled); quiet prints failed steps afterwards
+	if s.verbosity == VerbosityQuiet {
+		// Nothing before the step runs
+	} else if step.Sensitive && !step.LogSuppressed() {
+		s.printStepExecution(step, stepNum, maskComparisonArgs(args), nil, nil)
+	} else if !step.LogSuppressed() {
+		// Apply masking using step-level sensitive fields
+		sensitiveFields := s.sensitiveFieldsFor(step)
+		maskedArgs := common.MaskSecretValuesIn(s.getMaskedArgsForPrinting(step.Action, args, sensitiveFields), outputSecrets).([]any)
+		maskedOptions := common.MaskSecretValuesIn(s.getMaskedOptionsForPrinting(options, sensitiveFields), outputSecrets).(map[string]any)
+		var templateArgs []any
+		if s.verbosity >= VerbosityDebug {
+			templateArgs = s.getMaskedArgsForPrinting(step.Action, step.Args, sensitiveFields)
+		}
+		s.printStepExecution(step, stepNum, maskedArgs, templateArgs, maskedOptions)
+		if len(env) > 0 {
+			fmt.Printf("  Env: %v\n", common.MaskSecretValuesIn(maskedStepEnv(env, sensitiveFields), outputSecrets))
+		}
+	} else {
+		s.printNoLogStepHeader(step, stepNum)
+	}
+
+	// Let the action extract while reading its response; set after printing since it is internal
+	if streamsExtraction(step) {
+		options[actions.StreamExtractOption] = step.Extract.Path
+	}
+	if step.Sensitive {
+		options[actions.SensitiveAssertOption] = true
+	}
+	if s.maskOutput {
+		options[actions.MaskOutputOption] = true
+	}
+	// Relative paths resolve against the file that declares the step
+	if step.Source != "" {
+		options[actions.BaseDirOption] = filepath.Dir(step.Source)
+	}
+	if s.suiteDir != "" {
+		options[actions.SuiteDirOption] = s.suiteDir
+	}
+
+	// Execute action directly, unless its result is cached or the dependency's circuit is open
+	var output types.ActionResult
+	cacheKey, cacheTTL := s.cacheSettings(step, args, options)
+	if cacheKey != "" {
+		output, result.Cached = lookupCachedResult(cacheKey)
+	}
+	breakerKey := ""
+	if s.circuitBreaker != nil && !result.Cached {
+		breakerKey = circuitKey(step.Action, args)
+	}
+	if result.Cached {
+		if s.verbosity != VerbosityQuiet {
+			fmt.Println("  [Cache] Served from cache")
+		}
+	} else if openResult, allowed := s.allowByCircuit(breakerKey); !allowed {
+		output = openResult
+	} else {
+		output = action(args, options, s.variables)
+		if breakerKey != "" {
+			s.circuitBreaker.Record(breakerKey, step.Name, output)
+		}
+		if cacheKey != "" {
+			storeCachedResult(cacheKey, output, cacheTTL)
+		}
+	}
+	restoreStepVariable()
+	restoreEnv()
+	result.Duration = time.Since(start)
+
+	// Mask step-level sensitive fields in error context before it is printed or reported
+	secrets := append(s.declaredSecretValues(step.Action, args), sensitiveEnvValues(env, s.sensitiveFieldsFor(step))...)
+	secrets = append(secrets, s.outputSecrets()...)
+	s.maskResultMessages(&output, s.sensitiveFieldsFor(step), secrets)
+	collectArtifacts(step, &output, result, secrets)
+	result.Result = output
+
+	// Only a limited preview is printed and kept; extraction reads the full data
+	s.retainData(step, result)
+	s.maskResultValues(result, secrets)
+	printed := output
+	printed.Data, printed.Meta = result.Result.Data, result.Result.Meta
+
+	// Print execution result (unless no_log is enabled)
+	if s.verbosity == VerbosityQuiet {
+		if output.Status == constants.ActionStatusFailed || output.Status == constants.ActionStatusError {
+			s.printFailedStep(step, stepNum, printed, result.Duration)
+		}
+	} else if step.Sensitive && !step.LogSuppressed() {
+		s.printSensitiveAssertResult(printed, result.Duration)
+	} else if !step.LogSuppressed() {
+		s.printStepResult(printed, result.Duration)
+	} else {
+		// For no_log steps, print only status and duration, no sensitive data
+		s.printSecureStepResult(printed, result.Duration)
+	}
+
+	// Keep the HTTP status for retry_on, since extraction replaces the response
+	if data, ok := output.Data.(map[string]any); ok {
+		if statusCode, ok := data["status_code"].(int); ok {
+			result.StatusCode = statusCode
+		}
+	}
+
+	// Apply extraction if specified and action was successful
+	var finalData any = output.Data
+	if step.Extract != nil && output.Status == constants.ActionStatusPassed {
+		var extractedData any
+		var err error
+		if streamsExtraction(step) {
+			extractedData, err = streamedExtraction(output.Data)
+		} else {
+			extractedData, err = s.applyExtraction(output.Data, step.Extract)
+		}
+		if err != nil {
+			errorResult := types.NewErrorBuilder(types.ErrorCategoryExecution, "EXTRACTION_FAILED").
+				WithTemplate("Failed to extract data: %s").
+				WithContext("extraction_type", step.Extract.Type).
+				WithContext("extraction_path", step.Extract.Path).
+				WithContext("error", err.Error()).
+				Build(err)
+			result.Result = errorResult
+			return result
+		}
+		finalData = extractedData
+		result.Result.Data = finalData
+		s.retainData(step, result)
+		s.maskResultValues(result, secrets)
+	}
+
+	// Store result variable if specified and action was successful
+	if step.Result != "" && (output.Status == constants.ActionStatusPassed || finalData != nil) {
+		if err := s.variables.Assign(step.Result, finalData); err != nil {
+			result.Result = resultTypeError(step.Result, err)
+		}
+	}
+	if err := s.variables.SecretFindings(); err != nil && result.Result.Status == constants.ActionStatusPassed {
+		result.Result = secretInVariableError(err)
+	}
+
+	return result
+}
+
+// cacheSettings returns the result cache key and ttl of a step with a cache option, or
+// an empty key when the call is not read-only, such as an http POST, and always runs
+func (s *BasicExecutionStrategy) cacheSettings(step types.Step, args []any, options map[string]any) (string, time.Duration) {
+	if step.Cache == nil {
+		return "", 0
+	}
+	meta, _ := s.actionRegistry.Describe(step.Action)
+	if meta.ReadOnly == nil || !meta.ReadOnly(args) {
+		if s.verbosity != VerbosityQuiet {
+			fmt.Println("  [Cache] Not cached: only read-only calls, such as http GET and postgres SELECT, are cached")
+		}
+		return "", 0
+	}
+	ttl, err := step.Cache.Lifetime()
+	if err != nil {
+		return "", 0
+	}
+	return resultCacheKey(step.Action, s.variables.Substitute(step.Cache.Key), args, options), ttl
+}
+
+// CanHandle returns true for steps that have an action and no control flow
+func (s *BasicExecutionStrategy) CanHandle(step types.Step) bool {
+	return step.Action != "" && 
+		step.Retry == nil && 
+		step.If == "" && 
+		step.For == "" && 
+		step.While == "" &&
+		step.Repeat == 0 &&
+		len(step.Steps) == 0
+}
+
+// Priority returns low priority as this is the fallback strategy
+func (s *BasicExecutionStrategy) Priority() int {
+	return 1
 }
\ No newline at end of file
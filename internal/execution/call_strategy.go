@@ -0,0 +1,62 @@
+package execution
+
+import (
+	"github.com/JianLoong/robogo/internal/common"
+	"github.com/JianLoong/robogo/internal/constants"
+	"github.com/JianLoong/robogo/internal/types"
+)
+
+// CallFunc runs the test case file named by a call step with the given inputs.
+// It is provided by the test runner, since a called test case runs with a runner of its own.
+type CallFunc func(step types.Step, stepNum int, inputs map[string]any) *types.StepResult
+
+// CallExecutionStrategy handles steps that call another test case file
+type CallExecutionStrategy struct {
+	variables *common.Variables
+	call      CallFunc
+}
+
+// NewCallExecutionStrategy creates a new call execution strategy
+func NewCallExecutionStrategy(variables *common.Variables, call CallFunc) *CallExecutionStrategy {
+	return &CallExecutionStrategy{
+		variables: variables,
+		call:      call,
+	}
+}
+
+// Execute runs the called test case and stores its outputs in the step's result variable
+func (s *CallExecutionStrategy) Execute(step types.Step, stepNum int, loopCtx *types.LoopContext) *types.StepResult {
+	// with: values are substituted in the caller's scope
+	inputs := map[string]any{}
+	if len(step.With) > 0 {
+		inputs, _ = s.variables.SubstituteArgs([]any{step.With})[0].(map[string]any)
+	}
+
+	result := s.call(step, stepNum, inputs)
+	if result != nil && step.Result != "" && result.Result.Status == constants.ActionStatusPassed {
+		if err := s.variables.Assign(step.Result, result.Result.Data); err != nil {
+			result.Result = resultTypeError(step.Result, err)
+		}
+	}
+	return result
+}
+
+// resultTypeError fails a step whose result does not match the declared type of its
+// result variable
+func resultTypeError(variable string, err error) types.ActionResult {
+	return types.NewErrorBuilder(types.ErrorCategoryValidation, "RESULT_TYPE_MISMATCH").
+		WithTemplate("Result does not match the declared type: %v").
+		WithContext("result", variable).
+		WithSuggestion("Extract the expected value with extract:, or change the declaration in variables.types").
+		Build(err)
+}
+
+// CanHandle returns true for steps that call another test case
+func (s *CallExecutionStrategy) CanHandle(step types.Step) bool {
+	return step.Call != ""
+}
+
+// Priority returns medium priority; call steps never have an action or nested steps
+func (s *CallExecutionStrategy) Priority() int {
+	return 2
+}
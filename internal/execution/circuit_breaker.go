@@ -0,0 +1,165 @@
+package execution
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/JianLoong/robogo/internal/common"
+	"github.com/JianLoong/robogo/internal/types"
+)
+
+// Circuit breaker defaults used when the test case omits them
+const (
+	defaultCircuitThreshold = 3
+	defaultCircuitCooldown  = 30 * time.Second
+)
+
+// circuitState tracks consecutive failures for one dependency
+type circuitState struct {
+	failures  int
+	lastError string
+	open      bool
+	halfOpen  bool
+	openedAt  time.Time
+}
+
+// CircuitBreaker fails steps fast when a dependency (action + endpoint) keeps failing.
+// Only network and database errors count; assertion and validation problems never trip it.
+type CircuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	circuits  map[string]*circuitState
+	events    []string
+}
+
+// NewCircuitBreaker creates a circuit breaker from the test case configuration
+func NewCircuitBreaker(config *types.CircuitBreakerConfig) (*CircuitBreaker, error) {
+	breaker := &CircuitBreaker{
+		threshold: defaultCircuitThreshold,
+		cooldown:  defaultCircuitCooldown,
+		circuits:  make(map[string]*circuitState),
+	}
+
+	if config.Threshold > 0 {
+		breaker.threshold = config.Threshold
+	}
+	if config.Cooldown != "" {
+		cooldown, err := time.ParseDuration(config.Cooldown)
+		if err != nil {
+			return nil, fmt.Errorf("invalid circuit_breaker cooldown '%s': %w", config.Cooldown, err)
+		}
+		breaker.cooldown = cooldown
+	}
+
+	return breaker, nil
+}
+
+// Allow checks whether a step against the dependency may run.
+// Returns a CIRCUIT_OPEN error result when the circuit is open and still cooling down.
+func (cb *CircuitBreaker) Allow(key string) (types.ActionResult, bool) {
+	state, exists := cb.circuits[key]
+	if !exists || !state.open {
+		return types.ActionResult{}, true
+	}
+
+	if time.Since(state.openedAt) >= cb.cooldown {
+		// Half-open: let one step through to probe the dependency
+		state.halfOpen = true
+		return types.ActionResult{}, true
+	}
+
+	return types.NewErrorBuilder(types.ErrorCategoryExecution, "CIRCUIT_OPEN").
+		WithTemplate("Circuit open for %s after %d consecutive failures, failing fast").
+		WithContext("original_error", state.lastError).
+		WithContext("retry_after", (cb.cooldown-time.Since(state.openedAt)).Round(time.Second).String()).
+		WithSuggestion("Check that the dependency is reachable; the circuit half-opens after the cooldown").
+		Build(key, state.failures), false
+}
+
+// Record updates the circuit for a dependency with the outcome of a step
+func (cb *CircuitBreaker) Record(key, stepName string, result types.ActionResult) {
+	state, exists := cb.circuits[key]
+	if !exists {
+		state = &circuitState{}
+		cb.circuits[key] = state
+	}
+
+	// Any other outcome means the dependency answered, so the circuit closes
+	if !isDependencyFailure(result) {
+		if state.open {
+			cb.events = append(cb.events, fmt.Sprintf("%s circuit closed after step '%s'", key, stepName))
+		}
+		*state = circuitState{}
+		return
+	}
+
+	state.failures++
+	state.lastError = firstLine(result.GetMessage())
+
+	if state.halfOpen || (!state.open && state.failures >= cb.threshold) {
+		if !state.open {
+			cb.events = append(cb.events, fmt.Sprintf("%s circuit opened after step '%s' (%d consecutive failures)", key, stepName, state.failures))
+		}
+		state.open = true
+		state.halfOpen = false
+		state.openedAt = time.Now()
+	}
+}
+
+// Events returns the open/close transitions in the order they happened
+func (cb *CircuitBreaker) Events() []string {
+	return cb.events
+}
+
+// isDependencyFailure reports whether a result indicates the external dependency is unhealthy
+func isDependencyFailure(result types.ActionResult) bool {
+	if !result.IsError() || result.ErrorInfo == nil {
+		return false
+	}
+	switch result.ErrorInfo.Category {
+	case types.ErrorCategoryNetwork, types.ErrorCategoryDatabase:
+		return true
+	}
+	return false
+}
+
+// circuitKey derives "<action> at <endpoint>" for actions that talk to external dependencies.
+// Returns an empty string for actions that are not tracked.
+func circuitKey(action string, args []any) string {
+	argIndex := -1
+	switch action {
+	case "http", "postgres", "spanner", "mongodb", "kafka", "rabbitmq":
+		argIndex = 1
+	case "sse":
+		argIndex = 0
+	}
+	if argIndex < 0 || len(args) <= argIndex {
+		return ""
+	}
+
+	endpoint := fmt.Sprintf("%v", args[argIndex])
+	if parsed, err := url.Parse(endpoint); err == nil && parsed.Host != "" {
+		endpoint = parsed.Host
+	} else {
+		endpoint = common.MaskCredentials(endpoint)
+	}
+	return fmt.Sprintf("%s at %s", action, endpoint)
+}
+
+// firstLine returns the headline of a multi-line error message
+func firstLine(message string) string {
+	if idx := strings.Index(message, "\n"); idx != -1 {
+		return message[:idx]
+	}
+	return message
+}
+
+// allowByCircuit checks the circuit for a dependency key; an empty key is always allowed
+func (s *BasicExecutionStrategy) allowByCircuit(key string) (types.ActionResult, bool) {
+	if key == "" || s.circuitBreaker == nil {
+		return types.ActionResult{}, true
+	}
+	return s.circuitBreaker.Allow(key)
+}
@@ -2,6 +2,7 @@ package execution
 
 import (
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -21,40 +22,100 @@ func NewBasicConditionEvaluator(variables *common.Variables) *BasicConditionEval
 }
 
 // Evaluate evaluates a condition string and returns true/false.
+// Conditions may combine comparisons with &&, ||, ! and parentheses;
+// malformed expressions return a *ConditionSyntaxError. Variables are
+// substituted per operand when a comparison is evaluated.
 func (evaluator *BasicConditionEvaluator) Evaluate(condition string) (bool, error) {
-	// Substitute variables first
-	condition = evaluator.variables.Substitute(condition)
-
-	// Handle simple boolean values
-	if condition == "true" {
-		return true, nil
+	expression, err := parseCondition(condition)
+	if err != nil {
+		return false, err
 	}
-	if condition == "false" {
-		return false, nil
+	return expression.eval(evaluator)
+}
+
+// conditionVariable matches a ${name} reference in a condition
+var conditionVariable = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// VariableValues returns what each variable referenced in a condition substitutes to,
+// and the names of the variables that are not set
+func (evaluator *BasicConditionEvaluator) VariableValues(condition string) (map[string]string, []string) {
+	values := make(map[string]string)
+	var unresolved []string
+	for _, match := range conditionVariable.FindAllStringSubmatch(condition, -1) {
+		name := match[1]
+		if _, seen := values[name]; seen {
+			continue
+		}
+		value := evaluator.variables.Substitute(match[0])
+		if strings.Contains(value, "__UNRESOLVED") {
+			unresolved = append(unresolved, name)
+			continue
+		}
+		values[name] = value
 	}
+	return values, unresolved
+}
 
-	// Handle comparison operators
+// evaluateLeaf evaluates a single comparison or truthy value, as written in the condition
+func (evaluator *BasicConditionEvaluator) evaluateLeaf(condition string) (bool, error) {
+	// Handle comparison operators outside quotes and ${...} references
 	operators := []string{">=", "<=", ">", "<", "==", "!=", "contains", "starts_with", "ends_with"}
 
 	for _, op := range operators {
-		if strings.Contains(condition, op) {
-			return evaluator.evaluateComparison(condition, op)
+		if index := operatorIndex(condition, op); index >= 0 {
+			return evaluator.evaluateComparison(condition[:index], condition[index+len(op):], op)
 		}
 	}
 
+	// Handle simple boolean values
+	value := evaluator.operand(condition)
+	if value == "true" {
+		return true, nil
+	}
+	if value == "false" {
+		return false, nil
+	}
+
 	// If no operators found, treat non-empty strings as true
-	return strings.TrimSpace(condition) != "" && strings.TrimSpace(condition) != "0", nil
+	return strings.TrimSpace(value) != "" && strings.TrimSpace(value) != "0", nil
+}
+
+// operatorIndex returns the position of operator in a leaf, skipping quoted text and
+// ${...} references, or -1 when it does not occur there
+func operatorIndex(condition, operator string) int {
+	var quote byte
+	for i := 0; i < len(condition); i++ {
+		ch := condition[i]
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case strings.HasPrefix(condition[i:], "${"):
+			i = variableEnd(condition, i) - 1
+		case ch == '\'' || ch == '"':
+			quote = ch
+		case strings.HasPrefix(condition[i:], operator):
+			return i
+		}
+	}
+	return -1
 }
 
-// evaluateComparison evaluates a comparison expression
-func (evaluator *BasicConditionEvaluator) evaluateComparison(condition, operator string) (bool, error) {
-	parts := strings.SplitN(condition, operator, 2)
-	if len(parts) != 2 {
-		return false, fmt.Errorf("invalid comparison: %s", condition)
+// operand returns the value of one side of a comparison: quotes are stripped from the
+// text as written and variables substituted after, so quotes in values are kept
+func (evaluator *BasicConditionEvaluator) operand(text string) string {
+	text = strings.TrimSpace(text)
+	if unquoted := unquote(text); unquoted != text {
+		return evaluator.variables.Substitute(unquoted)
 	}
+	return strings.TrimSpace(evaluator.variables.Substitute(text))
+}
 
-	left := strings.TrimSpace(parts[0])
-	right := strings.TrimSpace(parts[1])
+// evaluateComparison evaluates a comparison between the two sides of an operator
+func (evaluator *BasicConditionEvaluator) evaluateComparison(leftText, rightText, operator string) (bool, error) {
+	left := evaluator.operand(leftText)
+	right := evaluator.operand(rightText)
 
 	switch operator {
 	case "==":
@@ -74,6 +135,18 @@ func (evaluator *BasicConditionEvaluator) evaluateComparison(condition, operator
 	return false, fmt.Errorf("unsupported operator: %s", operator)
 }
 
+// unquote strips one pair of matching single or double quotes, so
+// "${role} == 'admin'" compares the bare values
+func unquote(value string) string {
+	if len(value) >= 2 {
+		first, last := value[0], value[len(value)-1]
+		if (first == '\'' || first == '"') && first == last {
+			return value[1 : len(value)-1]
+		}
+	}
+	return value
+}
+
 // compareNumeric compares two values numerically
 func (evaluator *BasicConditionEvaluator) compareNumeric(left, right, operator string) (bool, error) {
 	leftNum, err1 := strconv.ParseFloat(left, 64)
@@ -0,0 +1,239 @@
+package execution
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ConditionSyntaxError reports a malformed condition expression
+type ConditionSyntaxError struct {
+	Condition string
+	Position  int // 1-based column in the condition as written
+	Message   string
+}
+
+func (e *ConditionSyntaxError) Error() string {
+	return fmt.Sprintf("%s at position %d in condition '%s'", e.Message, e.Position, e.Condition)
+}
+
+// conditionNode is a parsed condition expression
+type conditionNode interface {
+	eval(evaluator *BasicConditionEvaluator) (bool, error)
+}
+
+// logicalNode joins two expressions with && or ||
+type logicalNode struct {
+	operator    string
+	left, right conditionNode
+}
+
+func (n *logicalNode) eval(evaluator *BasicConditionEvaluator) (bool, error) {
+	left, err := n.left.eval(evaluator)
+	if err != nil {
+		return false, err
+	}
+	// Short-circuit like most languages
+	if n.operator == "&&" && !left {
+		return false, nil
+	}
+	if n.operator == "||" && left {
+		return true, nil
+	}
+	return n.right.eval(evaluator)
+}
+
+// notNode negates an expression
+type notNode struct {
+	operand conditionNode
+}
+
+func (n *notNode) eval(evaluator *BasicConditionEvaluator) (bool, error) {
+	value, err := n.operand.eval(evaluator)
+	return !value, err
+}
+
+// leafNode is a single comparison or truthy value, e.g. "200 >= 200"
+type leafNode struct {
+	text string
+}
+
+func (n *leafNode) eval(evaluator *BasicConditionEvaluator) (bool, error) {
+	return evaluator.evaluateLeaf(n.text)
+}
+
+// conditionParser parses conditions with &&, ||, ! and parentheses.
+// Precedence from lowest to highest: ||, &&, !. Leaves keep the original
+// comparison syntax, so spaces inside values are allowed. Conditions are
+// parsed before substitution and ${...} references are single operands, so
+// a value such as "a || b" or "O'Brien" is never read as syntax.
+type conditionParser struct {
+	input string
+	pos   int
+}
+
+// parseCondition parses a condition string into an expression tree
+func parseCondition(condition string) (conditionNode, error) {
+	parser := &conditionParser{input: condition}
+	node, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	parser.skipSpaces()
+	if parser.pos < len(parser.input) {
+		return nil, parser.errorf("unexpected '%c'", parser.input[parser.pos])
+	}
+	return node, nil
+}
+
+func (p *conditionParser) parseOr() (conditionNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.consume("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{operator: "||", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parseAnd() (conditionNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.consume("&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{operator: "&&", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parseUnary() (conditionNode, error) {
+	p.skipSpaces()
+
+	// "!" negates, but "!=" belongs to a comparison
+	if strings.HasPrefix(p.input[p.pos:], "!") && !strings.HasPrefix(p.input[p.pos:], "!=") {
+		p.pos++
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{operand: operand}, nil
+	}
+
+	if strings.HasPrefix(p.input[p.pos:], "(") {
+		open := p.pos
+		p.pos++
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.consume(")") {
+			p.pos = open
+			return nil, p.errorf("unclosed '('")
+		}
+		return node, nil
+	}
+
+	return p.parseLeaf()
+}
+
+// parseLeaf reads comparison text up to the next &&, || or unmatched ')'.
+// Quoted text and balanced parentheses inside values are kept as-is.
+func (p *conditionParser) parseLeaf() (conditionNode, error) {
+	start := p.pos
+	depth := 0
+	var quote byte
+
+	for p.pos < len(p.input) {
+		ch := p.input[p.pos]
+		rest := p.input[p.pos:]
+
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case strings.HasPrefix(rest, "${"):
+			p.pos = variableEnd(p.input, p.pos)
+			continue
+		case ch == '\'' || ch == '"':
+			quote = ch
+		case ch == '(':
+			depth++
+		case ch == ')':
+			if depth == 0 {
+				return p.leafFrom(start)
+			}
+			depth--
+		case strings.HasPrefix(rest, "&&") || strings.HasPrefix(rest, "||"):
+			return p.leafFrom(start)
+		}
+		p.pos++
+	}
+
+	if quote != 0 {
+		return nil, p.errorf("unterminated %c quote", quote)
+	}
+	return p.leafFrom(start)
+}
+
+// variableEnd returns the position after the ${...} reference starting at start,
+// allowing nested references such as ${${env}_url}, or the end of the input when the
+// reference is not closed
+func variableEnd(input string, start int) int {
+	depth := 0
+	for i := start; i < len(input); i++ {
+		switch {
+		case strings.HasPrefix(input[i:], "${"):
+			depth++
+			i++
+		case input[i] == '}':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return len(input)
+}
+
+// leafFrom builds a leaf from start to the current position
+func (p *conditionParser) leafFrom(start int) (conditionNode, error) {
+	text := strings.TrimSpace(p.input[start:p.pos])
+	if text == "" {
+		return nil, p.errorf("expected a condition")
+	}
+	return &leafNode{text: text}, nil
+}
+
+// consume skips spaces and advances past token if it is next
+func (p *conditionParser) consume(token string) bool {
+	p.skipSpaces()
+	if strings.HasPrefix(p.input[p.pos:], token) {
+		p.pos += len(token)
+		return true
+	}
+	return false
+}
+
+func (p *conditionParser) skipSpaces() {
+	for p.pos < len(p.input) && (p.input[p.pos] == ' ' || p.input[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func (p *conditionParser) errorf(format string, args ...any) error {
+	return &ConditionSyntaxError{
+		Condition: p.input,
+		Position:  p.pos + 1,
+		Message:   fmt.Sprintf(format, args...),
+	}
+}
@@ -1,6 +1,12 @@
 package execution
 
 import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/JianLoong/robogo/internal/common"
 	"github.com/JianLoong/robogo/internal/types"
 )
 
@@ -26,28 +32,38 @@ func (s *ConditionalExecutionStrategy) Execute(step types.Step, stepNum int, loo
 		includeSummary = *step.Summary
 	}
 
-	// Evaluate condition
-	condition, err := s.conditionEvaluator.Evaluate(step.If)
-	if err != nil {
+	// Evaluate condition; a missing variable must not quietly skip the step
+	condition, values, failed := evaluateCondition(s.conditionEvaluator, step.If)
+	if failed != nil {
 		return &types.StepResult{
 			Name:           step.Name,
 			Action:         step.Action,
 			IncludeSummary: includeSummary,
-			Result: types.NewErrorBuilder(types.ErrorCategoryExecution, "CONDITION_EVALUATION_FAILED").
-				WithTemplate("Failed to evaluate condition: %s").
-				WithContext("condition", step.If).
-				WithContext("error", err.Error()).
-				Build(err),
+			Result:         *failed,
 		}
 	}
+
 	
 	// If condition is false, skip execution
 	if !condition {
+		reason := step.SkipReason
+		if reason == "" {
+			reason = fmt.Sprintf("condition '%s' evaluated to false%s", step.If, formatConditionValues(values))
+		}
+		result := types.NewSkippedResult(types.SkipCategoryConditional, reason)
+		result.SkipInfo.Condition = step.If
+		result.SkipInfo.Values = values
 		return &types.StepResult{
 			Name:           step.Name,
 			Action:         step.Action,
 			IncludeSummary: includeSummary,
-			Result:         types.ActionResult{Status: "SKIPPED"},
+			Result:         result,
+			ControlFlow: &types.ControlFlow{
+				Condition:      step.If,
+				ConditionValue: &condition,
+				Branch:         types.BranchSkipped,
+				Values:         s.recordedValues(values),
+			},
 		}
 	}
 	
@@ -56,7 +72,94 @@ func (s *ConditionalExecutionStrategy) Execute(step types.Step, stepNum int, loo
 	execStep.If = ""
 	
 	// Execute the step normally
-	return s.strategyRouter.Execute(execStep, stepNum, loopCtx)
+	result := s.strategyRouter.Execute(execStep, stepNum, loopCtx)
+	if result != nil {
+		flow := stepControlFlow(result)
+		flow.Condition = step.If
+		flow.ConditionValue = &condition
+		flow.Branch = types.BranchThen
+		flow.Values = s.recordedValues(values)
+	}
+	return result
+}
+
+// recordedValues returns the condition values kept in a result, with the values of
+// sensitive variables and secrets masked, since reports keep them after the run
+func (s *ConditionalExecutionStrategy) recordedValues(values map[string]string) map[string]string {
+	if len(values) == 0 {
+		return nil
+	}
+	variables := s.conditionEvaluator.variables
+	secrets := append(variables.SecretValues(), variables.DetectedSecretValues()...)
+	recorded := make(map[string]string, len(values))
+	for name, value := range values {
+		if common.IsSensitiveKey(name, common.SensitiveKeys()) {
+			value = "***"
+		}
+		recorded[name] = common.MaskSecretValues(value, secrets)
+	}
+	return recorded
+}
+
+// stepControlFlow returns the control-flow record of a result, adding one when it has
+// none, so an if around a repeat records both decisions on the same result
+func stepControlFlow(result *types.StepResult) *types.ControlFlow {
+	if result.ControlFlow == nil {
+		result.ControlFlow = &types.ControlFlow{}
+	}
+	return result.ControlFlow
+}
+
+// evaluateCondition evaluates a condition and returns the values of its variables. An
+// undefined variable, malformed syntax or a failed comparison returns an error result.
+func evaluateCondition(evaluator *BasicConditionEvaluator, expression string) (bool, map[string]string, *types.ActionResult) {
+	values, unresolved := evaluator.VariableValues(expression)
+	if len(unresolved) > 0 {
+		failed := types.NewErrorBuilder(types.ErrorCategoryVariable, "CONDITION_UNRESOLVED_VARIABLE").
+			WithTemplate("Condition uses undefined variable(s): %s").
+			WithContext("condition", expression).
+			WithSuggestion("Define the variable in variables.vars or set it in an earlier step").
+			Build(strings.Join(unresolved, ", "))
+		return false, values, &failed
+	}
+
+	condition, err := evaluator.Evaluate(expression)
+	var syntaxErr *ConditionSyntaxError
+	if errors.As(err, &syntaxErr) {
+		failed := types.NewErrorBuilder(types.ErrorCategoryValidation, "CONDITION_SYNTAX_ERROR").
+			WithTemplate("Invalid condition syntax: %s at position %d").
+			WithContext("condition", expression).
+			WithContext("position", syntaxErr.Position).
+			WithSuggestion("Combine comparisons with &&, || and !, and check that parentheses and quotes are balanced").
+			Build(syntaxErr.Message, syntaxErr.Position)
+		return false, values, &failed
+	}
+	if err != nil {
+		failed := types.NewErrorBuilder(types.ErrorCategoryExecution, "CONDITION_EVALUATION_FAILED").
+			WithTemplate("Failed to evaluate condition: %s").
+			WithContext("condition", expression).
+			WithContext("error", err.Error()).
+			Build(err)
+		return false, values, &failed
+	}
+	return condition, values, nil
+}
+
+// formatConditionValues lists variable values as " (name=value, ...)" in name order
+func formatConditionValues(values map[string]string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = fmt.Sprintf("%s=%s", name, values[name])
+	}
+	return " (" + strings.Join(pairs, ", ") + ")"
 }
 
 // CanHandle returns true for steps with if conditions
@@ -66,6 +169,6 @@ func (s *ConditionalExecutionStrategy) CanHandle(step types.Step) bool {
 
 // Priority returns highest priority as conditional logic is most specific
 func (s *ConditionalExecutionStrategy) Priority() int {
-	return 4
+	return 5
 }
 
@@ -0,0 +1,65 @@
+package execution
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/JianLoong/robogo/internal/types"
+)
+
+// DefaultMaxDataBytes is how much of a step's result data is kept, as printed, when
+// neither the step's max_data_bytes nor --max-data-bytes sets a limit
+const DefaultMaxDataBytes = 64 * 1024
+
+// SetMaxDataBytes sets how many bytes of result data steps keep by default; 0 restores
+// the default
+func (s *BasicExecutionStrategy) SetMaxDataBytes(limit int) {
+	s.maxDataBytes = limit
+}
+
+// dataLimitFor returns how many bytes of result data the step keeps
+func (s *BasicExecutionStrategy) dataLimitFor(step types.Step) int {
+	if step.MaxDataBytes > 0 {
+		return step.MaxDataBytes
+	}
+	if s.maxDataBytes > 0 {
+		return s.maxDataBytes
+	}
+	return DefaultMaxDataBytes
+}
+
+// retainData limits the result data kept in the step result. Data within the limit
+// keeps its type; larger data becomes a truncated preview, and discard_data drops it.
+// Either way the size and SHA-256 of the printed data are recorded. Extraction and
+// the result variable use the data before it is limited.
+func (s *BasicExecutionStrategy) retainData(step types.Step, result *types.StepResult) {
+	result.DataSize, result.DataHash = 0, ""
+	result.DataTruncated, result.DataDiscarded = false, false
+	if result.Result.Data == nil {
+		return
+	}
+
+	limit := s.dataLimitFor(step)
+	printable := fmt.Sprintf("%v", result.Result.Data)
+	if len(printable) <= limit && !step.DiscardData {
+		return
+	}
+
+	sum := sha256.Sum256([]byte(printable))
+	result.DataSize = len(printable)
+	result.DataHash = hex.EncodeToString(sum[:])
+	if step.DiscardData {
+		result.DataDiscarded = true
+		result.Result.Data = nil
+		return
+	}
+
+	// Cut on a character boundary
+	for limit > 0 && !utf8.RuneStart(printable[limit]) {
+		limit--
+	}
+	result.DataTruncated = true
+	result.Result.Data = fmt.Sprintf("%s...[truncated %d bytes]", printable[:limit], len(printable)-limit)
+}
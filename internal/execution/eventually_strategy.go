@@ -0,0 +1,135 @@
+package execution
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/JianLoong/robogo/internal/common"
+	"github.com/JianLoong/robogo/internal/types"
+)
+
+// EventuallyExecutionStrategy re-runs a step until it passes or its timeout ends, so a
+// check such as "eventually the status is COMPLETE" is one step instead of a while loop
+type EventuallyExecutionStrategy struct {
+	strategyRouter *ExecutionStrategyRouter
+	variables      *common.Variables
+}
+
+// NewEventuallyExecutionStrategy creates a new eventually execution strategy
+func NewEventuallyExecutionStrategy(variables *common.Variables, strategyRouter *ExecutionStrategyRouter) *EventuallyExecutionStrategy {
+	return &EventuallyExecutionStrategy{
+		strategyRouter: strategyRouter,
+		variables:      variables,
+	}
+}
+
+// Execute runs the step until it passes, waiting the interval between attempts. Every
+// attempt is routed again, so its arguments are substituted with the current variables.
+func (s *EventuallyExecutionStrategy) Execute(step types.Step, stepNum int, loopCtx *types.LoopContext) *types.StepResult {
+	start := time.Now()
+	timeout, interval, err := step.Eventually.Timing()
+	if err != nil {
+		return &types.StepResult{
+			Name:   step.Name,
+			Action: step.Action,
+			Result: types.NewErrorBuilder(types.ErrorCategoryValidation, "INVALID_EVENTUALLY").
+				WithTemplate("Invalid eventually settings: %v").
+				Build(err),
+		}
+	}
+	deadline := start.Add(timeout)
+
+	// Route each attempt without the eventually settings to avoid recursion
+	attemptStep := step
+	attemptStep.Eventually = nil
+
+	var result *types.StepResult
+	attempts := 0
+	for {
+		attempts++
+		if attempts > 1 {
+			fmt.Printf("  [Eventually] Attempt %d\n", attempts)
+		}
+		result = s.strategyRouter.Execute(attemptStep, stepNum, loopCtx)
+		if result == nil || result.Result.IsSuccess() || result.Result.IsSkipped() {
+			if result != nil {
+				result.Duration = time.Since(start)
+				flow := stepControlFlow(result)
+				flow.Iterations = attempts
+				if result.Result.IsSuccess() {
+					flow.StopReason = fmt.Sprintf("passed on attempt %d", attempts)
+				}
+				if attempts > 1 {
+					fmt.Printf("  [Eventually] Passed on attempt %d after %s\n", attempts, result.Duration.Round(time.Millisecond))
+				}
+			}
+			return result
+		}
+
+		delay := backoffDelay(interval, step.Eventually.Backoff, attempts-1)
+		if time.Now().Add(delay).After(deadline) {
+			break
+		}
+		fmt.Printf("  [Eventually] Not passed yet, waiting %v before the next attempt...\n", delay)
+		time.Sleep(delay)
+	}
+
+	elapsed := time.Since(start).Round(time.Millisecond)
+	fmt.Printf("  [Eventually] Did not pass within %s (%d attempt(s))\n", timeout, attempts)
+
+	lastMessage, _, _ := strings.Cut(result.Result.GetMessage(), "\n")
+	data := map[string]any{
+		"attempts": attempts,
+		"elapsed":  elapsed.String(),
+	}
+	builder := types.NewFailureBuilder(types.FailureCategoryAssertion, "EVENTUALLY_TIMEOUT").
+		WithTemplate("Step did not pass within %s after %d attempt(s); last attempt: %s").
+		WithContext("attempts", attempts).
+		WithSuggestion("Raise eventually.timeout if the system takes longer to converge")
+	if value, ok := s.observedValue(step); ok {
+		builder = builder.WithContext("last_value", value)
+		data["last_value"] = value
+	}
+	failed := builder.Build(timeout, attempts, lastMessage)
+	failed.Data = data
+
+	result.Result = failed
+	result.Duration = time.Since(start)
+	flow := stepControlFlow(result)
+	flow.Iterations = attempts
+	flow.StopReason = fmt.Sprintf("did not pass within %s", timeout)
+	return result
+}
+
+// observedValue returns the value the step's assert last compared: the first argument
+// of the step, or of the last assert among its nested steps, substituted now. Sensitive
+// and no_log asserts keep their values out of the failure.
+func (s *EventuallyExecutionStrategy) observedValue(step types.Step) (any, bool) {
+	assertStep := step
+	if step.Action != "assert" {
+		found := false
+		for _, nested := range step.Steps {
+			if nested.Action == "assert" {
+				assertStep, found = nested, true
+			}
+		}
+		if !found {
+			return nil, false
+		}
+	}
+	if len(assertStep.Args) == 0 || assertStep.Sensitive || assertStep.LogSuppressed() || step.LogSuppressed() {
+		return nil, false
+	}
+	return s.variables.SubstituteArgs(assertStep.Args[:1])[0], true
+}
+
+// CanHandle returns true for steps with eventually settings
+func (s *EventuallyExecutionStrategy) CanHandle(step types.Step) bool {
+	return step.Eventually != nil
+}
+
+// Priority runs eventually after conditions, like repeat, which it cannot be combined with
+func (s *EventuallyExecutionStrategy) Priority() int {
+	return 4
+}
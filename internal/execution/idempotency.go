@@ -0,0 +1,36 @@
+package execution
+
+import (
+	"github.com/JianLoong/robogo/internal/common"
+)
+
+// pinIdempotencyKey gives every execution until the returned function is called the same
+// idempotency key, so all attempts of a retried step send the same key
+func (s *BasicExecutionStrategy) pinIdempotencyKey() func() {
+	previous := s.idempotencyKey
+	s.idempotencyKey = common.NewIdempotencyKey()
+	return func() {
+		s.idempotencyKey = previous
+	}
+}
+
+// setStepVariable sets ${step.idempotency_key} for the step about to run: the pinned key
+// of a retried step, otherwise a new one. The returned function removes the variable
+// again; calls after the first do nothing, so it can also be deferred. Tests cannot set
+// "step" themselves, see common.ReservedVariable.
+func (s *BasicExecutionStrategy) setStepVariable() func() {
+	key := s.idempotencyKey
+	if key == "" {
+		key = common.NewIdempotencyKey()
+	}
+
+	s.variables.Set(common.StepVariable, map[string]any{common.IdempotencyKeyField: key})
+	removed := false
+	return func() {
+		if removed {
+			return
+		}
+		removed = true
+		s.variables.Delete(common.StepVariable)
+	}
+}
@@ -1,6 +1,11 @@
 package execution
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/JianLoong/robogo/internal/common"
 	"github.com/JianLoong/robogo/internal/constants"
 	"github.com/JianLoong/robogo/internal/types"
 )
@@ -8,12 +13,16 @@ import (
 // NestedStepsExecutionStrategy handles steps with nested steps
 type NestedStepsExecutionStrategy struct {
 	strategyRouter *ExecutionStrategyRouter
+	variables      *common.Variables
+	verbosity      Verbosity
 }
 
 // NewNestedStepsExecutionStrategy creates a new nested steps execution strategy
-func NewNestedStepsExecutionStrategy(strategyRouter *ExecutionStrategyRouter) *NestedStepsExecutionStrategy {
+func NewNestedStepsExecutionStrategy(variables *common.Variables, strategyRouter *ExecutionStrategyRouter) *NestedStepsExecutionStrategy {
 	return &NestedStepsExecutionStrategy{
 		strategyRouter: strategyRouter,
+		variables:      variables,
+		verbosity:      VerbosityNormal,
 	}
 }
 
@@ -21,13 +30,23 @@ func NewNestedStepsExecutionStrategy(strategyRouter *ExecutionStrategyRouter) *N
 func (s *NestedStepsExecutionStrategy) Execute(step types.Step, stepNum int, loopCtx *types.LoopContext) *types.StepResult {
 	// Execute all nested steps and aggregate results
 	var allResults []types.StepResult
+	var artifacts []types.Artifact
 	var hasError bool
 	var firstErrorResult *types.StepResult
+
+	// with: values, e.g. the inputs of included steps, are set before the nested steps run
+	if len(step.With) > 0 {
+		values, _ := s.variables.SubstituteArgs([]any{step.With})[0].(map[string]any)
+		for name, value := range values {
+			s.variables.Set(name, value)
+		}
+	}
 	
 	for i, nestedStep := range step.Steps {
-		result := s.strategyRouter.Execute(nestedStep, i+1, loopCtx)
+		result := s.executeRecovered(nestedStep, i+1, loopCtx)
 		if result != nil {
 			allResults = append(allResults, *result)
+			artifacts = append(artifacts, result.Artifacts...)
 			
 			// Check if this step had an error
 			if result.Result.Status == constants.ActionStatusError || result.Result.Status == constants.ActionStatusFailed {
@@ -44,6 +63,39 @@ func (s *NestedStepsExecutionStrategy) Execute(step types.Step, stepNum int, loo
 		}
 	}
 	
+	// Finally steps always run, also after a panic, and each one even if an earlier one
+	// failed, so cleanup is not cut short. Each reports its own status.
+	var finallyResults []types.StepResult
+	var finallyErrorResult *types.StepResult
+	for i, finallyStep := range step.Finally {
+		result := s.executeRecovered(finallyStep, i+1, loopCtx)
+		if result == nil {
+			continue
+		}
+		finallyResults = append(finallyResults, *result)
+		artifacts = append(artifacts, result.Artifacts...)
+		if result.Result.Status == constants.ActionStatusError || result.Result.Status == constants.ActionStatusFailed {
+			message, _, _ := strings.Cut(result.Result.GetMessage(), "\n")
+			fmt.Printf("[FINALLY] ⚠️  Finally step failed: %s\n", finallyStep.Name)
+			fmt.Printf("[FINALLY] ⚠️  Error: %s\n", message)
+			if finallyErrorResult == nil {
+				finallyErrorResult = &finallyResults[len(finallyResults)-1]
+			}
+		} else if s.verbosity != VerbosityQuiet {
+			outcome := "passed"
+			if result.Result.IsSkipped() {
+				outcome = "skipped"
+			}
+			fmt.Printf("[FINALLY] ✓ Finally step %s: %s\n", outcome, finallyStep.Name)
+		}
+	}
+
+	// A cleanup failure fails the block only if its steps passed, so it never masks their error
+	if !hasError && finallyErrorResult != nil {
+		hasError = true
+		firstErrorResult = finallyErrorResult
+	}
+
 	// Determine if step should be included in summary (default: true)
 	includeSummary := true
 	if step.Summary != nil {
@@ -56,6 +108,8 @@ func (s *NestedStepsExecutionStrategy) Execute(step types.Step, stepNum int, loo
 		Action:         "nested_steps",
 		Duration:       0, // Could sum durations from allResults if needed
 		IncludeSummary: includeSummary,
+		Artifacts:      artifacts,
+		FinallySteps:   finallyResults,
 	}
 	
 	// Set overall status based on nested results
@@ -75,6 +129,23 @@ func (s *NestedStepsExecutionStrategy) Execute(step types.Step, stepNum int, loo
 	return aggregateResult
 }
 
+// executeRecovered runs one nested or finally step. A panic is reported as an error
+// result of that step, so the block's finally steps still run.
+func (s *NestedStepsExecutionStrategy) executeRecovered(step types.Step, stepNum int, loopCtx *types.LoopContext) (result *types.StepResult) {
+	start := time.Now()
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			result = PanicStepResult(step, start, recovered)
+		}
+	}()
+	return s.strategyRouter.Execute(step, stepNum, loopCtx)
+}
+
+// SetVerbosity sets how much the block prints about its finally steps
+func (s *NestedStepsExecutionStrategy) SetVerbosity(verbosity Verbosity) {
+	s.verbosity = verbosity
+}
+
 // CanHandle returns true for steps that have nested steps
 func (s *NestedStepsExecutionStrategy) CanHandle(step types.Step) bool {
 	return len(step.Steps) > 0
@@ -0,0 +1,45 @@
+package execution
+
+import (
+	"github.com/JianLoong/robogo/internal/common"
+	"github.com/JianLoong/robogo/internal/types"
+)
+
+// SetMaskOutput turns masking by value on or off: with it on, the values of sensitive
+// variables and ${ENV:...} references are replaced with *** in everything steps print
+// and keep in their results, whatever key they appear under
+func (s *BasicExecutionStrategy) SetMaskOutput(enabled bool) {
+	s.maskOutput = enabled
+}
+
+// outputSecrets returns the values to mask in step output: with mask_output off, only
+// the secret-looking values found in other variables
+func (s *BasicExecutionStrategy) outputSecrets() []string {
+	if !s.maskOutput {
+		return s.variables.DetectedSecretValues()
+	}
+	return s.variables.SecretValues()
+}
+
+// maskResultValues masks secret values in the data and metadata a step result keeps,
+// or only the detected ones when mask_output is off. The data extraction and result
+// variables see is not changed.
+func (s *BasicExecutionStrategy) maskResultValues(result *types.StepResult, secrets []string) {
+	if !s.maskOutput {
+		secrets = s.variables.DetectedSecretValues()
+	}
+	if len(secrets) == 0 {
+		return
+	}
+	result.Result.Data = common.MaskSecretValuesIn(result.Result.Data, secrets)
+	result.Result.Meta = common.MaskSecretValuesIn(result.Result.Meta, secrets)
+}
+
+// secretInVariableError fails a step that stored a secret-looking value in a variable
+// without a sensitive name, under the fail secret policy
+func secretInVariableError(err error) types.ActionResult {
+	return types.NewErrorBuilder(types.ErrorCategoryVariable, "SECRET_IN_VARIABLE").
+		WithTemplate("Secret value stored in a regular variable: %v").
+		WithSuggestion("Give the variable a sensitive name such as api_token, or read the secret with ${ENV:...}").
+		Build(err)
+}
@@ -0,0 +1,174 @@
+package execution
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/JianLoong/robogo/internal/common"
+	"github.com/JianLoong/robogo/internal/constants"
+	"github.com/JianLoong/robogo/internal/types"
+)
+
+// RepeatExecutionStrategy runs a step several times to measure its pass rate.
+// Unlike retry, every iteration runs regardless of its outcome.
+type RepeatExecutionStrategy struct {
+	strategyRouter     *ExecutionStrategyRouter
+	conditionEvaluator *BasicConditionEvaluator
+	verbosity          Verbosity
+}
+
+// NewRepeatExecutionStrategy creates a new repeat execution strategy
+func NewRepeatExecutionStrategy(variables *common.Variables, strategyRouter *ExecutionStrategyRouter) *RepeatExecutionStrategy {
+	return &RepeatExecutionStrategy{
+		strategyRouter:     strategyRouter,
+		conditionEvaluator: NewBasicConditionEvaluator(variables),
+		verbosity:          VerbosityNormal,
+	}
+}
+
+// SetVerbosity sets how much the strategy prints about iterations; quiet prints none,
+// leaving the step's result to the summary
+func (s *RepeatExecutionStrategy) SetVerbosity(verbosity Verbosity) {
+	s.verbosity = verbosity
+}
+
+// Execute runs the step up to step.Repeat times and aggregates the outcomes
+func (s *RepeatExecutionStrategy) Execute(step types.Step, stepNum int, loopCtx *types.LoopContext) *types.StepResult {
+	start := time.Now()
+
+	// Route each iteration without the repeat settings to avoid recursion
+	iterationStep := step
+	iterationStep.Repeat = 0
+	iterationStep.RepeatUntil = ""
+	iterationStep.RepeatWhile = ""
+
+	var statuses []any
+	var artifacts []types.Artifact
+	var stopReason string
+	var conditionFailed *types.ActionResult
+	passed := 0
+
+	for iteration := 1; iteration <= step.Repeat; iteration++ {
+		if s.verbosity != VerbosityQuiet {
+			fmt.Printf("  [Repeat] Iteration %d/%d\n", iteration, step.Repeat)
+		}
+
+		result := s.strategyRouter.Execute(iterationStep, stepNum, loopCtx)
+		if result == nil {
+			continue
+		}
+		statuses = append(statuses, string(result.Result.Status))
+		artifacts = append(artifacts, result.Artifacts...)
+		if result.Result.IsSuccess() {
+			passed++
+		}
+
+		stop, reason, failed := s.shouldStop(step)
+		if failed != nil {
+			if s.verbosity != VerbosityQuiet {
+				message, _, _ := strings.Cut(failed.GetMessage(), "\n")
+				fmt.Printf("  [Repeat] Stopping after iteration %d: %s: %s\n", iteration, reason, message)
+			}
+			stopReason, conditionFailed = reason, failed
+			break
+		}
+		if stop {
+			if s.verbosity != VerbosityQuiet {
+				fmt.Printf("  [Repeat] Stopping after iteration %d: %s\n", iteration, reason)
+			}
+			stopReason = reason
+			break
+		}
+	}
+
+	includeSummary := true
+	if step.Summary != nil {
+		includeSummary = *step.Summary
+	}
+
+	iterations := len(statuses)
+	summary := fmt.Sprintf("%d/%d passed", passed, iterations)
+	if s.verbosity != VerbosityQuiet {
+		fmt.Printf("  [Repeat] %s\n", summary)
+	}
+
+	aggregate := &types.StepResult{
+		Name:           step.Name,
+		Action:         step.Action,
+		Duration:       time.Since(start),
+		IncludeSummary: includeSummary,
+		Artifacts:      artifacts,
+		ControlFlow:    &types.ControlFlow{Iterations: iterations, StopReason: stopReason},
+	}
+
+	data := map[string]any{
+		"iterations": iterations,
+		"passed":     passed,
+		"failed":     iterations - passed,
+		"pass_rate":  passRate(passed, iterations),
+		"statuses":   statuses,
+		"summary":    summary,
+	}
+
+	// A repeat_until or repeat_while that cannot be evaluated errors the step, so a typo
+	// does not turn a stability run into a pass of the iterations run so far. Only a
+	// fully passing run counts as stable.
+	if conditionFailed != nil {
+		aggregate.Result = *conditionFailed
+		aggregate.Result.Data = data
+	} else if iterations > 0 && passed == iterations {
+		aggregate.Result = types.ActionResult{Status: constants.ActionStatusPassed, Data: data}
+	} else {
+		aggregate.Result = types.NewFailureBuilder(types.FailureCategoryValidation, "REPEAT_FAILURES").
+			WithTemplate("Step passed %d of %d iterations").
+			WithContext("statuses", statuses).
+			WithSuggestion("The step is flaky or failing; inspect the individual iteration output above").
+			Build(passed, iterations)
+		aggregate.Result.Data = data
+	}
+
+	return aggregate
+}
+
+// shouldStop evaluates repeat_until and repeat_while after an iteration. A condition
+// that cannot be evaluated returns an error result.
+func (s *RepeatExecutionStrategy) shouldStop(step types.Step) (bool, string, *types.ActionResult) {
+	if step.RepeatUntil != "" {
+		done, _, failed := evaluateCondition(s.conditionEvaluator, step.RepeatUntil)
+		if failed != nil {
+			return true, "failed to evaluate repeat_until", failed
+		}
+		if done {
+			return true, "repeat_until condition met", nil
+		}
+	}
+	if step.RepeatWhile != "" {
+		keepGoing, _, failed := evaluateCondition(s.conditionEvaluator, step.RepeatWhile)
+		if failed != nil {
+			return true, "failed to evaluate repeat_while", failed
+		}
+		if !keepGoing {
+			return true, "repeat_while condition no longer true", nil
+		}
+	}
+	return false, "", nil
+}
+
+// CanHandle returns true for steps with a repeat count
+func (s *RepeatExecutionStrategy) CanHandle(step types.Step) bool {
+	return step.Repeat > 0
+}
+
+// Priority runs repeat after conditions but before retry, so each iteration may retry
+func (s *RepeatExecutionStrategy) Priority() int {
+	return 4
+}
+
+// passRate returns the share of passed iterations as a percentage
+func passRate(passed, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(passed) * 100 / float64(total)
+}
@@ -0,0 +1,152 @@
+package execution
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/JianLoong/robogo/internal/constants"
+	"github.com/JianLoong/robogo/internal/types"
+)
+
+// resultCache holds the results of steps with a cache option, shared by the test cases
+// of a run, by cache key
+var resultCache = struct {
+	sync.Mutex
+	disabled bool
+	entries  map[string]cachedResult
+}{entries: map[string]cachedResult{}}
+
+// cachedResult is an action result and when it stops being reused
+type cachedResult struct {
+	output  types.ActionResult
+	expires time.Time
+}
+
+// DisableResultCache makes steps with a cache option run their action every time, for --no-cache
+func DisableResultCache() {
+	resultCache.Lock()
+	defer resultCache.Unlock()
+	resultCache.disabled = true
+}
+
+// ClearResultCache drops the cached results at the end of a run
+func ClearResultCache() {
+	resultCache.Lock()
+	defer resultCache.Unlock()
+	resultCache.entries = map[string]cachedResult{}
+}
+
+// resultCacheKey returns the key a step's result is cached under: the action and the
+// step's own key, or when it has none, the action, arguments and options. Options
+// include the internal ones, such as streamed extraction, that change the result.
+func resultCacheKey(action, key string, args []any, options map[string]any) string {
+	if key != "" {
+		return action + "\x00" + key
+	}
+	names := make([]string, 0, len(options))
+	for name := range options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\x00%#v", action, args)
+	for _, name := range names {
+		fmt.Fprintf(&b, "\x00%s=%#v", name, options[name])
+	}
+	sum := sha256.Sum256([]byte(b.String()))
+	return action + "\x00" + hex.EncodeToString(sum[:])
+}
+
+// lookupCachedResult returns a copy of the result cached under key, if it has not expired
+func lookupCachedResult(key string) (types.ActionResult, bool) {
+	resultCache.Lock()
+	defer resultCache.Unlock()
+	if resultCache.disabled {
+		return types.ActionResult{}, false
+	}
+	entry, ok := resultCache.entries[key]
+	if !ok {
+		return types.ActionResult{}, false
+	}
+	if time.Now().After(entry.expires) {
+		delete(resultCache.entries, key)
+		return types.ActionResult{}, false
+	}
+	return copyActionResult(entry.output), true
+}
+
+// storeCachedResult caches a copy of a passed result, so steps changing the data they
+// were given, e.g. with dot-notation writes, do not change what later steps get. HTTP
+// responses outside 2xx are not cached, since a retried request may well succeed.
+func storeCachedResult(key string, output types.ActionResult, ttl time.Duration) bool {
+	if output.Status != constants.ActionStatusPassed {
+		return false
+	}
+	if data, ok := output.Data.(map[string]any); ok {
+		if statusCode, ok := data["status_code"].(int); ok && (statusCode < 200 || statusCode > 299) {
+			return false
+		}
+	}
+	resultCache.Lock()
+	defer resultCache.Unlock()
+	if resultCache.disabled {
+		return false
+	}
+	resultCache.entries[key] = cachedResult{output: copyActionResult(output), expires: time.Now().Add(ttl)}
+	return true
+}
+
+// copyActionResult copies a result with its data and meta
+func copyActionResult(output types.ActionResult) types.ActionResult {
+	output.Data = copyCachedValue(reflect.ValueOf(output.Data))
+	output.Meta = copyCachedValue(reflect.ValueOf(output.Meta))
+	return output
+}
+
+// copyCachedValue deep-copies the maps and slices of a value; other values are returned
+// as they are, since action results hold them by value or never change them
+func copyCachedValue(value reflect.Value) any {
+	if !value.IsValid() {
+		return nil
+	}
+	switch value.Kind() {
+	case reflect.Map:
+		if value.IsNil() {
+			return value.Interface()
+		}
+		copied := reflect.MakeMapWithSize(value.Type(), value.Len())
+		iter := value.MapRange()
+		for iter.Next() {
+			copied.SetMapIndex(iter.Key(), copiedElem(iter.Value(), value.Type().Elem()))
+		}
+		return copied.Interface()
+	case reflect.Slice:
+		if value.IsNil() {
+			return value.Interface()
+		}
+		copied := reflect.MakeSlice(value.Type(), value.Len(), value.Len())
+		for i := 0; i < value.Len(); i++ {
+			copied.Index(i).Set(copiedElem(value.Index(i), value.Type().Elem()))
+		}
+		return copied.Interface()
+	case reflect.Interface:
+		return copyCachedValue(value.Elem())
+	}
+	return value.Interface()
+}
+
+// copiedElem copies a map or slice element, converted back to the element type
+func copiedElem(value reflect.Value, elemType reflect.Type) reflect.Value {
+	copied := copyCachedValue(value)
+	if copied == nil {
+		return reflect.Zero(elemType)
+	}
+	return reflect.ValueOf(copied).Convert(elemType)
+}
@@ -5,7 +5,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/JianLoong/robogo/internal/actions"
 	"github.com/JianLoong/robogo/internal/common"
 	"github.com/JianLoong/robogo/internal/constants"
 	"github.com/JianLoong/robogo/internal/types"
@@ -17,10 +16,11 @@ type RetryExecutionStrategy struct {
 	variables     *common.Variables
 }
 
-// NewRetryExecutionStrategy creates a new retry execution strategy
-func NewRetryExecutionStrategy(variables *common.Variables, actionRegistry *actions.ActionRegistry) *RetryExecutionStrategy {
+// NewRetryExecutionStrategy creates a new retry execution strategy.
+// It shares the basic strategy so retries see the same circuit breaker state.
+func NewRetryExecutionStrategy(variables *common.Variables, basicStrategy *BasicExecutionStrategy) *RetryExecutionStrategy {
 	return &RetryExecutionStrategy{
-		basicStrategy: NewBasicExecutionStrategy(variables, actionRegistry),
+		basicStrategy: basicStrategy,
 		variables:     variables,
 	}
 }
@@ -48,6 +48,9 @@ func (s *RetryExecutionStrategy) executeStepWithRetry(step types.Step, stepNum i
 	// Create a condition evaluator for retry_if conditions
 	conditionEvaluator := NewBasicConditionEvaluator(s.variables)
 
+	// Every attempt sends the same ${step.idempotency_key}
+	defer s.basicStrategy.pinIdempotencyKey()()
+
 	for attempt := 1; attempt <= config.Attempts; attempt++ {
 		if attempt > 1 {
 			fmt.Printf("  [Retry] Attempt %d/%d\n", attempt, config.Attempts)
@@ -56,8 +59,12 @@ func (s *RetryExecutionStrategy) executeStepWithRetry(step types.Step, stepNum i
 		result := s.basicStrategy.Execute(step, stepNum, loopCtx)
 		lastResult = result
 
+		// A passed request whose HTTP status is listed in retry_on is not a success yet
+		retryStatus := result != nil && retryStatusListed(config.RetryOn, result.StatusCode) &&
+			!retryStatusListed(config.RetryUnless, result.StatusCode)
+
 		// Check if we should stop retrying based on success
-		if result != nil && result.Result.Status == constants.ActionStatusPassed {
+		if result != nil && result.Result.Status == constants.ActionStatusPassed && !retryStatus {
 			// If stop_on_success is true or not specified, stop retrying on success
 			if config.StopOnSuccess {
 				return result
@@ -78,27 +85,21 @@ func (s *RetryExecutionStrategy) executeStepWithRetry(step types.Step, stepNum i
 			s.variables.Set("step_status", string(result.Result.Status))
 		}
 
+		// Exclusions win over retry_on, e.g. retry_on: [all] with retry_unless: ["4xx"]
+		for _, errorType := range config.RetryUnless {
+			if retryFilterMatches(errorType, result, errorOccurred, errorMessage) {
+				fmt.Printf("  [Retry] '%s' matched retry_unless, stopping retry\n", errorType)
+				return lastResult
+			}
+		}
+
 		// Check if we should retry based on retry_on error types
 		if len(config.RetryOn) > 0 {
 			shouldRetry := false
 
 			// Check if the error type matches any in the retry_on list
 			for _, errorType := range config.RetryOn {
-				switch strings.ToLower(errorType) {
-				case "all":
-					shouldRetry = errorOccurred
-				case "http_error":
-					shouldRetry = errorOccurred && strings.Contains(errorMessage, "HTTP")
-				case "timeout":
-					shouldRetry = errorOccurred && strings.Contains(errorMessage, "timeout")
-				case "connection_error":
-					shouldRetry = errorOccurred && (strings.Contains(errorMessage, "connection") ||
-						strings.Contains(errorMessage, "dial") ||
-						strings.Contains(errorMessage, "network"))
-				case "assertion_failed":
-					shouldRetry = errorOccurred && strings.Contains(errorMessage, "assertion")
-				}
-
+				shouldRetry = retryFilterMatches(errorType, result, errorOccurred, errorMessage)
 				if shouldRetry {
 					fmt.Printf("  [Retry] Error type '%s' matched, continuing retry\n", errorType)
 					break
@@ -144,6 +145,89 @@ func (s *RetryExecutionStrategy) executeStepWithRetry(step types.Step, stepNum i
 	return lastResult
 }
 
+// retryFilterMatches reports whether a retry_on or retry_unless entry matches the result
+// of an attempt. Entries are HTTP statuses ("503", "5xx", "http_5xx"), the keywords below,
+// or an error or failure category or code such as "network" or "TIMEOUT".
+func retryFilterMatches(entry string, result *types.StepResult, errorOccurred bool, errorMessage string) bool {
+	entry = strings.ToLower(strings.TrimSpace(entry))
+	if pattern, ok := retryStatusPattern(entry); ok {
+		return result != nil && retryStatusMatches(pattern, result.StatusCode)
+	}
+
+	switch entry {
+	case "all":
+		return errorOccurred
+	case "http_error":
+		return errorOccurred && strings.Contains(errorMessage, "HTTP")
+	case "timeout":
+		return errorOccurred && (strings.Contains(errorMessage, "timeout") || retryCodeMatches(entry, result))
+	case "connection_error":
+		return errorOccurred && (strings.Contains(errorMessage, "connection") ||
+			strings.Contains(errorMessage, "dial") ||
+			strings.Contains(errorMessage, "network"))
+	case "assertion_failed":
+		return errorOccurred && strings.Contains(errorMessage, "assertion")
+	}
+	return errorOccurred && retryCodeMatches(entry, result)
+}
+
+// retryCodeMatches reports whether entry names the category or code of the result's error or failure
+func retryCodeMatches(entry string, result *types.StepResult) bool {
+	if result == nil {
+		return false
+	}
+	if info := result.Result.ErrorInfo; info != nil {
+		if strings.EqualFold(string(info.Category), entry) || strings.EqualFold(info.Code, entry) {
+			return true
+		}
+	}
+	if info := result.Result.FailureInfo; info != nil {
+		if strings.EqualFold(string(info.Category), entry) || strings.EqualFold(info.Code, entry) {
+			return true
+		}
+	}
+	return false
+}
+
+// retryStatusListed reports whether any entry is an HTTP status pattern matching statusCode
+func retryStatusListed(entries []string, statusCode int) bool {
+	for _, entry := range entries {
+		if pattern, ok := retryStatusPattern(strings.ToLower(strings.TrimSpace(entry))); ok && retryStatusMatches(pattern, statusCode) {
+			return true
+		}
+	}
+	return false
+}
+
+// retryStatusPattern returns the three-character status pattern of an entry such as
+// "503", "5xx" or "http_5xx"
+func retryStatusPattern(entry string) (string, bool) {
+	pattern := strings.TrimPrefix(entry, "http_")
+	if len(pattern) != 3 || pattern[0] < '1' || pattern[0] > '5' {
+		return "", false
+	}
+	for _, c := range pattern[1:] {
+		if c != 'x' && (c < '0' || c > '9') {
+			return "", false
+		}
+	}
+	return pattern, true
+}
+
+// retryStatusMatches reports whether statusCode fits a pattern such as "503" or "5xx"
+func retryStatusMatches(pattern string, statusCode int) bool {
+	if statusCode == 0 {
+		return false
+	}
+	status := fmt.Sprintf("%03d", statusCode)
+	for i := range pattern {
+		if pattern[i] != 'x' && pattern[i] != status[i] {
+			return false
+		}
+	}
+	return true
+}
+
 // calculateDelay calculates the delay for retry attempts
 func (s *RetryExecutionStrategy) calculateDelay(config *types.RetryConfig, attemptNum int) time.Duration {
 	if config.Delay == "" {
@@ -154,8 +238,13 @@ func (s *RetryExecutionStrategy) calculateDelay(config *types.RetryConfig, attem
 	if err != nil {
 		return time.Second // Default to 1 second if parsing fails
 	}
+	return backoffDelay(baseDuration, config.Backoff, attemptNum)
+}
 
-	switch config.Backoff {
+// backoffDelay returns the wait that follows attempt attemptNum, counting from 0, for a
+// fixed, linear or exponential backoff; retry and eventually share it
+func backoffDelay(baseDuration time.Duration, backoff string, attemptNum int) time.Duration {
+	switch backoff {
 	case "exponential":
 		multiplier := 1
 		for i := 0; i < attemptNum; i++ {
@@ -0,0 +1,37 @@
+package execution
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/JianLoong/robogo/internal/actions"
+	"github.com/JianLoong/robogo/internal/types"
+)
+
+// collectArtifacts moves the artifacts the action attached to the step result and adds
+// the step's attach, which holds the full result data whatever max_data_bytes keeps.
+// Attached values are masked like the step's messages; the run masks them again, and
+// reads attached files, when it writes them.
+func collectArtifacts(step types.Step, output *types.ActionResult, result *types.StepResult, secrets []string) {
+	artifacts := output.Artifacts
+	output.Artifacts = nil
+	if step.Attach != "" && output.Data != nil {
+		artifacts = append(artifacts, actions.DataArtifact(step.Attach, output.Data))
+	}
+
+	for _, artifact := range artifacts {
+		if step.LogSuppressed() {
+			artifact.Content = nil
+			artifact.Skipped = "not written for a no_log step"
+		} else if len(artifact.Content) > 0 && len(secrets) > 0 && utf8.Valid(artifact.Content) {
+			content := string(artifact.Content)
+			for _, secret := range secrets {
+				content = strings.ReplaceAll(content, secret, "***")
+			}
+			if content != string(artifact.Content) {
+				artifact.Content, artifact.Size, artifact.Masked = []byte(content), int64(len(content)), true
+			}
+		}
+		result.Artifacts = append(result.Artifacts, artifact)
+	}
+}
@@ -0,0 +1,37 @@
+package execution
+
+import "github.com/JianLoong/robogo/internal/common"
+
+// substituteStepEnv resolves variables in env values
+func (s *BasicExecutionStrategy) substituteStepEnv(env map[string]string) map[string]string {
+	if len(env) == 0 {
+		return nil
+	}
+	resolved := make(map[string]string, len(env))
+	for name, value := range env {
+		resolved[name] = s.variables.Substitute(value)
+	}
+	return resolved
+}
+
+// maskedStepEnv returns the env for printing, with the values of sensitive names masked
+func maskedStepEnv(env map[string]string, sensitiveFields []string) map[string]any {
+	masked := make(map[string]any, len(env))
+	for name, value := range env {
+		masked[name] = value
+	}
+	common.MaskSensitiveFields(masked, common.SensitiveKeys(sensitiveFields))
+	return masked
+}
+
+// sensitiveEnvValues returns the env values whose names are sensitive, for masking in messages
+func sensitiveEnvValues(env map[string]string, sensitiveFields []string) []string {
+	keys := common.SensitiveKeys(sensitiveFields)
+	var secrets []string
+	for name, value := range env {
+		if value != "" && common.IsSensitiveKey(name, keys) {
+			secrets = append(secrets, value)
+		}
+	}
+	return secrets
+}
@@ -4,10 +4,11 @@ import (
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
-	"regexp"
 	"strconv"
 	"strings"
 
+	"github.com/JianLoong/robogo/internal/actions"
+	"github.com/JianLoong/robogo/internal/common"
 	"github.com/JianLoong/robogo/internal/constants"
 	"github.com/JianLoong/robogo/internal/types"
 )
@@ -22,7 +23,7 @@ func (s *BasicExecutionStrategy) applyExtraction(data any, config *types.Extract
 	case "jq":
 		return s.applyJQExtraction(data, config.Path)
 	case "xpath":
-		return s.applyXPathExtraction(data, config.Path)
+		return s.applyXPathExtraction(data, config.Path, config.Namespaces)
 	case "regex":
 		return s.applyRegexExtraction(data, config.Path, config.Group)
 	case "csv":
@@ -45,6 +46,21 @@ func (s *BasicExecutionStrategy) applyExtraction(data any, config *types.Extract
 	}
 }
 
+// streamsExtraction reports whether the action applies the step's extraction itself while
+// reading its response. Only jq extraction from http responses can be streamed.
+func streamsExtraction(step types.Step) bool {
+	return step.Extract != nil && step.Extract.Stream && step.Extract.Type == "jq" && step.Action == "http"
+}
+
+// streamedExtraction returns the value an action already extracted while streaming
+func streamedExtraction(data any) (any, error) {
+	result, ok := data.(map[string]any)
+	if !ok {
+		return nil, types.NewExtractionError("streamed response has no extracted value")
+	}
+	return result[actions.StreamedResultKey], nil
+}
+
 // applyJQExtraction applies JQ extraction to data
 func (s *BasicExecutionStrategy) applyJQExtraction(data any, path string) (any, error) {
 	jqAction, exists := s.actionRegistry.Get("jq")
@@ -61,13 +77,17 @@ func (s *BasicExecutionStrategy) applyJQExtraction(data any, path string) (any,
 }
 
 // applyXPathExtraction applies XPath extraction to data  
-func (s *BasicExecutionStrategy) applyXPathExtraction(data any, path string) (any, error) {
+func (s *BasicExecutionStrategy) applyXPathExtraction(data any, path string, namespaces map[string]string) (any, error) {
 	xpathAction, exists := s.actionRegistry.Get("xpath")
 	if !exists {
 		return nil, types.NewExtractionError("xpath action not available")
 	}
 	
-	result := xpathAction([]any{data, path}, map[string]any{}, s.variables)
+	options := map[string]any{}
+	if len(namespaces) > 0 {
+		options["namespaces"] = namespaces
+	}
+	result := xpathAction([]any{data, path}, options, s.variables)
 	if result.Status != constants.ActionStatusPassed {
 		return nil, types.NewExtractionError(result.GetMessage())
 	}
@@ -89,7 +109,7 @@ func (s *BasicExecutionStrategy) applyRegexExtraction(data any, pattern string,
 	}
 	
 	// Apply regex
-	re, err := regexp.Compile(pattern)
+	re, err := common.CompileCached(pattern)
 	if err != nil {
 		return nil, types.NewInvalidRegexPatternError(pattern, err.Error())
 	}
@@ -1,12 +1,11 @@
 package execution
 
 import (
-	"encoding/json"
 	"fmt"
-	"regexp"
 	"strings"
 
 	"github.com/JianLoong/robogo/internal/common"
+	"github.com/JianLoong/robogo/internal/types"
 )
 
 // maskSensitiveArgs masks sensitive information in step arguments based on action type
@@ -15,17 +14,22 @@ func (s *BasicExecutionStrategy) maskSensitiveArgs(action string, args []any) []
 	copy(maskedArgs, args)
 	
 	switch action {
-	case "postgres", "spanner":
-		// Database actions: mask connection strings (usually first argument)
-		if len(args) > 0 {
-			if connStr, ok := args[0].(string); ok {
-				maskedArgs[0] = common.MaskConnectionString(connStr)
+	case "postgres", "spanner", "mongodb":
+		// Database actions: mask connection strings (second argument, after the operation)
+		if len(args) > 1 {
+			if connStr, ok := args[1].(string); ok {
+				maskedArgs[1] = common.MaskConnectionString(connStr)
 			}
 		}
 		
 	case "http":
-		// HTTP actions: mask request bodies that might contain sensitive data
-		if len(args) > 2 { // method, url, body
+		// HTTP actions: mask URL credentials and request bodies that might contain sensitive data
+		if len(args) > 1 { // method, url, body
+			if urlStr, ok := args[1].(string); ok {
+				maskedArgs[1] = common.MaskURLQuery(common.MaskCredentials(urlStr), common.SensitiveKeys())
+			}
+		}
+		if len(args) > 2 {
 			if bodyStr, ok := args[2].(string); ok {
 				maskedArgs[2] = s.maskHTTPBody(bodyStr)
 			}
@@ -63,20 +67,63 @@ func (s *BasicExecutionStrategy) maskSensitiveArgs(action string, args []any) []
 			}
 		}
 	}
+
+	// Arguments the action's metadata declares sensitive, e.g. plugin secrets
+	if meta, ok := s.actionRegistry.Describe(action); ok {
+		for i, spec := range meta.Args {
+			if spec.Sensitive && i < len(maskedArgs) {
+				maskedArgs[i] = "***"
+			}
+		}
+	}
 	
 	return maskedArgs
 }
 
+// sensitiveFieldsFor returns the step's sensitive_fields plus option names the action declares sensitive
+func (s *BasicExecutionStrategy) sensitiveFieldsFor(step types.Step) []string {
+	meta, ok := s.actionRegistry.Describe(step.Action)
+	if !ok {
+		return step.SensitiveFields
+	}
+
+	fields := append([]string{}, step.SensitiveFields...)
+	for _, option := range meta.Options {
+		if option.Sensitive {
+			fields = append(fields, option.Name)
+		}
+	}
+	return fields
+}
+
+// declaredSecretValues returns the values of arguments the action's metadata declares sensitive
+func (s *BasicExecutionStrategy) declaredSecretValues(action string, args []any) []string {
+	meta, ok := s.actionRegistry.Describe(action)
+	if !ok {
+		return nil
+	}
+
+	var secrets []string
+	for i, spec := range meta.Args {
+		if spec.Sensitive && i < len(args) {
+			if value := fmt.Sprintf("%v", args[i]); value != "" {
+				secrets = append(secrets, value)
+			}
+		}
+	}
+	return secrets
+}
+
 // maskHTTPBody masks sensitive data in HTTP request bodies
 func (s *BasicExecutionStrategy) maskHTTPBody(body string) string {
-	// Use the same sophisticated JSON-aware masking as the HTTP action
-	return s.maskSensitiveHTTPData(body)
+	// Use the same JSON-aware masking as the HTTP action
+	return common.MaskSensitiveBody(body, common.SensitiveKeys())
 }
 
 // maskSensitiveStringArg masks sensitive data in string arguments
 func (s *BasicExecutionStrategy) maskSensitiveStringArg(str string) string {
 	// Use common security utilities for general string masking
-	return common.MaskSensitiveData(str, common.DefaultSensitiveKeys)
+	return common.MaskSensitiveData(common.MaskCredentials(str), common.SensitiveKeys())
 }
 
 // getMaskedArgsForPrinting returns masked arguments for printing, considering step-level sensitive_fields
@@ -91,7 +138,9 @@ func (s *BasicExecutionStrategy) getMaskedArgsForPrinting(action string, args []
 			if str, ok := arg.(string); ok {
 				// For HTTP actions, use sophisticated JSON-aware masking for body arguments
 				if action == "http" && i == 2 { // HTTP body is the 3rd argument
-					maskedArgs[i] = s.maskSensitiveHTTPDataWithCustom(str, sensitiveFields)
+					maskedArgs[i] = common.MaskSensitiveBody(str, common.SensitiveKeys(sensitiveFields))
+				} else if action == "http" && i == 1 { // URL query parameters named like a sensitive field
+					maskedArgs[i] = common.MaskURLQuery(str, sensitiveFields)
 				} else {
 					// For other arguments and actions, use general string masking
 					maskedArgs[i] = common.MaskSensitiveData(str, sensitiveFields)
@@ -103,190 +152,77 @@ func (s *BasicExecutionStrategy) getMaskedArgsForPrinting(action string, args []
 	return maskedArgs
 }
 
-// maskSensitiveHTTPData masks sensitive information in HTTP request bodies
-// This mirrors the implementation from the HTTP action for consistency
-func (s *BasicExecutionStrategy) maskSensitiveHTTPData(data string) string {
-	// Try to parse as JSON first for more intelligent masking
-	var jsonData map[string]any
-	if json.Unmarshal([]byte(data), &jsonData) == nil {
-		// For JSON data, use field-based masking
-		return s.maskJSONSensitiveFields(data)
-	}
-	
-	// Fallback to regex-based masking for non-JSON data
-	sensitiveKeys := []string{
-		"password", "pass", "passwd", "pwd",
-		"secret", "token", "key", "apikey", "api_key",
-		"authorization", "auth", "bearer",
-		"credential", "cred", "access_token", "refresh_token",
-		"session", "cookie", "jwt",
-	}
-	
-	result := data
-	for _, key := range sensitiveKeys {
-		// Match various patterns: "key":"value", key=value, key: value
-		patterns := []string{
-			fmt.Sprintf(`(?i)"%s"\s*:\s*"[^"]*"`, key),
-			fmt.Sprintf(`(?i)"%s"\s*:\s*'[^']*'`, key),
-			fmt.Sprintf(`(?i)%s\s*=\s*"[^"]*"`, key),
-			fmt.Sprintf(`(?i)%s\s*=\s*'[^']*'`, key),
-			fmt.Sprintf(`(?i)%s\s*=\s*[^\s&;]+`, key),
-		}
-		
-		for _, pattern := range patterns {
-			re := regexp.MustCompile(pattern)
-			result = re.ReplaceAllStringFunc(result, func(match string) string {
-				// Keep the key but mask the value
-				if strings.Contains(match, ":") {
-					if strings.Contains(match, `"`) {
-						return fmt.Sprintf(`"%s": "***"`, key)
-					} else {
-						return fmt.Sprintf(`"%s": '***'`, key)
-					}
-				} else {
-					return fmt.Sprintf(`%s=***`, key)
-				}
-			})
-		}
-	}
-	
-	return result
-}
+// getMaskedOptionsForPrinting returns a masked copy of step options for printing.
+// Options often carry headers or credentials, e.g. headers.Authorization or a multipart token field.
+func (s *BasicExecutionStrategy) getMaskedOptionsForPrinting(options map[string]any, sensitiveFields []string) map[string]any {
+	sensitiveKeys := common.SensitiveKeys(sensitiveFields)
 
-// maskJSONSensitiveFields masks sensitive fields in JSON strings
-func (s *BasicExecutionStrategy) maskJSONSensitiveFields(jsonStr string) string {
-	var data map[string]any
-	if err := json.Unmarshal([]byte(jsonStr), &data); err != nil {
-		return jsonStr // Return original if not valid JSON
+	masked := make(map[string]any, len(options))
+	for key, value := range options {
+		masked[key] = copyOptionValue(value)
 	}
-	
-	s.maskSensitiveJSONValues(data)
-	
-	maskedBytes, err := json.Marshal(data)
-	if err != nil {
-		return jsonStr // Return original if can't re-marshal
+	common.MaskSensitiveFields(masked, sensitiveKeys)
+
+	for key, value := range masked {
+		if str, ok := value.(string); ok {
+			masked[key] = common.MaskSensitiveData(common.MaskCredentials(str), sensitiveKeys)
+		}
 	}
-	
-	return string(maskedBytes)
+	return masked
 }
 
-// maskSensitiveJSONValues recursively masks sensitive values in JSON objects
-func (s *BasicExecutionStrategy) maskSensitiveJSONValues(obj map[string]any) {
-	sensitiveKeys := []string{
-		"password", "pass", "passwd", "pwd",
-		"secret", "token", "key", "apikey", "api_key", "access_token",
-		"authorization", "auth", "bearer", "credential", "cred",
-		"session", "cookie", "jwt", "refresh_token",
-	}
-	
-	for key, value := range obj {
-		lowerKey := strings.ToLower(key)
-		
-		// Check if this key should be masked
-		shouldMask := false
-		for _, sensitiveKey := range sensitiveKeys {
-			if strings.Contains(lowerKey, sensitiveKey) {
-				shouldMask = true
-				break
-			}
+// copyOptionValue deep-copies nested option maps and slices so masking never alters what the action receives
+func copyOptionValue(value any) any {
+	switch val := value.(type) {
+	case map[string]any:
+		copied := make(map[string]any, len(val))
+		for key, item := range val {
+			copied[key] = copyOptionValue(item)
 		}
-		
-		if shouldMask {
-			obj[key] = "***"
-		} else if nested, ok := value.(map[string]any); ok {
-			// Recursively process nested objects
-			s.maskSensitiveJSONValues(nested)
+		return copied
+	case []any:
+		copied := make([]any, len(val))
+		for i, item := range val {
+			copied[i] = copyOptionValue(item)
 		}
+		return copied
+	default:
+		return val
 	}
 }
 
-// maskSensitiveHTTPDataWithCustom masks sensitive information in HTTP request bodies with custom fields
-func (s *BasicExecutionStrategy) maskSensitiveHTTPDataWithCustom(data string, customKeys []string) string {
-	// Combine default sensitive keys with custom fields
-	sensitiveKeys := []string{
-		"password", "pass", "passwd", "pwd",
-		"secret", "token", "key", "apikey", "api_key",
-		"authorization", "auth", "bearer",
-		"credential", "cred", "access_token", "refresh_token",
-		"session", "cookie", "jwt",
-	}
-	sensitiveKeys = append(sensitiveKeys, customKeys...)
-	
-	// Try to parse as JSON first for more intelligent masking
-	var jsonData map[string]any
-	if json.Unmarshal([]byte(data), &jsonData) == nil {
-		// For JSON data, use field-based masking with custom fields
-		return s.maskJSONSensitiveFieldsWithCustom(data, sensitiveKeys)
+// maskResultMessages masks step-level sensitive fields and declared secret argument values
+// in error and failure messages. Built-in keys and connection strings are already masked
+// when the error context is built.
+func (s *BasicExecutionStrategy) maskResultMessages(result *types.ActionResult, sensitiveFields []string, secrets []string) {
+	if len(sensitiveFields) == 0 && len(secrets) == 0 {
+		return
 	}
-	
-	// Fallback to regex-based masking for non-JSON data
-	result := data
-	for _, key := range sensitiveKeys {
-		// Match various patterns: "key":"value", key=value, key: value
-		patterns := []string{
-			fmt.Sprintf(`(?i)"%s"\s*:\s*"[^"]*"`, key),
-			fmt.Sprintf(`(?i)"%s"\s*:\s*'[^']*'`, key),
-			fmt.Sprintf(`(?i)%s\s*=\s*"[^"]*"`, key),
-			fmt.Sprintf(`(?i)%s\s*=\s*'[^']*'`, key),
-			fmt.Sprintf(`(?i)%s\s*=\s*[^\s&;]+`, key),
+	mask := func(message string) string {
+		if len(sensitiveFields) > 0 {
+			message = common.MaskSensitiveData(message, sensitiveFields)
 		}
-		
-		for _, pattern := range patterns {
-			re := regexp.MustCompile(pattern)
-			result = re.ReplaceAllStringFunc(result, func(match string) string {
-				// Keep the key but mask the value
-				if strings.Contains(match, ":") {
-					if strings.Contains(match, `"`) {
-						return fmt.Sprintf(`"%s": "***"`, key)
-					} else {
-						return fmt.Sprintf(`"%s": '***'`, key)
-					}
-				} else {
-					return fmt.Sprintf(`%s=***`, key)
-				}
-			})
+		for _, secret := range secrets {
+			message = strings.ReplaceAll(message, secret, "***")
 		}
+		return message
 	}
-	
-	return result
-}
-
-// maskJSONSensitiveFieldsWithCustom masks sensitive fields in JSON strings with custom keys
-func (s *BasicExecutionStrategy) maskJSONSensitiveFieldsWithCustom(jsonStr string, sensitiveKeys []string) string {
-	var data map[string]any
-	if err := json.Unmarshal([]byte(jsonStr), &data); err != nil {
-		return jsonStr // Return original if not valid JSON
+	if result.ErrorInfo != nil {
+		result.ErrorInfo.Message = mask(result.ErrorInfo.Message)
 	}
-	
-	s.maskSensitiveJSONValuesWithCustom(data, sensitiveKeys)
-	
-	maskedBytes, err := json.Marshal(data)
-	if err != nil {
-		return jsonStr // Return original if can't re-marshal
+	if result.FailureInfo != nil {
+		result.FailureInfo.Message = mask(result.FailureInfo.Message)
 	}
-	
-	return string(maskedBytes)
 }
 
-// maskSensitiveJSONValuesWithCustom recursively masks sensitive values in JSON objects with custom keys
-func (s *BasicExecutionStrategy) maskSensitiveJSONValuesWithCustom(obj map[string]any, sensitiveKeys []string) {
-	for key, value := range obj {
-		lowerKey := strings.ToLower(key)
-		
-		// Check if this key should be masked
-		shouldMask := false
-		for _, sensitiveKey := range sensitiveKeys {
-			if strings.Contains(lowerKey, sensitiveKey) {
-				shouldMask = true
-				break
-			}
-		}
-		
-		if shouldMask {
-			obj[key] = "***"
-		} else if nested, ok := value.(map[string]any); ok {
-			// Recursively process nested objects
-			s.maskSensitiveJSONValuesWithCustom(nested, sensitiveKeys)
+// maskComparisonArgs masks the values of a sensitive assert, keeping only the operator
+func maskComparisonArgs(args []any) []any {
+	masked := make([]any, len(args))
+	for i, arg := range args {
+		masked[i] = "***"
+		if i == 1 && len(args) >= 3 {
+			masked[i] = arg
 		}
 	}
+	return masked
 }
\ No newline at end of file
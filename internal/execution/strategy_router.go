@@ -1,7 +1,9 @@
 package execution
 
 import (
+	"fmt"
 	"sort"
+	"time"
 
 	"github.com/JianLoong/robogo/internal/types"
 )
@@ -31,21 +33,63 @@ func (r *ExecutionStrategyRouter) RegisterStrategy(strategy ExecutionStrategy) {
 // Execute selects the appropriate strategy and executes the step
 func (r *ExecutionStrategyRouter) Execute(step types.Step, stepNum int, loopCtx *types.LoopContext) *types.StepResult {
 	// Find the first strategy that can handle this step
+	start := time.Now()
 	for _, strategy := range r.strategies {
 		if strategy.CanHandle(step) {
-			return strategy.Execute(step, stepNum, loopCtx)
+			result := strategy.Execute(step, stepNum, loopCtx)
+			if result != nil {
+				result.ID = step.ID
+				result.Source = step.Source
+				result.StartedAt = start
+			}
+			return result
 		}
 	}
 	
 	// No strategy found - return error result
+	builder := types.NewErrorBuilder(types.ErrorCategoryExecution, "NO_STRATEGY_FOUND").
+		WithTemplate("No execution strategy found for step: %s").
+		WithContext("step_name", step.Name).
+		WithContext("action", step.Action)
+	if step.ID != "" {
+		builder = builder.WithContext("step_id", step.ID)
+	}
+	return &types.StepResult{
+		Name:      step.Name,
+		ID:        step.ID,
+		Action:    step.Action,
+		Source:    step.Source,
+		StartedAt: start,
+		Result:    builder.Build(step.Name),
+	}
+}
+
+// SetVerbosity sets how much output the registered strategies that print step output print
+func (r *ExecutionStrategyRouter) SetVerbosity(verbosity Verbosity) {
+	for _, strategy := range r.strategies {
+		if printer, ok := strategy.(interface{ SetVerbosity(Verbosity) }); ok {
+			printer.SetVerbosity(verbosity)
+		}
+	}
+}
+
+// PanicStepResult reports a panic in a step started at start as an error result of that
+// step, so the steps that clean up after it still run
+func PanicStepResult(step types.Step, start time.Time, recovered any) *types.StepResult {
+	fmt.Printf("❌ Step panicked: %s: %v\n", step.Name, recovered)
 	return &types.StepResult{
-		Name:   step.Name,
-		Action: step.Action,
-		Result: types.NewErrorBuilder(types.ErrorCategoryExecution, "NO_STRATEGY_FOUND").
-			WithTemplate("No execution strategy found for step: %s").
-			WithContext("step_name", step.Name).
-			WithContext("action", step.Action).
-			Build(step.Name),
+		Name:           step.Name,
+		ID:             step.ID,
+		Action:         step.Action,
+		Source:         step.Source,
+		StartedAt:      start,
+		Duration:       time.Since(start),
+		IncludeSummary: true,
+		Result: types.NewErrorBuilder(types.ErrorCategorySystem, "STEP_PANIC").
+			WithTemplate("Step panicked: %v").
+			WithContext("step", step.Name).
+			WithSuggestion("This is a bug in the action; finally and teardown steps still ran").
+			Build(recovered),
 	}
 }
 
@@ -0,0 +1,34 @@
+package execution
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Verbosity controls how much step output is printed while a test runs.
+// no_log steps stay masked at every level.
+type Verbosity int
+
+const (
+	VerbosityQuiet   Verbosity = iota // only failed and errored steps, then the summary
+	VerbosityNormal                   // every step with its arguments and a short result
+	VerbosityVerbose                  // also result data in full instead of truncated
+	VerbosityDebug                    // also arguments as written, before variable substitution
+)
+
+// verbosityNames maps --verbosity values to levels
+var verbosityNames = map[string]Verbosity{
+	"quiet":   VerbosityQuiet,
+	"normal":  VerbosityNormal,
+	"verbose": VerbosityVerbose,
+	"debug":   VerbosityDebug,
+}
+
+// ParseVerbosity parses a --verbosity value
+func ParseVerbosity(value string) (Verbosity, error) {
+	verbosity, ok := verbosityNames[strings.ToLower(strings.TrimSpace(value))]
+	if !ok {
+		return VerbosityNormal, fmt.Errorf("unknown verbosity '%s' (use quiet, normal, verbose or debug)", value)
+	}
+	return verbosity, nil
+}
@@ -0,0 +1,63 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// TestCaseFilter selects test cases by name. A pattern wrapped in slashes, like
+// /^Checkout (guest|member)$/, is a regular expression matched anywhere in the name;
+// any other pattern is a glob over the whole name where * and ? match any characters.
+type TestCaseFilter struct {
+	patterns []*regexp.Regexp
+}
+
+// NewTestCaseFilter compiles name patterns. A filter without patterns matches every test case.
+func NewTestCaseFilter(patterns []string) (*TestCaseFilter, error) {
+	filter := &TestCaseFilter{}
+	for _, pattern := range patterns {
+		expression := nameGlobToRegexp(pattern)
+		if len(pattern) > 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+			expression = pattern[1 : len(pattern)-1]
+		}
+		compiled, err := regexp.Compile(expression)
+		if err != nil {
+			return nil, fmt.Errorf("invalid filter %q: %w", pattern, err)
+		}
+		filter.patterns = append(filter.patterns, compiled)
+	}
+	return filter, nil
+}
+
+// Matches reports whether a test case name matches any of the patterns
+func (f *TestCaseFilter) Matches(name string) bool {
+	if len(f.patterns) == 0 {
+		return true
+	}
+	for _, pattern := range f.patterns {
+		if pattern.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// nameGlobToRegexp converts a name glob to an anchored regular expression.
+// Unlike path globs, * also matches "/" since test case names are not paths.
+func nameGlobToRegexp(glob string) string {
+	var builder strings.Builder
+	builder.WriteString("^")
+	for _, char := range glob {
+		switch char {
+		case '*':
+			builder.WriteString(".*")
+		case '?':
+			builder.WriteString(".")
+		default:
+			builder.WriteString(regexp.QuoteMeta(string(char)))
+		}
+	}
+	builder.WriteString("$")
+	return builder.String()
+}
@@ -0,0 +1,108 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/JianLoong/robogo/internal/common"
+	"github.com/JianLoong/robogo/internal/execution"
+	"github.com/JianLoong/robogo/internal/types"
+)
+
+const panickingBlock = `testcase: "finally after a panic"
+steps:
+  - name: "block"
+    steps:
+      - name: "create"
+        action: log
+        args: ["created"]
+        result: created
+      - name: "explode"
+        action: panic
+      - name: "never runs"
+        action: log
+        args: ["unreachable"]
+    finally:
+      - name: "failing cleanup"
+        action: assert
+        args: [1, "==", 2]
+      - name: "cleanup"
+        action: log
+        args: ["cleaning up ${created}"]
+`
+
+// runWithPanicAction runs the test case in content with a panic action registered
+func runWithPanicAction(t *testing.T, content string, verbosity execution.Verbosity) (*types.TestResult, string) {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), "test.yaml")
+	if err := os.WriteFile(filename, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	testCases, err := LoadTestCases(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runner := NewTestRunner()
+	runner.SetVerbosity(verbosity)
+	runner.actionRegistry.Register("panic", func(args []any, options map[string]any, vars *common.Variables) types.ActionResult {
+		panic("driver state corrupted")
+	})
+	var result *types.TestResult
+	output := captureStdout(t, func() {
+		result, err = runner.RunTest(context.Background(), filename, testCases[0])
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result, output
+}
+
+func TestFinallyRunsAfterNestedStepPanic(t *testing.T) {
+	result, output := runWithPanicAction(t, panickingBlock, execution.VerbosityNormal)
+
+	block := result.Steps[0]
+	if block.Result.ErrorInfo == nil || block.Result.ErrorInfo.Code != "STEP_PANIC" {
+		t.Fatalf("block result %+v, want the STEP_PANIC of its nested step", block.Result)
+	}
+	if strings.Contains(output, "unreachable") {
+		t.Error("a nested step after the panic ran")
+	}
+
+	// Both finally steps ran, with their own statuses, and the cleanup saw the block's variables
+	if len(block.FinallySteps) != 2 {
+		t.Fatalf("got %d finally results, want 2", len(block.FinallySteps))
+	}
+	for i, want := range []types.ActionStatus{types.ActionStatusFailed, types.ActionStatusPassed} {
+		if got := block.FinallySteps[i].Result.Status; got != want {
+			t.Errorf("finally step %q is %s, want %s", block.FinallySteps[i].Name, got, want)
+		}
+	}
+	for _, want := range []string{"cleaning up created", "[FINALLY] ⚠️  Finally step failed: failing cleanup", "[FINALLY] ✓ Finally step passed: cleanup"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output lacks %q:\n%s", want, output)
+		}
+	}
+}
+
+func TestFinallyStatusAtQuietVerbosity(t *testing.T) {
+	_, output := runWithPanicAction(t, panickingBlock, execution.VerbosityQuiet)
+
+	// Failed cleanup is a warning and stays visible; passing cleanup is not printed
+	if !strings.Contains(output, "Finally step failed: failing cleanup") {
+		t.Errorf("quiet output lacks the failed finally step:\n%s", output)
+	}
+	if strings.Contains(output, "Finally step passed") {
+		t.Errorf("quiet output holds a passed finally step:\n%s", output)
+	}
+}
+
+func TestFinallyFailureKeepsBlockError(t *testing.T) {
+	result, _ := runWithPanicAction(t, panickingBlock, execution.VerbosityNormal)
+	if result.Status != string(types.ActionStatusError) {
+		t.Errorf("test status %s, want the panic's ERROR rather than the cleanup's FAIL", result.Status)
+	}
+}
@@ -0,0 +1,261 @@
+package internal
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/JianLoong/robogo/internal/types"
+	"gopkg.in/yaml.v3"
+)
+
+// formatIndent is the indentation used for formatted test files
+const formatIndent = 2
+
+// maxFlowWidth is the longest flow collection, like [a, b] or {k: v}, kept on one line
+const maxFlowWidth = 100
+
+// blankLineMarker is emitted as a comment and replaced by an empty line after encoding,
+// since yaml.v3 does not keep blank lines between nodes
+const blankLineMarker = "#robogo:fmt:blank"
+
+// preferredKeyOrder lists keys in the order used throughout the examples.
+// Keys not listed follow in struct field order, so new fields are never dropped.
+var preferredKeyOrder = map[reflect.Type][]string{
+	reflect.TypeOf(types.TestCase{}): {"testcase", "description", "only_on", "not_on", "circuit_breaker", "collect_assertions", "no_log", "mask_output", "inputs", "outputs", "variables", "defaults", "setup", "steps", "teardown"},
+	reflect.TypeOf(types.Step{}): {
+		"name", "id", "if", "skip_reason", "for", "while", "repeat", "repeat_until", "repeat_while", "eventually",
+		"action", "call", "include", "with", "env", "args", "options", "steps", "finally", "extract", "result", "retry", "cache", "continue",
+	},
+}
+
+// FormatTestFile returns the canonical form of a test file: keys in canonical order,
+// two-space indentation and a blank line between top-level sections and between steps.
+// Comments and unknown keys are kept, and every document of a multi-document file is formatted.
+// The result is checked to parse to the same test cases.
+func FormatTestFile(filename string) ([]byte, error) {
+	if strings.EqualFold(filepath.Ext(filename), ".json") {
+		return nil, fmt.Errorf("only YAML test files can be formatted")
+	}
+	original, err := LoadTestCases(filename)
+	if err != nil {
+		return nil, err
+	}
+	data, err := readTestFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	formatted, err := formatDocuments(data, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Never hand back output that changes the meaning of the test
+	reparsed, err := parseTestDocuments(filename, formatted)
+	if err != nil {
+		return nil, fmt.Errorf("formatted output does not parse: %w", err)
+	}
+	if !reflect.DeepEqual(original, reparsed) {
+		return nil, fmt.Errorf("formatted output does not round-trip; file left unchanged")
+	}
+
+	return formatted, nil
+}
+
+// formatDocuments formats every non-empty document of YAML test file data. edit, when not
+// nil, may change the root node of each document, numbered from 0, before it is formatted.
+func formatDocuments(data []byte, edit func(document int, root *yaml.Node)) ([]byte, error) {
+	// yaml.v3 splits comment blocks on CRLF line endings; restore them after encoding
+	crlf := bytes.Contains(data, []byte("\r\n"))
+	if crlf {
+		data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(formatIndent)
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	index := 0
+	for {
+		var document yaml.Node
+		err := decoder.Decode(&document)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse YAML: %w", err)
+		}
+		// Empty documents are dropped, as the parser skips them
+		if len(document.Content) == 0 || document.Content[0].Tag == "!!null" {
+			continue
+		}
+
+		root := document.Content[0]
+		if edit != nil {
+			edit(index, root)
+		}
+		index++
+		canonicalizeMapping(root, reflect.TypeOf(types.TestCase{}))
+		expandLongFlowNodes(root)
+		for i := 2; i+1 < len(root.Content); i += 2 {
+			// Keep scalar headers like testcase and description together
+			if root.Content[i-1].Kind != yaml.ScalarNode || root.Content[i+1].Kind != yaml.ScalarNode {
+				addBlankLineBefore(root.Content[i])
+			}
+		}
+
+		// The encoder separates documents with "---"
+		if err := encoder.Encode(&document); err != nil {
+			return nil, fmt.Errorf("failed to encode YAML: %w", err)
+		}
+	}
+	encoder.Close()
+
+	formatted := removeBlankLineMarkers(buf.Bytes())
+	if crlf {
+		formatted = bytes.ReplaceAll(formatted, []byte("\n"), []byte("\r\n"))
+	}
+	return formatted, nil
+}
+
+// canonicalizeMapping orders the keys of a mapping node like the fields of structType
+// and recurses into nested structs and step lists. Unknown keys keep their order at the end.
+func canonicalizeMapping(node *yaml.Node, structType reflect.Type) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+
+	order, fieldTypes := yamlFieldOrder(structType)
+
+	type pair struct{ key, value *yaml.Node }
+	known := make(map[string]pair)
+	var unknown []pair
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		p := pair{node.Content[i], node.Content[i+1]}
+		if _, ok := fieldTypes[p.key.Value]; ok {
+			known[p.key.Value] = p
+		} else {
+			unknown = append(unknown, p)
+		}
+	}
+
+	content := make([]*yaml.Node, 0, len(node.Content))
+	for _, name := range order {
+		p, ok := known[name]
+		if !ok {
+			continue
+		}
+		canonicalizeValue(p.value, fieldTypes[name])
+		content = append(content, p.key, p.value)
+	}
+	for _, p := range unknown {
+		content = append(content, p.key, p.value)
+	}
+	node.Content = content
+}
+
+// canonicalizeValue recurses into struct values and lists of structs such as steps
+func canonicalizeValue(node *yaml.Node, fieldType reflect.Type) {
+	for fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+
+	switch fieldType.Kind() {
+	case reflect.Struct:
+		canonicalizeMapping(node, fieldType)
+	case reflect.Slice:
+		elemType := fieldType.Elem()
+		if elemType.Kind() != reflect.Struct || node.Kind != yaml.SequenceNode {
+			return
+		}
+		for i, item := range node.Content {
+			canonicalizeMapping(item, elemType)
+			if i > 0 && elemType == reflect.TypeOf(types.Step{}) {
+				addBlankLineBefore(item)
+			}
+		}
+	}
+}
+
+// expandLongFlowNodes switches flow collections that would not fit on one line to block style.
+// Lists of plain values such as args stay inline, since a long value cannot be shortened.
+// Parents are handled before children so a block node never ends up inside a flow one.
+func expandLongFlowNodes(node *yaml.Node) {
+	if node.Style&yaml.FlowStyle != 0 && (node.Kind == yaml.MappingNode || node.Kind == yaml.SequenceNode) {
+		if node.Kind == yaml.SequenceNode && onlyScalars(node) {
+			return
+		}
+		encoded, err := yaml.Marshal(node)
+		if err == nil && len(strings.TrimSpace(string(encoded))) <= maxFlowWidth {
+			return
+		}
+		node.Style &^= yaml.FlowStyle
+	}
+	for _, child := range node.Content {
+		expandLongFlowNodes(child)
+	}
+}
+
+// onlyScalars reports whether every child of a node is a plain value
+func onlyScalars(node *yaml.Node) bool {
+	for _, child := range node.Content {
+		if child.Kind != yaml.ScalarNode {
+			return false
+		}
+	}
+	return true
+}
+
+// yamlFieldOrder returns the yaml keys of a struct in canonical order with their types
+func yamlFieldOrder(structType reflect.Type) ([]string, map[string]reflect.Type) {
+	var declared []string
+	fieldTypes := make(map[string]reflect.Type)
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		declared = append(declared, name)
+		fieldTypes[name] = field.Type
+	}
+
+	var order []string
+	listed := make(map[string]bool)
+	for _, name := range preferredKeyOrder[structType] {
+		if _, ok := fieldTypes[name]; ok {
+			order = append(order, name)
+			listed[name] = true
+		}
+	}
+	for _, name := range declared {
+		if !listed[name] {
+			order = append(order, name)
+		}
+	}
+	return order, fieldTypes
+}
+
+// addBlankLineBefore marks a node to be preceded by an empty line
+func addBlankLineBefore(node *yaml.Node) {
+	if node.HeadComment == "" {
+		node.HeadComment = blankLineMarker
+	} else if !strings.HasPrefix(node.HeadComment, blankLineMarker) {
+		node.HeadComment = blankLineMarker + "\n" + node.HeadComment
+	}
+}
+
+// removeBlankLineMarkers turns marker comment lines into empty lines
+func removeBlankLineMarkers(data []byte) []byte {
+	lines := strings.Split(string(data), "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) == blankLineMarker {
+			lines[i] = ""
+		}
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,82 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/JianLoong/robogo/internal/types"
+)
+
+// GlobalSetupEnvVar names the global setup file when --global-setup is not given
+const GlobalSetupEnvVar = "ROBOGO_GLOBAL_SETUP"
+
+// globalSetup is a test case run once around the test cases of a run: its setup and steps
+// run before the first test case and its teardown after the last, also when test cases
+// fail or the run is interrupted. The variables it lists in outputs become variables of
+// every test case.
+type globalSetup struct {
+	filename string
+	testCase *types.TestCase
+	runner   *TestRunner
+	result   *types.TestResult // set once the setup and steps ran
+}
+
+// loadGlobalSetup loads the single test case of a global setup file
+func loadGlobalSetup(filename string) (*globalSetup, error) {
+	testCases, err := LoadTestCases(filename)
+	if err != nil {
+		return nil, err
+	}
+	if len(testCases) != 1 {
+		return nil, fmt.Errorf("%s defines %d test cases; a global setup file must define exactly one", filename, len(testCases))
+	}
+	return &globalSetup{filename: filename, testCase: testCases[0]}, nil
+}
+
+// start runs the setup and steps of the global setup with the run variables so far and
+// returns them with the global setup's outputs added. A global setup that did not pass,
+// or did not set an output, is an error.
+func (g *globalSetup) start(ctx context.Context, args ParsedArgs, runVariables map[string]any) (map[string]any, error) {
+	fmt.Printf("\n[GLOBAL SETUP] %s (%s)\n", g.testCase.Name, g.filename)
+	g.runner = newRunRunner(args, runVariables)
+	g.runner.DeferTeardown()
+	result, err := g.runner.RunTest(ctx, g.filename, g.testCase)
+	if err != nil {
+		return nil, fmt.Errorf("global setup '%s' could not run: %w", g.testCase.Name, err)
+	}
+	g.result = result
+
+	if result.Status != string(types.ActionStatusPassed) {
+		printTestSummary(result)
+		reason := result.GetMessage()
+		if result.SkipInfo != nil {
+			reason = result.SkipInfo.Reason
+		}
+		return nil, fmt.Errorf("global setup '%s' did not pass (%s): %s", g.testCase.Name, result.Status, reason)
+	}
+
+	variables := make(map[string]any, len(runVariables)+len(g.testCase.Outputs))
+	for name, value := range runVariables {
+		variables[name] = value
+	}
+	for _, name := range g.testCase.Outputs {
+		if !g.runner.variables.Has(name) {
+			return nil, fmt.Errorf("global setup '%s' did not set its output '%s'", g.testCase.Name, name)
+		}
+		variables[name] = g.runner.variables.Get(name)
+	}
+	if len(g.testCase.Outputs) > 0 {
+		fmt.Printf("[GLOBAL SETUP] Outputs: %s\n", strings.Join(maskedVariableNames(g.testCase.Outputs), ", "))
+	}
+	return variables, nil
+}
+
+// finish runs the teardown of the global setup, if its setup and steps ran
+func (g *globalSetup) finish() {
+	if g.result == nil {
+		return
+	}
+	fmt.Printf("\n[GLOBAL TEARDOWN] %s\n", g.testCase.Name)
+	g.runner.RunDeferredTeardown(g.result)
+}
@@ -0,0 +1,83 @@
+package internal
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/JianLoong/robogo/internal/types"
+)
+
+// runTestYAML runs the test cases of a YAML test file as the run command does, and
+// returns their results and everything they printed
+func runTestYAML(t *testing.T, content string) ([]*types.TestResult, string) {
+	t.Helper()
+	return runTestFiles(t, map[string]string{"test.yaml": content}, "test.yaml")
+}
+
+// runTestFiles writes test files, such as a test case and the files it calls or
+// includes, to a temporary directory and runs the test cases of the main one
+func runTestFiles(t *testing.T, files map[string]string, main string) ([]*types.TestResult, string) {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	filename := filepath.Join(dir, main)
+	testCases, err := LoadTestCases(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var results []*types.TestResult
+	output := captureStdout(t, func() {
+		for _, testCase := range testCases {
+			result, err := NewTestRunner().RunTest(context.Background(), filename, testCase)
+			if err != nil {
+				t.Errorf("%s: %v", testCase.Name, err)
+				continue
+			}
+			results = append(results, result)
+		}
+	})
+	return results, output
+}
+
+// captureStdout returns what run prints to standard output
+func captureStdout(t *testing.T, run func()) string {
+	t.Helper()
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = writer
+	defer func() { os.Stdout = stdout }()
+
+	done := make(chan string)
+	go func() {
+		output, _ := io.ReadAll(reader)
+		done <- string(output)
+	}()
+	run()
+	writer.Close()
+	return <-done
+}
+
+// readFile returns the content of a written report
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
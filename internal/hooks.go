@@ -0,0 +1,304 @@
+package internal
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JianLoong/robogo/internal/common"
+	"github.com/JianLoong/robogo/internal/types"
+	"gopkg.in/yaml.v3"
+)
+
+// HooksEnvVar names the hooks file when --hooks is not given
+const HooksEnvVar = "ROBOGO_HOOKS"
+
+// defaultHookTimeout bounds a hook that sets no timeout; bootstrap scripts may be slow
+const defaultHookTimeout = 5 * time.Minute
+
+// runHooks is the layout of the hooks YAML file: commands run once per run, before the
+// first test case and after the last one
+type runHooks struct {
+	PreRun  []runHook `yaml:"pre_run,omitempty"`
+	PostRun []runHook `yaml:"post_run,omitempty"`
+}
+
+// runHook declares one command. The stdout of a pre_run hook is parsed as a JSON object,
+// such as terraform output -json, or as KEY=value lines, and becomes run variables.
+type runHook struct {
+	Name    string   `yaml:"name,omitempty"`
+	Command string   `yaml:"command"`
+	Args    []string `yaml:"args,omitempty"`
+	Timeout string   `yaml:"timeout,omitempty"`
+	Output  string   `yaml:"output,omitempty"` // json, env or none (default: json when stdout starts with '{', else env)
+
+	timeout time.Duration
+}
+
+// loadRunHooks reads and checks a hooks file. Commands with a path are relative to the
+// file; bare names are looked up in PATH.
+func loadRunHooks(path string) (*runHooks, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hooks file %s: %w", path, err)
+	}
+	var hooks runHooks
+	if err := yaml.Unmarshal(data, &hooks); err != nil {
+		return nil, fmt.Errorf("failed to parse hooks file %s: %w", path, err)
+	}
+
+	baseDir := filepath.Dir(path)
+	for phase, list := range map[string][]runHook{"pre_run": hooks.PreRun, "post_run": hooks.PostRun} {
+		for i := range list {
+			if err := list[i].resolve(baseDir); err != nil {
+				return nil, fmt.Errorf("%s hook %d in %s: %w", phase, i+1, path, err)
+			}
+		}
+	}
+	return &hooks, nil
+}
+
+// resolve checks the hook and resolves its command and timeout
+func (h *runHook) resolve(baseDir string) error {
+	if h.Command == "" {
+		return fmt.Errorf("command is required")
+	}
+	if h.Name == "" {
+		h.Name = filepath.Base(h.Command)
+	}
+	switch h.Output {
+	case "", "json", "env", "none":
+	default:
+		return fmt.Errorf("output must be json, env or none, got '%s'", h.Output)
+	}
+
+	command := h.Command
+	if strings.Contains(command, "/") || strings.ContainsRune(command, filepath.Separator) {
+		if !filepath.IsAbs(command) {
+			absolute, err := filepath.Abs(filepath.Join(baseDir, command))
+			if err != nil {
+				return fmt.Errorf("invalid command path '%s': %w", h.Command, err)
+			}
+			command = absolute
+		}
+	}
+	resolved, err := exec.LookPath(command)
+	if err != nil {
+		return fmt.Errorf("command '%s' not found or not executable: %w", h.Command, err)
+	}
+	h.Command = resolved
+
+	h.timeout = defaultHookTimeout
+	if h.Timeout != "" {
+		if h.timeout, err = time.ParseDuration(h.Timeout); err != nil || h.timeout <= 0 {
+			return fmt.Errorf("invalid timeout '%s'", h.Timeout)
+		}
+	}
+	return nil
+}
+
+// run executes the hook with extra environment variables and returns its stdout
+func (h *runHook) run(ctx context.Context, env map[string]string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, h.Command, h.Args...)
+	cmd.Env = os.Environ()
+	for name, value := range env {
+		cmd.Env = append(cmd.Env, name+"="+value)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("did not finish within %s", h.timeout)
+	}
+	if err != nil {
+		if detail := strings.TrimSpace(stderr.String()); detail != "" {
+			lines := strings.Split(detail, "\n")
+			if len(lines) > 5 {
+				lines = lines[len(lines)-5:]
+			}
+			return nil, fmt.Errorf("%v: %s", err, strings.Join(lines, "; "))
+		}
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
+
+// runPreRunHooks runs the pre_run hooks in order and returns the variables they printed.
+// The variables are also added to env, so later hooks, including post_run hooks, see them.
+// The first failure stops the hooks and is returned.
+func runPreRunHooks(ctx context.Context, hooks []runHook, env map[string]string) (map[string]any, error) {
+	variables := make(map[string]any)
+	for _, hook := range hooks {
+		fmt.Printf("[PRE_RUN] %s\n", hook.Name)
+		stdout, err := hook.run(ctx, env)
+		if err != nil {
+			return nil, fmt.Errorf("pre_run hook '%s' failed: %v", hook.Name, err)
+		}
+		values, err := parseHookOutput(stdout, hook.Output)
+		if err != nil {
+			return nil, fmt.Errorf("pre_run hook '%s' printed invalid output: %v", hook.Name, err)
+		}
+
+		names := sortedKeys(values)
+		for _, name := range names {
+			variables[name] = values[name]
+			if str, ok := values[name].(string); ok {
+				env[name] = str
+			} else if encoded, err := json.Marshal(values[name]); err == nil {
+				env[name] = string(encoded)
+			}
+		}
+		if len(names) > 0 {
+			fmt.Printf("  Variables: %s\n", strings.Join(maskedVariableNames(names), ", "))
+		}
+	}
+	return variables, nil
+}
+
+// runPostRunHooks runs every post_run hook, even after a failed one, with the run's
+// outcome in the environment, and prints what they print. Failures are warnings: they
+// do not change the run's result.
+func runPostRunHooks(ctx context.Context, hooks []runHook, env map[string]string) {
+	for _, hook := range hooks {
+		fmt.Printf("[POST_RUN] %s\n", hook.Name)
+		stdout, err := hook.run(ctx, env)
+		if err != nil {
+			fmt.Printf("[WARN] post_run hook '%s' failed: %v\n", hook.Name, err)
+			continue
+		}
+		if output := strings.TrimSpace(string(stdout)); output != "" {
+			fmt.Printf("  %s\n", strings.ReplaceAll(output, "\n", "\n  "))
+		}
+	}
+}
+
+// parseHookOutput reads the variables a pre_run hook printed. A JSON object in the shape
+// of terraform output -json, {"name": {"value": ..., "type": ...}}, is unwrapped to its values.
+func parseHookOutput(stdout []byte, format string) (map[string]any, error) {
+	trimmed := bytes.TrimSpace(stdout)
+	if format == "" {
+		format = "env"
+		if bytes.HasPrefix(trimmed, []byte("{")) {
+			format = "json"
+		}
+	}
+
+	values := make(map[string]any)
+	switch format {
+	case "none":
+	case "json":
+		if len(trimmed) == 0 {
+			return values, nil
+		}
+		if err := json.Unmarshal(trimmed, &values); err != nil {
+			return nil, fmt.Errorf("expected a JSON object: %v", err)
+		}
+		if isTerraformOutput(values) {
+			for name, output := range values {
+				values[name] = output.(map[string]any)["value"]
+			}
+		}
+	case "env":
+		scanner := bufio.NewScanner(bytes.NewReader(trimmed))
+		for line := 1; scanner.Scan(); line++ {
+			text := strings.TrimSpace(scanner.Text())
+			if text == "" || strings.HasPrefix(text, "#") {
+				continue
+			}
+			name, value, found := strings.Cut(strings.TrimPrefix(text, "export "), "=")
+			name = strings.TrimSpace(name)
+			if !found || name == "" {
+				return nil, fmt.Errorf("line %d is not KEY=value: %s", line, text)
+			}
+			value = strings.TrimSpace(value)
+			if unquoted, err := strconv.Unquote(value); err == nil {
+				value = unquoted
+			} else if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+				value = value[1 : len(value)-1]
+			}
+			values[name] = value
+		}
+	}
+	return values, nil
+}
+
+// isTerraformOutput reports whether every value is a terraform output with a value and a type
+func isTerraformOutput(values map[string]any) bool {
+	if len(values) == 0 {
+		return false
+	}
+	for _, value := range values {
+		output, ok := value.(map[string]any)
+		if !ok {
+			return false
+		}
+		if _, ok := output["value"]; !ok {
+			return false
+		}
+		if _, ok := output["type"]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// maskedVariableNames lists variable names for printing; the values are never printed
+func maskedVariableNames(names []string) []string {
+	listed := make([]string, len(names))
+	for i, name := range names {
+		listed[i] = name
+		if common.IsSensitiveKey(name, common.SensitiveKeys()) {
+			listed[i] = name + " (sensitive)"
+		}
+	}
+	return listed
+}
+
+// hookRunEnvironment is the environment every hook gets: what is being run and where
+func hookRunEnvironment(target, environment string) map[string]string {
+	return map[string]string{
+		"ROBOGO_RUN_TARGET":  target,
+		"ROBOGO_ENVIRONMENT": environment,
+	}
+}
+
+// hookResultEnvironment adds the outcome of the run for post_run hooks
+func hookResultEnvironment(env map[string]string, results []*types.TestResult, anyFailed bool, duration time.Duration) map[string]string {
+	passed, skipped := 0, 0
+	for _, result := range results {
+		switch result.Status {
+		case string(types.ActionStatusPassed):
+			passed++
+		case "SKIPPED":
+			skipped++
+		}
+	}
+	status := string(types.ActionStatusPassed)
+	if anyFailed {
+		status = string(types.ActionStatusFailed)
+	} else if len(results) > 0 && skipped == len(results) {
+		status = "SKIPPED"
+	}
+
+	env["ROBOGO_RUN_STATUS"] = status
+	env["ROBOGO_RUN_TOTAL"] = strconv.Itoa(len(results))
+	env["ROBOGO_RUN_PASSED"] = strconv.Itoa(passed)
+	env["ROBOGO_RUN_FAILED"] = strconv.Itoa(len(results) - passed - skipped)
+	env["ROBOGO_RUN_SKIPPED"] = strconv.Itoa(skipped)
+	env["ROBOGO_RUN_DURATION_MS"] = strconv.FormatInt(duration.Milliseconds(), 10)
+	return env
+}
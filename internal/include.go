@@ -0,0 +1,108 @@
+package internal
+
+import (
+	"os"
+	"strings"
+
+	"github.com/JianLoong/robogo/internal/types"
+	"gopkg.in/yaml.v3"
+)
+
+// snippetFile is a file of shared steps pulled into test cases with include:
+type snippetFile struct {
+	Inputs []string     `yaml:"inputs,omitempty"` // variables the including step must pass with with:
+	Steps  []types.Step `yaml:"steps"`
+}
+
+// expandIncludes replaces the steps of every include step with the steps of its snippet
+// file, relative to file, recursively. stack holds the snippet files being expanded, so
+// a snippet that ends up including itself is reported instead of expanding forever.
+func (p *testFileParser) expandIncludes(steps []types.Step, list *yaml.Node, file string, stack []string) error {
+	items := sequenceItems(list)
+	for i := range steps {
+		step := &steps[i]
+		var node *yaml.Node
+		if i < len(items) {
+			node = items[i]
+		}
+
+		if step.Include == "" {
+			if err := p.expandIncludes(step.Steps, mappingValue(node, "steps"), file, stack); err != nil {
+				return err
+			}
+			if err := p.expandIncludes(step.Finally, mappingValue(node, "finally"), file, stack); err != nil {
+				return err
+			}
+			continue
+		}
+
+		at := &testFileParser{filename: file}
+		if step.Action != "" || len(step.Steps) > 0 || step.Call != "" {
+			return at.errorAt(valueOrSelf(node, "include"), "step '%s': cannot combine 'include' with 'action', 'steps' or 'call'", step.Name)
+		}
+
+		path := resolveCallPath(file, step.Include)
+		for _, including := range stack {
+			if including == path {
+				return at.errorAt(valueOrSelf(node, "include"), "circular include: %s", strings.Join(append(stack, path), " -> "))
+			}
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return at.errorAt(valueOrSelf(node, "include"), "included file '%s' not found (resolved to %s)", step.Include, absolutePath(path))
+		}
+		var document yaml.Node
+		if err := yaml.Unmarshal(data, &document); err != nil {
+			return (&testFileParser{filename: path}).yamlError(err)
+		}
+		var snippet snippetFile
+		if err := document.Decode(&snippet); err != nil {
+			return (&testFileParser{filename: path}).yamlError(err)
+		}
+		var root *yaml.Node
+		if len(document.Content) > 0 {
+			root = document.Content[0]
+		}
+		if len(snippet.Steps) == 0 {
+			return (&testFileParser{filename: path}).errorAt(valueOrSelf(root, "steps"), "included file must have at least one step")
+		}
+
+		var missing []string
+		for _, name := range snippet.Inputs {
+			if _, ok := step.With[name]; !ok {
+				missing = append(missing, name)
+			}
+		}
+		if len(missing) > 0 {
+			return at.errorAt(valueOrSelf(node, "include"), "include of '%s' is missing required input(s): %s", step.Include, strings.Join(missing, ", "))
+		}
+
+		stepsNode := mappingValue(root, "steps")
+		if err := p.expandIncludes(snippet.Steps, stepsNode, path, append(stack, path)); err != nil {
+			return err
+		}
+		setStepSource(snippet.Steps, path)
+		step.Steps = snippet.Steps
+		p.includes[path] = stepsNode
+	}
+	return nil
+}
+
+// setStepSource records the file that declares each step without one, nested steps
+// included; steps of nested includes already have their snippet file
+func setStepSource(steps []types.Step, file string) {
+	for i := range steps {
+		if steps[i].Source == "" {
+			steps[i].Source = file
+		}
+		setStepSource(steps[i].Steps, file)
+		setStepSource(steps[i].Finally, file)
+	}
+}
+
+// includeParser returns a parser for validating the steps of an included file, so their
+// errors point into that file. Step ids stay unique across the whole test case.
+func (p *testFileParser) includeParser(include string) *testFileParser {
+	return &testFileParser{filename: resolveCallPath(p.filename, include), ids: p.ids, includes: p.includes}
+}
@@ -0,0 +1,125 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// scaffoldFile is a starter file written by robogo init
+type scaffoldFile struct {
+	path    string
+	content string
+}
+
+// scaffoldFiles are the starter files of a new project: settings, a secrets template and a
+// small suite of test cases
+var scaffoldFiles = []scaffoldFile{
+	{".env", `# Robogo project settings, loaded from the current directory on every run.
+# Secrets go in .env.local (see .env.local.example), which is loaded first.
+
+# Environments test cases can target with only_on/not_on, and the active one
+ROBOGO_ENVIRONMENTS=dev,staging
+ROBOGO_ENVIRONMENT=dev
+
+# API under test
+API_BASE_URL=https://httpbin.org
+`},
+	{".env.local.example", `# Secrets for this project. Copy this file to .env.local and fill in the values;
+# keep .env.local out of version control.
+#
+# Values of keys such as token, password, secret and authorization are masked in
+# step output, reports and debug dumps. List any other secret keys here:
+ROBOGO_SENSITIVE_KEYS=account_number
+
+API_TOKEN=replace-me
+`},
+	{filepath.Join("tests", "get-request.yaml"), `testcase: "GET request"
+description: "Sample test case: send a request and check the response"
+
+variables:
+  vars:
+    base_url: "${ENV:API_BASE_URL}"
+
+steps:
+  - name: "Send GET request"
+    action: http
+    args: ["GET", "${base_url}/get"]
+    options:
+      timeout: "10s"
+    result: response
+
+  - name: "Status code is 200"
+    action: assert
+    args: ["${response.status_code}", "==", "200"]
+`},
+	{filepath.Join("tests", "bearer-auth.yaml"), `testcase: "Bearer authentication"
+description: "Sample test case: call an endpoint with the API token from .env.local"
+
+variables:
+  vars:
+    base_url: "${ENV:API_BASE_URL}"
+    api_token: "${ENV:API_TOKEN}"
+
+steps:
+  # The Authorization header is masked in the step output
+  - name: "Send authenticated request"
+    action: http
+    args: ["GET", "${base_url}/bearer"]
+    options:
+      timeout: "10s"
+      headers:
+        Authorization: "Bearer ${api_token}"
+    result: response
+
+  - name: "Token is accepted"
+    action: assert
+    args: ["${response.status_code}", "==", "200"]
+`},
+}
+
+// runInit writes the starter files into dir. Existing files are only replaced with --force;
+// otherwise nothing is written.
+func runInit(dir string, args ParsedArgs) {
+	if !args.force {
+		var existing []string
+		for _, file := range scaffoldFiles {
+			path := filepath.Join(dir, file.path)
+			if _, err := os.Stat(path); err == nil {
+				existing = append(existing, path)
+			}
+		}
+		if len(existing) > 0 {
+			fmt.Println("Error: these files already exist (use --force to overwrite them):")
+			for _, path := range existing {
+				fmt.Printf("  %s\n", path)
+			}
+			os.Exit(ExitUsageError)
+		}
+	}
+
+	fmt.Println("Created:")
+	for _, file := range scaffoldFiles {
+		path := filepath.Join(dir, file.path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(ExitUsageError)
+		}
+		if err := os.WriteFile(path, []byte(file.content), 0644); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(ExitUsageError)
+		}
+		fmt.Printf("  %s\n", path)
+	}
+
+	fmt.Println("")
+	fmt.Println("Next steps:")
+	if filepath.Clean(dir) != "." {
+		fmt.Printf("  cd %s\n", dir)
+	}
+	fmt.Println("  cp .env.local.example .env.local       # then set API_TOKEN; add .env.local to .gitignore")
+	fmt.Println("  robogo run tests/get-request.yaml      # run one test case")
+	fmt.Println("  robogo run tests/                      # run the suite")
+	fmt.Println("  robogo watch tests/ --compact          # re-run tests as you edit them")
+	fmt.Println("  source <(robogo completion bash)       # tab completion (also zsh and fish)")
+}
@@ -1,33 +1,218 @@
 package internal
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 
+	"github.com/JianLoong/robogo/internal/common"
 	"github.com/JianLoong/robogo/internal/types"
 	"gopkg.in/yaml.v3"
 )
 
-// validateSteps recursively validates steps and nested steps
-func validateSteps(steps []types.Step, stepPath string) error {
+// stepIDPattern restricts step ids to characters that are safe in reports and log lines
+var stepIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// yamlErrorLine matches the line prefix yaml.v3 puts on syntax and type errors
+var yamlErrorLine = regexp.MustCompile(`^line (\d+): `)
+
+// ParseError is a problem in a test file, reported as file:line:column.
+// Line and Column are 1-based; zero means the position is unknown.
+type ParseError struct {
+	File    string
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e *ParseError) Error() string {
+	location := e.File
+	if e.Line > 0 {
+		location += fmt.Sprintf(":%d", e.Line)
+		if e.Column > 0 {
+			location += fmt.Sprintf(":%d", e.Column)
+		}
+	}
+	if location == "" {
+		return e.Message
+	}
+	return location + ": " + e.Message
+}
+
+// testFileParser validates a decoded test case against the YAML nodes it came from,
+// so errors can point at the offending line
+type testFileParser struct {
+	filename string
+	ids      map[string]string     // step id -> path of the step using it; ids are unique across the test case
+	includes map[string]*yaml.Node // included file -> its steps node, for error positions
+}
+
+// errorAt builds a ParseError positioned at node, or without a position if node is nil
+func (p *testFileParser) errorAt(node *yaml.Node, format string, args ...any) error {
+	parseErr := &ParseError{File: p.filename, Message: fmt.Sprintf(format, args...)}
+	if node != nil {
+		parseErr.Line, parseErr.Column = node.Line, node.Column
+	}
+	return parseErr
+}
+
+// yamlError converts a yaml.v3 error into ParseErrors carrying its line numbers
+func (p *testFileParser) yamlError(err error) error {
+	var messages []string
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		messages = typeErr.Errors
+	} else {
+		messages = []string{strings.TrimPrefix(err.Error(), "yaml: ")}
+	}
+
+	parseErrs := make([]error, 0, len(messages))
+	for _, message := range messages {
+		parseErr := &ParseError{File: p.filename, Message: "invalid YAML: " + message}
+		if match := yamlErrorLine.FindStringSubmatch(message); match != nil {
+			parseErr.Line, _ = strconv.Atoi(match[1])
+			parseErr.Message = "invalid YAML: " + message[len(match[0]):]
+		}
+		parseErrs = append(parseErrs, parseErr)
+	}
+	return errors.Join(parseErrs...)
+}
+
+// validateSteps recursively validates steps and nested steps.
+// list is the sequence node the steps were decoded from and may be nil.
+func (p *testFileParser) validateSteps(steps []types.Step, list *yaml.Node, stepPath string) error {
+	items := sequenceItems(list)
 	for i, step := range steps {
 		currentPath := fmt.Sprintf("%sstep %d", stepPath, i+1)
-		
+		var node *yaml.Node
+		if i < len(items) {
+			node = items[i]
+		}
+
 		if step.Name == "" {
-			return fmt.Errorf("%s: name is required", currentPath)
+			return p.errorAt(node, "%s: name is required", currentPath)
+		}
+
+		if step.ID != "" {
+			idNode := valueOrSelf(node, "id")
+			if !stepIDPattern.MatchString(step.ID) {
+				return p.errorAt(idNode, "%s: id '%s' may only contain letters, digits, '_' and '-'", currentPath, step.ID)
+			}
+			if previous, exists := p.ids[step.ID]; exists {
+				return p.errorAt(idNode, "%s: id '%s' is already used by %s", currentPath, step.ID, previous)
+			}
+			p.ids[step.ID] = currentPath
+		}
+
+		if step.Sensitive && step.Action != "assert" {
+			return p.errorAt(valueOrSelf(node, "sensitive"), "%s: 'sensitive' is only supported on assert steps", currentPath)
+		}
+
+		if common.ReservedVariable(step.Result) {
+			return p.errorAt(valueOrSelf(node, "result"), "%s: %s", currentPath, reservedVariableMessage(step.Result))
+		}
+		for name := range step.With {
+			if common.ReservedVariable(name) {
+				return p.errorAt(valueOrSelf(mappingValue(node, "with"), name), "%s: with: %s", currentPath, reservedVariableMessage(name))
+			}
+		}
+
+		if step.Repeat < 0 {
+			return p.errorAt(valueOrSelf(node, "repeat"), "%s: repeat must not be negative", currentPath)
+		}
+
+		if step.MaxDataBytes < 0 {
+			return p.errorAt(valueOrSelf(node, "max_data_bytes"), "%s: max_data_bytes must be positive", currentPath)
+		}
+
+		if len(step.Env) > 0 {
+			if step.Action == "" {
+				return p.errorAt(valueOrSelf(node, "env"), "%s: 'env' is only supported on action steps", currentPath)
+			}
+			if err := validateStepEnv(step.Env); err != nil {
+				return p.errorAt(valueOrSelf(node, "env"), "%s: %v", currentPath, err)
+			}
+		}
+
+		if step.Attach != "" {
+			if step.Action == "" {
+				return p.errorAt(valueOrSelf(node, "attach"), "%s: 'attach' is only supported on action steps", currentPath)
+			}
+			if filepath.Base(step.Attach) != step.Attach {
+				return p.errorAt(valueOrSelf(node, "attach"), "%s: attach '%s' must be a file name without directories", currentPath, step.Attach)
+			}
 		}
-		
-		if step.Action == "" && len(step.Steps) == 0 {
-			return fmt.Errorf("%s: either 'action' or 'steps' field is required", currentPath)
+
+		if step.Eventually != nil {
+			if step.Retry != nil || step.Repeat > 0 {
+				return p.errorAt(valueOrSelf(node, "eventually"), "%s: cannot combine 'eventually' with 'retry' or 'repeat'", currentPath)
+			}
+			if _, _, err := step.Eventually.Timing(); err != nil {
+				return p.errorAt(valueOrSelf(node, "eventually"), "%s: eventually %v", currentPath, err)
+			}
+		}
+
+		if step.Cache != nil {
+			if step.Action == "" {
+				return p.errorAt(valueOrSelf(node, "cache"), "%s: 'cache' is only supported on action steps", currentPath)
+			}
+			// A cached result would make every retry or repeat see the first response
+			if step.Retry != nil || step.Repeat > 0 || step.Eventually != nil {
+				return p.errorAt(valueOrSelf(node, "cache"), "%s: cannot combine 'cache' with 'retry', 'repeat' or 'eventually'", currentPath)
+			}
+			if _, err := step.Cache.Lifetime(); err != nil {
+				return p.errorAt(valueOrSelf(node, "cache"), "%s: cache %v", currentPath, err)
+			}
+		}
+
+		if step.Call != "" {
+			if step.Action != "" || len(step.Steps) > 0 {
+				return p.errorAt(valueOrSelf(node, "call"), "%s: cannot combine 'call' with 'action' or 'steps'", currentPath)
+			}
+			if err := p.validateCall(step, node, currentPath); err != nil {
+				return err
+			}
+		} else if len(step.With) > 0 && step.Include == "" {
+			return p.errorAt(valueOrSelf(node, "with"), "%s: 'with' needs 'call' or 'include'", currentPath)
+		}
+
+		// Included steps were expanded in place; their errors point into the included file
+		if step.Include != "" {
+			sub := p.includeParser(step.Include)
+			if err := sub.validateSteps(step.Steps, p.includes[sub.filename], currentPath+" -> "); err != nil {
+				return err
+			}
+			continue
 		}
-		
+
+		if step.Action == "" && len(step.Steps) == 0 && step.Call == "" {
+			return p.errorAt(node, "%s: either 'action', 'steps' or 'call' field is required", currentPath)
+		}
+
 		if step.Action != "" && len(step.Steps) > 0 {
-			return fmt.Errorf("%s: cannot have both 'action' and 'steps' fields", currentPath)
+			return p.errorAt(valueOrSelf(node, "steps"), "%s: cannot have both 'action' and 'steps' fields", currentPath)
+		}
+
+		if len(step.Finally) > 0 && len(step.Steps) == 0 {
+			return p.errorAt(valueOrSelf(node, "finally"), "%s: 'finally' needs 'steps' to run after; use teardown for the test case", currentPath)
 		}
-		
+
 		// Recursively validate nested steps
 		if len(step.Steps) > 0 {
-			if err := validateSteps(step.Steps, currentPath+" -> "); err != nil {
+			if err := p.validateSteps(step.Steps, mappingValue(node, "steps"), currentPath+" -> "); err != nil {
+				return err
+			}
+		}
+		if len(step.Finally) > 0 {
+			if err := p.validateSteps(step.Finally, mappingValue(node, "finally"), currentPath+" -> finally "); err != nil {
 				return err
 			}
 		}
@@ -35,45 +220,360 @@ func validateSteps(steps []types.Step, stepPath string) error {
 	return nil
 }
 
-// Simple parser - no complex validation, just parse YAML
-func ParseTestFile(filename string) (*types.TestCase, error) {
+// validateCall checks that the file of a call step exists and that every input it
+// declares is passed with with:. The called file is fully validated when it runs.
+func (p *testFileParser) validateCall(step types.Step, node *yaml.Node, currentPath string) error {
+	if p.filename == "" {
+		return nil
+	}
+	path := resolveCallPath(p.filename, step.Call)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return p.errorAt(valueOrSelf(node, "call"), "%s: called test case file '%s' not found (resolved to %s)", currentPath, step.Call, absolutePath(path))
+	}
+	var callee types.TestCase
+	if err := yaml.Unmarshal(data, &callee); err != nil {
+		return p.errorAt(valueOrSelf(node, "call"), "%s: called test case file '%s' is invalid: %v", currentPath, absolutePath(path), err)
+	}
+	if missing := missingCallInputs(&callee, step.With); len(missing) > 0 {
+		return p.errorAt(valueOrSelf(node, "call"), "%s: call to '%s' is missing required input(s): %s", currentPath, step.Call, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// validateVariableNames rejects variables a test may not set, see common.ReservedVariable
+func (p *testFileParser) validateVariableNames(variables types.TestVariables, node *yaml.Node) error {
+	for name := range variables.Vars {
+		if common.ReservedVariable(name) {
+			return p.errorAt(valueOrSelf(mappingValue(node, "vars"), name), "variables.vars: %s", reservedVariableMessage(name))
+		}
+	}
+	for name := range variables.Types {
+		if common.ReservedVariable(name) {
+			return p.errorAt(valueOrSelf(mappingValue(node, "types"), name), "variables.types: %s", reservedVariableMessage(name))
+		}
+	}
+	return nil
+}
+
+// validateVariableTypes checks the declarations in variables.types and the values the test
+// file gives declared variables. Values with ${...} are only known at run time and are
+// checked when the test case starts.
+func (p *testFileParser) validateVariableTypes(variables types.TestVariables, node *yaml.Node) error {
+	typesNode, varsNode := mappingValue(node, "types"), mappingValue(node, "vars")
+	names := make([]string, 0, len(variables.Types))
+	for name := range variables.Types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		declaration := variables.Types[name]
+		if err := declaration.Validate(); err != nil {
+			return p.errorAt(valueOrSelf(typesNode, name), "variables.types.%s: %v", name, err)
+		}
+		value, ok := variables.Vars[name]
+		if str, isString := value.(string); !ok || (isString && strings.Contains(str, "${")) {
+			continue
+		}
+		if err := declaration.Check(name, value); err != nil {
+			return p.errorAt(valueOrSelf(varsNode, name), "%v", err)
+		}
+	}
+	return nil
+}
+
+// reservedVariableMessage explains why a test may not set a variable, see common.ReservedVariable
+func reservedVariableMessage(name string) string {
+	return fmt.Sprintf("variable name '%s' is reserved for the running step's values, e.g. ${%s.idempotency_key}; rename the variable", name, name)
+}
+
+// absolutePath returns path as an absolute path for error messages, so they can be
+// followed from CI logs; path is returned unchanged if it cannot be resolved
+func absolutePath(path string) string {
+	if absolute, err := filepath.Abs(path); err == nil {
+		return absolute
+	}
+	return path
+}
+
+// mappingValue returns the value node for key in a mapping node, or nil if absent
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	node = resolveAlias(node)
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return resolveAlias(node.Content[i+1])
+		}
+	}
+	return nil
+}
+
+// valueOrSelf returns the value node for key, falling back to the mapping itself
+func valueOrSelf(node *yaml.Node, key string) *yaml.Node {
+	if value := mappingValue(node, key); value != nil {
+		return value
+	}
+	return node
+}
+
+// sequenceItems returns the items of a sequence node with aliases resolved
+func sequenceItems(node *yaml.Node) []*yaml.Node {
+	node = resolveAlias(node)
+	if node == nil || node.Kind != yaml.SequenceNode {
+		return nil
+	}
+	items := make([]*yaml.Node, len(node.Content))
+	for i, item := range node.Content {
+		items[i] = resolveAlias(item)
+	}
+	return items
+}
+
+// resolveAlias follows an alias node to the node it refers to
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node != nil && node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	return node
+}
+
+// testFileLoaders parses test files by extension. Files with other extensions are read as YAML.
+var testFileLoaders = map[string]func(filename string, data []byte) ([]*types.TestCase, error){
+	".yaml": parseTestDocuments,
+	".yml":  parseTestDocuments,
+	".json": parseJSONTestData,
+}
+
+// LoadTestCases reads the test cases defined in a file. A YAML file may hold several
+// test cases as documents separated by "---"; a JSON file holds one test case.
+func LoadTestCases(filename string) ([]*types.TestCase, error) {
+	data, err := readTestFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	loader, ok := testFileLoaders[strings.ToLower(filepath.Ext(filename))]
+	if !ok {
+		loader = parseTestDocuments
+	}
+	return loader(filename, data)
+}
+
+// readTestFile reads the raw contents of a test file
+func readTestFile(filename string) ([]byte, error) {
 	data, err := os.ReadFile(filename)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file %s: %w", filename, err)
 	}
+	return data, nil
+}
+
+// parseJSONTestData parses a JSON test case. JSON is valid YAML, so it shares the YAML
+// validation; syntax errors are reported by the JSON decoder for clearer messages.
+func parseJSONTestData(filename string, data []byte) ([]*types.TestCase, error) {
+	var document any
+	if err := json.Unmarshal(data, &document); err != nil {
+		parseErr := &ParseError{File: filename, Message: "invalid JSON: " + err.Error()}
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			// Offset counts the bytes read, including the one that failed
+			parseErr.Line, parseErr.Column = offsetPosition(data, max(syntaxErr.Offset-1, 0))
+		}
+		return nil, parseErr
+	}
+	if _, ok := document.(map[string]any); !ok {
+		return nil, &ParseError{File: filename, Line: 1, Column: 1, Message: "a JSON test file must contain a single test case object"}
+	}
+	return parseTestDocuments(filename, data)
+}
+
+// offsetPosition converts a byte offset into a 1-based line and column
+func offsetPosition(data []byte, offset int64) (int, int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := len(before) - bytes.LastIndexByte(before, '\n')
+	return line, column
+}
+
+// parseTestDocuments parses and validates every YAML document in data as a test case.
+// Errors are ParseErrors located in filename.
+func parseTestDocuments(filename string, data []byte) ([]*types.TestCase, error) {
+	var testCases []*types.TestCase
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var document yaml.Node
+		err := decoder.Decode(&document)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, (&testFileParser{filename: filename}).yamlError(err)
+		}
+		// Skip empty documents, e.g. after a trailing "---"
+		if len(document.Content) == 0 || document.Content[0].Tag == "!!null" {
+			continue
+		}
+		testCase, err := parseTestDocument(filename, &document)
+		if err != nil {
+			return nil, err
+		}
+		testCases = append(testCases, testCase)
+	}
+
+	if len(testCases) == 0 {
+		// An empty file fails the usual validation
+		testCase, err := parseTestDocument(filename, &yaml.Node{Kind: yaml.DocumentNode})
+		if err != nil {
+			return nil, err
+		}
+		testCases = append(testCases, testCase)
+	}
+	return testCases, nil
+}
+
+// parseTestDocument decodes and validates one YAML document as a test case
+func parseTestDocument(filename string, document *yaml.Node) (*types.TestCase, error) {
+	parser := &testFileParser{filename: filename, ids: make(map[string]string), includes: make(map[string]*yaml.Node)}
 
+	// Decode through the node tree so validation errors can report positions
 	var testCase types.TestCase
-	if err := yaml.Unmarshal(data, &testCase); err != nil {
-		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	var root *yaml.Node
+	if len(document.Content) > 0 {
+		root = document.Content[0]
+		if err := document.Decode(&testCase); err != nil {
+			return nil, parser.yamlError(err)
+		}
 	}
 
+	// Included steps are validated and run like the test case's own
+	for _, section := range []struct {
+		steps []types.Step
+		key   string
+	}{{testCase.Setup, "setup"}, {testCase.Steps, "steps"}, {testCase.Teardown, "teardown"}} {
+		if err := parser.expandIncludes(section.steps, mappingValue(root, section.key), filename, []string{filename}); err != nil {
+			return nil, err
+		}
+		setStepSource(section.steps, filename)
+	}
+
+	// Steps are validated and run with their defaults applied
+	applyStepDefaults(&testCase)
+	applyTestCaseNoLog(&testCase)
+
 	// Basic validation
 	if testCase.Name == "" {
-		return nil, fmt.Errorf("test case name is required")
+		return nil, parser.errorAt(valueOrSelf(root, "testcase"), "test case name is required")
 	}
 
 	if len(testCase.Steps) == 0 {
-		return nil, fmt.Errorf("test case must have at least one step")
+		return nil, parser.errorAt(valueOrSelf(root, "steps"), "test case must have at least one step")
+	}
+
+	// Validate variable names, declared variable types and the values given to them
+	if err := parser.validateVariableNames(testCase.Variables, mappingValue(root, "variables")); err != nil {
+		return nil, err
+	}
+	if err := parser.validateVariableTypes(testCase.Variables, mappingValue(root, "variables")); err != nil {
+		return nil, err
 	}
 
 	// Validate main steps
-	if err := validateSteps(testCase.Steps, ""); err != nil {
+	if err := parser.validateSteps(testCase.Steps, mappingValue(root, "steps"), ""); err != nil {
 		return nil, err
 	}
 
 	// Validate setup steps if present
 	if len(testCase.Setup) > 0 {
-		if err := validateSteps(testCase.Setup, "setup "); err != nil {
+		if err := parser.validateSteps(testCase.Setup, mappingValue(root, "setup"), "setup "); err != nil {
 			return nil, err
 		}
 	}
 
 	// Validate teardown steps if present
 	if len(testCase.Teardown) > 0 {
-		if err := validateSteps(testCase.Teardown, "teardown "); err != nil {
+		if err := parser.validateSteps(testCase.Teardown, mappingValue(root, "teardown"), "teardown "); err != nil {
 			return nil, err
 		}
 	}
 
 	return &testCase, nil
 }
+
+// DuplicateStepNames describes step names used more than once in a test case, which make
+// results and logs ambiguous. Steps with an id are told apart by it and are not reported.
+func DuplicateStepNames(testCase *types.TestCase) []string {
+	var names []string
+	paths := make(map[string][]string)
+	var collect func(steps []types.Step, stepPath string)
+	collect = func(steps []types.Step, stepPath string) {
+		for i, step := range steps {
+			currentPath := fmt.Sprintf("%sstep %d", stepPath, i+1)
+			if step.ID == "" {
+				if _, seen := paths[step.Name]; !seen {
+					names = append(names, step.Name)
+				}
+				paths[step.Name] = append(paths[step.Name], currentPath)
+			}
+			// Steps included more than once share their names by design
+			if step.Include == "" {
+				collect(step.Steps, currentPath+" -> ")
+			}
+			collect(step.Finally, currentPath+" -> finally ")
+		}
+	}
+	collect(testCase.Setup, "setup ")
+	collect(testCase.Steps, "")
+	collect(testCase.Teardown, "teardown ")
+
+	var duplicates []string
+	for _, name := range names {
+		if len(paths[name]) > 1 {
+			duplicates = append(duplicates, fmt.Sprintf("step name '%s' is used by %s", name, strings.Join(paths[name], ", ")))
+		}
+	}
+	return duplicates
+}
+
+// envNamePattern matches portable environment variable names
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// reservedEnvNames are environment variables a step may not replace, since robogo and the
+// tools it runs depend on them. PATH may be extended by including ${ENV:PATH} in its value.
+var reservedEnvNames = map[string]bool{
+	"PATH": true, "HOME": true, "USER": true, "SHELL": true, "PWD": true, "TMPDIR": true,
+}
+
+// validateStepEnv checks that a step's env only sets variables it can safely override
+func validateStepEnv(env map[string]string) error {
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		upper := strings.ToUpper(name)
+		switch {
+		case !envNamePattern.MatchString(name):
+			return fmt.Errorf("env name '%s' may only contain letters, digits and '_', and may not start with a digit", name)
+		case strings.HasPrefix(upper, "ROBOGO_"):
+			return fmt.Errorf("env name '%s' is reserved: robogo settings are read at startup, set them in .env instead", name)
+		case strings.HasPrefix(upper, "LD_") || strings.HasPrefix(upper, "DYLD_"):
+			return fmt.Errorf("env name '%s' is reserved: dynamic loader settings cannot be set per step", name)
+		case upper == "PATH" && strings.Contains(env[name], "${ENV:"+name+"}"):
+			// Extends the search path rather than replacing it
+		case reservedEnvNames[upper]:
+			hint := ""
+			if upper == "PATH" {
+				hint = `; extend it instead, e.g. "./bin:${ENV:PATH}"`
+			}
+			return fmt.Errorf("env name '%s' is reserved and cannot be replaced%s", name, hint)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const singleCaseYAML = `testcase: "first"
+steps:
+  - name: "s"
+    action: log
+    args: ["x"]
+`
+
+const singleCaseJSON = `{
+  "testcase": "json case",
+  "steps": [
+    {"name": "s", "action": "log", "args": ["x"]}
+  ]
+}
+`
+
+func TestLoadTestCases(t *testing.T) {
+	tests := []struct {
+		name      string
+		file      string
+		content   string
+		want      []string // test case names
+		errText   string   // part of the error, when loading fails
+		errLine   int
+		errColumn int
+	}{
+		{name: "yaml", file: "case.yaml", content: singleCaseYAML, want: []string{"first"}},
+		{name: "yml", file: "case.yml", content: singleCaseYAML, want: []string{"first"}},
+		{name: "upper case extension", file: "case.YAML", content: singleCaseYAML, want: []string{"first"}},
+		{name: "other extension read as yaml", file: "case.txt", content: singleCaseYAML, want: []string{"first"}},
+		{name: "json", file: "case.json", content: singleCaseJSON, want: []string{"json case"}},
+		{name: "upper case json", file: "case.JSON", content: singleCaseJSON, want: []string{"json case"}},
+		{
+			name:    "multi-document yaml",
+			file:    "cases.yaml",
+			content: singleCaseYAML + "---\n" + strings.Replace(singleCaseYAML, "first", "second", 1),
+			want:    []string{"first", "second"},
+		},
+		{
+			name:    "empty and trailing documents skipped",
+			file:    "cases.yaml",
+			content: "---\n" + singleCaseYAML + "---\n---\n" + strings.Replace(singleCaseYAML, "first", "second", 1) + "---\n",
+			want:    []string{"first", "second"},
+		},
+		{
+			name:    "invalid second document",
+			file:    "cases.yaml",
+			content: singleCaseYAML + "---\ntestcase: \"broken\"\nsteps: 5\n",
+			errText: "cases.yaml",
+		},
+		{
+			name:    "json syntax error has a position",
+			file:    "case.json",
+			content: "{\n  \"testcase\": \"x\",\n  \"steps\": [,]\n}\n",
+			errText: "invalid JSON",
+			errLine: 3, errColumn: 13,
+		},
+		{
+			name:    "json array rejected",
+			file:    "case.json",
+			content: "[" + singleCaseJSON + "]",
+			errText: "single test case object",
+			errLine: 1, errColumn: 1,
+		},
+		{name: "empty file", file: "empty.yaml", content: "", errText: "empty.yaml"},
+		{
+			name:    "reserved variable in vars",
+			file:    "case.yaml",
+			content: "testcase: \"x\"\nvariables:\n  vars:\n    step: \"mine\"\n" + singleCaseYAML[strings.Index(singleCaseYAML, "steps:"):],
+			errText: "variable name 'step' is reserved",
+			errLine: 4, errColumn: 11,
+		},
+		{
+			name:    "reserved variable in result",
+			file:    "case.yaml",
+			content: singleCaseYAML + "    result: step\n",
+			errText: "step 1: variable name 'step' is reserved",
+			errLine: 6, errColumn: 13,
+		},
+		{
+			name:    "negative repeat",
+			file:    "case.yaml",
+			content: singleCaseYAML + "    repeat: -1\n",
+			errText: "step 1: repeat must not be negative",
+			errLine: 6, errColumn: 13,
+		},
+		{
+			name:    "reserved variable in with",
+			file:    "case.yaml",
+			content: "testcase: \"x\"\nsteps:\n  - name: \"block\"\n    with:\n      step: 1\n    steps:\n      - name: \"s\"\n        action: log\n        args: [\"x\"]\n",
+			errText: "with: variable name 'step' is reserved",
+			errLine: 5, errColumn: 13,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), tc.file)
+			if err := os.WriteFile(filename, []byte(tc.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			testCases, err := LoadTestCases(filename)
+			if tc.errText != "" {
+				if err == nil {
+					t.Fatalf("loaded %d test cases, want an error containing %q", len(testCases), tc.errText)
+				}
+				if !strings.Contains(err.Error(), tc.errText) {
+					t.Errorf("error %q does not contain %q", err, tc.errText)
+				}
+				var parseErr *ParseError
+				if !errors.As(err, &parseErr) {
+					t.Fatalf("error %T is not a *ParseError", err)
+				}
+				if tc.errLine > 0 && (parseErr.Line != tc.errLine || parseErr.Column != tc.errColumn) {
+					t.Errorf("error at %d:%d, want %d:%d", parseErr.Line, parseErr.Column, tc.errLine, tc.errColumn)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, testCase := range testCases {
+				names = append(names, testCase.Name)
+			}
+			if strings.Join(names, ",") != strings.Join(tc.want, ",") {
+				t.Errorf("got test cases %q, want %q", names, tc.want)
+			}
+		})
+	}
+}
+
+func TestJSONAndYAMLLoadTheSameTestCase(t *testing.T) {
+	dir := t.TempDir()
+	yamlFile, jsonFile := filepath.Join(dir, "case.yaml"), filepath.Join(dir, "case.json")
+	yamlContent := strings.Replace(singleCaseYAML, "first", "json case", 1)
+	if err := os.WriteFile(yamlFile, []byte(yamlContent), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(jsonFile, []byte(singleCaseJSON), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	fromYAML, err := LoadTestCases(yamlFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromJSON, err := LoadTestCases(jsonFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, want := fromJSON[0], fromYAML[0]
+	if got.Name != want.Name || len(got.Steps) != len(want.Steps) {
+		t.Fatalf("JSON test case %+v differs from YAML %+v", got, want)
+	}
+	if got.Steps[0].Name != want.Steps[0].Name || got.Steps[0].Action != want.Steps[0].Action ||
+		fmt.Sprint(got.Steps[0].Args) != fmt.Sprint(want.Steps[0].Args) {
+		t.Errorf("JSON step %+v differs from YAML %+v", got.Steps[0], want.Steps[0])
+	}
+}
+
+func TestDiscoveryFindsEveryLoaderExtension(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.yaml":         singleCaseYAML,
+		"b.yml":          singleCaseYAML,
+		"c.json":         singleCaseJSON,
+		"plugins.yaml":   "plugins: []\n",
+		"notes.txt":      "not a test",
+		".hidden/d.yaml": singleCaseYAML,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0o755)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	found, err := DiscoverTestFiles(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, path := range found {
+		names = append(names, filepath.Base(path))
+	}
+	if got, want := strings.Join(names, ","), "a.yaml,b.yml,c.json"; got != want {
+		t.Errorf("discovered %s, want %s", got, want)
+	}
+}
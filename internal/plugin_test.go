@@ -0,0 +1,125 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/JianLoong/robogo/internal/types"
+)
+
+// echoPlugin is a plugin that returns the request it received as its data
+const echoPlugin = `#!/bin/sh
+printf '{"status": "PASS", "data": %s}' "$(cat)"
+`
+
+const echoPluginManifest = `plugins:
+  - name: echo_request
+    command: ./echo-request.sh
+    timeout: "5s"
+    variables: ["tenant", "api_token", "account", "region", "missing"]
+`
+
+const pluginVariablesTest = `testcase: "plugin variables"
+variables:
+  vars:
+    tenant: "acme"
+    api_token: "s3cr3t"
+    region: "eu-west-1"
+    internal_only: "not for plugins"
+    account:
+      id: 7
+      password: "hunter2"
+steps:
+  - name: "echo"
+    action: echo_request
+    sensitive_fields: ["region"]
+    result: request
+  - name: "account password kept"
+    action: assert
+    args: ["${account.password}", "==", "hunter2"]
+`
+
+const pluginTimeoutTest = `testcase: "plugin timeout"
+steps:
+  - name: "bad timeout"
+    action: echo_request
+    options:
+      timeout: "soon"
+`
+
+// runWithEchoPlugin runs the test case in content with the echo plugin loaded
+func runWithEchoPlugin(t *testing.T, content string) (*TestRunner, *types.TestResult) {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{"echo-request.sh": echoPlugin, "plugins.yaml": echoPluginManifest, "test.yaml": content}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	filename := filepath.Join(dir, "test.yaml")
+	testCases, err := LoadTestCases(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runner := NewTestRunner()
+	if err := runner.LoadPlugins(filepath.Join(dir, "plugins.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	var result *types.TestResult
+	captureStdout(t, func() {
+		result, err = runner.RunTest(context.Background(), filename, testCases[0])
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return runner, result
+}
+
+func TestPluginReceivesOnlyDeclaredVariables(t *testing.T) {
+	runner, result := runWithEchoPlugin(t, pluginVariablesTest)
+	if result.Status != string(types.ActionStatusPassed) {
+		t.Fatalf("test status %s: %+v", result.Status, result.ErrorInfo)
+	}
+
+	variables, ok := runner.variables.Get("request").(map[string]any)["variables"].(map[string]any)
+	if !ok {
+		t.Fatalf("request has no variables: %v", runner.variables.Get("request"))
+	}
+	tests := []struct {
+		variable string
+		want     any
+	}{
+		{"tenant", "acme"},
+		{"api_token", "***"}, // built-in sensitive key
+		{"region", "***"},    // the step's sensitive_fields
+	}
+	for _, tc := range tests {
+		if got := variables[tc.variable]; got != tc.want {
+			t.Errorf("%s = %v, want %v", tc.variable, got, tc.want)
+		}
+	}
+	account, _ := variables["account"].(map[string]any)
+	if account["password"] != "***" || account["id"] != float64(7) {
+		t.Errorf("account = %v, want the password masked and the id kept", account)
+	}
+	for _, name := range []string{"internal_only", "missing", "request"} {
+		if _, sent := variables[name]; sent {
+			t.Errorf("%s was sent to the plugin", name)
+		}
+	}
+}
+
+func TestPluginRejectsInvalidTimeout(t *testing.T) {
+	_, result := runWithEchoPlugin(t, pluginTimeoutTest)
+	if len(result.Steps) != 1 {
+		t.Fatalf("got %d step results, want 1", len(result.Steps))
+	}
+	step := result.Steps[0].Result
+	if step.Status != types.ActionStatusError || step.ErrorInfo == nil || step.ErrorInfo.Code != "INVALID_TIMEOUT" {
+		t.Errorf("step result = %s %+v, want ERROR INVALID_TIMEOUT", step.Status, step.ErrorInfo)
+	}
+}
@@ -0,0 +1,62 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/JianLoong/robogo/internal/execution"
+	"github.com/JianLoong/robogo/internal/types"
+)
+
+const repeatTest = `testcase: "repeat"
+steps:
+  - name: "stable"
+    action: log
+    args: ["x"]
+    repeat: 3
+  - name: "until"
+    action: log
+    args: ["x"]
+    repeat: 3
+    repeat_until: "${missing} == 1"
+`
+
+func TestRepeatOutputFollowsVerbosity(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.yaml")
+	if err := os.WriteFile(filename, []byte(repeatTest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	testCases, err := LoadTestCases(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		verbosity execution.Verbosity
+		lines     int
+	}{
+		// 3 iterations and a summary, then 1 iteration, the stop and a summary
+		{execution.VerbosityNormal, 7},
+		{execution.VerbosityQuiet, 0},
+	} {
+		runner := NewTestRunner()
+		runner.SetVerbosity(tc.verbosity)
+		var result *types.TestResult
+		output := captureStdout(t, func() {
+			result, err = runner.RunTest(context.Background(), filename, testCases[0])
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Count(output, "[Repeat]"); got != tc.lines {
+			t.Errorf("verbosity %d printed %d [Repeat] lines, want %d:\n%s", tc.verbosity, got, tc.lines, output)
+		}
+		// The condition error errors the step at every verbosity
+		if info := result.Steps[1].Result.ErrorInfo; info == nil || info.Code != "CONDITION_UNRESOLVED_VARIABLE" {
+			t.Errorf("verbosity %d: repeat_until error gave %+v", tc.verbosity, result.Steps[1].Result)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/JianLoong/robogo/internal/types"
+)
+
+// historyRunsKept is how many runs a --history file keeps; older runs are dropped
+const historyRunsKept = 50
+
+// runHistory is the on-disk layout of the --history file, oldest run first
+type runHistory struct {
+	Runs []runReport `json:"runs"`
+}
+
+// runDelta is what changed since the previous run of the same target and environment
+type runDelta struct {
+	Since        time.Time    `json:"since"`
+	NewlyFailing []caseChange `json:"newly_failing"`
+	NewlyPassing []caseChange `json:"newly_passing"`
+	SlowerCases  []caseChange `json:"slower_cases"`
+	SlowerSteps  []stepChange `json:"slower_steps"`
+}
+
+// stepChange pairs the old and new outcome of one step of a test case
+type stepChange struct {
+	File     string        `json:"file"`
+	TestCase string        `json:"testcase"`
+	Old      runReportStep `json:"old"`
+	New      runReportStep `json:"new"`
+}
+
+// recordRunHistory compares a run with the previous run of the same target and environment
+// in the history file, prints the delta and appends the run. It returns the delta, or nil
+// for the first run.
+func recordRunHistory(path, target, environment string, planned []plannedTestCase, results []*types.TestResult, threshold float64) (*runDelta, error) {
+	history, err := loadRunHistory(path)
+	if err != nil {
+		return nil, err
+	}
+
+	current := buildRunReport(target, environment, planned, results, true)
+	var delta *runDelta
+	for i := len(history.Runs) - 1; i >= 0; i-- {
+		previous := &history.Runs[i]
+		if previous.Target == target && previous.Environment == environment {
+			delta = diffRuns(previous, current, threshold)
+			break
+		}
+	}
+
+	history.Runs = append(history.Runs, *current)
+	if len(history.Runs) > historyRunsKept {
+		history.Runs = history.Runs[len(history.Runs)-historyRunsKept:]
+	}
+	return delta, writeJSONFile(path, history)
+}
+
+// loadRunHistory reads a history file; a missing file is an empty history
+func loadRunHistory(path string) (*runHistory, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &runHistory{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	var history runHistory
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil, fmt.Errorf("%s is not a robogo history file: %w", path, err)
+	}
+	return &history, nil
+}
+
+// diffRuns finds the test cases that started failing or passing and the test cases and
+// steps that got slower by threshold or more
+func diffRuns(previous, current *runReport, threshold float64) *runDelta {
+	diff := diffRunReports(previous, current, threshold)
+	delta := &runDelta{
+		Since:        previous.CreatedAt,
+		NewlyFailing: []caseChange{},
+		NewlyPassing: []caseChange{},
+		SlowerCases:  diff.Slower,
+		SlowerSteps:  []stepChange{},
+	}
+	passed := string(types.ActionStatusPassed)
+	for _, change := range diff.StatusChanged {
+		if isRegression(change.Old.Status, change.New.Status) {
+			delta.NewlyFailing = append(delta.NewlyFailing, change)
+		} else if change.New.Status == passed && change.Old.Status != string(types.ActionStatusSkipped) {
+			delta.NewlyPassing = append(delta.NewlyPassing, change)
+		}
+	}
+
+	previousCases := make(map[string]runReportCase)
+	for _, c := range previous.Cases {
+		previousCases[c.File+"\x00"+c.Name] = c
+	}
+	for _, c := range current.Cases {
+		old, ok := previousCases[c.File+"\x00"+c.Name]
+		if !ok {
+			continue
+		}
+		oldSteps := make(map[string]runReportStep)
+		for _, step := range old.Steps {
+			oldSteps[step.Key] = step
+		}
+		for _, step := range c.Steps {
+			oldStep, ok := oldSteps[step.Key]
+			if !ok || oldStep.DurationMs <= 0 {
+				continue
+			}
+			slowdown := time.Duration((step.DurationMs - oldStep.DurationMs) * float64(time.Millisecond))
+			if step.DurationMs >= oldStep.DurationMs*threshold && slowdown >= minSlowdown {
+				delta.SlowerSteps = append(delta.SlowerSteps, stepChange{File: c.File, TestCase: c.Name, Old: oldStep, New: step})
+			}
+		}
+	}
+	sort.Slice(delta.SlowerSteps, func(a, b int) bool {
+		return delta.SlowerSteps[a].New.DurationMs/delta.SlowerSteps[a].Old.DurationMs >
+			delta.SlowerSteps[b].New.DurationMs/delta.SlowerSteps[b].Old.DurationMs
+	})
+	return delta
+}
+
+// printRunDelta prints the changes since the previous run
+func printRunDelta(delta *runDelta, threshold float64) {
+	fmt.Printf("\nSince last run (%s):\n", delta.Since.Local().Format("2006-01-02 15:04:05"))
+	if len(delta.NewlyFailing)+len(delta.NewlyPassing)+len(delta.SlowerCases)+len(delta.SlowerSteps) == 0 {
+		fmt.Println("  No changes")
+		return
+	}
+
+	if len(delta.NewlyFailing) > 0 {
+		fmt.Printf("  Newly failing (%d):\n", len(delta.NewlyFailing))
+		for _, change := range delta.NewlyFailing {
+			fmt.Printf("  ! %s -> %-7s %s (%s)\n", change.Old.Status, change.New.Status, change.New.Name, change.New.File)
+		}
+	}
+	if len(delta.NewlyPassing) > 0 {
+		fmt.Printf("  Newly passing (%d):\n", len(delta.NewlyPassing))
+		for _, change := range delta.NewlyPassing {
+			fmt.Printf("    %s -> %-7s %s (%s)\n", change.Old.Status, change.New.Status, change.New.Name, change.New.File)
+		}
+	}
+	if len(delta.SlowerCases) > 0 {
+		fmt.Printf("  Test cases slower by %.1fx or more (%d):\n", threshold, len(delta.SlowerCases))
+		for _, change := range delta.SlowerCases {
+			fmt.Printf("    %.0fms -> %.0fms  %s (%s)\n", change.Old.DurationMs, change.New.DurationMs, change.New.Name, change.New.File)
+		}
+	}
+	if len(delta.SlowerSteps) > 0 {
+		fmt.Printf("  Steps slower by %.1fx or more (%d):\n", threshold, len(delta.SlowerSteps))
+		for _, change := range delta.SlowerSteps {
+			fmt.Printf("    %.0fms -> %.0fms  %s [%s] (%s)\n", change.Old.DurationMs, change.New.DurationMs, change.New.Name, change.TestCase, change.File)
+		}
+	}
+}
@@ -0,0 +1,303 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/JianLoong/robogo/internal/execution"
+	"github.com/JianLoong/robogo/internal/types"
+)
+
+// defaultSlowerThreshold flags a test case as slower when it takes this many times as long
+const defaultSlowerThreshold = 1.5
+
+// minSlowdown ignores slowdowns smaller than this, which are mostly scheduling noise
+const minSlowdown = 50 * time.Millisecond
+
+// runReport is the on-disk layout of the --report file
+type runReport struct {
+	CreatedAt     time.Time            `json:"created_at"`
+	Target        string               `json:"target"`
+	Environment   string               `json:"environment,omitempty"`
+	Cases         []runReportCase      `json:"cases"`
+	Annotations   map[string]any       `json:"annotations,omitempty"`
+	SlowestSteps  []types.ActionTiming `json:"slowest_steps,omitempty"`
+	ActionTimings []types.ActionStats  `json:"action_timings,omitempty"`
+}
+
+// runReportCase is the outcome of one test case. Index is its position among the
+// test cases of its file, used to match cases that were renamed between runs.
+type runReportCase struct {
+	File        string           `json:"file"`
+	Index       int              `json:"index"`
+	Name        string           `json:"name"`
+	Status      string           `json:"status"`
+	DurationMs  float64          `json:"duration_ms"`
+	Message     string           `json:"message,omitempty"`
+	Teardown    string           `json:"teardown_status,omitempty"`
+	Annotations map[string]any   `json:"annotations,omitempty"`
+	Artifacts   []types.Artifact `json:"artifacts,omitempty"`    // files written by --artifacts and --debug-dump, or why they were not
+	CachedSteps []string         `json:"cached_steps,omitempty"` // names of the steps served from their cache
+	Steps       []runReportStep  `json:"steps,omitempty"`        // only kept in --history files
+}
+
+// runReportStep is the outcome of one step. Key identifies the step between runs: its
+// section, position and id, or name when it has no id.
+type runReportStep struct {
+	Key         string             `json:"key"`
+	Name        string             `json:"name"`
+	Status      string             `json:"status"`
+	DurationMs  float64            `json:"duration_ms"`
+	Cached      bool               `json:"cached,omitempty"`
+	ControlFlow *types.ControlFlow `json:"control_flow,omitempty"` // branch taken and iterations run
+}
+
+// writeRunReport saves the outcome of every planned test case that produced a result
+func writeRunReport(path, target, environment string, planned []plannedTestCase, results []*types.TestResult) error {
+	return writeJSONFile(path, buildRunReport(target, environment, planned, results, false))
+}
+
+// buildRunReport summarizes a run; withSteps keeps the outcome of every step for --history
+func buildRunReport(target, environment string, planned []plannedTestCase, results []*types.TestResult, withSteps bool) *runReport {
+	report := &runReport{
+		CreatedAt:   time.Now(),
+		Target:      target,
+		Environment: environment,
+		Cases:       make([]runReportCase, 0, len(results)),
+	}
+	indexes := make(map[string]int)
+	for i, result := range results {
+		file := planned[i].filename
+		report.Cases = append(report.Cases, runReportCase{
+			File:        file,
+			Index:       indexes[file],
+			Name:        result.Name,
+			Status:      result.Status,
+			DurationMs:  float64(result.Duration) / float64(time.Millisecond),
+			Message:     result.GetMessage(),
+			Teardown:    result.TeardownStatus,
+			Annotations: result.Annotations,
+			Artifacts:   caseArtifacts(result),
+			CachedSteps: cachedSteps(result),
+		})
+		if withSteps {
+			report.Cases[len(report.Cases)-1].Steps = reportSteps(result)
+		}
+		indexes[file]++
+	}
+	report.Annotations = aggregateAnnotations(results)
+	if timings := runTimings(results); len(timings) > 0 {
+		report.SlowestSteps = execution.SlowestTimings(timings, slowestStepsShown)
+		report.ActionTimings = execution.SummarizeTimings(timings)
+	}
+	return report
+}
+
+// reportSteps lists the outcome of the setup, main and teardown steps of a test case
+func reportSteps(result *types.TestResult) []runReportStep {
+	var steps []runReportStep
+	for _, section := range []struct {
+		name  string
+		steps []types.StepResult
+	}{{"setup", result.SetupSteps}, {"steps", result.Steps}, {"teardown", result.TeardownSteps}} {
+		for i, step := range section.steps {
+			label := step.ID
+			if label == "" {
+				label = step.Name
+			}
+			steps = append(steps, runReportStep{
+				Key:         fmt.Sprintf("%s/%d/%s", section.name, i+1, label),
+				Name:        step.Name,
+				Status:      string(step.Result.Status),
+				DurationMs:  float64(step.Duration) / float64(time.Millisecond),
+				Cached:      step.Cached,
+				ControlFlow: step.ControlFlow,
+			})
+		}
+	}
+	return steps
+}
+
+// cachedSteps lists the setup, main and teardown steps of a test case served from their cache
+func cachedSteps(result *types.TestResult) []string {
+	var names []string
+	for _, section := range [][]types.StepResult{result.SetupSteps, result.Steps, result.TeardownSteps} {
+		for _, step := range section {
+			if step.Cached {
+				names = append(names, step.Name)
+			}
+		}
+	}
+	return names
+}
+
+// writeJSONFile writes a report as indented JSON, creating its directory
+func writeJSONFile(path string, report any) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal report: %w", err)
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create report directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
+
+// loadRunReport reads a report written by --report
+func loadRunReport(path string) (*runReport, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report: %w", err)
+	}
+	var report runReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("%s is not a robogo report: %w", path, err)
+	}
+	return &report, nil
+}
+
+// reportDiff lists what changed between two runs
+type reportDiff struct {
+	Added         []runReportCase `json:"added"`
+	Removed       []runReportCase `json:"removed"`
+	Renamed       []caseChange    `json:"renamed"`
+	StatusChanged []caseChange    `json:"status_changed"`
+	Slower        []caseChange    `json:"slower"`
+	Regressions   int             `json:"regressions"`
+}
+
+// caseChange pairs the old and new outcome of one test case
+type caseChange struct {
+	Old runReportCase `json:"old"`
+	New runReportCase `json:"new"`
+}
+
+// diffRunReports matches test cases by file and name, then matches the rest by file
+// and position so a renamed case is compared with its old self instead of showing
+// up as removed and added. threshold is the duration ratio counted as slower.
+func diffRunReports(oldReport, newReport *runReport, threshold float64) reportDiff {
+	diff := reportDiff{
+		Added:         []runReportCase{},
+		Removed:       []runReportCase{},
+		Renamed:       []caseChange{},
+		StatusChanged: []caseChange{},
+		Slower:        []caseChange{},
+	}
+
+	byName := make(map[string]int)
+	byIndex := make(map[string]int)
+	for i, c := range oldReport.Cases {
+		byName[c.File+"\x00"+c.Name] = i
+		byIndex[fmt.Sprintf("%s\x00%d", c.File, c.Index)] = i
+	}
+	matchedOld := make(map[int]bool)
+	matchedNew := make(map[int]int)
+
+	for i, c := range newReport.Cases {
+		if j, ok := byName[c.File+"\x00"+c.Name]; ok && !matchedOld[j] {
+			matchedOld[j] = true
+			matchedNew[i] = j
+		}
+	}
+	for i, c := range newReport.Cases {
+		if _, ok := matchedNew[i]; ok {
+			continue
+		}
+		if j, ok := byIndex[fmt.Sprintf("%s\x00%d", c.File, c.Index)]; ok && !matchedOld[j] {
+			matchedOld[j] = true
+			matchedNew[i] = j
+			diff.Renamed = append(diff.Renamed, caseChange{Old: oldReport.Cases[j], New: c})
+		}
+	}
+
+	for i, c := range newReport.Cases {
+		j, ok := matchedNew[i]
+		if !ok {
+			diff.Added = append(diff.Added, c)
+			continue
+		}
+		old := oldReport.Cases[j]
+		if old.Status != c.Status {
+			diff.StatusChanged = append(diff.StatusChanged, caseChange{Old: old, New: c})
+			if isRegression(old.Status, c.Status) {
+				diff.Regressions++
+			}
+		}
+		slowdown := time.Duration((c.DurationMs - old.DurationMs) * float64(time.Millisecond))
+		if old.DurationMs > 0 && c.DurationMs >= old.DurationMs*threshold && slowdown >= minSlowdown {
+			diff.Slower = append(diff.Slower, caseChange{Old: old, New: c})
+		}
+	}
+	for j, c := range oldReport.Cases {
+		if !matchedOld[j] {
+			diff.Removed = append(diff.Removed, c)
+		}
+	}
+
+	sort.Slice(diff.Slower, func(a, b int) bool {
+		return diff.Slower[a].New.DurationMs/diff.Slower[a].Old.DurationMs >
+			diff.Slower[b].New.DurationMs/diff.Slower[b].Old.DurationMs
+	})
+	return diff
+}
+
+// isRegression reports whether a test case went from passing to failing or erroring
+func isRegression(oldStatus, newStatus string) bool {
+	return oldStatus == string(types.ActionStatusPassed) &&
+		(newStatus == string(types.ActionStatusFailed) || newStatus == string(types.ActionStatusError))
+}
+
+// printReportDiff prints a report diff for people
+func printReportDiff(diff reportDiff, threshold float64) {
+	if len(diff.Added)+len(diff.Removed)+len(diff.Renamed)+len(diff.StatusChanged)+len(diff.Slower) == 0 {
+		fmt.Println("No differences")
+		return
+	}
+
+	if len(diff.StatusChanged) > 0 {
+		fmt.Printf("Status changed (%d):\n", len(diff.StatusChanged))
+		for _, change := range diff.StatusChanged {
+			marker := " "
+			if isRegression(change.Old.Status, change.New.Status) {
+				marker = "!"
+			}
+			fmt.Printf(" %s %s -> %-7s %s (%s)\n", marker, change.Old.Status, change.New.Status, change.New.Name, change.New.File)
+		}
+	}
+	if len(diff.Slower) > 0 {
+		fmt.Printf("Slower by %.1fx or more (%d):\n", threshold, len(diff.Slower))
+		for _, change := range diff.Slower {
+			fmt.Printf("   %.0fms -> %.0fms  %s (%s)\n", change.Old.DurationMs, change.New.DurationMs, change.New.Name, change.New.File)
+		}
+	}
+	if len(diff.Added) > 0 {
+		fmt.Printf("Added (%d):\n", len(diff.Added))
+		for _, c := range diff.Added {
+			fmt.Printf("   %-7s %s (%s)\n", c.Status, c.Name, c.File)
+		}
+	}
+	if len(diff.Removed) > 0 {
+		fmt.Printf("Removed (%d):\n", len(diff.Removed))
+		for _, c := range diff.Removed {
+			fmt.Printf("   %-7s %s (%s)\n", c.Status, c.Name, c.File)
+		}
+	}
+	if len(diff.Renamed) > 0 {
+		fmt.Printf("Renamed (%d):\n", len(diff.Renamed))
+		for _, change := range diff.Renamed {
+			fmt.Printf("   %s -> %s (%s)\n", change.Old.Name, change.New.Name, change.New.File)
+		}
+	}
+	if diff.Regressions > 0 {
+		fmt.Printf("\n%d regression(s): passed before, not passing now\n", diff.Regressions)
+	}
+}
@@ -1,20 +1,40 @@
 package internal
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/JianLoong/robogo/internal/actions"
 	"github.com/JianLoong/robogo/internal/common"
 	"github.com/JianLoong/robogo/internal/execution"
 	"github.com/JianLoong/robogo/internal/types"
+	"github.com/google/uuid"
 )
 
 // TestRunner executes a test case and manages variables and control flow.
 type TestRunner struct {
 	variables      *common.Variables
 	strategyRouter *execution.ExecutionStrategyRouter
+	basicStrategy  *execution.BasicExecutionStrategy
+	actionRegistry *actions.ActionRegistry
+	strict         bool
+	verbosity      execution.Verbosity
+	maxDataBytes   int
+	maskOutput     bool           // mask secret values in all output: the test case's mask_output, or its caller's
+	runVariables   map[string]any // values printed by pre_run hooks, for every test case of the run
+
+	deferTeardown    bool         // RunTest leaves the teardown to RunDeferredTeardown
+	deferredTeardown []types.Step // teardown steps held back by DeferTeardown
+
+	pluginManifests []string        // loaded plugin manifests, loaded again by called test cases
+	ctx             context.Context // context of the running test case, for call steps
+	filename        string          // file of the running test case; call paths are relative to it
+	callStack       []string        // files of the calling test cases, outermost first
+	inputs          map[string]any  // with: values of the call step running this test case
 }
 
 // NewTestRunner creates a new TestRunner with direct strategy router.
@@ -24,27 +44,85 @@ func NewTestRunner() *TestRunner {
 	conditionEvaluator := execution.NewBasicConditionEvaluator(variables)
 	
 	// Create strategy router and register strategies directly
+	basicStrategy := execution.NewBasicExecutionStrategy(variables, actionRegistry)
 	router := execution.NewExecutionStrategyRouter()
 	router.RegisterStrategy(execution.NewConditionalExecutionStrategy(conditionEvaluator, router))
-	router.RegisterStrategy(execution.NewRetryExecutionStrategy(variables, actionRegistry))
-	router.RegisterStrategy(execution.NewNestedStepsExecutionStrategy(router))
-	router.RegisterStrategy(execution.NewBasicExecutionStrategy(variables, actionRegistry))
+	router.RegisterStrategy(execution.NewRepeatExecutionStrategy(variables, router))
+	router.RegisterStrategy(execution.NewEventuallyExecutionStrategy(variables, router))
+	router.RegisterStrategy(execution.NewRetryExecutionStrategy(variables, basicStrategy))
+	router.RegisterStrategy(execution.NewNestedStepsExecutionStrategy(variables, router))
+	router.RegisterStrategy(basicStrategy)
 	
-	return &TestRunner{
+	runner := &TestRunner{
 		variables:      variables,
 		strategyRouter: router,
+		basicStrategy:  basicStrategy,
+		actionRegistry: actionRegistry,
+		verbosity:      execution.VerbosityNormal,
+		ctx:            context.Background(),
 	}
+	router.RegisterStrategy(execution.NewCallExecutionStrategy(variables, runner.callTestCase))
+	return runner
 }
 
-// RunTest executes a single test file and returns the aggregated result.
-func (r *TestRunner) RunTest(filename string) (*types.TestResult, error) {
-	testCase, err := ParseTestFile(filename)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse test file: %w", err)
+// LoadPlugins registers the external actions declared in a plugin manifest
+func (r *TestRunner) LoadPlugins(manifestPath string) error {
+	if err := r.actionRegistry.LoadPlugins(manifestPath); err != nil {
+		return err
 	}
+	r.pluginManifests = append(r.pluginManifests, manifestPath)
+	return nil
+}
 
-	if testCase.Variables.Vars != nil {
-		r.variables.Load(testCase.Variables.Vars)
+// SetStrict makes ambiguous test definitions, such as duplicate step names, fail the run
+// instead of printing a warning
+func (r *TestRunner) SetStrict(strict bool) {
+	r.strict = strict
+}
+
+// SetVerbosity sets how much is printed while the test case runs; quiet leaves only
+// failed steps, warnings and the summary
+func (r *TestRunner) SetVerbosity(verbosity execution.Verbosity) {
+	r.verbosity = verbosity
+	r.strategyRouter.SetVerbosity(verbosity)
+}
+
+// SetMaxDataBytes sets how many bytes of result data each step keeps, unless the step sets
+// max_data_bytes; 0 keeps execution.DefaultMaxDataBytes
+func (r *TestRunner) SetMaxDataBytes(limit int) {
+	r.maxDataBytes = limit
+	r.basicStrategy.SetMaxDataBytes(limit)
+}
+
+// SetRunVariables sets variables shared by every test case of the run, such as the values
+// printed by pre_run hooks. They are set before the test case's own variables, which may
+// refer to them, and are not substituted.
+func (r *TestRunner) SetRunVariables(vars map[string]any) {
+	r.runVariables = vars
+}
+
+// DeferTeardown makes RunTest return without running the teardown, which
+// RunDeferredTeardown runs later, e.g. after every test case of a run
+func (r *TestRunner) DeferTeardown() {
+	r.deferTeardown = true
+}
+
+// RunDeferredTeardown runs the teardown held back by DeferTeardown and records it in the
+// result RunTest returned. Teardown failures do not change the result's status.
+func (r *TestRunner) RunDeferredTeardown(result *types.TestResult) {
+	teardownResults := r.runTeardownPhase(r.deferredTeardown, result.Status != string(types.ActionStatusPassed))
+	r.deferredTeardown = nil
+	result.TeardownSteps = teardownResults
+	result.TeardownStatus = r.teardownStatus(teardownResults)
+}
+
+// RunTest executes a test case loaded from filename and returns the aggregated result.
+// Cancelling ctx stops the run before the next setup or main step; teardown
+// still runs and the partial result is returned.
+func (r *TestRunner) RunTest(ctx context.Context, filename string, testCase *types.TestCase) (*types.TestResult, error) {
+	circuitBreaker, metrics, err := r.prepare(ctx, filename, testCase)
+	if err != nil {
+		return nil, err
 	}
 
 	start := time.Now()
@@ -57,12 +135,17 @@ func (r *TestRunner) RunTest(filename string) (*types.TestResult, error) {
 	r.printTestHeader(testCase)
 
 	// 1. Run setup phase
-	setupResults, setupSkipped := r.runSetupPhase(testCase.Setup)
+	setupResults, setupSkipped := r.runSetupPhase(ctx, testCase.Setup)
 	result.SetupSteps = setupResults
 	
 	// If setup failed critically, skip the main test
 	if setupSkipped {
 		result.Status = "SKIPPED"
+		result.SkipInfo = types.NewSkipInfo(types.SkipCategorySetupFailure, "critical setup step failed")
+		result.CircuitEvents = circuitEvents(circuitBreaker)
+		result.ActionMetrics = metrics.Summary()
+		result.Timings = metrics.Timings()
+		result.Annotations = r.annotations()
 		result.Duration = time.Since(start)
 		fmt.Printf("\n[SETUP] Test skipped due to critical setup failure\n")
 		return result, nil
@@ -70,8 +153,14 @@ func (r *TestRunner) RunTest(filename string) (*types.TestResult, error) {
 
 	// 2. Run main test steps
 	testFailed := false
+	var failedAssertions []types.StepResult
 	for i, step := range testCase.Steps {
-		stepResult := r.strategyRouter.Execute(step, i+1, nil)
+		if ctx.Err() != nil {
+			result.Steps = append(result.Steps, r.cancelledStepResults(testCase.Steps[i:])...)
+			break
+		}
+
+		stepResult := r.executeStep(step, i+1)
 		var stepResults []types.StepResult
 		if stepResult != nil {
 			stepResults = append(stepResults, *stepResult)
@@ -79,6 +168,13 @@ func (r *TestRunner) RunTest(filename string) (*types.TestResult, error) {
 		result.Steps = append(result.Steps, stepResults...)
 
 		if r.anyStepFailedOrErrored(stepResults) {
+			// Soft assertions: record the failure and check them all after the last step
+			if testCase.CollectAssertions && onlyAssertionFailures(stepResults) {
+				failedAssertions = append(failedAssertions, stepResults...)
+				fmt.Printf("⚠️  Assertion failed, collecting and continuing: %s\n", step.Name)
+				continue
+			}
+
 			result.Status = r.aggregateStatus(stepResults)
 			result.ErrorInfo = r.getFirstErrorInfo(stepResults)
 			testFailed = true
@@ -92,16 +188,134 @@ func (r *TestRunner) RunTest(filename string) (*types.TestResult, error) {
 		}
 	}
 
-	// 3. Always run teardown phase (regardless of test outcome)
-	teardownResults := r.runTeardownPhase(testCase.Teardown, testFailed)
-	result.TeardownSteps = teardownResults
+	// Final implicit check of the assertions collected with collect_assertions
+	if len(failedAssertions) > 0 {
+		check := collectedAssertionsResult(failedAssertions)
+		result.Steps = append(result.Steps, check)
+		if !testFailed {
+			result.Status = string(types.ActionStatusFailed)
+			result.ErrorInfo = r.getFirstErrorInfo([]types.StepResult{check})
+			testFailed = true
+		}
+	}
+
+	if ctx.Err() != nil && !testFailed {
+		result.Status = string(types.ActionStatusError)
+		result.ErrorInfo = &types.ErrorInfo{
+			Category:  types.ErrorCategoryExecution,
+			Code:      "TEST_CANCELLED",
+			Message:   "Test run was interrupted before all steps completed",
+			Timestamp: time.Now(),
+		}
+		testFailed = true
+	}
+
+	// 3. Always run teardown phase (regardless of test outcome), unless it is deferred
+	if r.deferTeardown {
+		r.deferredTeardown = testCase.Teardown
+	} else {
+		teardownResults := r.runTeardownPhase(testCase.Teardown, testFailed)
+		result.TeardownSteps = teardownResults
+		result.TeardownStatus = r.teardownStatus(teardownResults)
+	}
 
+	result.CircuitEvents = circuitEvents(circuitBreaker)
+	result.ActionMetrics = metrics.Summary()
+	result.Timings = metrics.Timings()
+	result.Annotations = r.annotations()
 	result.Duration = time.Since(start)
 	return result, nil
 }
 
+// prepare warns about ambiguous steps and unused variables, then loads the test case's
+// variables and sets up its circuit breaker and metrics
+func (r *TestRunner) prepare(ctx context.Context, filename string, testCase *types.TestCase) (*execution.CircuitBreaker, *execution.MetricsCollector, error) {
+	if duplicates := DuplicateStepNames(testCase); len(duplicates) > 0 {
+		if r.strict {
+			return nil, nil, fmt.Errorf("duplicate step names: %s", strings.Join(duplicates, "; "))
+		}
+		for _, duplicate := range duplicates {
+			fmt.Printf("[WARN] %s; add an id to tell them apart\n", duplicate)
+		}
+	}
+	for _, warning := range UnusedVariableWarnings(filename, testCase) {
+		fmt.Printf("[WARN] %s (mark with '# %s' if intended)\n", warning, ignoreUnusedMarker)
+	}
+
+	r.ctx = ctx
+	r.filename = filename
+
+	// For building paths explicitly, e.g. ${suite.dir}/fixtures/users.json; a test case may set its own
+	r.variables.Set(common.RunVariable, map[string]any{common.DirField: common.RunDir()})
+	suiteDir := absolutePath(filepath.Dir(filename))
+	r.variables.Set(common.SuiteVariable, map[string]any{common.DirField: suiteDir})
+	r.basicStrategy.SetSuiteDir(suiteDir)
+	for name, value := range r.runVariables {
+		r.variables.Set(name, value)
+	}
+	if testCase.Variables.Vars != nil {
+		r.variables.Load(testCase.Variables.Vars)
+	}
+	// A called test case's own variables are defaults for the caller's with: values
+	if r.inputs != nil {
+		r.variables.Load(r.inputs)
+	}
+	// Substituted values, such as ${ENV:PORT}, are only known now
+	for name, declaration := range testCase.Variables.Types {
+		r.variables.Declare(name, declaration)
+	}
+	if err := r.variables.CheckDeclared(); err != nil {
+		return nil, nil, fmt.Errorf("invalid variables: %w", err)
+	}
+	if err := r.variables.SecretFindings(); err != nil {
+		return nil, nil, fmt.Errorf("secret values in variables: %w", err)
+	}
+	// Sent as a header by http steps; a test case may set its own
+	r.variables.SetIfAbsent(actions.CorrelationIDVariable, uuid.NewString())
+
+	var circuitBreaker *execution.CircuitBreaker
+	if testCase.CircuitBreaker != nil {
+		var err error
+		circuitBreaker, err = execution.NewCircuitBreaker(testCase.CircuitBreaker)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	r.basicStrategy.SetCircuitBreaker(circuitBreaker)
+	r.maskOutput = r.maskOutput || testCase.MaskOutput
+	r.basicStrategy.SetMaskOutput(r.maskOutput)
+	metrics := execution.NewMetricsCollector(testCase.Name)
+	r.basicStrategy.SetMetricsCollector(metrics)
+	return circuitBreaker, metrics, nil
+}
+
+// annotations returns a copy of the annotations recorded by summary steps
+func (r *TestRunner) annotations() map[string]any {
+	recorded, _ := r.variables.Get(actions.AnnotationsVariable).(map[string]any)
+	if len(recorded) == 0 {
+		return nil
+	}
+	annotations := make(map[string]any, len(recorded))
+	for name, value := range recorded {
+		annotations[name] = common.MaskSecretValuesIn(value, r.outputSecrets())
+	}
+	return annotations
+}
+
+// outputSecrets returns the secret values to mask in reports and dumps: with mask_output
+// off, only the secret-looking values found in other variables
+func (r *TestRunner) outputSecrets() []string {
+	if !r.maskOutput {
+		return r.variables.DetectedSecretValues()
+	}
+	return r.variables.SecretValues()
+}
+
 // printTestHeader prints the test case header information.
 func (r *TestRunner) printTestHeader(testCase *types.TestCase) {
+	if r.verbosity == execution.VerbosityQuiet {
+		return
+	}
 	fmt.Printf("Running test case: %s\n", testCase.Name)
 	if testCase.Description != "" {
 		fmt.Printf("Description: %s\n", testCase.Description)
@@ -113,17 +327,25 @@ func (r *TestRunner) printTestHeader(testCase *types.TestCase) {
 }
 
 // runSetupPhase executes setup steps, returns (results, shouldSkipTest)
-func (r *TestRunner) runSetupPhase(setupSteps []types.Step) ([]types.StepResult, bool) {
+func (r *TestRunner) runSetupPhase(ctx context.Context, setupSteps []types.Step) ([]types.StepResult, bool) {
 	if len(setupSteps) == 0 {
 		return nil, false
 	}
 
-	fmt.Printf("[SETUP] Running %d setup steps...\n", len(setupSteps))
+	if r.verbosity > execution.VerbosityQuiet {
+		fmt.Printf("[SETUP] Running %d setup steps...\n", len(setupSteps))
+	}
 	
 	var results []types.StepResult
 	
 	for i, step := range setupSteps {
-		stepResult := r.strategyRouter.Execute(step, i+1, nil)
+		if ctx.Err() != nil {
+			fmt.Printf("[SETUP] ⚠️  Run interrupted, skipping remaining setup steps\n")
+			results = append(results, r.cancelledStepResults(setupSteps[i:])...)
+			break
+		}
+
+		stepResult := r.executeStep(step, i+1)
 		var stepResults []types.StepResult
 		if stepResult != nil {
 			stepResults = append(stepResults, *stepResult)
@@ -140,7 +362,9 @@ func (r *TestRunner) runSetupPhase(setupSteps []types.Step) ([]types.StepResult,
 		}
 	}
 	
-	fmt.Printf("[SETUP] ✓ Setup phase completed\n\n")
+	if r.verbosity > execution.VerbosityQuiet {
+		fmt.Printf("[SETUP] ✓ Setup phase completed\n\n")
+	}
 	return results, false
 }
 
@@ -150,12 +374,14 @@ func (r *TestRunner) runTeardownPhase(teardownSteps []types.Step, testFailed boo
 		return nil
 	}
 
-	fmt.Printf("\n[TEARDOWN] Running %d teardown steps...\n", len(teardownSteps))
+	if r.verbosity > execution.VerbosityQuiet {
+		fmt.Printf("\n[TEARDOWN] Running %d teardown steps...\n", len(teardownSteps))
+	}
 	
 	var results []types.StepResult
 	
 	for i, step := range teardownSteps {
-		stepResult := r.strategyRouter.Execute(step, i+1, nil)
+		stepResult := r.executeStep(step, i+1)
 		var stepResults []types.StepResult
 		if stepResult != nil {
 			stepResults = append(stepResults, *stepResult)
@@ -169,7 +395,53 @@ func (r *TestRunner) runTeardownPhase(teardownSteps []types.Step, testFailed boo
 		}
 	}
 	
-	fmt.Printf("[TEARDOWN] ✓ Teardown phase completed\n")
+	if r.verbosity > execution.VerbosityQuiet {
+		fmt.Printf("[TEARDOWN] ✓ Teardown phase completed\n")
+	}
+	return results
+}
+
+// executeStep runs one top-level step. A panic in an action is reported as an error
+// result of that step, so the run goes on to teardown instead of crashing.
+func (r *TestRunner) executeStep(step types.Step, stepNum int) (stepResult *types.StepResult) {
+	start := time.Now()
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			stepResult = execution.PanicStepResult(step, start, recovered)
+		}
+	}()
+	return r.strategyRouter.Execute(step, stepNum, nil)
+}
+
+// teardownStatus summarizes the teardown steps separately from the test status,
+// so a cleanup failure is visible without masking the test's own outcome
+func (r *TestRunner) teardownStatus(teardownResults []types.StepResult) string {
+	if len(teardownResults) == 0 {
+		return ""
+	}
+	return r.aggregateStatus(teardownResults)
+}
+
+// circuitEvents returns the circuit breaker transitions, if a breaker was configured
+func circuitEvents(breaker *execution.CircuitBreaker) []string {
+	if breaker == nil {
+		return nil
+	}
+	return breaker.Events()
+}
+
+// cancelledStepResults records steps that never started because the run was interrupted
+func (r *TestRunner) cancelledStepResults(steps []types.Step) []types.StepResult {
+	results := make([]types.StepResult, 0, len(steps))
+	for _, step := range steps {
+		results = append(results, types.StepResult{
+			Name:           step.Name,
+			ID:             step.ID,
+			Action:         step.Action,
+			IncludeSummary: true,
+			Result:         types.NewSkippedResult(types.SkipCategoryCancelled, "run interrupted before step started"),
+		})
+	}
 	return results
 }
 
@@ -198,6 +470,44 @@ func (r *TestRunner) anyStepFailedOrErrored(stepResults []types.StepResult) bool
 	return false
 }
 
+// onlyAssertionFailures reports whether every failed step failed an assertion,
+// as opposed to erroring or failing some other kind of check
+func onlyAssertionFailures(stepResults []types.StepResult) bool {
+	for _, sr := range stepResults {
+		switch sr.Result.Status {
+		case types.ActionStatusError:
+			return false
+		case types.ActionStatusFailed:
+			if sr.Result.FailureInfo == nil || sr.Result.FailureInfo.Category != types.FailureCategoryAssertion {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// collectedAssertionsResult builds the step that fails a collect_assertions test case,
+// listing every failed assertion with the step it came from
+func collectedAssertionsResult(failed []types.StepResult) types.StepResult {
+	var lines []string
+	for _, sr := range failed {
+		if sr.Result.Status != types.ActionStatusFailed {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("  - %s: %s", sr.Name, firstLine(sr.Result.GetMessage())))
+	}
+	result := types.NewFailureBuilder(types.FailureCategoryAssertion, "ASSERTIONS_FAILED").
+		WithTemplate("%d assertion(s) failed:\n%s").
+		WithSuggestion("Each failed assertion is also reported on its own step").
+		Build(len(lines), strings.Join(lines, "\n"))
+	return types.StepResult{
+		Name:           "Collected assertions",
+		Action:         "assert",
+		Result:         result,
+		IncludeSummary: true,
+	}
+}
+
 // aggregateStatus returns the most severe status among the step results.
 func (r *TestRunner) aggregateStatus(stepResults []types.StepResult) string {
 	for _, sr := range stepResults {
@@ -0,0 +1,191 @@
+package internal
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/JianLoong/robogo/internal/actions"
+	"github.com/JianLoong/robogo/internal/types"
+)
+
+// schemaDraft is the JSON Schema dialect of the generated schema
+const schemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// GenerateTestSchema builds a JSON Schema for test files from the test case types and
+// the action registry, so editors get completion and validation that match this build.
+// Field constraints come from `schema:"enum=a|b"` struct tags.
+func GenerateTestSchema(registry *actions.ActionRegistry) map[string]any {
+	defs := map[string]any{}
+
+	stepSchema := structSchema(reflect.TypeOf(types.Step{}), defs)
+	stepSchema["required"] = []string{"name"}
+	// Mirrors validateSteps: exactly one of action or steps
+	stepSchema["oneOf"] = []any{
+		map[string]any{"required": []string{"action"}},
+		map[string]any{"required": []string{"steps"}},
+	}
+
+	properties := stepSchema["properties"].(map[string]any)
+	names := registry.ListActions()
+	properties["action"] = map[string]any{
+		"type":        "string",
+		"description": "Action to run; see `robogo actions describe <action>`",
+		"enum":        names,
+	}
+
+	var rules []any
+	for _, name := range names {
+		meta, _ := registry.Describe(name)
+		rules = append(rules, actionRule(meta))
+	}
+	stepSchema["allOf"] = rules
+	defs["step"] = stepSchema
+
+	schema := structSchema(reflect.TypeOf(types.TestCase{}), defs)
+	schema["$schema"] = schemaDraft
+	schema["title"] = "Robogo test case"
+	schema["required"] = []string{"testcase", "steps"}
+	schema["$defs"] = defs
+	schema["properties"].(map[string]any)["steps"].(map[string]any)["minItems"] = 1
+	return schema
+}
+
+// actionRule constrains args and options when a step uses a specific action
+func actionRule(meta actions.ActionMetadata) map[string]any {
+	then := map[string]any{}
+	if meta.Description != "" {
+		then["description"] = meta.Description
+	}
+
+	thenProperties := map[string]any{}
+	if meta.Args != nil {
+		required := 0
+		prefixItems := make([]any, 0, len(meta.Args))
+		for _, arg := range meta.Args {
+			if arg.Required {
+				required++
+			}
+			prefixItems = append(prefixItems, argSchema(arg))
+		}
+
+		args := map[string]any{"prefixItems": prefixItems}
+		if required > 0 {
+			args["minItems"] = required
+		}
+		if !meta.Variadic {
+			args["maxItems"] = len(meta.Args)
+		}
+		thenProperties["args"] = args
+		if required > 0 {
+			then["required"] = []string{"args"}
+		}
+	}
+
+	if len(meta.Options) > 0 {
+		options := map[string]any{}
+		for _, option := range meta.Options {
+			options[option.Name] = argSchema(option)
+		}
+		thenProperties["options"] = map[string]any{"properties": options}
+	}
+
+	if len(thenProperties) > 0 {
+		then["properties"] = thenProperties
+	}
+
+	return map[string]any{
+		"if": map[string]any{
+			"properties": map[string]any{"action": map[string]any{"const": meta.Name}},
+			"required":   []string{"action"},
+		},
+		"then": then,
+	}
+}
+
+// argSchema describes one argument or option. Strings are always allowed since
+// values are often ${variables} that are only resolved at run time.
+func argSchema(spec actions.ArgSpec) map[string]any {
+	schema := map[string]any{"title": spec.Name}
+	if spec.Description != "" {
+		schema["description"] = spec.Description
+	}
+
+	switch spec.Type {
+	case "string":
+		schema["type"] = []string{"string", "number", "boolean"}
+	case "number":
+		schema["type"] = []string{"number", "string"}
+	case "bool":
+		schema["type"] = []string{"boolean", "string"}
+	case "object":
+		schema["type"] = []string{"object", "string"}
+	case "array":
+		schema["type"] = []string{"array", "string"}
+	}
+	return schema
+}
+
+// structSchema describes a struct from its yaml tags. Steps are referenced through $defs
+// since they nest recursively.
+func structSchema(structType reflect.Type, defs map[string]any) map[string]any {
+	properties := map[string]any{}
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+
+		property := typeSchema(field.Type, defs)
+		if enum := schemaEnum(field.Tag.Get("schema")); enum != nil {
+			property["enum"] = enum
+		}
+		properties[name] = property
+	}
+
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+// typeSchema maps a Go type to its JSON Schema
+func typeSchema(fieldType reflect.Type, defs map[string]any) map[string]any {
+	for fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+
+	if fieldType == reflect.TypeOf(types.Step{}) {
+		return map[string]any{"$ref": "#/$defs/step"}
+	}
+
+	switch fieldType.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int64, reflect.Int32:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": typeSchema(fieldType.Elem(), defs)}
+	case reflect.Map:
+		return map[string]any{"type": "object"}
+	case reflect.Struct:
+		return structSchema(fieldType, defs)
+	}
+	// interface{} values such as args accept anything
+	return map[string]any{}
+}
+
+// schemaEnum parses `schema:"enum=a|b|c"`
+func schemaEnum(tag string) []string {
+	for _, part := range strings.Split(tag, ",") {
+		if values, ok := strings.CutPrefix(part, "enum="); ok {
+			return strings.Split(values, "|")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/JianLoong/robogo/internal/common"
+)
+
+// sensitiveValue is the value stored under the sensitive key in every step
+const sensitiveValue = "123-45-6789"
+
+// keyedSteps returns a test case whose http, log and assert steps carry a value under
+// key, in a URL query, a JSON body and key=value text
+func keyedSteps(serverURL, key, stepFields string) string {
+	return fmt.Sprintf(`testcase: "keys"
+steps:
+  - name: "http"
+    action: http
+    args: ["POST", "%[1]s/people?%[2]s=%[3]s", '{"name": "a", "%[2]s": "%[3]s"}']%[4]s
+  - name: "log"
+    action: log
+    args: ["%[2]s=%[3]s"]%[4]s
+  - name: "assert"
+    action: assert
+    args: ["%[2]s=%[3]s", "==", "%[2]s=%[3]s"]%[4]s
+`, serverURL, key, sensitiveValue, stepFields)
+}
+
+// variableOutput matches the parts of step output that change between runs
+var variableOutput = regexp.MustCompile(`\((\d+(\.\d+)?(µs|ms|s|ns))\)|\[\d+ characters\]|\d+(\.\d+)?(µs|ms|ns)\b`)
+
+// printedArgs returns the Args lines of step output
+func printedArgs(output string) []string {
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "Args:") {
+			lines = append(lines, strings.TrimSpace(line))
+		}
+	}
+	return lines
+}
+
+func TestCustomGlobalKeyMasksLikeBuiltInKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok": true}`))
+	}))
+	defer server.Close()
+
+	_, builtIn := runTestYAML(t, keyedSteps(server.URL, "password", ""))
+
+	t.Setenv(common.SensitiveKeysEnvVar, "ssn")
+	_, custom := runTestYAML(t, keyedSteps(server.URL, "ssn", ""))
+
+	args := printedArgs(custom)
+	if len(args) != 3 {
+		t.Fatalf("got %d Args lines, want one per step:\n%s", len(args), custom)
+	}
+	for _, line := range args {
+		if strings.Contains(line, sensitiveValue) || !strings.Contains(line, "ssn=***") {
+			t.Errorf("custom global key not masked in %q", line)
+		}
+	}
+
+	// The custom key is masked wherever the built-in key is, in every action
+	normalize := func(output string) string {
+		return variableOutput.ReplaceAllString(output, "<varies>")
+	}
+	if got, want := normalize(strings.ReplaceAll(custom, "ssn", "password")), normalize(builtIn); got != want {
+		t.Errorf("custom key output differs from built-in key output\ncustom:\n%s\nbuilt-in:\n%s", got, want)
+	}
+}
+
+func TestStepSensitiveFieldsAddToGlobalKeys(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	// Without the global key, steps naming it in sensitive_fields mask it the same way
+	_, output := runTestYAML(t, keyedSteps(server.URL, "ssn", "\n    sensitive_fields: [\"ssn\"]"))
+	for _, line := range printedArgs(output) {
+		if strings.Contains(line, sensitiveValue) {
+			t.Errorf("sensitive_fields key not masked in %q", line)
+		}
+	}
+
+	// And with neither, the value is printed
+	_, output = runTestYAML(t, keyedSteps(server.URL, "ssn", ""))
+	if !strings.Contains(strings.Join(printedArgs(output), "\n"), sensitiveValue) {
+		t.Errorf("ssn masked without being configured:\n%s", output)
+	}
+}
@@ -0,0 +1,264 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/JianLoong/robogo/internal/common"
+	"github.com/JianLoong/robogo/internal/types"
+	"github.com/google/uuid"
+)
+
+// sentryTimeout bounds each event submission so a slow tracker never stalls the run
+const sentryTimeout = 5 * time.Second
+
+// SentryExporter sends failed step results to Sentry using the store API.
+// Export is best-effort: problems are reported as warnings and never fail the run.
+type SentryExporter struct {
+	storeURL  string
+	publicKey string
+	client    *http.Client
+}
+
+// NewSentryExporter creates an exporter from a Sentry DSN
+// (https://<public_key>@<host>/<project_id>)
+func NewSentryExporter(dsn string) (*SentryExporter, error) {
+	parsed, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Sentry DSN: %w", err)
+	}
+	if parsed.User == nil || parsed.User.Username() == "" {
+		return nil, fmt.Errorf("invalid Sentry DSN: missing public key")
+	}
+
+	projectID := strings.Trim(parsed.Path, "/")
+	if projectID == "" {
+		return nil, fmt.Errorf("invalid Sentry DSN: missing project id")
+	}
+
+	// Project ID is the last path segment; anything before it is a path prefix
+	prefix := ""
+	if idx := strings.LastIndex(projectID, "/"); idx != -1 {
+		prefix = "/" + projectID[:idx]
+		projectID = projectID[idx+1:]
+	}
+
+	return &SentryExporter{
+		storeURL:  fmt.Sprintf("%s://%s%s/api/%s/store/", parsed.Scheme, parsed.Host, prefix, projectID),
+		publicKey: parsed.User.Username(),
+		client:    &http.Client{Timeout: sentryTimeout},
+	}, nil
+}
+
+// ExportFailures sends one event per failed or errored step in the test result of
+// filename. Each event carries the steps before it as breadcrumbs, at the time they ended.
+func (e *SentryExporter) ExportFailures(filename string, result *types.TestResult) {
+	var breadcrumbs []map[string]any
+
+	phases := []struct {
+		name  string
+		steps []types.StepResult
+	}{
+		{"setup", result.SetupSteps},
+		{"steps", result.Steps},
+		{"teardown", result.TeardownSteps},
+	}
+
+	for _, phase := range phases {
+		for i, step := range phase.steps {
+			if step.Result.HasIssue() {
+				event := e.buildEvent(filename, result, phase.name, i+1, step, breadcrumbs)
+				if err := e.send(event); err != nil {
+					fmt.Printf("[WARN] Failed to export error for step '%s' to Sentry: %v\n", step.Name, err)
+				}
+			}
+
+			// Earlier steps become breadcrumbs for later failures
+			breadcrumb := map[string]any{
+				"category": phase.name,
+				"message":  fmt.Sprintf("%s (%s): %s", step.Name, step.Action, step.Result.Status),
+				"level":    breadcrumbLevel(step.Result),
+			}
+			if ended, ok := stepEnded(step); ok {
+				breadcrumb["timestamp"] = sentryTimestamp(ended)
+			}
+			breadcrumbs = append(breadcrumbs, breadcrumb)
+		}
+	}
+}
+
+// buildEvent creates the Sentry event payload for a failed step, the stepNum-th of its phase
+func (e *SentryExporter) buildEvent(filename string, result *types.TestResult, phase string, stepNum int, step types.StepResult, breadcrumbs []map[string]any) map[string]any {
+	// Messages are already masked when built; mask again in case an action
+	// embedded credentials directly in its message template
+	message := sentryMessage(step.Result)
+
+	tags := map[string]string{
+		"test_case": result.Name,
+		"action":    step.Action,
+		"step":      step.Name,
+		"phase":     phase,
+		"status":    string(step.Result.Status),
+	}
+	extra := map[string]any{
+		"duration": step.Duration.String(),
+	}
+
+	// Step ids are unique, so they keep same-named steps in separate issues
+	stepKey := step.Name
+	if step.ID != "" {
+		tags["step_id"] = step.ID
+		stepKey = step.ID
+	}
+
+	category, code := issueCode(step.Result)
+	if code != "" {
+		tags["category"] = category
+		tags["code"] = code
+	}
+
+	// The failing step is the innermost frame, below its test case; a failed step of a
+	// called test case is below the call step
+	frames := []map[string]any{{
+		"filename": filename,
+		"function": result.Name,
+		"module":   "test case",
+		"in_app":   true,
+	}}
+	frames = append(frames, stepFrames(filename, phase, stepNum, step)...)
+
+	timestamp := time.Now()
+	if ended, ok := stepEnded(step); ok {
+		timestamp = ended
+	}
+
+	return map[string]any{
+		"event_id":  strings.ReplaceAll(uuid.New().String(), "-", ""),
+		"timestamp": sentryTimestamp(timestamp),
+		"platform":  "go",
+		"logger":    "robogo",
+		"level":     breadcrumbLevel(step.Result),
+		"message":   map[string]any{"formatted": message},
+		"exception": map[string]any{"values": []map[string]any{{
+			"type":       code,
+			"value":      message,
+			"module":     category,
+			"stacktrace": map[string]any{"frames": frames},
+		}}},
+		"fingerprint": []string{result.Name, stepKey, tags["code"]},
+		"tags":        tags,
+		"extra":       extra,
+		"breadcrumbs": map[string]any{"values": breadcrumbs},
+	}
+}
+
+// stepFrames returns the stack frames of a failed step: its own frame with the action,
+// error code and location, followed by the frames of the first failed step of a called
+// test case, if any
+func stepFrames(filename, phase string, stepNum int, step types.StepResult) []map[string]any {
+	source := step.Source
+	if source == "" {
+		source = filename
+	}
+	category, code := issueCode(step.Result)
+	vars := map[string]any{
+		"action": step.Action,
+		"status": string(step.Result.Status),
+		"code":   code,
+	}
+	if category != "" {
+		vars["category"] = category
+	}
+	if step.ID != "" {
+		vars["step_id"] = step.ID
+	}
+	if step.Result.ErrorInfo != nil && step.Result.ErrorInfo.CorrelationID != "" {
+		vars["correlation_id"] = step.Result.ErrorInfo.CorrelationID
+	}
+	frames := []map[string]any{{
+		"filename": source,
+		"function": fmt.Sprintf("%s %d: %s", phase, stepNum, step.Name),
+		"module":   step.Action,
+		"in_app":   true,
+		"vars":     vars,
+	}}
+
+	for i, inner := range step.Steps {
+		if inner.Result.HasIssue() {
+			return append(frames, stepFrames(source, "steps", i+1, inner)...)
+		}
+	}
+	return frames
+}
+
+// issueCode returns the category and code of a failed or errored step
+func issueCode(result types.ActionResult) (string, string) {
+	if result.ErrorInfo != nil {
+		return string(result.ErrorInfo.Category), result.ErrorInfo.Code
+	}
+	if result.FailureInfo != nil {
+		return string(result.FailureInfo.Category), result.FailureInfo.Code
+	}
+	return "", ""
+}
+
+// sentryMessage returns the masked message of a step result
+func sentryMessage(result types.ActionResult) string {
+	return common.MaskSensitiveData(common.MaskCredentials(result.GetMessage()), common.SensitiveKeys())
+}
+
+// stepEnded returns when a step ended, if its start time is known
+func stepEnded(step types.StepResult) (time.Time, bool) {
+	if step.StartedAt.IsZero() {
+		return time.Time{}, false
+	}
+	return step.StartedAt.Add(step.Duration), true
+}
+
+// sentryTimestamp formats a time as Sentry expects: seconds since the epoch, with fractions
+func sentryTimestamp(t time.Time) float64 {
+	return float64(t.UnixNano()) / float64(time.Second)
+}
+
+// send posts a single event to the Sentry store endpoint
+func (e *SentryExporter) send(event map[string]any) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, e.storeURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sentry-Auth", fmt.Sprintf("Sentry sentry_version=7, sentry_client=robogo/1.0, sentry_key=%s", e.publicKey))
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// breadcrumbLevel maps a step status to a Sentry level
+func breadcrumbLevel(result types.ActionResult) string {
+	switch {
+	case result.IsError():
+		return "error"
+	case result.IsFailed():
+		return "warning"
+	default:
+		return "info"
+	}
+}
@@ -0,0 +1,141 @@
+package internal
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const sentryCaller = `testcase: "checkout"
+steps:
+  - name: "wait one"
+    action: sleep
+    args: ["40ms"]
+  - name: "wait two"
+    action: sleep
+    args: ["40ms"]
+  - name: "pay"
+    id: "pay"
+    call: "payment.yaml"
+`
+
+const sentryCallee = `testcase: "payment"
+steps:
+  - name: "charge"
+    action: assert
+    args: [1, "==", 2]
+`
+
+// sentryEvent is the part of a Sentry event the tests check
+type sentryEvent struct {
+	Timestamp   float64 `json:"timestamp"`
+	Breadcrumbs struct {
+		Values []struct {
+			Timestamp float64 `json:"timestamp"`
+			Message   string  `json:"message"`
+		} `json:"values"`
+	} `json:"breadcrumbs"`
+	Exception struct {
+		Values []struct {
+			Type       string `json:"type"`
+			Value      string `json:"value"`
+			Stacktrace struct {
+				Frames []struct {
+					Filename string         `json:"filename"`
+					Function string         `json:"function"`
+					Module   string         `json:"module"`
+					Vars     map[string]any `json:"vars"`
+				} `json:"frames"`
+			} `json:"stacktrace"`
+		} `json:"values"`
+	} `json:"exception"`
+}
+
+// exportToServer exports the failures of the checkout test case and returns the events sent
+func exportToServer(t *testing.T) []sentryEvent {
+	t.Helper()
+	results, _ := runTestFiles(t, map[string]string{"checkout.yaml": sentryCaller, "payment.yaml": sentryCallee}, "checkout.yaml")
+
+	var mu sync.Mutex
+	var events []sentryEvent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var event sentryEvent
+		if err := json.Unmarshal(body, &event); err != nil {
+			t.Errorf("invalid event: %v", err)
+		}
+		mu.Lock()
+		events = append(events, event)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	exporter, err := NewSentryExporter(strings.Replace(server.URL, "://", "://public@", 1) + "/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exporter.ExportFailures("checkout.yaml", results[0])
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want 1 for the failed call step", len(events))
+	}
+	return events
+}
+
+func TestSentryBreadcrumbsAtStepTimes(t *testing.T) {
+	before := float64(time.Now().UnixNano()) / float64(time.Second)
+	event := exportToServer(t)[0]
+
+	crumbs := event.Breadcrumbs.Values
+	if len(crumbs) != 2 {
+		t.Fatalf("got %d breadcrumbs, want the 2 steps before the failure", len(crumbs))
+	}
+	// Each breadcrumb is when its step ended: 40ms apart, not all at export time
+	if gap := crumbs[1].Timestamp - crumbs[0].Timestamp; gap < 0.035 || gap > 0.5 {
+		t.Errorf("breadcrumbs %.3fs apart, want about the 40ms the second step slept", gap)
+	}
+	if crumbs[0].Timestamp < before+0.035 {
+		t.Errorf("first breadcrumb at %.3f, before its step could have ended (run started %.3f)", crumbs[0].Timestamp, before)
+	}
+	if event.Timestamp < crumbs[1].Timestamp {
+		t.Errorf("event at %.3f, before the last breadcrumb at %.3f", event.Timestamp, crumbs[1].Timestamp)
+	}
+}
+
+func TestSentryExceptionFrames(t *testing.T) {
+	event := exportToServer(t)[0]
+
+	if len(event.Exception.Values) != 1 {
+		t.Fatalf("got %d exceptions, want 1", len(event.Exception.Values))
+	}
+	exception := event.Exception.Values[0]
+	if exception.Value == "" {
+		t.Error("exception has no message")
+	}
+
+	// Test case, then the call step, then the failed step of the called test case
+	frames := exception.Stacktrace.Frames
+	if len(frames) != 3 {
+		t.Fatalf("got %d frames, want 3: %+v", len(frames), frames)
+	}
+	if frames[0].Filename != "checkout.yaml" || frames[0].Function != "checkout" {
+		t.Errorf("test case frame %+v", frames[0])
+	}
+	if exception.Type == "" || frames[1].Vars["code"] != exception.Type {
+		t.Errorf("exception type %q, want the call step's code %v", exception.Type, frames[1].Vars["code"])
+	}
+	if !strings.HasSuffix(frames[1].Filename, "checkout.yaml") || frames[1].Function != "steps 3: pay" || frames[1].Vars["step_id"] != "pay" {
+		t.Errorf("call step frame %+v", frames[1])
+	}
+	charge := frames[2]
+	if !strings.HasSuffix(charge.Filename, "payment.yaml") || charge.Function != "steps 1: charge" || charge.Module != "assert" {
+		t.Errorf("called step frame %+v", charge)
+	}
+	if charge.Vars["action"] != "assert" || charge.Vars["code"] == "" || charge.Vars["status"] != "FAIL" {
+		t.Errorf("called step frame vars %v", charge.Vars)
+	}
+}
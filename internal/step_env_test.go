@@ -0,0 +1,137 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/JianLoong/robogo/internal/common"
+	"github.com/JianLoong/robogo/internal/types"
+)
+
+// regionPlugin is a plugin that returns the SAMPLE_REGION its process sees
+const regionPlugin = `#!/bin/sh
+cat > /dev/null
+printf '{"status": "PASS", "data": {"region": "%s"}}' "$SAMPLE_REGION"
+`
+
+const regionPluginManifest = `plugins:
+  - name: print_region
+    command: ./print-region.sh
+    timeout: "5s"
+`
+
+const stepEnvTest = `testcase: "step env"
+variables:
+  vars:
+    region: "eu-west-1"
+steps:
+  - name: "plugin with env"
+    action: print_region
+    env:
+      SAMPLE_REGION: "${region}"
+    result: plugin
+  - name: "probe with env"
+    action: probe_env
+    env:
+      SAMPLE_REGION: "us-east-1"
+    args: ["${ENV:SAMPLE_REGION}"]
+    result: probe
+  - name: "plugin without env"
+    action: print_region
+    result: plugin_after
+  - name: "probe without env"
+    action: probe_env
+    args: ["${ENV:SAMPLE_REGION}"]
+    result: probe_after
+`
+
+// probeEnv returns its argument, the SAMPLE_REGION its step sees and the one of the process
+func probeEnv(args []any, options map[string]any, vars *common.Variables) types.ActionResult {
+	return types.ActionResult{
+		Status: types.ActionStatusPassed,
+		Data: map[string]any{
+			"arg":     args[0],
+			"step":    vars.Getenv("SAMPLE_REGION"),
+			"process": os.Getenv("SAMPLE_REGION"),
+		},
+	}
+}
+
+func TestStepEnvScopedToStep(t *testing.T) {
+	t.Setenv("SAMPLE_REGION", "process-region")
+	dir := t.TempDir()
+	files := map[string]string{"print-region.sh": regionPlugin, "plugins.yaml": regionPluginManifest, "test.yaml": stepEnvTest}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	filename := filepath.Join(dir, "test.yaml")
+	testCases, err := LoadTestCases(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runner := NewTestRunner()
+	if err := runner.LoadPlugins(filepath.Join(dir, "plugins.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	runner.actionRegistry.Register("probe_env", probeEnv)
+	var result *types.TestResult
+	captureStdout(t, func() {
+		result, err = runner.RunTest(context.Background(), filename, testCases[0])
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != string(types.ActionStatusPassed) {
+		t.Fatalf("test status %s: %+v", result.Status, result.ErrorInfo)
+	}
+
+	tests := []struct {
+		variable string
+		want     string
+	}{
+		{"plugin.region", "eu-west-1"},      // the plugin process gets the step's env
+		{"probe.arg", "us-east-1"},          // ${ENV:...} in the step's args sees its env
+		{"probe.step", "us-east-1"},         // the action sees it through vars
+		{"probe.process", "process-region"}, // the process environment is not changed
+		{"plugin_after.region", "process-region"},
+		{"probe_after.arg", "process-region"},
+		{"probe_after.step", "process-region"},
+	}
+	for _, tc := range tests {
+		if got := runner.variables.Substitute("${" + tc.variable + "}"); got != tc.want {
+			t.Errorf("%s = %q, want %q", tc.variable, got, tc.want)
+		}
+	}
+	if got := os.Getenv("SAMPLE_REGION"); got != "process-region" {
+		t.Errorf("SAMPLE_REGION is %q after the run, want it unchanged", got)
+	}
+}
+
+func TestEnvironAppliesStepEnv(t *testing.T) {
+	t.Setenv("SAMPLE_REGION", "process-region")
+	vars := common.NewVariables()
+	restore := vars.SetStepEnv(map[string]string{"SAMPLE_REGION": "eu-west-1", "SAMPLE_ZONE": "a"})
+
+	found := map[string]int{}
+	for _, entry := range vars.Environ() {
+		switch entry {
+		case "SAMPLE_REGION=eu-west-1", "SAMPLE_ZONE=a":
+			found[entry]++
+		case "SAMPLE_REGION=process-region":
+			t.Error("Environ keeps the overridden process value")
+		}
+	}
+	if found["SAMPLE_REGION=eu-west-1"] != 1 || found["SAMPLE_ZONE=a"] != 1 {
+		t.Errorf("Environ holds the step env %v times", found)
+	}
+
+	restore()
+	if got := vars.Getenv("SAMPLE_ZONE"); got != "" {
+		t.Errorf("SAMPLE_ZONE = %q after restore, want it unset", got)
+	}
+}
@@ -0,0 +1,94 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/JianLoong/robogo/internal/types"
+)
+
+// traceEvent is one entry in the Chrome trace event format, which chrome://tracing,
+// Perfetto and speedscope open as a timeline or flame graph
+type traceEvent struct {
+	Name      string         `json:"name"`
+	Category  string         `json:"cat,omitempty"`
+	Phase     string         `json:"ph"`
+	Timestamp int64          `json:"ts"` // microseconds since the run started
+	Duration  int64          `json:"dur,omitempty"`
+	Process   int            `json:"pid"`
+	Thread    int            `json:"tid"`
+	Args      map[string]any `json:"args,omitempty"`
+}
+
+// writeStepProfile writes every action execution of a run as a trace, one track per test case.
+// Each test case span runs from its first action to the end of its last.
+func writeStepProfile(path string, results []*types.TestResult) error {
+	var runStart time.Time
+	for _, result := range results {
+		for _, timing := range result.Timings {
+			if runStart.IsZero() || timing.Start.Before(runStart) {
+				runStart = timing.Start
+			}
+		}
+	}
+
+	events := []traceEvent{}
+	for i, result := range results {
+		if len(result.Timings) == 0 {
+			continue
+		}
+		track := i + 1
+		events = append(events, traceEvent{
+			Name:   "thread_name",
+			Phase:  "M",
+			Thread: track,
+			Args:   map[string]any{"name": result.Name},
+		})
+
+		caseStart, caseEnd := result.Timings[0].Start, result.Timings[0].Start
+		for _, timing := range result.Timings {
+			end := timing.Start.Add(timing.Duration)
+			if timing.Start.Before(caseStart) {
+				caseStart = timing.Start
+			}
+			if end.After(caseEnd) {
+				caseEnd = end
+			}
+			events = append(events, traceEvent{
+				Name:      timing.Step,
+				Category:  "step",
+				Phase:     "X",
+				Timestamp: timing.Start.Sub(runStart).Microseconds(),
+				Duration:  timing.Duration.Microseconds(),
+				Thread:    track,
+				Args:      map[string]any{"action": timing.Action, "status": timing.Status},
+			})
+		}
+		events = append(events, traceEvent{
+			Name:      result.Name,
+			Category:  "testcase",
+			Phase:     "X",
+			Timestamp: caseStart.Sub(runStart).Microseconds(),
+			Duration:  caseEnd.Sub(caseStart).Microseconds(),
+			Thread:    track,
+			Args:      map[string]any{"status": result.Status},
+		})
+	}
+
+	data, err := json.Marshal(map[string]any{"traceEvents": events, "displayTimeUnit": "ms"})
+	if err != nil {
+		return fmt.Errorf("failed to marshal step profile: %w", err)
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create step profile directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write step profile: %w", err)
+	}
+	return nil
+}
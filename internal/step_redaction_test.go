@@ -0,0 +1,114 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/JianLoong/robogo/internal/types"
+)
+
+// redactedStep is a failing assert whose arguments and options carry a value under a
+// step-level sensitive field
+const redactedStep = `    action: assert
+    args: ["member_id=M-4711", "==", "member_id=M-0000"]
+    options:
+      headers: {Authorization: "Bearer tok-4711"}
+    sensitive_fields: ["member_id"]
+`
+
+// redactionPaths wraps the same step in each execution path: run directly, under a
+// condition, with retry, and as the only nested step of a group
+var redactionPaths = map[string]string{
+	"direct":      "  - name: \"check\"\n" + redactedStep,
+	"conditional": "  - name: \"check\"\n    if: \"1 == 1\"\n" + redactedStep,
+	"retry":       "  - name: \"check\"\n    retry: {attempts: 1, delay: \"1ms\"}\n" + redactedStep,
+	"nested":      "  - name: \"group\"\n    steps:\n      - name: \"check\"\n" + indent(redactedStep, "    "),
+}
+
+// indent prefixes every non-empty line of text
+func indent(text, prefix string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// printedLines returns the trimmed lines of output starting with one of the prefixes
+func printedLines(output string, prefixes ...string) []string {
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(line, prefix) {
+				lines = append(lines, line)
+			}
+		}
+	}
+	return lines
+}
+
+// failedMessage returns the message of the assert step, however deeply it is nested
+func failedMessage(steps []types.StepResult) string {
+	for _, step := range steps {
+		if step.Action == "assert" {
+			return step.Result.GetMessage()
+		}
+		if message := failedMessage(step.Steps); message != "" {
+			return message
+		}
+	}
+	return ""
+}
+
+func TestSameStepRedactedAlikeOnEveryPath(t *testing.T) {
+	var directArgs []string
+	var directMessage string
+	for _, path := range []string{"direct", "conditional", "retry", "nested"} {
+		t.Run(path, func(t *testing.T) {
+			results, output := runTestYAML(t, "testcase: \"redaction\"\nsteps:\n"+redactionPaths[path])
+			for _, secret := range []string{"M-4711", "tok-4711"} {
+				if strings.Contains(output, secret) {
+					t.Errorf("output leaks %q:\n%s", secret, output)
+				}
+			}
+
+			args := printedLines(output, "Args:", "Options:")
+			message := failedMessage(results[0].Steps)
+			if message == "" {
+				message = results[0].GetMessage()
+			}
+			if strings.Contains(message, "M-4711") {
+				t.Errorf("result message leaks the sensitive field: %s", message)
+			}
+
+			if len(args) == 0 || message == "" {
+				t.Fatalf("step printed no args or has no failure message:\n%s", output)
+			}
+			if path == "direct" {
+				directArgs, directMessage = args, message
+				return
+			}
+			if strings.Join(args, "\n") != strings.Join(directArgs, "\n") {
+				t.Errorf("printed args differ from the direct path\ngot:\n%s\nwant:\n%s", strings.Join(args, "\n"), strings.Join(directArgs, "\n"))
+			}
+			if message != directMessage {
+				t.Errorf("result message differs from the direct path\ngot:  %s\nwant: %s", message, directMessage)
+			}
+		})
+	}
+}
+
+func TestNoLogHonoredOnEveryPath(t *testing.T) {
+	for _, path := range []string{"direct", "conditional", "retry", "nested"} {
+		t.Run(path, func(t *testing.T) {
+			step := strings.Replace(redactionPaths[path], "sensitive_fields: [\"member_id\"]", "no_log: true", 1)
+			_, output := runTestYAML(t, "testcase: \"no log\"\nsteps:\n"+step)
+			if strings.Contains(output, "member_id=") || strings.Contains(output, "tok-4711") {
+				t.Errorf("no_log step printed its arguments:\n%s", output)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// sleepingSuite holds three test cases that sleep in setup, steps and teardown
+const sleepingSuite = `testcase: "setup and steps"
+setup:
+  - name: "warm up"
+    action: sleep
+    args: ["40ms"]
+steps:
+  - name: "work"
+    action: sleep
+    args: ["60ms"]
+---
+testcase: "steps only"
+steps:
+  - name: "work"
+    action: sleep
+    args: ["80ms"]
+---
+testcase: "steps and teardown"
+steps:
+  - name: "work"
+    action: sleep
+    args: ["30ms"]
+teardown:
+  - name: "clean up"
+    action: sleep
+    args: ["50ms"]
+`
+
+func TestSequentialCaseDurationsSumToSuiteDuration(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "suite.yaml")
+	if err := os.WriteFile(filename, []byte(sleepingSuite), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	reportPath := filepath.Join(dir, "report.json")
+
+	start := time.Now()
+	captureStdout(t, func() {
+		runTest(context.Background(), filename, ParsedArgs{report: reportPath})
+	})
+	suite := time.Since(start)
+
+	var report runReport
+	if err := json.Unmarshal([]byte(readFile(t, reportPath)), &report); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Cases) != 3 {
+		t.Fatalf("report holds %d test cases, want 3", len(report.Cases))
+	}
+
+	// Each case's duration covers its setup, steps and teardown
+	slept := []time.Duration{100 * time.Millisecond, 80 * time.Millisecond, 80 * time.Millisecond}
+	var sum time.Duration
+	for i, c := range report.Cases {
+		duration := time.Duration(c.DurationMs * float64(time.Millisecond))
+		if duration < slept[i] {
+			t.Errorf("%s took %s, less than the %s it slept", c.Name, duration, slept[i])
+		}
+		sum += duration
+	}
+
+	// The cases run one after the other, so only loading, summaries and reports are
+	// outside their durations
+	if sum > suite {
+		t.Errorf("case durations sum to %s, more than the suite's %s", sum, suite)
+	}
+	if outside := suite - sum; outside > suite/4 {
+		t.Errorf("case durations sum to %s of the suite's %s", sum, suite)
+	}
+}
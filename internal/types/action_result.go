@@ -21,8 +21,10 @@ type ActionResult struct {
 	Status      ActionStatus `json:"status"`                 // "pending", "running", "success", "error", "skipped"
 	ErrorInfo   *ErrorInfo   `json:"error_info,omitempty"`   // Structured error information (technical errors)
 	FailureInfo *FailureInfo `json:"failure_info,omitempty"` // Structured failure information (logical failures)
+	SkipInfo    *SkipInfo    `json:"skip_info,omitempty"`    // Why the step was skipped (status == "skipped")
 	Data        any          `json:"data,omitempty"`         // Result data if status == "success"
 	Meta        any          `json:"meta,omitempty"`         // Optional metadata (timing, logs, etc.)
+	Artifacts   []Artifact   `json:"-"`                      // Files the action attaches, moved to the step result
 }
 
 // NewSuccessResult creates an ActionResult with passed status
@@ -40,17 +42,40 @@ func NewSuccessResultWithData(data any) ActionResult {
 	}
 }
 
-// NewSkippedResult creates an ActionResult with skipped status
-func NewSkippedResult(reason string) ActionResult {
-	errorInfo := &ErrorInfo{
-		Category:  ErrorCategoryValidation,
-		Code:      "SKIPPED",
-		Message:   reason,
+// SkipCategory distinguishes intentional skips from skips caused by upstream failures
+type SkipCategory string
+
+const (
+	SkipCategoryConditional  SkipCategory = "conditional"   // step's if condition evaluated to false
+	SkipCategorySetupFailure SkipCategory = "setup_failure" // test skipped because setup failed
+	SkipCategoryCancelled    SkipCategory = "cancelled"     // run was interrupted before the step started
+	SkipCategoryFiltered     SkipCategory = "filtered"      // test case excluded by --filter
+	SkipCategoryEnvironment  SkipCategory = "environment"   // test case excluded by only_on/not_on
+)
+
+// SkipInfo contains structured information about why something was skipped
+type SkipInfo struct {
+	Category  SkipCategory      `json:"category"`
+	Reason    string            `json:"reason"`
+	Condition string            `json:"condition,omitempty"` // The if condition that skipped a step
+	Values    map[string]string `json:"values,omitempty"`    // Values of the variables in Condition when it was evaluated
+	Timestamp time.Time         `json:"timestamp"`
+}
+
+// NewSkipInfo creates skip information with the current timestamp
+func NewSkipInfo(category SkipCategory, reason string) *SkipInfo {
+	return &SkipInfo{
+		Category:  category,
+		Reason:    reason,
 		Timestamp: time.Now(),
 	}
+}
+
+// NewSkippedResult creates an ActionResult with skipped status
+func NewSkippedResult(category SkipCategory, reason string) ActionResult {
 	return ActionResult{
-		Status:    ActionStatusSkipped,
-		ErrorInfo: errorInfo,
+		Status:   ActionStatusSkipped,
+		SkipInfo: NewSkipInfo(category, reason),
 	}
 }
 
@@ -66,8 +91,11 @@ func (ar *ActionResult) GetMessage() string {
 }
 
 
-// GetSkipReason returns the skip reason from ErrorInfo
+// GetSkipReason returns the skip reason from SkipInfo, falling back to ErrorInfo
 func (ar *ActionResult) GetSkipReason() string {
+	if ar.SkipInfo != nil {
+		return ar.SkipInfo.Reason
+	}
 	if ar.ErrorInfo != nil && ar.ErrorInfo.Category == ErrorCategoryValidation {
 		return ar.ErrorInfo.Message
 	}
@@ -0,0 +1,16 @@
+package types
+
+// Artifact is a file attached to a step or test case result, such as a screenshot, a
+// downloaded file or a response dump. The run writes it to the --artifacts directory and
+// records where in Path; an artifact that was not written says why in Skipped.
+type Artifact struct {
+	Name        string `json:"name"`
+	ContentType string `json:"content_type,omitempty"`
+	Source      string `json:"source,omitempty"` // file the artifact was attached from
+	Content     []byte `json:"-"`                // attached bytes, when there is no Source
+	Path        string `json:"path,omitempty"`   // where the artifact was written
+	Size        int64  `json:"size"`
+	SHA256      string `json:"sha256,omitempty"`  // of the content as written
+	Masked      bool   `json:"masked,omitempty"`  // secrets were masked before writing
+	Skipped     string `json:"skipped,omitempty"` // why the artifact was not written
+}
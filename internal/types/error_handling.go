@@ -3,6 +3,8 @@ package types
 import (
 	"fmt"
 	"time"
+
+	"github.com/JianLoong/robogo/internal/common"
 )
 
 // ErrorCategory represents different categories of errors that can occur
@@ -16,14 +18,16 @@ const (
 	ErrorCategoryNetwork    ErrorCategory = "network"
 	ErrorCategoryDatabase   ErrorCategory = "database"
 	ErrorCategorySystem     ErrorCategory = "system"
+	ErrorCategoryFileSystem ErrorCategory = "filesystem"
 )
 
 // ErrorInfo contains structured information about an error
 type ErrorInfo struct {
-	Category  ErrorCategory `json:"category"`
-	Code      string        `json:"code"`
-	Message   string        `json:"message"`
-	Timestamp time.Time     `json:"timestamp"`
+	Category      ErrorCategory `json:"category"`
+	Code          string        `json:"code"`
+	Message       string        `json:"message"`
+	CorrelationID string        `json:"correlation_id,omitempty"` // Correlation header sent with the failed request
+	Timestamp     time.Time     `json:"timestamp"`
 }
 
 // NewError creates a simple error result
@@ -51,6 +55,7 @@ type ErrorBuilder struct {
 	expected    any
 	actual      any
 	comparison  string
+	correlation string
 }
 
 // NewErrorBuilder creates a new ErrorBuilder
@@ -83,6 +88,16 @@ func (eb *ErrorBuilder) WithSuggestion(suggestion string) *ErrorBuilder {
 	return eb
 }
 
+// WithCorrelationID records the correlation ID sent with a failed request, so the
+// failure can be found in server logs
+func (eb *ErrorBuilder) WithCorrelationID(correlationID string) *ErrorBuilder {
+	if correlationID == "" {
+		return eb
+	}
+	eb.correlation = correlationID
+	return eb.WithContext("correlation_id", correlationID)
+}
+
 // WithExpected sets the expected value for comparison errors
 func (eb *ErrorBuilder) WithExpected(expected any) *ErrorBuilder {
 	eb.expected = expected
@@ -111,11 +126,14 @@ func (eb *ErrorBuilder) Build(args ...any) ActionResult {
 		message = fmt.Sprintf(eb.template, args...)
 	}
 
+	// Template args often carry raw driver or transport errors that echo credentials
+	message = common.MaskCredentials(message)
+
 	// Enhance message with context if available
 	if len(eb.context) > 0 {
 		message += "\nContext:"
 		for key, value := range eb.context {
-			message += fmt.Sprintf("\n  %s: %v", key, value)
+			message += fmt.Sprintf("\n  %s: %s", key, maskContextValue(value))
 		}
 	}
 
@@ -141,5 +159,15 @@ func (eb *ErrorBuilder) Build(args ...any) ActionResult {
 		}
 	}
 
-	return NewError(eb.category, eb.code, message)
+	result := NewError(eb.category, eb.code, message)
+	result.ErrorInfo.CorrelationID = eb.correlation
+	return result
+}
+
+// maskContextValue formats a context value with credentials masked.
+// Context often carries connection strings or captured variables, and error
+// messages may be shipped outside the run, so secrets must never leak here.
+func maskContextValue(value any) string {
+	str := common.MaskCredentials(fmt.Sprintf("%v", value))
+	return common.MaskSensitiveData(str, common.SensitiveKeys())
 }
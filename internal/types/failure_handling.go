@@ -3,6 +3,8 @@ package types
 import (
 	"fmt"
 	"time"
+
+	"github.com/JianLoong/robogo/internal/common"
 )
 
 // FailureCategory represents different categories of logical failures
@@ -107,11 +109,14 @@ func (fb *FailureBuilder) Build(args ...any) ActionResult {
 		message = fmt.Sprintf(fb.template, args...)
 	}
 
+	// Template args often carry raw driver or transport errors that echo credentials
+	message = common.MaskCredentials(message)
+
 	// Enhance message with context if available
 	if len(fb.context) > 0 {
 		message += "\nContext:"
 		for key, value := range fb.context {
-			message += fmt.Sprintf("\n  %s: %v", key, value)
+			message += fmt.Sprintf("\n  %s: %s", key, maskContextValue(value))
 		}
 	}
 
@@ -118,6 +118,15 @@ func AssertionFailure(expected, actual any, operator string) ActionResult {
 		Build(actual, operator, expected, actual)
 }
 
+// SensitiveAssertionFailure reports a failed sensitive assertion without the compared values
+func SensitiveAssertionFailure(operator string) ActionResult {
+	return NewFailureBuilder(FailureCategoryAssertion, "SENSITIVE_ASSERTION_FAILED").
+		WithTemplate("Sensitive assertion failed: values do not satisfy '%s' (values not logged)").
+		WithComparison(operator).
+		WithSuggestion("Check the source of both values; they are never printed for sensitive assertions").
+		Build(operator)
+}
+
 func BooleanAssertionFailure(actual any) ActionResult {
 	return NewFailureBuilder(FailureCategoryAssertion, "BOOLEAN_ASSERTION_FAILED").
 		WithTemplate("Boolean assertion failed: expected true, got %v (%T)").
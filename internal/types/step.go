@@ -1,7 +1,13 @@
 package types
 
+import (
+	"fmt"
+	"time"
+)
+
 type Step struct {
 	Name     string         `yaml:"name"`
+	ID       string         `yaml:"id,omitempty"` // Optional unique identifier, for steps that share a name
 	Action   string         `yaml:"action,omitempty"`
 	Steps    []Step         `yaml:"steps,omitempty"`
 	Args     []any          `yaml:"args,omitempty"`
@@ -13,16 +19,39 @@ type Step struct {
 	While    string         `yaml:"while,omitempty"`
 	Retry    *RetryConfig   `yaml:"retry,omitempty"`
 	Continue bool           `yaml:"continue,omitempty"`
-	NoLog           bool     `yaml:"no_log,omitempty"`           // Suppress logging for sensitive steps
+	NoLog           *bool    `yaml:"no_log,omitempty"`           // Suppress logging for sensitive steps (default: the test case's no_log)
 	SensitiveFields []string `yaml:"sensitive_fields,omitempty"` // Custom fields to mask in logs and output
+	Sensitive       bool     `yaml:"sensitive,omitempty"`        // For assert: compare the values but only ever log whether they matched
+	MaxDataBytes    int      `yaml:"max_data_bytes,omitempty"`   // Bytes of result data kept in the step result (default: --max-data-bytes, 64 KiB)
+	DiscardData     bool     `yaml:"discard_data,omitempty"`     // Drop the result data once extract and result have used it
+	Attach          string   `yaml:"attach,omitempty"`           // Attach the full result data as an artifact with this file name, see --artifacts
 	Summary         *bool    `yaml:"summary,omitempty"`          // Include step in summary table (default: true)
+	SkipReason      string   `yaml:"skip_reason,omitempty"`      // Message reported when the if condition skips the step
+	Repeat          int      `yaml:"repeat,omitempty"`           // Run the step N times and report the pass rate
+	RepeatUntil     string   `yaml:"repeat_until,omitempty"`     // Stop repeating once this condition is true
+	RepeatWhile     string   `yaml:"repeat_while,omitempty"`     // Keep repeating only while this condition is true
+	Eventually      *EventuallyConfig `yaml:"eventually,omitempty"` // Re-run the step until it passes or the timeout ends
+	Cache           *CacheConfig      `yaml:"cache,omitempty"`      // Reuse the result of an identical read-only step run earlier, see --no-cache
+	Finally         []Step   `yaml:"finally,omitempty"`          // Run after the nested steps whatever their outcome, e.g. cleanup
+	Call            string         `yaml:"call,omitempty"`     // Test case file to run as this step, relative to the calling file
+	Include         string         `yaml:"include,omitempty"`  // File of shared steps run as this step's nested steps
+	With            map[string]any `yaml:"with,omitempty"`     // Variables passed to the called test case or included steps
+	Env             map[string]string `yaml:"env,omitempty"`   // Environment variables set while the step's action runs, then restored
+	Source          string            `yaml:"-"`               // File that declares the step, set by the parser; relative paths in the step resolve against it
+}
+
+// LogSuppressed reports whether no_log is enabled for the step
+func (s Step) LogSuppressed() bool {
+	return s.NoLog != nil && *s.NoLog
 }
 
 // ExtractConfig defines data extraction from action results
 type ExtractConfig struct {
-	Type      string `yaml:"type"`               // "jq", "xpath", "regex", "csv"
+	Type      string `yaml:"type" schema:"enum=jq|xpath|regex|csv"` // "jq", "xpath", "regex", "csv"
 	Path      string `yaml:"path"`               // The extraction expression
 	Group     int    `yaml:"group,omitempty"`    // For regex: which capture group (default: 1)
+	Stream    bool   `yaml:"stream,omitempty"`   // For jq on http: apply the path to the JSON body while reading it, keeping only the result
+	Namespaces map[string]string `yaml:"namespaces,omitempty"` // For xpath: prefix to namespace URI, e.g. ns: "urn:orders"
 	
 	// CSV-specific options
 	Row       *int   `yaml:"row,omitempty"`      // For csv: specific row index (0-based), nil means not specified
@@ -32,14 +61,65 @@ type ExtractConfig struct {
 	Filter    string `yaml:"filter,omitempty"`   // For csv: simple filtering expression
 }
 
+// EventuallyConfig re-runs a step until it passes, for checks against eventually consistent
+// systems: an assert step, whose arguments are substituted again on every attempt, or
+// steps that fetch a value and then assert on it
+type EventuallyConfig struct {
+	Timeout  string `yaml:"timeout,omitempty"`  // How long to keep trying (default: "30s")
+	Interval string `yaml:"interval,omitempty"` // Wait between attempts (default: "1s")
+	Backoff  string `yaml:"backoff,omitempty" schema:"enum=fixed|linear|exponential"` // How the wait grows, as for retry (default: "fixed")
+}
+
+// Timing returns the timeout and interval, with the defaults for those not set
+func (c EventuallyConfig) Timing() (timeout, interval time.Duration, err error) {
+	timeout, interval = 30*time.Second, time.Second
+	if c.Timeout != "" {
+		if timeout, err = time.ParseDuration(c.Timeout); err != nil || timeout <= 0 {
+			return 0, 0, fmt.Errorf("timeout '%s' is not a positive duration such as 30s", c.Timeout)
+		}
+	}
+	if c.Interval != "" {
+		if interval, err = time.ParseDuration(c.Interval); err != nil || interval <= 0 {
+			return 0, 0, fmt.Errorf("interval '%s' is not a positive duration such as 1s", c.Interval)
+		}
+	}
+	switch c.Backoff {
+	case "", "fixed", "linear", "exponential":
+	default:
+		return 0, 0, fmt.Errorf("backoff '%s' must be fixed, linear or exponential", c.Backoff)
+	}
+	return timeout, interval, nil
+}
+
+// CacheConfig reuses the result of a read-only step, such as an http GET or a postgres
+// SELECT, for later steps of the run with the same action, arguments and options
+type CacheConfig struct {
+	Key string `yaml:"key,omitempty"` // Steps of the same action with this key share the result, whatever their arguments
+	TTL string `yaml:"ttl,omitempty"` // How long the result is reused (default: "5m")
+}
+
+// Lifetime returns how long the result is reused, 5 minutes when no ttl is set
+func (c CacheConfig) Lifetime() (time.Duration, error) {
+	if c.TTL == "" {
+		return 5 * time.Minute, nil
+	}
+	ttl, err := time.ParseDuration(c.TTL)
+	if err != nil || ttl <= 0 {
+		return 0, fmt.Errorf("ttl '%s' is not a positive duration such as 5m", c.TTL)
+	}
+	return ttl, nil
+}
+
 // RetryConfig defines retry behavior for a step
 type RetryConfig struct {
 	Attempts      int    `yaml:"attempts"`                  // Number of retry attempts
 	Delay         string `yaml:"delay"`                     // Base delay between retries (e.g., "1s", "500ms")
-	Backoff       string `yaml:"backoff,omitempty"`         // "fixed", "linear", "exponential"
+	Backoff       string `yaml:"backoff,omitempty" schema:"enum=fixed|linear|exponential"` // "fixed", "linear", "exponential"
 	StopOnSuccess bool   `yaml:"stop_on_success,omitempty"` // Stop retrying on first success
 	RetryIf       string `yaml:"retry_if,omitempty"`        // Condition to determine if retry should continue
 	// Can use extracted values, e.g., "${author} == 'Yours Truly'"
 	RetryOn []string `yaml:"retry_on,omitempty"` // Specific error types to retry on
-	// e.g., ["assertion_failed", "http_error", "timeout"]
+	// e.g., ["assertion_failed", "http_error", "timeout"], an error category or code such as "network",
+	// or HTTP statuses such as "503" or "5xx", which are retried even though the request itself passed
+	RetryUnless []string `yaml:"retry_unless,omitempty"` // Same entries as retry_on; a match stops retrying, e.g. ["4xx"]
 }
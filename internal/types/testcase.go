@@ -4,12 +4,38 @@ package types
 type TestCase struct {
 	Name        string        `yaml:"testcase"`
 	Description string        `yaml:"description,omitempty"`
+	OnlyOn      []string      `yaml:"only_on,omitempty"` // Environments the test case runs in; skipped elsewhere
+	NotOn       []string      `yaml:"not_on,omitempty"`  // Environments the test case never runs in
 	Setup       []Step        `yaml:"setup,omitempty"`
 	Steps       []Step        `yaml:"steps"`
 	Teardown    []Step        `yaml:"teardown,omitempty"`
 	Variables   TestVariables `yaml:"variables,omitempty"`
+
+	CircuitBreaker *CircuitBreakerConfig `yaml:"circuit_breaker,omitempty"` // Fail fast on repeatedly failing dependencies
+
+	CollectAssertions bool `yaml:"collect_assertions,omitempty"` // Keep going after failed assertions and report them all at the end
+	NoLog             bool `yaml:"no_log,omitempty"`             // Suppress logging for every step that does not set its own no_log
+	MaskOutput        bool `yaml:"mask_output,omitempty"`        // Mask the values of sensitive variables wherever they appear in output and reports
+
+	Inputs  []string `yaml:"inputs,omitempty"`  // Variables a call step must pass with with:
+	Outputs []string `yaml:"outputs,omitempty"` // Variables returned to a call step as its result
+
+	Defaults map[string]StepDefaults `yaml:"defaults,omitempty"` // Options merged into steps by action name; "all" applies to every action
+}
+
+// StepDefaults holds the default options for the steps of one action.
+// A step's own options win over its defaults.
+type StepDefaults struct {
+	Options map[string]any `yaml:"options,omitempty"`
+}
+
+// CircuitBreakerConfig defines when steps against a failing dependency start failing fast
+type CircuitBreakerConfig struct {
+	Threshold int    `yaml:"threshold,omitempty"` // Consecutive network/database errors before opening (default: 3)
+	Cooldown  string `yaml:"cooldown,omitempty"`  // Time before a probe step is allowed through (default: "30s")
 }
 
 type TestVariables struct {
-	Vars map[string]any `yaml:"vars,omitempty"`
+	Vars  map[string]any          `yaml:"vars,omitempty"`
+	Types map[string]VariableType `yaml:"types,omitempty"` // Declared types, checked on load and when a result: assigns the variable
 }
@@ -9,17 +9,79 @@ type TestResult struct {
 	SetupSteps   []StepResult  `json:"setup_steps,omitempty"`
 	Steps        []StepResult  `json:"steps"`
 	TeardownSteps []StepResult `json:"teardown_steps,omitempty"`
+	TeardownStatus string      `json:"teardown_status,omitempty"` // PASS or the worst teardown step status; does not change Status
 	ErrorInfo    *ErrorInfo    `json:"error_info,omitempty"`
+	SkipInfo     *SkipInfo     `json:"skip_info,omitempty"`
+	CircuitEvents []string     `json:"circuit_events,omitempty"`
+	ActionMetrics []ActionStats `json:"action_metrics,omitempty"`
+	Annotations  map[string]any `json:"annotations,omitempty"` // recorded by summary steps, e.g. orders_created: 42
+	Artifacts    []Artifact     `json:"artifacts,omitempty"`   // attached to the test case itself, e.g. its debug dump
+	Timings      []ActionTiming `json:"-"` // every action execution, for run-wide timing reports
 }
 
 type StepResult struct {
 	Name        string        `json:"name"`
+	ID          string        `json:"id,omitempty"`
 	Action      string        `json:"action"`
+	Source      string        `json:"source,omitempty"`     // File that declares the step
+	StartedAt   time.Time     `json:"started_at,omitzero"`  // When the step started; with Duration, when it ended
 	Duration    time.Duration `json:"duration"`
 	Result      ActionResult  `json:"result"`
+	StatusCode  int           `json:"status_code,omitempty"` // HTTP status of http steps, kept when extract replaces the data
+	Steps       []StepResult  `json:"steps,omitempty"`       // Results of a called test case's setup, steps and teardown
+	FinallySteps []StepResult `json:"finally_steps,omitempty"` // Results of a block's finally steps, each with its own status
+	DataSize      int    `json:"data_size,omitempty"`      // Printed size of result data that was truncated or discarded
+	DataHash      string `json:"data_hash,omitempty"`      // SHA-256 of that printed data
+	DataTruncated bool   `json:"data_truncated,omitempty"` // Data holds a truncated preview, see max_data_bytes
+	DataDiscarded bool   `json:"data_discarded,omitempty"` // Data was dropped by discard_data
+	Artifacts     []Artifact `json:"artifacts,omitempty"`  // Files attached by the action or the step's attach
+	Cached        bool       `json:"cached,omitempty"`     // The action did not run; its result came from the step's cache
+	ControlFlow   *ControlFlow `json:"control_flow,omitempty"` // How the step's if, repeat or eventually settings ran it
 	IncludeSummary bool       `json:"include_summary"` // Whether to include this step in summary table
 }
 
+// ControlFlow records the decisions the control-flow settings of a step made, so reports
+// can show which path a test took and why, not only that a step ran or was skipped
+type ControlFlow struct {
+	Condition      string            `json:"condition,omitempty"`       // the step's if condition
+	ConditionValue *bool             `json:"condition_value,omitempty"` // what the if condition evaluated to
+	Branch         string            `json:"branch,omitempty"`          // "then" when the step ran, "skipped" when it did not
+	Values         map[string]string `json:"values,omitempty"`          // values of the variables in the condition
+	Iterations     int               `json:"iterations,omitempty"`      // repeat iterations or eventually attempts run
+	StopReason     string            `json:"stop_reason,omitempty"`     // why a repeat stopped before its count, or how eventually ended
+}
+
+// Branches of an if condition, see ControlFlow
+const (
+	BranchThen    = "then"
+	BranchSkipped = "skipped"
+)
+
+// ActionStats summarizes every execution of one action type during a run
+type ActionStats struct {
+	Action          string        `json:"action"`
+	Count           int           `json:"count"`
+	Failures        int           `json:"failures"`
+	FailureRate     float64       `json:"failure_rate"`
+	TotalDuration   time.Duration `json:"total_duration"`
+	AverageDuration time.Duration `json:"average_duration"`
+	P50Duration     time.Duration `json:"p50_duration"`
+	P95Duration     time.Duration `json:"p95_duration"`
+	MaxDuration     time.Duration `json:"max_duration"`
+	MaxStep         string        `json:"max_step"`      // step of the slowest execution
+	MaxTestCase     string        `json:"max_test_case"` // test case of the slowest execution
+}
+
+// ActionTiming is one execution of an action by a step
+type ActionTiming struct {
+	TestCase string        `json:"test_case"`
+	Step     string        `json:"step"`
+	Action   string        `json:"action"`
+	Start    time.Time     `json:"start"`
+	Duration time.Duration `json:"duration"`
+	Status   string        `json:"status"`
+}
+
 // GetMessage returns the error message from ErrorInfo
 func (tr *TestResult) GetMessage() string {
 	if tr.ErrorInfo != nil {
@@ -0,0 +1,197 @@
+package types
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/JianLoong/robogo/internal/common"
+)
+
+// Types a variable can be declared with in variables.types
+const (
+	VariableTypeString = "string"
+	VariableTypeInt    = "int"
+	VariableTypeNumber = "number"
+	VariableTypeBool   = "bool"
+	VariableTypeEnum   = "enum"
+	VariableTypeList   = "list"
+	VariableTypeObject = "object"
+)
+
+// maxShownValueLength keeps long values out of type errors
+const maxShownValueLength = 60
+
+// VariableType declares the type of a variable and the values it may take. Min and Max
+// bound numbers and the length of strings and lists; Values lists the values of an enum.
+type VariableType struct {
+	Type   string   `yaml:"type"`
+	Min    *float64 `yaml:"min,omitempty"`
+	Max    *float64 `yaml:"max,omitempty"`
+	Values []any    `yaml:"values,omitempty"`
+}
+
+// Validate checks the declaration itself
+func (t VariableType) Validate() error {
+	switch t.Type {
+	case VariableTypeString, VariableTypeInt, VariableTypeNumber, VariableTypeList:
+	case VariableTypeEnum:
+		if len(t.Values) == 0 {
+			return fmt.Errorf("enum needs values")
+		}
+	case VariableTypeBool, VariableTypeObject:
+	case "":
+		return fmt.Errorf("type is required")
+	default:
+		return fmt.Errorf("unknown type '%s' (string, int, number, bool, enum, list or object)", t.Type)
+	}
+	if (t.Min != nil || t.Max != nil) && (t.Type == VariableTypeEnum || t.Type == VariableTypeBool || t.Type == VariableTypeObject) {
+		return fmt.Errorf("min and max do not apply to %s", t.Type)
+	}
+	if len(t.Values) > 0 && t.Type != VariableTypeEnum {
+		return fmt.Errorf("values only apply to enum")
+	}
+	if t.Min != nil && t.Max != nil && *t.Min > *t.Max {
+		return fmt.Errorf("min %v is above max %v", *t.Min, *t.Max)
+	}
+	return nil
+}
+
+// String describes the declared type, e.g. int or enum
+func (t VariableType) String() string {
+	return t.Type
+}
+
+// Check checks a value against the declaration without converting it, as for values
+// written in the test file. The error names the variable and shows the offending value,
+// masked for sensitive names.
+func (t VariableType) Check(name string, value any) error {
+	if err := t.check(value); err != nil {
+		return fmt.Errorf("variable '%s' value %s: %v", name, shownValue(name, value), err)
+	}
+	return nil
+}
+
+// Convert checks a value assigned at run time, first converting strings to int, number
+// or bool as declared, since substitution such as ${ENV:PORT} always produces strings.
+// It implements common.VariableDeclaration.
+func (t VariableType) Convert(name string, value any) (any, error) {
+	if str, ok := value.(string); ok {
+		trimmed := strings.TrimSpace(str)
+		switch t.Type {
+		case VariableTypeInt:
+			if n, err := strconv.ParseInt(trimmed, 10, 64); err == nil {
+				value = int(n)
+			}
+		case VariableTypeNumber:
+			if n, err := strconv.ParseFloat(trimmed, 64); err == nil {
+				value = n
+			}
+		case VariableTypeBool:
+			if b, err := strconv.ParseBool(trimmed); err == nil {
+				value = b
+			}
+		}
+	}
+	return value, t.Check(name, value)
+}
+
+// check returns what is wrong with a value, or nil
+func (t VariableType) check(value any) error {
+	actual := common.ValueTypeName(value)
+	if value == nil {
+		return fmt.Errorf("expected %s, got null", t.Type)
+	}
+
+	var size float64
+	switch t.Type {
+	case VariableTypeString:
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("expected string, got %s", actual)
+		}
+		size = float64(len(str))
+	case VariableTypeInt:
+		n, ok := numberValue(value)
+		if !ok || n != math.Trunc(n) {
+			return fmt.Errorf("expected int, got %s", actual)
+		}
+		size = n
+	case VariableTypeNumber:
+		n, ok := numberValue(value)
+		if !ok {
+			return fmt.Errorf("expected number, got %s", actual)
+		}
+		size = n
+	case VariableTypeBool:
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("expected bool, got %s", actual)
+		}
+		return nil
+	case VariableTypeEnum:
+		for _, allowed := range t.Values {
+			if fmt.Sprintf("%v", allowed) == fmt.Sprintf("%v", value) {
+				return nil
+			}
+		}
+		allowed := make([]string, len(t.Values))
+		for i, v := range t.Values {
+			allowed[i] = fmt.Sprintf("%v", v)
+		}
+		return fmt.Errorf("expected one of %s", strings.Join(allowed, ", "))
+	case VariableTypeList:
+		if actual != VariableTypeList {
+			return fmt.Errorf("expected list, got %s", actual)
+		}
+		size = float64(reflect.ValueOf(value).Len())
+	case VariableTypeObject:
+		if actual != VariableTypeObject {
+			return fmt.Errorf("expected object, got %s", actual)
+		}
+		return nil
+	}
+
+	measure := ""
+	if t.Type == VariableTypeString || t.Type == VariableTypeList {
+		measure = fmt.Sprintf("length %v is ", size)
+	}
+	if t.Min != nil && size < *t.Min {
+		return fmt.Errorf("%sbelow the minimum %v", measure, *t.Min)
+	}
+	if t.Max != nil && size > *t.Max {
+		return fmt.Errorf("%sabove the maximum %v", measure, *t.Max)
+	}
+	return nil
+}
+
+// numberValue returns a numeric value as a float64
+func numberValue(value any) (float64, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+// shownValue formats a value for a type error: quoted strings, shortened, and *** for
+// sensitive variables
+func shownValue(name string, value any) string {
+	if common.IsSensitiveKey(name, common.SensitiveKeys()) {
+		return "***"
+	}
+	shown := fmt.Sprintf("%v", value)
+	if str, ok := value.(string); ok {
+		shown = strconv.Quote(str)
+	}
+	if len(shown) > maxShownValueLength {
+		shown = shown[:maxShownValueLength] + "..."
+	}
+	return shown
+}
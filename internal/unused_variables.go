@@ -0,0 +1,231 @@
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/JianLoong/robogo/internal/types"
+	"gopkg.in/yaml.v3"
+)
+
+// ignoreUnusedMarker suppresses unused warnings for a variable or result when placed in a
+// comment on its line or directly above it
+const ignoreUnusedMarker = "robogo:ignore unused"
+
+// variableReference matches ${name}, ${name.field}, ${name[id=5]} and ${ENV:NAME}; the first group is the root name
+var variableReference = regexp.MustCompile(`\$\{([^}.\[]+)[^}]*\}`)
+
+// variableDefinition is a variable or result waiting to be read
+type variableDefinition struct {
+	where string
+	read  bool
+}
+
+// unusedVariableChecker walks steps in execution order, tracking definitions and reads
+type unusedVariableChecker struct {
+	ignored   map[string]bool
+	results   map[string]*variableDefinition
+	resultSeq []string // result names in the order they were first defined
+	read      map[string]bool
+	warnings  []string
+}
+
+// UnusedVariableWarnings reports variables from the variables section and result captures
+// that are never read, and results that are overwritten before being read.
+// Names marked with a "# robogo:ignore unused" comment in the file are skipped.
+func UnusedVariableWarnings(filename string, testCase *types.TestCase) []string {
+	ignored := map[string]bool{}
+	if data, err := readTestFile(filename); err == nil {
+		ignored = ignoredUnusedNames(data)
+	}
+
+	checker := &unusedVariableChecker{
+		ignored: ignored,
+		results: make(map[string]*variableDefinition),
+		read:    make(map[string]bool),
+	}
+
+	// Variables may be defined in terms of each other
+	for _, value := range testCase.Variables.Vars {
+		checker.markReferences(value)
+	}
+	checker.walk(testCase.Setup, "setup ", false)
+	checker.walk(testCase.Steps, "", false)
+	checker.walk(testCase.Teardown, "teardown ", false)
+
+	// Outputs are read by the calling test case
+	for _, name := range testCase.Outputs {
+		checker.markReferences("${" + name + "}")
+	}
+
+	var warnings []string
+	for _, name := range sortedKeys(testCase.Variables.Vars) {
+		if !checker.read[name] && !checker.ignored[name] && checker.results[name] == nil {
+			warnings = append(warnings, fmt.Sprintf("variable '%s' is never used", name))
+		}
+	}
+	for _, name := range checker.resultSeq {
+		if definition := checker.results[name]; !definition.read && !checker.ignored[name] {
+			warnings = append(warnings, fmt.Sprintf("result '%s' from %s is never used", name, definition.where))
+		}
+	}
+	return append(warnings, checker.warnings...)
+}
+
+// walk processes steps in order. Conditional steps may not run, so results they
+// define never count as overwriting an earlier one.
+func (c *unusedVariableChecker) walk(steps []types.Step, stepPath string, conditional bool) {
+	for i, step := range steps {
+		currentPath := fmt.Sprintf("%sstep %d", stepPath, i+1)
+		stepConditional := conditional || step.If != ""
+
+		// Arguments and conditions are read before the result is stored
+		c.markReferences(stepFields(step))
+		c.walk(step.Steps, currentPath+" -> ", stepConditional)
+		c.walk(step.Finally, currentPath+" -> finally ", stepConditional)
+
+		if step.Result != "" {
+			c.define(step.Result, currentPath, stepConditional)
+		}
+
+		// Repeated steps read values stored by earlier iterations, including their own result
+		if step.For != "" || step.While != "" || step.Repeat > 0 || step.Retry != nil || step.Eventually != nil {
+			c.markReferences(step)
+		}
+	}
+}
+
+// define records a result capture, warning when an unread value from an earlier step is replaced
+func (c *unusedVariableChecker) define(name, where string, conditional bool) {
+	previous, exists := c.results[name]
+	if !exists {
+		c.resultSeq = append(c.resultSeq, name)
+	} else if !previous.read && !conditional && !c.ignored[name] {
+		c.warnings = append(c.warnings, fmt.Sprintf("result '%s' from %s is overwritten by %s before being read", name, previous.where, where))
+	}
+	if exists && conditional {
+		// The earlier value is still the one read if this step is skipped
+		return
+	}
+	c.results[name] = &variableDefinition{where: where}
+}
+
+// markReferences marks every variable referenced in a value as read
+func (c *unusedVariableChecker) markReferences(value any) {
+	for _, name := range variableReferences(value) {
+		c.read[name] = true
+		if definition, exists := c.results[name]; exists {
+			definition.read = true
+		}
+	}
+}
+
+// variableReferences returns the root names of the variables referenced anywhere in a
+// value, in order of appearance. ${ENV:...} and functions such as ${unique()} are not
+// variables.
+func variableReferences(value any) []string {
+	var names []string
+	for _, text := range collectStrings(reflect.ValueOf(value), nil) {
+		for _, match := range variableReference.FindAllStringSubmatch(text, -1) {
+			name := match[1]
+			if strings.HasPrefix(name, "ENV:") || strings.Contains(name, "(") {
+				continue
+			}
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// stepFields returns the step without its nested and finally steps, which are walked separately
+func stepFields(step types.Step) types.Step {
+	step.Steps = nil
+	step.Finally = nil
+	return step
+}
+
+// collectStrings gathers every string inside a value, including struct fields, maps and slices
+func collectStrings(value reflect.Value, texts []string) []string {
+	switch value.Kind() {
+	case reflect.String:
+		texts = append(texts, value.String())
+	case reflect.Interface, reflect.Ptr:
+		if !value.IsNil() {
+			texts = collectStrings(value.Elem(), texts)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			texts = collectStrings(value.Index(i), texts)
+		}
+	case reflect.Map:
+		iter := value.MapRange()
+		for iter.Next() {
+			texts = collectStrings(iter.Key(), texts)
+			texts = collectStrings(iter.Value(), texts)
+		}
+	case reflect.Struct:
+		for i := 0; i < value.NumField(); i++ {
+			if value.Type().Field(i).IsExported() {
+				texts = collectStrings(value.Field(i), texts)
+			}
+		}
+	}
+	return texts
+}
+
+// ignoredUnusedNames finds variables and results marked with the ignore comment,
+// either on the variable key, on a result: line, or above the step that stores the result.
+// Every document of a multi-document file is searched.
+func ignoredUnusedNames(data []byte) map[string]bool {
+	ignored := map[string]bool{}
+
+	var visit func(node *yaml.Node)
+	visit = func(node *yaml.Node) {
+		switch node.Kind {
+		case yaml.DocumentNode, yaml.SequenceNode:
+			for _, child := range node.Content {
+				visit(child)
+			}
+		case yaml.MappingNode:
+			// A comment above a step in a list is attached to its first key
+			stepComment := node.HeadComment
+			if len(node.Content) > 0 {
+				stepComment += node.Content[0].HeadComment
+			}
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				key, value := node.Content[i], node.Content[i+1]
+				comments := key.HeadComment + key.LineComment + value.LineComment
+				if value.Kind == yaml.ScalarNode && strings.Contains(comments, ignoreUnusedMarker) {
+					ignored[key.Value] = true
+				}
+				if key.Value == "result" && value.Kind == yaml.ScalarNode && strings.Contains(comments+stepComment, ignoreUnusedMarker) {
+					ignored[value.Value] = true
+				}
+				visit(value)
+			}
+		}
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var document yaml.Node
+		if err := decoder.Decode(&document); err != nil {
+			break
+		}
+		visit(&document)
+	}
+	return ignored
+}
+
+// sortedKeys returns map keys in a stable order for reporting
+func sortedKeys(values map[string]any) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
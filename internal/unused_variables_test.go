@@ -0,0 +1,171 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/JianLoong/robogo/internal/types"
+)
+
+// referenceCorpus lists tricky templates with the variables they depend on
+var referenceCorpus = []struct {
+	name     string
+	template any
+	want     []string
+}{
+	{"plain", "${a}", []string{"a"}},
+	{"dot path", "${user.name}", []string{"user"}},
+	{"deep dot path", "${response.body.items.0.id}", []string{"response"}},
+	{"filter", "${items[id=5].name}", []string{"items"}},
+	{"index", "${rows[0]}", []string{"rows"}},
+	{"in text", "Bearer ${token} for ${user.id}", []string{"token", "user"}},
+	{"adjacent", "${a}${b}", []string{"a", "b"}},
+	{"repeated", "${a}-${a}", []string{"a"}},
+	{"environment", "${ENV:HOME}/${dir}", []string{"dir"}},
+	{"unique function", "order-${unique()}-${unique(order)}", nil},
+	{"step variable", "${step.idempotency_key}", []string{"step"}},
+	{"no braces", "$a {b} $$ ${", nil},
+	{"unterminated", "${a", nil},
+	{"empty", "${}", nil},
+	{"json body", `{"name": "${name}", "tags": ["${tag}"]}`, []string{"name", "tag"}},
+	{"condition", "${status} == 200 && (${retries} < 3 || !${done})", []string{"done", "retries", "status"}},
+	{"quoted condition", "'${name}' == 'O''Brien' || ${op} contains '||'", []string{"name", "op"}},
+	{"jq path", `.items[] | select(.id == "${id}") | .name`, []string{"id"}},
+	{"nested options", map[string]any{
+		"headers": map[string]any{"Authorization": "Bearer ${token}"},
+		"query":   []any{"page=${page}", 3, true},
+	}, []string{"page", "token"}},
+	{"option keys", map[string]any{"${header_name}": "x"}, []string{"header_name"}},
+	{"non-strings", []any{1, 2.5, false, nil}, nil},
+}
+
+func TestVariableReferencesCorpus(t *testing.T) {
+	for _, tc := range referenceCorpus {
+		t.Run(tc.name, func(t *testing.T) {
+			got := uniqueSorted(variableReferences(tc.template))
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("variableReferences(%v) = %v, want %v", tc.template, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestVariableReferencesInStepFields(t *testing.T) {
+	// Every field of a step that is substituted is scanned, not only its arguments
+	step := types.Step{
+		Name:    "fetch ${title}",
+		Action:  "http",
+		Args:    []any{"GET", "${base_url}/users"},
+		Options: map[string]any{"headers": map[string]any{"X-Trace": "${trace}"}},
+		Extract: &types.ExtractConfig{Type: "jq", Path: ".body.${field}"},
+		If:      "${enabled} == true",
+		Retry:   &types.RetryConfig{Attempts: 2, RetryIf: "${code} >= 500"},
+		Env:     map[string]string{"TOKEN": "${api_token}"},
+	}
+	want := []string{"api_token", "base_url", "code", "enabled", "field", "title", "trace"}
+	if got := uniqueSorted(variableReferences(step)); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestUnusedVariableWarnings(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want []string
+	}{
+		{
+			name: "read in a nested option",
+			yaml: `testcase: t
+variables:
+  vars:
+    token: x
+steps:
+  - name: s
+    action: http
+    args: ["GET", "http://localhost"]
+    options:
+      headers: {Authorization: "Bearer ${token}"}
+`,
+		},
+		{
+			name: "only read by a function of the same name",
+			yaml: `testcase: t
+variables:
+  vars:
+    unique: x
+steps:
+  - name: s
+    action: log
+    args: ["${unique()}"]
+`,
+			want: []string{"variable 'unique' is never used"},
+		},
+		{
+			name: "result read by a later condition",
+			yaml: `testcase: t
+steps:
+  - name: a
+    action: variable
+    args: ["x", "1"]
+    result: first
+  - name: b
+    if: "!${first}"
+    action: log
+    args: ["b"]
+`,
+		},
+		{
+			name: "result overwritten before being read",
+			yaml: `testcase: t
+steps:
+  - name: a
+    action: log
+    args: ["a"]
+    result: r
+  - name: b
+    action: log
+    args: ["${r"]
+    result: r
+  - name: c
+    action: log
+    args: ["${r}"]
+`,
+			want: []string{"result 'r' from step 1 is overwritten by step 2 before being read"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "test.yaml")
+			if err := os.WriteFile(filename, []byte(tc.yaml), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			testCases, err := LoadTestCases(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := UnusedVariableWarnings(filename, testCases[0])
+			if strings.Join(got, "\n") != strings.Join(tc.want, "\n") {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+// uniqueSorted returns the distinct names in order, or nil when there are none
+func uniqueSorted(names []string) []string {
+	seen := map[string]bool{}
+	var distinct []string
+	for _, name := range names {
+		if !seen[name] {
+			seen[name] = true
+			distinct = append(distinct, name)
+		}
+	}
+	sort.Strings(distinct)
+	return distinct
+}
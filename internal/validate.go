@@ -0,0 +1,276 @@
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/JianLoong/robogo/internal/actions"
+	"github.com/JianLoong/robogo/internal/types"
+)
+
+// ValidationReport is the result of the validate command; --format json prints it as is.
+// Suggestions never make a file invalid; Fixes lists what --fix changed, or would change
+// with --dry-run.
+type ValidationReport struct {
+	Target      string               `json:"target"`
+	Valid       bool                 `json:"valid"`
+	Errors      []ValidationIssue    `json:"errors"`
+	Warnings    []ValidationIssue    `json:"warnings"`
+	Suggestions []ValidationIssue    `json:"suggestions"`
+	Fixes       []ValidationFix      `json:"fixes,omitempty"`
+	DryRun      bool                 `json:"dry_run,omitempty"`
+	Statistics  ValidationStatistics `json:"statistics"`
+}
+
+// ValidationIssue is one problem found in a test file. Line and Column are 1-based and
+// omitted when the position is unknown. AutoFix marks suggestions --fix can apply, when
+// their Confidence is at least --fix-confidence.
+type ValidationIssue struct {
+	File       string  `json:"file"`
+	Line       int     `json:"line,omitempty"`
+	Column     int     `json:"column,omitempty"`
+	TestCase   string  `json:"testcase,omitempty"`
+	Message    string  `json:"message"`
+	Suggestion string  `json:"suggestion,omitempty"`
+	AutoFix    bool    `json:"auto_fix,omitempty"`
+	Confidence float64 `json:"confidence,omitempty"`
+
+	fix *issueFix
+}
+
+// ValidationStatistics counts what was validated
+type ValidationStatistics struct {
+	Files       int `json:"files"`
+	TestCases   int `json:"test_cases"`
+	Steps       int `json:"steps"`
+	Errors      int `json:"errors"`
+	Warnings    int `json:"warnings"`
+	Suggestions int `json:"suggestions"`
+	Fixes       int `json:"fixes"`
+}
+
+// runValidate checks the test files of a target without running them: parse errors and
+// unknown actions are errors; duplicate names and unused variables are warnings, which
+// fail the command too with --strict. With --fix, auto-fixable suggestions are applied.
+func runValidate(target string, args ParsedArgs) {
+	if args.format != "text" && args.format != "json" {
+		fmt.Printf("Error: --format must be text or json, got '%s'\n", args.format)
+		os.Exit(ExitUsageError)
+	}
+	files, err := DiscoverTestFiles(target, args.pattern)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(ExitUsageError)
+	}
+
+	// --dry-run on its own previews the fixes
+	args.fix = args.fix || args.dryRun
+
+	report := validateTestFiles(target, files, newActionRegistry(args))
+	if args.fix {
+		report.Fixes = applyFixes(report, args.fixConfidence, args.dryRun)
+		report.DryRun = args.dryRun
+		report.Statistics.Fixes = len(report.Fixes)
+		report.Statistics.Errors = len(report.Errors)
+	}
+	report.Valid = len(report.Errors) == 0 && (!args.strict || len(report.Warnings) == 0)
+
+	if args.format == "json" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			fmt.Printf("Error: failed to marshal validation report: %v\n", err)
+			os.Exit(ExitUsageError)
+		}
+		fmt.Println(string(data))
+	} else {
+		printValidationReport(report, args)
+	}
+
+	if !report.Valid {
+		os.Exit(ExitTestFailure)
+	}
+}
+
+// validateTestFiles builds the validation report for test files
+func validateTestFiles(target string, files []string, registry *actions.ActionRegistry) *ValidationReport {
+	report := &ValidationReport{
+		Target:      target,
+		Errors:      []ValidationIssue{},
+		Warnings:    []ValidationIssue{},
+		Suggestions: []ValidationIssue{},
+	}
+	var planned []plannedTestCase
+	for _, filename := range files {
+		report.Statistics.Files++
+		testCases, err := LoadTestCases(filename)
+		if err != nil {
+			report.Errors = append(report.Errors, parseErrorIssue(filename, err))
+			continue
+		}
+		for document, testCase := range testCases {
+			planned = append(planned, plannedTestCase{filename: filename, testCase: testCase})
+			report.Statistics.TestCases++
+			report.Statistics.Steps += countSteps(testCase.Setup) + countSteps(testCase.Steps) + countSteps(testCase.Teardown)
+
+			issue := func(message, suggestion string) ValidationIssue {
+				return ValidationIssue{File: filename, TestCase: testCase.Name, Message: message, Suggestion: suggestion}
+			}
+			for _, unknown := range unknownActions(testCase, registry) {
+				suggestion := "Run 'robogo actions list' to see the available actions; plugin actions need --plugins"
+				if completions := registry.GetActionCompletions(unknown.action); len(completions) > 0 {
+					suggestion = "Did you mean: " + strings.Join(completions, ", ")
+				}
+				report.Errors = append(report.Errors, issue(fmt.Sprintf("%s: unknown action '%s'", unknown.path, unknown.action), suggestion))
+			}
+			for _, duplicate := range DuplicateStepNames(testCase) {
+				report.Warnings = append(report.Warnings, issue(duplicate, "Add an id to tell the steps apart"))
+			}
+			for _, warning := range UnusedVariableWarnings(filename, testCase) {
+				report.Warnings = append(report.Warnings, issue(warning, fmt.Sprintf("Mark with '# %s' if intended", ignoreUnusedMarker)))
+			}
+			report.Suggestions = append(report.Suggestions, stepSuggestions(filename, document, testCase, registry)...)
+		}
+	}
+	for _, duplicate := range duplicateTestCaseNames(planned) {
+		report.Warnings = append(report.Warnings, ValidationIssue{
+			File:       target,
+			Message:    duplicate,
+			Suggestion: "Rename the test cases so results and reports can tell them apart",
+		})
+	}
+
+	report.Statistics.Errors = len(report.Errors)
+	report.Statistics.Warnings = len(report.Warnings)
+	report.Statistics.Suggestions = len(report.Suggestions)
+	return report
+}
+
+// parseErrorIssue turns a load error into an issue, keeping its position when known
+func parseErrorIssue(filename string, err error) ValidationIssue {
+	var parseErr *ParseError
+	if errors.As(err, &parseErr) {
+		file := parseErr.File
+		if file == "" {
+			file = filename
+		}
+		return ValidationIssue{File: file, Line: parseErr.Line, Column: parseErr.Column, Message: parseErr.Message}
+	}
+	return ValidationIssue{File: filename, Message: err.Error()}
+}
+
+// unknownAction is a step whose action is not registered
+type unknownAction struct {
+	path   string
+	action string
+}
+
+// unknownActions returns the steps of a test case, nested and included ones too, whose
+// action is not registered
+func unknownActions(testCase *types.TestCase, registry *actions.ActionRegistry) []unknownAction {
+	var unknown []unknownAction
+	var walk func(steps []types.Step, stepPath string)
+	walk = func(steps []types.Step, stepPath string) {
+		for i, step := range steps {
+			currentPath := fmt.Sprintf("%sstep %d", stepPath, i+1)
+			if step.Action != "" {
+				if _, exists := registry.Get(step.Action); !exists {
+					unknown = append(unknown, unknownAction{path: currentPath, action: step.Action})
+				}
+			}
+			walk(step.Steps, currentPath+" -> ")
+			walk(step.Finally, currentPath+" -> finally ")
+		}
+	}
+	walk(testCase.Setup, "setup ")
+	walk(testCase.Steps, "")
+	walk(testCase.Teardown, "teardown ")
+	return unknown
+}
+
+// countSteps counts steps including nested ones
+func countSteps(steps []types.Step) int {
+	count := len(steps)
+	for _, step := range steps {
+		count += countSteps(step.Steps) + countSteps(step.Finally)
+	}
+	return count
+}
+
+// printValidationReport prints the report as file:line:column lines and a summary.
+// Suggestions are listed with --fix, as the fixes made and the hints left to the author.
+func printValidationReport(report *ValidationReport, args ParsedArgs) {
+	printIssues := func(level string, issues []ValidationIssue) {
+		for _, issue := range issues {
+			location := issue.File
+			if issue.Line > 0 {
+				location += fmt.Sprintf(":%d", issue.Line)
+				if issue.Column > 0 {
+					location += fmt.Sprintf(":%d", issue.Column)
+				}
+			}
+			if issue.TestCase != "" {
+				location += fmt.Sprintf(" (%s)", issue.TestCase)
+			}
+			fmt.Printf("%s %s: %s\n", level, location, issue.Message)
+			if issue.Suggestion != "" {
+				fmt.Printf("        %s\n", issue.Suggestion)
+			}
+		}
+	}
+	printIssues("[ERROR]", report.Errors)
+	printIssues("[WARN] ", report.Warnings)
+	if args.fix {
+		var hints []ValidationIssue
+		for _, suggestion := range report.Suggestions {
+			if !suggestion.AutoFix || suggestion.Confidence < args.fixConfidence {
+				hints = append(hints, suggestion)
+			}
+		}
+		printIssues("[HINT] ", hints)
+		label := "[FIXED]"
+		if report.DryRun {
+			label = "[WOULD FIX]"
+		}
+		for _, fix := range report.Fixes {
+			fmt.Printf("%s %s (%s): %s\n", label, fix.File, fix.TestCase, fix.Change)
+		}
+	}
+
+	stats := report.Statistics
+	fmt.Printf("\nValidated %d file(s), %d test case(s), %d step(s): %d error(s), %d warning(s), %d suggestion(s)\n",
+		stats.Files, stats.TestCases, stats.Steps, stats.Errors, stats.Warnings, stats.Suggestions)
+	if args.fix {
+		files := make(map[string]bool)
+		for _, fix := range report.Fixes {
+			files[fix.File] = true
+		}
+		if report.DryRun {
+			fmt.Printf("Dry run: %d fix(es) would be applied to %d file(s)\n", stats.Fixes, len(files))
+		} else {
+			fmt.Printf("Applied %d fix(es) to %d file(s)\n", stats.Fixes, len(files))
+		}
+	} else if autoFixable := countAutoFixable(report.Suggestions, args.fixConfidence); autoFixable > 0 {
+		fmt.Printf("%d suggestion(s) can be applied with --fix (preview with --fix --dry-run)\n", autoFixable)
+	}
+	if report.Valid {
+		fmt.Println("✓ valid")
+	} else if len(report.Errors) == 0 && args.strict {
+		fmt.Println("✗ warnings are errors with --strict")
+	} else {
+		fmt.Println("✗ invalid")
+	}
+}
+
+// countAutoFixable counts the suggestions --fix would apply at a confidence threshold
+func countAutoFixable(suggestions []ValidationIssue, minConfidence float64) int {
+	count := 0
+	for _, suggestion := range suggestions {
+		if suggestion.AutoFix && suggestion.Confidence >= minConfidence {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,316 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/JianLoong/robogo/internal/actions"
+	"github.com/JianLoong/robogo/internal/common"
+	"github.com/JianLoong/robogo/internal/types"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultFixConfidence is the lowest suggestion confidence validate --fix applies
+const defaultFixConfidence = 0.8
+
+// Confidence of each suggestion that --fix can apply: how sure validate is that the change
+// is what the author wants
+const (
+	httpTimeoutConfidence    = 0.9
+	sensitiveFieldConfidence = 0.9
+	noLogConfidence          = 0.6 // hides all of the step's output, so only applied on request
+)
+
+// suggestedHTTPTimeout is the timeout --fix sets on http steps, the http action's default
+const suggestedHTTPTimeout = "30s"
+
+// envReference matches ${ENV:NAME} references; the group is the variable name
+var envReference = regexp.MustCompile(`\$\{ENV:([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// stepRef is one level of the path to a step: the key of its list and its index there
+type stepRef struct {
+	key   string
+	index int
+}
+
+// issueFix is the change that resolves a suggestion. apply edits the step's mapping node
+// and returns false when the step already has the change.
+type issueFix struct {
+	document int // test case's document in the file, counting from 0
+	path     []stepRef
+	apply    func(step *yaml.Node) bool
+}
+
+// ValidationFix is a change validate --fix made, or would make with --dry-run
+type ValidationFix struct {
+	File     string `json:"file"`
+	TestCase string `json:"testcase,omitempty"`
+	Change   string `json:"change"`
+}
+
+// stepSuggestions returns the suggestions for the steps of a test case: http steps without a
+// timeout, and secrets from the environment that would be printed unmasked. Steps from
+// included files are left to the files that define them.
+func stepSuggestions(filename string, document int, testCase *types.TestCase, registry *actions.ActionRegistry) []ValidationIssue {
+	fixable := !strings.EqualFold(filepath.Ext(filename), ".json")
+	var suggestions []ValidationIssue
+	suggest := func(path []stepRef, stepPath, message, suggestion string, confidence float64, apply func(*yaml.Node) bool) {
+		issue := ValidationIssue{
+			File:       filename,
+			TestCase:   testCase.Name,
+			Message:    stepPath + ": " + message,
+			Suggestion: suggestion,
+			AutoFix:    fixable,
+			Confidence: confidence,
+		}
+		if fixable {
+			issue.fix = &issueFix{document: document, path: append([]stepRef{}, path...), apply: apply}
+		}
+		suggestions = append(suggestions, issue)
+	}
+
+	var walk func(steps []types.Step, key string, path []stepRef, stepPath string)
+	walk = func(steps []types.Step, key string, path []stepRef, stepPath string) {
+		for i, step := range steps {
+			currentPath := append(path, stepRef{key: key, index: i})
+			currentStepPath := fmt.Sprintf("%sstep %d", stepPath, i+1)
+			if step.Action != "" {
+				stepSuggestionsFor(step, registry, func(message, suggestion string, confidence float64, apply func(*yaml.Node) bool) {
+					suggest(currentPath, currentStepPath, message, suggestion, confidence, apply)
+				})
+			}
+			if step.Include == "" {
+				walk(step.Steps, "steps", currentPath, currentStepPath+" -> ")
+			}
+			walk(step.Finally, "finally", currentPath, currentStepPath+" -> finally ")
+		}
+	}
+	walk(testCase.Setup, "setup", nil, "setup ")
+	walk(testCase.Steps, "steps", nil, "")
+	walk(testCase.Teardown, "teardown", nil, "teardown ")
+	return suggestions
+}
+
+// stepSuggestionsFor checks one action step
+func stepSuggestionsFor(step types.Step, registry *actions.ActionRegistry, suggest func(message, suggestion string, confidence float64, apply func(*yaml.Node) bool)) {
+	if step.Action == "http" {
+		if _, ok := step.Options["timeout"]; !ok {
+			suggest("http step has no timeout",
+				fmt.Sprintf("Set timeout: %q so a hung server fails the step at a known point", suggestedHTTPTimeout),
+				httpTimeoutConfidence, setStepOption("timeout", suggestedHTTPTimeout))
+		}
+	}
+
+	// Secrets are masked by option name; a secret under another name is printed as is
+	if step.Sensitive || step.LogSuppressed() {
+		return
+	}
+	meta, _ := registry.Describe(step.Action)
+	maskedFields := append([]string{}, step.SensitiveFields...)
+	for _, option := range meta.Options {
+		if option.Sensitive {
+			maskedFields = append(maskedFields, option.Name)
+		}
+	}
+	maskedKeys := common.SensitiveKeys(maskedFields)
+
+	for _, name := range sortedKeys(step.Options) {
+		for _, unmasked := range unmaskedSecrets(name, step.Options[name], maskedKeys) {
+			suggest(fmt.Sprintf("option '%s' holds ${ENV:%s} but is printed unmasked", unmasked.path, unmasked.env),
+				fmt.Sprintf("Add '%s' to sensitive_fields", unmasked.key),
+				sensitiveFieldConfidence, addSensitiveField(unmasked.key))
+		}
+	}
+	for i, arg := range step.Args {
+		if i < len(meta.Args) && meta.Args[i].Sensitive {
+			continue
+		}
+		for _, env := range sensitiveEnvReferences(arg) {
+			suggest(fmt.Sprintf("argument %d holds ${ENV:%s} and is printed unmasked", i+1, env),
+				"Set no_log: true, which also hides the rest of the step's output",
+				noLogConfidence, setStepField("no_log", "true", "!!bool"))
+		}
+	}
+}
+
+// unmaskedSecret is an option value referring to a sensitive environment variable
+type unmaskedSecret struct {
+	path string // option path for messages, e.g. headers.X-Api
+	key  string // innermost key, which sensitive_fields masks
+	env  string
+}
+
+// unmaskedSecrets finds sensitive ${ENV:...} references below option keys that are not masked
+func unmaskedSecrets(path string, value any, maskedKeys []string) []unmaskedSecret {
+	key := path[strings.LastIndex(path, ".")+1:]
+	if common.IsSensitiveKey(key, maskedKeys) {
+		return nil
+	}
+	var secrets []unmaskedSecret
+	switch val := value.(type) {
+	case map[string]any:
+		for _, name := range sortedKeys(val) {
+			secrets = append(secrets, unmaskedSecrets(path+"."+name, val[name], maskedKeys)...)
+		}
+	case []any:
+		for _, item := range val {
+			secrets = append(secrets, unmaskedSecrets(path, item, maskedKeys)...)
+		}
+	default:
+		for _, env := range sensitiveEnvReferences(val) {
+			secrets = append(secrets, unmaskedSecret{path: path, key: key, env: env})
+		}
+	}
+	return secrets
+}
+
+// sensitiveEnvReferences returns the names of sensitive environment variables, such as
+// API_TOKEN, referenced in a value or anywhere inside it
+func sensitiveEnvReferences(value any) []string {
+	var names []string
+	switch val := value.(type) {
+	case string:
+		for _, match := range envReference.FindAllStringSubmatch(val, -1) {
+			if common.IsSensitiveKey(match[1], common.SensitiveKeys()) {
+				names = append(names, match[1])
+			}
+		}
+	case map[string]any:
+		for _, name := range sortedKeys(val) {
+			names = append(names, sensitiveEnvReferences(val[name])...)
+		}
+	case []any:
+		for _, item := range val {
+			names = append(names, sensitiveEnvReferences(item)...)
+		}
+	}
+	return names
+}
+
+// setStepOption returns a fix that sets an option the step does not have
+func setStepOption(name, value string) func(*yaml.Node) bool {
+	return func(step *yaml.Node) bool {
+		options := mappingValue(step, "options")
+		if options == nil {
+			options = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			step.Content = append(step.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "options"}, options)
+		}
+		if options.Kind != yaml.MappingNode || mappingValue(options, name) != nil {
+			return false
+		}
+		options.Content = append(options.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name},
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value, Style: yaml.DoubleQuotedStyle})
+		return true
+	}
+}
+
+// setStepField returns a fix that sets a step field the step does not have
+func setStepField(name, value, tag string) func(*yaml.Node) bool {
+	return func(step *yaml.Node) bool {
+		if mappingValue(step, name) != nil {
+			return false
+		}
+		step.Content = append(step.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name},
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value})
+		return true
+	}
+}
+
+// addSensitiveField returns a fix that adds a key to the step's sensitive_fields
+func addSensitiveField(key string) func(*yaml.Node) bool {
+	return func(step *yaml.Node) bool {
+		fields := mappingValue(step, "sensitive_fields")
+		if fields == nil {
+			fields = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Style: yaml.FlowStyle}
+			step.Content = append(step.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "sensitive_fields"}, fields)
+		}
+		if fields.Kind != yaml.SequenceNode {
+			return false
+		}
+		for _, item := range fields.Content {
+			if item.Value == key {
+				return false
+			}
+		}
+		fields.Content = append(fields.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key})
+		return true
+	}
+}
+
+// findStepNode follows a step path from the root of a test case document
+func findStepNode(root *yaml.Node, path []stepRef) *yaml.Node {
+	node := root
+	for _, ref := range path {
+		items := sequenceItems(mappingValue(node, ref.key))
+		if ref.index >= len(items) {
+			return nil
+		}
+		node = items[ref.index]
+	}
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	return node
+}
+
+// applyFixes applies the auto-fixable suggestions at or above minConfidence, file by file,
+// and writes each changed file in the canonical form of robogo fmt unless dryRun is set.
+// A file whose fixed form does not parse is left unchanged and reported as an error.
+func applyFixes(report *ValidationReport, minConfidence float64, dryRun bool) []ValidationFix {
+	byFile := make(map[string][]ValidationIssue)
+	for _, suggestion := range report.Suggestions {
+		if suggestion.AutoFix && suggestion.fix != nil && suggestion.Confidence >= minConfidence {
+			byFile[suggestion.File] = append(byFile[suggestion.File], suggestion)
+		}
+	}
+	files := make([]string, 0, len(byFile))
+	for file := range byFile {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	fixes := []ValidationFix{}
+	for _, file := range files {
+		data, err := readTestFile(file)
+		if err != nil {
+			report.Errors = append(report.Errors, ValidationIssue{File: file, Message: err.Error()})
+			continue
+		}
+
+		var applied []ValidationFix
+		fixed, err := formatDocuments(data, func(document int, root *yaml.Node) {
+			for _, suggestion := range byFile[file] {
+				if suggestion.fix.document != document {
+					continue
+				}
+				if step := findStepNode(root, suggestion.fix.path); step != nil && suggestion.fix.apply(step) {
+					applied = append(applied, ValidationFix{File: file, TestCase: suggestion.TestCase, Change: suggestion.Message + " -> " + suggestion.Suggestion})
+				}
+			}
+		})
+		if err == nil {
+			_, err = parseTestDocuments(file, fixed)
+		}
+		if err != nil {
+			report.Errors = append(report.Errors, ValidationIssue{File: file, Message: fmt.Sprintf("fixes not applied: %v", err)})
+			continue
+		}
+		if len(applied) == 0 {
+			continue
+		}
+		if !dryRun {
+			if err := os.WriteFile(file, fixed, 0644); err != nil {
+				report.Errors = append(report.Errors, ValidationIssue{File: file, Message: fmt.Sprintf("failed to write fixes: %v", err)})
+				continue
+			}
+		}
+		fixes = append(fixes, applied...)
+	}
+	return fixes
+}
@@ -0,0 +1,122 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/JianLoong/robogo/internal/types"
+)
+
+// scopedCallee is a called test case that sets a variable named like one of its
+// caller's, one of its own, and returns a single output
+const scopedCallee = `testcase: "callee"
+inputs: [customer]
+outputs: [order_id]
+steps:
+  - name: "Overwrite user"
+    action: variable
+    args: ["user", "callee-user"]
+  - name: "Set own variable"
+    action: variable
+    args: ["scratch", "temporary"]
+  - name: "Build order id"
+    action: variable
+    args: ["order_id", "ORD-${customer}"]
+`
+
+func TestCallBlockRestoresOuterVariables(t *testing.T) {
+	results, output := runTestFiles(t, map[string]string{
+		"callee.yaml": scopedCallee,
+		"caller.yaml": `testcase: "caller"
+variables:
+  vars:
+    user: "outer-user"
+    order_id: "outer-order"
+steps:
+  - name: "Create order"
+    call: callee.yaml
+    with:
+      customer: "ada"
+    result: order
+  - name: "Outer user kept"
+    action: assert
+    args: ["${user}", "==", "outer-user"]
+  - name: "Outer order id kept"
+    action: assert
+    args: ["${order_id}", "==", "outer-order"]
+  - name: "Only the output is promoted"
+    action: assert
+    args: ["${order.order_id}", "==", "ORD-ada"]
+  - name: "Callee variable not visible"
+    if: "${scratch} == temporary"
+    action: log
+    args: ["leaked"]
+`,
+	}, "caller.yaml")
+
+	result := results[0]
+	if len(result.Steps) != 5 {
+		t.Fatalf("got %d steps, want 5:\n%s", len(result.Steps), output)
+	}
+	for _, step := range result.Steps[:4] {
+		if !step.Result.IsSuccess() {
+			t.Errorf("%s: %s", step.Name, step.Result.GetMessage())
+		}
+	}
+	// The condition may only use variables of the caller's scope
+	if code := result.Steps[4].Result.ErrorInfo; code == nil || code.Code != "CONDITION_UNRESOLVED_VARIABLE" {
+		t.Errorf("callee variable scratch leaked into the caller: %+v", result.Steps[4].Result)
+	}
+}
+
+func TestStepVariableRemovedAfterEachStep(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.yaml")
+	content := `testcase: "step variable"
+steps:
+  - name: "Key set while the step runs"
+    action: assert
+    args: ["${step.idempotency_key}", "!=", ""]
+  - name: "Group"
+    steps:
+      - name: "Nested key"
+        action: assert
+        args: ["${step.idempotency_key}", "!=", ""]
+  - name: "Repeated"
+    action: log
+    args: ["${step.idempotency_key}"]
+    repeat: 2
+  - name: "Cannot set the step variable"
+    action: variable
+    args: ["step", "mine"]
+    continue: true
+`
+	if err := os.WriteFile(filename, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	testCases, err := LoadTestCases(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runner := NewTestRunner()
+	var result *types.TestResult
+	output := captureStdout(t, func() {
+		result, err = runner.RunTest(context.Background(), filename, testCases[0])
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, step := range result.Steps[:3] {
+		if step.Result.Status != types.ActionStatusPassed {
+			t.Fatalf("%s: status %s:\n%s", step.Name, step.Result.Status, output)
+		}
+	}
+	if info := result.Steps[3].Result.ErrorInfo; info == nil || info.Code != "RESERVED_VARIABLE" {
+		t.Errorf("setting the step variable gave %+v, want RESERVED_VARIABLE", result.Steps[3].Result)
+	}
+	if runner.variables.Has("step") {
+		t.Errorf("step variable left after the run: %v", runner.variables.Get("step"))
+	}
+}
@@ -0,0 +1,367 @@
+package internal
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/JianLoong/robogo/internal/actions"
+	"github.com/JianLoong/robogo/internal/execution"
+	"github.com/JianLoong/robogo/internal/types"
+	"gopkg.in/yaml.v3"
+)
+
+// Watch timing: files are polled, and a run starts once they have stopped changing,
+// so an editor saving several files at once triggers one run
+const (
+	watchPollInterval = 300 * time.Millisecond
+	watchDebounce     = 500 * time.Millisecond
+)
+
+// fileStamp identifies one version of a watched file
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// testWatcher tracks the test files of a target and the files each one depends on
+type testWatcher struct {
+	target string
+	args   ParsedArgs
+	filter *TestCaseFilter
+	files  []string            // test files found in the target
+	deps   map[string][]string // test file -> included, called and data files it references
+}
+
+// runWatch runs every test case in the target, then re-runs the test files affected by each
+// change to a test file or a file it references. Enter forces a full run; Ctrl+C stops.
+func runWatch(ctx context.Context, target string, args ParsedArgs) {
+	filter, err := NewTestCaseFilter(args.filters)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(ExitUsageError)
+	}
+	w := &testWatcher{target: target, args: args, filter: filter}
+	if err := w.scan(); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(ExitUsageError)
+	}
+
+	forced := make(chan struct{}, 1)
+	go func() {
+		input := bufio.NewScanner(os.Stdin)
+		for input.Scan() {
+			select {
+			case forced <- struct{}{}:
+			default:
+			}
+		}
+	}()
+
+	fmt.Printf("Watching %s (press Enter to run everything, Ctrl+C to stop)\n", target)
+	stamps := w.run(ctx, w.files)
+
+	ticker := time.NewTicker(watchPollInterval)
+	defer ticker.Stop()
+	pending := map[string]bool{} // test files to re-run once changes settle
+	var lastChange time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-forced:
+			if err := w.scan(); err != nil {
+				fmt.Printf("[ERROR] %v\n", err)
+				continue
+			}
+			pending = map[string]bool{}
+			stamps = w.run(ctx, w.files)
+		case <-ticker.C:
+			current := w.stamps()
+			changed := changedFiles(stamps, current)
+			stamps = current
+			if len(changed) > 0 {
+				// Affected files are collected before and after rescanning: before, for
+				// references to deleted files, and after, for new test files and references
+				for _, file := range w.affected(changed) {
+					pending[file] = true
+				}
+				if err := w.scan(); err != nil {
+					fmt.Printf("[ERROR] %v\n", err)
+				}
+				for _, file := range w.affected(changed) {
+					pending[file] = true
+				}
+				stamps = w.stamps()
+				lastChange = time.Now()
+				continue
+			}
+			if len(pending) == 0 || time.Since(lastChange) < watchDebounce {
+				continue
+			}
+			var files []string
+			for _, file := range w.files {
+				if pending[file] {
+					files = append(files, file)
+				}
+			}
+			pending = map[string]bool{}
+			if len(files) > 0 {
+				stamps = w.run(ctx, files)
+			}
+		}
+	}
+}
+
+// scan finds the test files of the target and the files each one references
+func (w *testWatcher) scan() error {
+	files, err := DiscoverTestFiles(w.target, w.args.pattern)
+	if err != nil {
+		return err
+	}
+	w.files = files
+	w.deps = make(map[string][]string, len(files))
+	for _, file := range files {
+		w.deps[file] = testFileDependencies(file)
+	}
+	return nil
+}
+
+// stamps records the current version of every watched file; missing files are left out
+func (w *testWatcher) stamps() map[string]fileStamp {
+	stamps := map[string]fileStamp{}
+	add := func(path string) {
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+			stamps[path] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+		}
+	}
+	// The target is rediscovered so that new test files are noticed
+	if files, err := DiscoverTestFiles(w.target, w.args.pattern); err == nil {
+		for _, file := range files {
+			add(file)
+		}
+	}
+	for _, file := range w.files {
+		add(file)
+		for _, dep := range w.deps[file] {
+			add(dep)
+		}
+	}
+	return stamps
+}
+
+// changedFiles returns the files added, removed or modified between two sets of stamps
+func changedFiles(before, after map[string]fileStamp) map[string]bool {
+	changed := map[string]bool{}
+	for path, stamp := range after {
+		if previous, ok := before[path]; !ok || !previous.modTime.Equal(stamp.modTime) || previous.size != stamp.size {
+			changed[path] = true
+		}
+	}
+	for path := range before {
+		if _, ok := after[path]; !ok {
+			changed[path] = true
+		}
+	}
+	return changed
+}
+
+// affected returns the test files that changed or reference a changed file
+func (w *testWatcher) affected(changed map[string]bool) []string {
+	var files []string
+	for _, file := range w.files {
+		if changed[file] {
+			files = append(files, file)
+			continue
+		}
+		for _, dep := range w.deps[file] {
+			if changed[dep] {
+				files = append(files, file)
+				break
+			}
+		}
+	}
+	return files
+}
+
+// run runs the test cases of the given files and returns the file stamps afterwards, so
+// files written by the tests themselves, such as updated golden files, do not trigger
+// another run. Every test case gets a fresh runner, and sql connection pools are closed
+// at the end of the run, so nothing is carried over between runs.
+func (w *testWatcher) run(ctx context.Context, files []string) map[string]fileStamp {
+	fmt.Printf("\n[%s] Running %d test file(s)\n", time.Now().Format("15:04:05"), len(files))
+	passed, notPassed := 0, 0
+	for _, file := range files {
+		if ctx.Err() != nil {
+			break
+		}
+		testCases, err := LoadTestCases(file)
+		if err != nil {
+			fmt.Printf("✗ %-7s %s: %s\n", types.ActionStatusError, file, firstLine(err.Error()))
+			notPassed++
+			continue
+		}
+		for _, testCase := range testCases {
+			if ctx.Err() != nil {
+				break
+			}
+			if !w.filter.Matches(testCase.Name) || environmentSkipReason(testCase, w.args.environment) != "" {
+				continue
+			}
+			if w.runTestCase(ctx, file, testCase) {
+				passed++
+			} else {
+				notPassed++
+			}
+		}
+	}
+	actions.CloseSQLConnections()
+	// Every cycle requests fresh results, as a new run would
+	execution.ClearResultCache()
+	fmt.Printf("[%s] passed: %d, not passed: %d\n", time.Now().Format("15:04:05"), passed, notPassed)
+	return w.stamps()
+}
+
+// runTestCase runs one test case, printing its full output or, with --compact, one line.
+// Returns whether it passed.
+func (w *testWatcher) runTestCase(ctx context.Context, filename string, testCase *types.TestCase) bool {
+	runner := NewTestRunner()
+	runner.SetStrict(w.args.strict)
+	runner.SetMaxDataBytes(w.args.maxDataBytes)
+	if w.args.verbosity != "" {
+		verbosity, _ := execution.ParseVerbosity(w.args.verbosity) // validated in RunCLI
+		runner.SetVerbosity(verbosity)
+	}
+	if w.args.pluginsFile != "" {
+		if err := runner.LoadPlugins(w.args.pluginsFile); err != nil {
+			fmt.Printf("✗ %-7s %s: plugin configuration: %v\n", types.ActionStatusError, testCase.Name, err)
+			return false
+		}
+	}
+
+	if !w.args.compact {
+		result, err := runner.RunTest(ctx, filename, testCase)
+		if err != nil {
+			fmt.Printf("\nERROR: Test execution failed: %s\n", err.Error())
+			return false
+		}
+		printTestSummary(result)
+		return result.Status == string(types.ActionStatusPassed) || result.Status == "SKIPPED"
+	}
+
+	restoreStdout, err := discardStdout()
+	if err != nil {
+		fmt.Printf("✗ %-7s %s: %v\n", types.ActionStatusError, testCase.Name, err)
+		return false
+	}
+	result, err := runner.RunTest(ctx, filename, testCase)
+	restoreStdout()
+	if err != nil {
+		fmt.Printf("✗ %-7s %s (%s): %s\n", types.ActionStatusError, testCase.Name, filename, firstLine(err.Error()))
+		return false
+	}
+
+	passed := result.Status == string(types.ActionStatusPassed) || result.Status == "SKIPPED"
+	mark := "✓"
+	if !passed {
+		mark = "✗"
+	}
+	line := fmt.Sprintf("%s %-7s %s (%s, %s)", mark, result.Status, result.Name, filename, result.Duration.Round(time.Millisecond))
+	if message := firstFailureMessage(result); message != "" && !passed {
+		line += ": " + firstLine(message)
+	}
+	fmt.Println(line)
+	return passed
+}
+
+// testFileDependencies returns the files a test file references: included and called
+// files, recursively, and any string value that names an existing file or directory,
+// such as fixtures, request bodies and golden files. Paths are tried relative to the
+// file that declares them and as written, the two places file actions look.
+func testFileDependencies(filename string) []string {
+	seen := map[string]bool{filename: true}
+	var deps []string
+	var visit func(file string)
+	var walk func(file string, value any)
+
+	add := func(path string) {
+		if !seen[path] {
+			seen[path] = true
+			deps = append(deps, path)
+		}
+	}
+	visit = func(file string) {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return
+		}
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		for {
+			var document any
+			if decoder.Decode(&document) != nil {
+				return
+			}
+			walk(file, document)
+		}
+	}
+	walk = func(file string, value any) {
+		switch v := value.(type) {
+		case map[string]any:
+			for key, child := range v {
+				if path, ok := child.(string); ok && (key == "include" || key == "call") {
+					path = resolveCallPath(file, path)
+					if !seen[path] {
+						add(path)
+						visit(path)
+					}
+					continue
+				}
+				walk(file, child)
+			}
+		case []any:
+			for _, child := range v {
+				walk(file, child)
+			}
+		case string:
+			if !looksLikePath(v) {
+				return
+			}
+			for _, path := range []string{filepath.Clean(v), resolveCallPath(file, v)} {
+				info, err := os.Stat(path)
+				if err != nil || path == "." {
+					continue
+				}
+				if info.Mode().IsRegular() {
+					add(path)
+					continue
+				}
+				// A directory, e.g. of migrations: its files, but not subdirectories
+				entries, err := os.ReadDir(path)
+				if err != nil {
+					continue
+				}
+				for _, entry := range entries {
+					if entry.Type().IsRegular() {
+						add(filepath.Join(path, entry.Name()))
+					}
+				}
+			}
+		}
+	}
+
+	visit(filename)
+	sort.Strings(deps)
+	return deps
+}
+
+// looksLikePath reports whether a string value could be a local file path worth checking
+func looksLikePath(value string) bool {
+	return value != "" && len(value) < 1024 &&
+		!strings.ContainsAny(value, "\n${}") && !strings.Contains(value, "://")
+}
@@ -0,0 +1,8 @@
+#!/bin/sh
+# Sample pre_run hook. Prints the variables for the test cases as KEY=value lines;
+# a JSON object, such as the output of terraform output -json, works as well.
+# ROBOGO_RUN_TARGET and ROBOGO_ENVIRONMENT say what is about to run.
+echo "# sample stack for ${ROBOGO_ENVIRONMENT:-local}"
+echo "stack_id=sample-$$"
+echo "base_url=https://httpbin.org"
+echo 'export service_token="sample-token-123"'
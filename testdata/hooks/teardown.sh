@@ -0,0 +1,4 @@
+#!/bin/sh
+# Sample post_run hook. Runs after the last test case, also when tests failed or the
+# run was interrupted; the pre_run variables are still in the environment.
+echo "Removing ${stack_id}: ${ROBOGO_RUN_STATUS} (${ROBOGO_RUN_PASSED}/${ROBOGO_RUN_TOTAL} passed, ${ROBOGO_RUN_DURATION_MS}ms)"
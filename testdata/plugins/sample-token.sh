@@ -0,0 +1,14 @@
+#!/bin/sh
+# Sample robogo plugin. The request arrives as JSON on stdin:
+#   {"action": "...", "args": [...], "options": {...}, "variables": {...}}
+# and an ActionResult must be written as JSON to stdout.
+request=$(cat)
+
+case "$request" in
+  *'"scope":"admin"'*)
+    echo '{"status": "FAIL", "failure_info": {"category": "business_rule", "code": "SCOPE_DENIED", "message": "scope admin is not allowed"}}'
+    ;;
+  *)
+    echo '{"status": "PASS", "data": {"token": "sample-token-123", "expires_in": 3600}}'
+    ;;
+esac